@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gost-panel/internal/model"
+	"gost-panel/pkg/client"
+)
+
+// runLogin 登录面板并保存上下文
+//
+//	gostctl login -server http://panel:39100 [-name prod] [-u admin] [-p 密码]
+//	gostctl login -server http://panel:39100 -token gpt_xxx
+func runLogin(args []string) error {
+	fs, opts := newFlagSet("login")
+	name := fs.String("name", "", "上下文名称（默认使用面板主机名）")
+	username := fs.String("u", "admin", "用户名")
+	password := fs.String("p", os.Getenv("GOSTCTL_PASSWORD"), "密码（默认从 GOSTCTL_PASSWORD 或标准输入读取）")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+
+	// 未指定地址时重新登录当前上下文
+	ctxName := *name
+	if ctxName == "" {
+		ctxName = opts.context
+	}
+	server := strings.TrimRight(opts.server, "/")
+	if server == "" {
+		if ctxName == "" {
+			ctxName = cfg.CurrentContext
+		}
+		existing := cfg.find(ctxName)
+		if existing == nil {
+			return fmt.Errorf("请使用 -server 指定面板地址")
+		}
+		server = existing.Server
+	}
+	if ctxName == "" {
+		ctxName = contextNameFor(server)
+	}
+
+	c := client.New(server, client.WithTimeout(opts.timeout))
+	saved := Context{Name: ctxName, Server: server}
+
+	if opts.token != "" {
+		// API Token：校验可用后保存
+		c.SetToken(opts.token)
+		info, err := c.UserInfo(context.Background())
+		if err != nil {
+			return fmt.Errorf("Token 校验失败: %w", err)
+		}
+		saved.Token = opts.token
+		saved.Username = info.Username
+		if !strings.HasPrefix(opts.token, model.APITokenPrefix) {
+			fmt.Fprintln(os.Stderr, "提示: 保存的是登录 Token，过期后需要重新登录，自动化场景建议使用 API Token")
+		}
+	} else {
+		pwd := *password
+		if pwd == "" {
+			if pwd, err = readPassword(); err != nil {
+				return err
+			}
+		}
+		result, err := c.Login(context.Background(), *username, pwd)
+		if err != nil {
+			return fmt.Errorf("登录失败: %w", err)
+		}
+		saved.Token = result.Token
+		saved.Username = *username
+		saved.ExpireAt = result.ExpireAt
+	}
+
+	cfg.set(saved)
+	cfg.CurrentContext = ctxName
+	if err = cfg.save(opts.configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("已登录 %s (%s)，当前上下文: %s\n", server, saved.Username, ctxName)
+	return nil
+}
+
+// runLogout 清除上下文保存的 Token
+func runLogout(args []string) error {
+	fs, opts := newFlagSet("logout")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+	name := opts.context
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+	ctx := cfg.find(name)
+	if ctx == nil {
+		return fmt.Errorf("上下文 %s 不存在", name)
+	}
+
+	ctx.Token = ""
+	ctx.ExpireAt = 0
+	if err = cfg.save(opts.configPath); err != nil {
+		return err
+	}
+	fmt.Printf("已清除上下文 %s 的 Token\n", name)
+	return nil
+}
+
+// runContext 管理上下文
+//
+//	gostctl context list
+//	gostctl context use <名称>
+//	gostctl context delete <名称>
+func runContext(args []string) error {
+	fs, opts := newFlagSet("context")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		positional = []string{"list"}
+	}
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+
+	switch positional[0] {
+	case "list", "ls":
+		p, err := opts.printer()
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"CURRENT", "NAME", "SERVER", "USER", "AUTH"}}
+		type contextView struct {
+			Name     string `json:"name"`
+			Server   string `json:"server"`
+			Username string `json:"username"`
+			Auth     string `json:"auth"`
+			Current  bool   `json:"current"`
+		}
+		views := make([]contextView, 0, len(cfg.Contexts))
+		for _, c := range cfg.Contexts {
+			v := contextView{Name: c.Name, Server: c.Server, Username: c.Username, Auth: authKind(c), Current: c.Name == cfg.CurrentContext}
+			views = append(views, v)
+			current := ""
+			if v.Current {
+				current = "*"
+			}
+			t.add(current, v.Name, v.Server, orDash(v.Username), v.Auth)
+		}
+		return p.print(views, t)
+	case "use":
+		if len(positional) != 2 {
+			return fmt.Errorf("用法: gostctl context use <名称>")
+		}
+		if cfg.find(positional[1]) == nil {
+			return fmt.Errorf("上下文 %s 不存在", positional[1])
+		}
+		cfg.CurrentContext = positional[1]
+		if err = cfg.save(opts.configPath); err != nil {
+			return err
+		}
+		fmt.Printf("当前上下文: %s\n", positional[1])
+	case "delete", "rm":
+		if len(positional) != 2 {
+			return fmt.Errorf("用法: gostctl context delete <名称>")
+		}
+		if !cfg.remove(positional[1]) {
+			return fmt.Errorf("上下文 %s 不存在", positional[1])
+		}
+		if err = cfg.save(opts.configPath); err != nil {
+			return err
+		}
+		fmt.Printf("已删除上下文: %s\n", positional[1])
+	default:
+		return fmt.Errorf("未知子命令: %s（可用: list, use, delete）", positional[0])
+	}
+	return nil
+}
+
+// authKind 上下文的认证方式
+func authKind(c Context) string {
+	switch {
+	case c.Token == "":
+		return "未登录"
+	case strings.HasPrefix(c.Token, model.APITokenPrefix):
+		return "api-token"
+	default:
+		return "jwt"
+	}
+}
+
+// contextNameFor 由面板地址生成默认上下文名称
+func contextNameFor(server string) string {
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" {
+		return server
+	}
+	return u.Host
+}
+
+// readPassword 从标准输入读取密码
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "密码: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("读取密码失败: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gost-panel/pkg/client"
+)
+
+// runBackup 备份管理
+//
+//	gostctl backup create
+//	gostctl backup list
+//	gostctl backup download <文件名> [-f 本地文件]
+//	gostctl backup upload <本地文件> [-passphrase 口令]
+//	gostctl backup restore <文件名> [-passphrase 口令] -yes
+func runBackup(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: gostctl backup create|list|download|upload|restore")
+	}
+
+	fs, opts := newFlagSet("backup " + args[0])
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		_, c, _, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		if err = c.CreateBackup(ctx); err != nil {
+			return err
+		}
+		fmt.Println("备份成功")
+		return nil
+
+	case "list", "ls":
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		list, err := c.ListBackups(ctx)
+		if err != nil {
+			return err
+		}
+		return p.print(list, backupTable(list))
+
+	case "download":
+		file := fs.String("f", "", "保存路径（默认为备份文件名）")
+		positional, c, _, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("请指定备份文件名")
+		}
+		path := *file
+		if path == "" {
+			path = filepath.Base(positional[0])
+		}
+
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err = c.DownloadBackup(ctx, positional[0], f); err != nil {
+			_ = f.Close()
+			_ = os.Remove(path)
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("已下载到 %s\n", path)
+		return nil
+
+	case "upload":
+		passphrase := fs.String("passphrase", "", "加密备份的口令")
+		positional, c, _, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("请指定要上传的本地文件")
+		}
+
+		f, err := os.Open(positional[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		name, err := c.UploadBackup(ctx, filepath.Base(positional[0]), f, *passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已上传: %s\n", name)
+		return nil
+
+	case "restore":
+		passphrase := fs.String("passphrase", "", "加密备份的口令")
+		yes := fs.Bool("yes", false, "确认恢复（当前数据将被覆盖）")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("请指定备份文件名")
+		}
+		if !*yes {
+			return fmt.Errorf("恢复会覆盖当前数据（恢复前会自动创建安全备份），确认请加 -yes")
+		}
+
+		result, err := c.RestoreBackup(ctx, positional[0], *passphrase)
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"RESTORED", "SAFETY BACKUP", "TABLES", "TUNNELS", "RULES", "FAILED"}}
+		t.add(result.Restored, orDash(result.SafetyBackup), fmt.Sprint(result.Tables),
+			fmt.Sprint(result.Tunnels), fmt.Sprint(result.Rules), fmt.Sprint(result.Failed))
+		return p.print(result, t)
+
+	default:
+		return fmt.Errorf("未知子命令: %s", args[0])
+	}
+}
+
+// backupTable 备份文件表格
+func backupTable(list []client.BackupFile) *table {
+	t := &table{headers: []string{"NAME", "SIZE", "FORMAT", "ENCRYPTED", "TRIGGER", "CREATED"}}
+	for _, b := range list {
+		encrypted := "no"
+		if b.Encrypted {
+			encrypted = "yes"
+		}
+		t.add(b.Name, formatBytes(b.Size), b.Format, encrypted, orDash(b.Trigger), formatTime(b.CreatedAt))
+	}
+	return t
+}
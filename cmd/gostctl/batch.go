@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"gost-panel/pkg/client"
+)
+
+// batchVerbs 批量操作的中文名称
+var batchVerbs = map[string]string{
+	"start":   "启动",
+	"stop":    "停止",
+	"restart": "重启",
+	"delete":  "删除",
+	"drain":   "排空",
+}
+
+// batchAction 子命令对应的批量操作
+func batchAction(cmd string) string {
+	if cmd == "rm" {
+		return "delete"
+	}
+	return cmd
+}
+
+// printBatch 输出批量操作结果，有失败项时返回错误
+func printBatch(p *printer, result *client.BatchResult) error {
+	// 涉及多种资源时（如排空节点）显示资源类型
+	withResource := false
+	for _, r := range result.Results {
+		if r.Resource != "" {
+			withResource = true
+			break
+		}
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "RESULT", "ERROR"}}
+	if withResource {
+		t.headers = append([]string{"RESOURCE"}, t.headers...)
+	}
+	for _, r := range result.Results {
+		status := "ok"
+		switch {
+		case r.Skipped:
+			status = "skipped"
+		case !r.Success:
+			status = "failed"
+		}
+		row := []string{fmt.Sprint(r.ID), orDash(r.Name), status, orDash(r.Error)}
+		if withResource {
+			row = append([]string{r.Resource}, row...)
+		}
+		t.add(row...)
+	}
+	if err := p.print(result, t); err != nil {
+		return err
+	}
+	if p.format == formatTable {
+		fmt.Fprintf(p.w, "\n%s: 成功 %d, 跳过 %d, 失败 %d\n", batchVerbs[result.Action], result.Succeeded, result.Skipped, result.Failed)
+		if result.Snapshot != "" {
+			fmt.Fprintf(p.w, "删除前快照: %s\n", result.Snapshot)
+		}
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%s失败 %d 个", batchVerbs[result.Action], result.Failed)
+	}
+	return nil
+}
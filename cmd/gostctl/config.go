@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 配置文件，保存多个面板上下文
+type Config struct {
+	CurrentContext string    `yaml:"current-context"`
+	Contexts       []Context `yaml:"contexts"`
+}
+
+// Context 面板上下文
+type Context struct {
+	Name     string `yaml:"name"`                // 上下文名称
+	Server   string `yaml:"server"`              // 面板地址
+	Token    string `yaml:"token,omitempty"`     // JWT 或 API Token
+	Username string `yaml:"username,omitempty"`  // 登录用户名（仅用于显示）
+	ExpireAt int64  `yaml:"expire_at,omitempty"` // JWT 过期时间戳，API Token 为 0
+}
+
+// defaultConfigPath 默认配置文件路径
+func defaultConfigPath() string {
+	if p := os.Getenv("GOSTCTL_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gostctl.yaml"
+	}
+	return filepath.Join(dir, "gostctl", "config.yaml")
+}
+
+// loadConfig 读取配置文件，文件不存在时返回空配置
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// save 保存配置文件，文件包含 Token，仅当前用户可读写
+func (c *Config) save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// find 按名称查找上下文
+func (c *Config) find(name string) *Context {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// set 新增或替换上下文
+func (c *Config) set(ctx Context) {
+	if existing := c.find(ctx.Name); existing != nil {
+		*existing = ctx
+		return
+	}
+	c.Contexts = append(c.Contexts, ctx)
+}
+
+// remove 删除上下文，返回是否存在
+func (c *Config) remove(name string) bool {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			if c.CurrentContext == name {
+				c.CurrentContext = ""
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gost-panel/internal/dto"
+	"gost-panel/pkg/client"
+)
+
+// runIPLists 命名 IP 列表管理
+func runIPLists(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: gostctl iplists list|get|create|update|delete")
+	}
+
+	fs, opts := newFlagSet("iplists " + args[0])
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list", "ls":
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		lists, err := c.ListIPLists(ctx)
+		if err != nil {
+			return err
+		}
+		return p.print(lists, ipListTable(lists))
+
+	case "get":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		list, err := c.GetIPList(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(list, ipListTable([]client.IPList{*list}))
+
+	case "create":
+		req := &dto.CreateIPListReq{}
+		var entries stringList
+		fs.StringVar(&req.Name, "name", "", "列表名称")
+		fs.Var(&entries, "entry", "IP 或 CIDR，可重复或逗号分隔")
+		fs.StringVar(&req.Remark, "remark", "", "备注")
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		req.Entries = entries
+
+		list, err := c.CreateIPList(ctx, req)
+		if err != nil {
+			return err
+		}
+		return p.print(list, ipListTable([]client.IPList{*list}))
+
+	case "update":
+		name := fs.String("name", "", "列表名称")
+		var entries, add, remove stringList
+		fs.Var(&entries, "entry", "IP 或 CIDR，可重复（替换原有条目）")
+		fs.Var(&add, "add", "追加的 IP 或 CIDR，可重复")
+		fs.Var(&remove, "remove", "移除的 IP 或 CIDR，可重复")
+		remark := fs.String("remark", "", "备注")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+
+		// 未指定的字段保持原值
+		list, err := c.GetIPList(ctx, id)
+		if err != nil {
+			return err
+		}
+		req := &dto.UpdateIPListReq{Name: list.Name, Entries: list.Entries, Remark: list.Remark}
+		set := setFlags(fs)
+		if set["name"] {
+			req.Name = *name
+		}
+		if set["entry"] {
+			req.Entries = entries
+		}
+		req.Entries = append(req.Entries, add...)
+		if len(remove) > 0 {
+			req.Entries = slices.DeleteFunc(slices.Clone(req.Entries), func(entry string) bool {
+				return slices.Contains(remove, entry)
+			})
+		}
+		if set["remark"] {
+			req.Remark = *remark
+		}
+
+		list, err = c.UpdateIPList(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return p.print(list, ipListTable([]client.IPList{*list}))
+
+	case "delete", "rm":
+		positional, c, _, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		ids, err := parseIDs(positional)
+		if err != nil {
+			return err
+		}
+		return eachID(ids, "删除", func(id uint) error {
+			return c.DeleteIPList(ctx, id)
+		})
+
+	default:
+		return fmt.Errorf("未知子命令: %s", args[0])
+	}
+}
+
+// ipListTable IP 列表表格
+func ipListTable(lists []client.IPList) *table {
+	t := &table{headers: []string{"ID", "NAME", "ENTRIES", "REMARK", "UPDATED"}}
+	for _, l := range lists {
+		t.add(fmt.Sprint(l.ID), l.Name, truncate(strings.Join(l.Entries, ","), 60), truncate(orDash(l.Remark), 30), formatTime(l.UpdatedAt))
+	}
+	return t
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"gost-panel/internal/dto"
+	"gost-panel/pkg/client"
+)
+
+// runLatency 延迟统计，隧道中表现最好的出口以 * 标记
+//
+//	gostctl latency [-path node|target|tunnel] [-hours 1] [-node ID] [-rule ID] [-tunnel ID]
+func runLatency(args []string) error {
+	fs, opts := newFlagSet("latency")
+	req := &dto.LatencyStatsReq{}
+	fs.StringVar(&req.Path, "path", "", "路径类型: node | target | tunnel")
+	fs.IntVar(&req.Hours, "hours", 1, "统计最近 N 小时（1-168）")
+	fs.UintVar(&req.NodeID, "node", 0, "节点 ID 筛选（隧道路径为出口节点）")
+	fs.UintVar(&req.RuleID, "rule", 0, "规则 ID 筛选")
+	fs.UintVar(&req.TunnelID, "tunnel", 0, "隧道 ID 筛选")
+	_, c, p, err := setup(fs, opts, args)
+	if err != nil {
+		return err
+	}
+
+	stats, err := c.LatencyStats(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	return p.print(stats, latencyTable(stats))
+}
+
+// latencyTable 延迟统计表格
+func latencyTable(stats []client.LatencyStats) *table {
+	t := &table{headers: []string{"PATH", "NAME", "TARGET", "SAMPLES", "LOSS", "MIN", "AVG", "MAX", "BEST"}}
+	for _, s := range stats {
+		name := s.NodeName
+		switch s.Path {
+		case "target":
+			name = s.RuleName
+		case "tunnel":
+			name = s.TunnelName
+		}
+		best := ""
+		if s.Best {
+			best = "*"
+		}
+		t.add(s.Path, orDash(name), truncate(s.Target, 40), fmt.Sprint(s.Samples), strconv.FormatFloat(s.LossRate, 'f', -1, 64)+"%",
+			formatMs(s.MinMs), formatMs(s.AvgMs), formatMs(s.MaxMs), best)
+	}
+	return t
+}
+
+// formatMs 格式化延迟，没有成功的探测时显示 -
+func formatMs(ms *float64) string {
+	if ms == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*ms, 'f', 2, 64) + "ms"
+}
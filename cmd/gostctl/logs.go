@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/pkg/client"
+)
+
+// followPageSize 持续输出时每次拉取的数量
+const followPageSize = 100
+
+// runLogs 操作日志
+//
+//	gostctl logs [-n 20] [-user admin] [-action create] [-resource rule]
+//	gostctl logs -f [-interval 5s]
+func runLogs(args []string) error {
+	fs, opts := newFlagSet("logs")
+	req := &dto.LogListReq{Page: 1}
+	fs.IntVar(&req.PageSize, "n", 20, "显示最近 N 条")
+	fs.StringVar(&req.Username, "user", "", "用户名筛选")
+	fs.StringVar(&req.Action, "action", "", "操作类型筛选")
+	fs.StringVar(&req.ResourceType, "resource", "", "资源类型筛选")
+	follow := fs.Bool("f", false, "持续输出新日志")
+	interval := fs.Duration("interval", 5*time.Second, "持续输出时的轮询间隔")
+	_, c, p, err := setup(fs, opts, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	page, err := c.ListLogs(ctx, req)
+	if err != nil {
+		return err
+	}
+	logs := reverseLogs(page.List)
+
+	if !*follow {
+		return p.print(logs, logTable(logs, true))
+	}
+
+	// 持续输出：表格不重复打印表头，JSON 每行一条
+	var lastID uint
+	emit := func(list []client.OperationLog, header bool) error {
+		if len(list) == 0 {
+			return nil
+		}
+		lastID = list[len(list)-1].ID
+		if p.format == formatJSON {
+			for _, l := range list {
+				data, err := json.Marshal(l)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(p.w, string(data))
+			}
+			return nil
+		}
+		return p.print(list, logTable(list, header))
+	}
+	if err = emit(logs, true); err != nil {
+		return err
+	}
+
+	req.PageSize = followPageSize
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		page, err = c.ListLogs(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "获取日志失败: %v\n", err)
+			continue
+		}
+
+		var fresh []client.OperationLog
+		for _, l := range reverseLogs(page.List) {
+			if l.ID > lastID {
+				fresh = append(fresh, l)
+			}
+		}
+		if err = emit(fresh, false); err != nil {
+			return err
+		}
+	}
+}
+
+// reverseLogs 接口按时间倒序返回，转为从旧到新
+func reverseLogs(list []client.OperationLog) []client.OperationLog {
+	out := make([]client.OperationLog, len(list))
+	for i, l := range list {
+		out[len(list)-1-i] = l
+	}
+	return out
+}
+
+// logTable 日志表格
+func logTable(logs []client.OperationLog, header bool) *table {
+	t := &table{}
+	if header {
+		t.headers = []string{"TIME", "USER", "ACTION", "RESOURCE", "IP", "DETAILS"}
+	}
+	for _, l := range logs {
+		resource := l.ResourceType
+		if l.ResourceID > 0 {
+			resource = fmt.Sprintf("%s:%d", l.ResourceType, l.ResourceID)
+		}
+		t.add(formatTime(l.CreatedAt), l.Username, l.Action, orDash(resource), orDash(l.IPAddress), truncate(l.Details, 60))
+	}
+	return t
+}
@@ -0,0 +1,88 @@
+// gostctl GOST Panel 命令行工具
+// 通过面板 API 管理节点、规则、隧道，查看流量与操作日志，触发备份。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `gostctl - GOST Panel 命令行工具
+
+用法:
+  gostctl <命令> [子命令] [参数]
+
+认证与上下文:
+  login     登录面板并保存为上下文（用户名密码或 API Token）
+  logout    清除当前上下文保存的 Token
+  context   管理上下文: list | use <名称> | delete <名称>
+
+资源管理:
+  nodes     节点: list | get | create | update | delete | config | ports | maintenance | drain
+  rules     规则: list | get | targets | create | update | clone | migrate | delete | start | stop | restart
+  iplists   命名 IP 列表: list | get | create | update | delete
+  tunnels   隧道: list | get | create | update | rotate | delete | start | stop | restart
+            delete/start/stop/restart 可指定多个 ID 或按筛选条件批量执行
+
+运维:
+  traffic   流量统计
+  latency   节点、转发目标与隧道出口的延迟与丢失率
+  logs      操作日志（-f 持续输出）
+  backup    备份: create | list | download | upload | restore
+
+通用参数:
+  -o table|json|yaml   输出格式（默认 table）
+  -context <名称>      使用指定上下文
+  -config <文件>       配置文件（默认 ~/.config/gostctl/config.yaml）
+  -server / -token     临时指定面板地址和 Token
+
+环境变量 GOSTCTL_CONFIG、GOSTCTL_CONTEXT、GOSTCTL_SERVER、GOSTCTL_TOKEN 与对应参数作用相同。
+使用 "gostctl <命令> -h" 查看命令参数。
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run 分发命令
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Print(usage)
+		return nil
+	}
+
+	switch args[0] {
+	case "login":
+		return runLogin(args[1:])
+	case "logout":
+		return runLogout(args[1:])
+	case "context", "ctx":
+		return runContext(args[1:])
+	case "nodes", "node":
+		return runNodes(args[1:])
+	case "rules", "rule":
+		return runRules(args[1:])
+	case "iplists", "iplist":
+		return runIPLists(args[1:])
+	case "tunnels", "tunnel":
+		return runTunnels(args[1:])
+	case "traffic":
+		return runTraffic(args[1:])
+	case "latency":
+		return runLatency(args[1:])
+	case "logs", "log":
+		return runLogs(args[1:])
+	case "backup", "backups":
+		return runBackup(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return nil
+	default:
+		return fmt.Errorf("未知命令: %s（使用 gostctl help 查看帮助）", args[0])
+	}
+}
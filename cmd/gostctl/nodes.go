@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"gost-panel/internal/dto"
+	"gost-panel/pkg/client"
+)
+
+// runNodes 节点管理
+func runNodes(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: gostctl nodes list|get|create|update|delete|config|ports|maintenance|drain")
+	}
+
+	fs, opts := newFlagSet("nodes " + args[0])
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list", "ls":
+		req := &dto.NodeListReq{}
+		fs.IntVar(&req.Page, "page", 1, "页码")
+		fs.IntVar(&req.PageSize, "size", 20, "每页数量")
+		fs.StringVar(&req.Status, "status", "", "状态筛选: online | offline | error")
+		fs.StringVar(&req.Keyword, "keyword", "", "关键词")
+		all := fs.Bool("all", false, "获取全部")
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		nodes, total, err := listAll(*all, &req.Page, &req.PageSize, func() (*client.Page[client.Node], error) {
+			return c.ListNodes(ctx, req)
+		})
+		if err != nil {
+			return err
+		}
+		return p.print(nodes, nodeTable(nodes, total))
+
+	case "get":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		node, err := c.GetNode(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(node, nodeTable([]client.Node{*node}, 0))
+
+	case "create":
+		req := &dto.CreateNodeReq{}
+		fs.StringVar(&req.Name, "name", "", "节点名称")
+		fs.StringVar(&req.Address, "address", "", "IP 或域名")
+		fs.IntVar(&req.Port, "port", 0, "GOST API 端口")
+		fs.StringVar(&req.Username, "username", "", "API 认证用户名")
+		fs.StringVar(&req.Password, "password", "", "API 认证密码")
+		fs.StringVar(&req.Remark, "remark", "", "备注")
+		fs.StringVar(&req.PortRanges, "port-ranges", "", "端口池范围，如 10000-20000,30000")
+		fs.StringVar(&req.ReservedPorts, "reserved-ports", "", "保留端口，如 22,80,443")
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		node, err := c.CreateNode(ctx, req)
+		if err != nil {
+			return err
+		}
+		return p.print(node, nodeTable([]client.Node{*node}, 0))
+
+	case "update":
+		name := fs.String("name", "", "节点名称")
+		address := fs.String("address", "", "IP 或域名")
+		port := fs.Int("port", 0, "GOST API 端口")
+		username := fs.String("username", "", "API 认证用户名")
+		password := fs.String("password", "", "API 认证密码")
+		remark := fs.String("remark", "", "备注")
+		portRanges := fs.String("port-ranges", "", "端口池范围，如 10000-20000,30000（空字符串表示不限制）")
+		reservedPorts := fs.String("reserved-ports", "", "保留端口，如 22,80,443")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+
+		// 未指定的字段保持原值
+		node, err := c.GetNode(ctx, id)
+		if err != nil {
+			return err
+		}
+		req := &dto.UpdateNodeReq{
+			Name: node.Name, Address: node.Address, Port: node.Port,
+			Username: node.Username, Password: node.Password, Remark: node.Remark,
+			PortRanges: node.PortRanges, ReservedPorts: node.ReservedPorts,
+		}
+		set := setFlags(fs)
+		if set["name"] {
+			req.Name = *name
+		}
+		if set["address"] {
+			req.Address = *address
+		}
+		if set["port"] {
+			req.Port = *port
+		}
+		if set["username"] {
+			req.Username = *username
+		}
+		if set["password"] {
+			req.Password = *password
+		}
+		if set["remark"] {
+			req.Remark = *remark
+		}
+		if set["port-ranges"] {
+			req.PortRanges = *portRanges
+		}
+		if set["reserved-ports"] {
+			req.ReservedPorts = *reservedPorts
+		}
+
+		node, err = c.UpdateNode(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return p.print(node, nodeTable([]client.Node{*node}, 0))
+
+	case "delete", "rm":
+		positional, c, _, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		ids, err := parseIDs(positional)
+		if err != nil {
+			return err
+		}
+		return eachID(ids, "删除", func(id uint) error {
+			return c.DeleteNode(ctx, id)
+		})
+
+	case "maintenance", "maint":
+		reason := fs.String("reason", "", "维护原因")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 2 || (positional[1] != "on" && positional[1] != "off") {
+			return fmt.Errorf("用法: gostctl nodes maintenance <ID> on|off [-reason 原因]")
+		}
+		id, err := parseID(positional[:1])
+		if err != nil {
+			return err
+		}
+		node, err := c.SetNodeMaintenance(ctx, id, &dto.NodeMaintenanceReq{Enabled: positional[1] == "on", Reason: *reason})
+		if err != nil {
+			return err
+		}
+		return p.print(node, nodeTable([]client.Node{*node}, 0))
+
+	case "drain":
+		req := &dto.DrainNodeReq{}
+		fs.UintVar(&req.TargetNodeID, "to", 0, "替换节点 ID")
+		fs.BoolVar(&req.KeepStats, "keep-stats", false, "迁移规则时保留流量计数")
+		fs.BoolVar(&req.AutoPort, "auto-port", false, "端口在替换节点上冲突时自动分配")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		if req.TargetNodeID == 0 {
+			return fmt.Errorf("请用 -to 指定替换节点")
+		}
+		result, err := c.DrainNode(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return printBatch(p, result)
+
+	case "ports":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		ports, err := c.GetNodePorts(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(ports, portsTable(ports))
+
+	case "config":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		cfg, err := c.GetNodeConfig(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(cfg, nil)
+
+	default:
+		return fmt.Errorf("未知子命令: %s", args[0])
+	}
+}
+
+// nodeTable 节点表格
+func nodeTable(nodes []client.Node, total int64) *table {
+	t := &table{headers: []string{"ID", "NAME", "ADDRESS", "STATUS", "INPUT", "OUTPUT", "LAST CHECK", "REMARK"}}
+	for _, n := range nodes {
+		status := string(n.Status)
+		if n.Maintenance {
+			status += " (maintenance)"
+		}
+		t.add(fmt.Sprint(n.ID), n.Name, fmt.Sprintf("%s:%d", n.Address, n.Port), status,
+			formatBytes(n.InputBytes), formatBytes(n.OutputBytes), formatTimePtr(n.LastCheckAt), orDash(truncate(n.Remark, 30)))
+	}
+	addTotal(t, len(nodes), total)
+	return t
+}
+
+// portsTable 节点端口占用表格，末尾给出端口池和下一个可分配端口
+func portsTable(ports *client.NodePorts) *table {
+	t := &table{headers: []string{"PORT", "OWNER"}}
+	for _, u := range ports.Used {
+		t.add(fmt.Sprint(u.Port), u.Owner)
+	}
+	next := "无可用端口"
+	if ports.NextFree > 0 {
+		next = fmt.Sprint(ports.NextFree)
+	}
+	t.add(fmt.Sprintf("（端口池: %s，保留: %s，下一个可用: %s）", orDash(ports.PortRanges), orDash(ports.ReservedPorts), next))
+	return t
+}
+
+// addTotal 列表未显示全部时在表格末尾提示总数
+func addTotal(t *table, shown int, total int64) {
+	if total > int64(shown) {
+		t.add(fmt.Sprintf("（共 %d 条，显示 %d 条）", total, shown))
+	}
+}
+
+// listAll 获取列表，all 为 true 时逐页获取全部
+func listAll[T any](all bool, page, pageSize *int, fetch func() (*client.Page[T], error)) ([]T, int64, error) {
+	if all {
+		*page, *pageSize = 1, 100
+	}
+
+	var list []T
+	for {
+		result, err := fetch()
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, result.List...)
+		if !all || len(result.List) == 0 || int64(len(list)) >= result.Total {
+			return list, result.Total, nil
+		}
+		*page++
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/pkg/client"
+)
+
+// globalOptions 所有命令通用的参数
+type globalOptions struct {
+	configPath string
+	context    string
+	server     string
+	token      string
+	output     string
+	timeout    time.Duration
+}
+
+// newFlagSet 创建命令参数集并注册通用参数
+func newFlagSet(name string) (*flag.FlagSet, *globalOptions) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts := &globalOptions{}
+	fs.StringVar(&opts.configPath, "config", defaultConfigPath(), "配置文件")
+	fs.StringVar(&opts.context, "context", os.Getenv("GOSTCTL_CONTEXT"), "使用的上下文（默认当前上下文）")
+	fs.StringVar(&opts.server, "server", os.Getenv("GOSTCTL_SERVER"), "面板地址，覆盖上下文")
+	fs.StringVar(&opts.token, "token", os.Getenv("GOSTCTL_TOKEN"), "Token，覆盖上下文")
+	fs.StringVar(&opts.output, "o", "table", "输出格式: table | json | yaml")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "请求超时")
+	return fs, opts
+}
+
+// parseArgs 解析参数，允许参数与位置参数混排（如 rules start 1 2 -o json）
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// setFlags 返回显式设置过的参数名
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// resolveContext 确定使用的上下文，-server/-token 优先于配置文件
+func (o *globalOptions) resolveContext() (*Context, error) {
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := o.context
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+
+	ctx := &Context{}
+	if name != "" {
+		found := cfg.find(name)
+		if found == nil && o.context != "" {
+			return nil, fmt.Errorf("上下文 %s 不存在", name)
+		}
+		if found != nil {
+			*ctx = *found
+		}
+	}
+	if o.server != "" {
+		ctx.Server = o.server
+	}
+	if o.token != "" {
+		ctx.Token = o.token
+	}
+
+	if ctx.Server == "" {
+		return nil, fmt.Errorf("未配置面板地址，请先执行 gostctl login -server <地址>")
+	}
+	return ctx, nil
+}
+
+// client 创建 API 客户端
+func (o *globalOptions) client() (*client.Client, error) {
+	ctx, err := o.resolveContext()
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Token == "" {
+		return nil, fmt.Errorf("上下文 %s 未登录，请先执行 gostctl login", ctx.Name)
+	}
+	if ctx.ExpireAt > 0 && time.Now().Unix() >= ctx.ExpireAt {
+		return nil, fmt.Errorf("上下文 %s 的登录已过期，请重新执行 gostctl login", ctx.Name)
+	}
+	return client.New(ctx.Server, client.WithToken(ctx.Token), client.WithTimeout(o.timeout)), nil
+}
+
+// printer 创建输出器
+func (o *globalOptions) printer() (*printer, error) {
+	switch o.output {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{format: o.output, w: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("不支持的输出格式: %s", o.output)
+	}
+}
+
+// setup 解析参数并创建客户端与输出器，供资源命令使用
+func setup(fs *flag.FlagSet, opts *globalOptions, args []string) ([]string, *client.Client, *printer, error) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p, err := opts.printer()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c, err := opts.client()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return positional, c, p, nil
+}
+
+// parseIDs 解析资源 ID 列表
+func parseIDs(args []string) ([]uint, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("请指定资源 ID")
+	}
+	ids := make([]uint, 0, len(args))
+	for _, a := range args {
+		id, err := strconv.ParseUint(a, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("无效的 ID: %s", a)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// parseID 解析单个资源 ID
+func parseID(args []string) (uint, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("请指定一个资源 ID")
+	}
+	ids, err := parseIDs(args)
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// optionalID 未指定（0）时返回 nil
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// eachID 对每个 ID 执行操作，全部执行后汇总错误
+func eachID(ids []uint, action string, fn func(id uint) error) error {
+	failed := 0
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%d: %s失败: %v\n", id, action, err)
+			continue
+		}
+		fmt.Printf("%d: %s成功\n", id, action)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个操作失败", failed)
+	}
+	return nil
+}
+
+// stringList 可重复的字符串参数，也支持逗号分隔
+type stringList []string
+
+// String 实现 flag.Value
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set 实现 flag.Value
+func (s *stringList) Set(v string) error {
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*s = append(*s, item)
+		}
+	}
+	return nil
+}
+
+// idList 可重复的资源 ID 参数，也支持逗号分隔
+type idList []uint
+
+// String 实现 flag.Value
+func (l *idList) String() string {
+	ids := make([]string, len(*l))
+	for i, id := range *l {
+		ids[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(ids, ",")
+}
+
+// Set 实现 flag.Value
+func (l *idList) Set(v string) error {
+	var items stringList
+	_ = items.Set(v)
+	if len(items) == 0 {
+		return nil
+	}
+	ids, err := parseIDs(items)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, ids...)
+	return nil
+}
+
+// proxyUsers 代理认证用户参数，格式 user:password，可重复
+type proxyUsers []dto.ProxyUser
+
+// String 实现 flag.Value
+func (u *proxyUsers) String() string {
+	names := make([]string, len(*u))
+	for i, user := range *u {
+		names[i] = user.Username
+	}
+	return strings.Join(names, ",")
+}
+
+// Set 实现 flag.Value
+func (u *proxyUsers) Set(v string) error {
+	username, password, found := strings.Cut(v, ":")
+	if !found || username == "" || password == "" {
+		return fmt.Errorf("代理用户格式应为 user:password: %s", v)
+	}
+	*u = append(*u, dto.ProxyUser{Username: username, Password: password})
+	return nil
+}
+
+// targetWeights 目标权重参数，格式 host:port=N，可重复
+type targetWeights []dto.TargetOption
+
+// String 实现 flag.Value
+func (w *targetWeights) String() string {
+	items := make([]string, len(*w))
+	for i, opt := range *w {
+		items[i] = fmt.Sprintf("%s=%d", opt.Target, opt.Weight)
+	}
+	return strings.Join(items, ",")
+}
+
+// Set 实现 flag.Value
+func (w *targetWeights) Set(v string) error {
+	target, weight, found := strings.Cut(v, "=")
+	n, err := strconv.Atoi(strings.TrimSpace(weight))
+	if !found || strings.TrimSpace(target) == "" || err != nil {
+		return fmt.Errorf("目标权重格式应为 host:port=N: %s", v)
+	}
+	*w = append(*w, dto.TargetOption{Target: strings.TrimSpace(target), Weight: n})
+	return nil
+}
+
+// buildTargetOptions 合并目标权重与备用目标参数
+func buildTargetOptions(weights targetWeights, backups stringList) []dto.TargetOption {
+	options := append([]dto.TargetOption(nil), weights...)
+	for _, target := range backups {
+		found := false
+		for i := range options {
+			if options[i].Target == target {
+				options[i].Backup, found = true, true
+			}
+		}
+		if !found {
+			options = append(options, dto.TargetOption{Target: target, Backup: true})
+		}
+	}
+	return options
+}
+
+// portRange 端口或端口范围参数，如 8080 或 27015-27030
+type portRange struct {
+	start, end int
+}
+
+// String 实现 flag.Value
+func (p *portRange) String() string {
+	if p.end > p.start {
+		return fmt.Sprintf("%d-%d", p.start, p.end)
+	}
+	return strconv.Itoa(p.start)
+}
+
+// Set 实现 flag.Value
+func (p *portRange) Set(v string) error {
+	start, end, isRange := strings.Cut(v, "-")
+	var err error
+	if p.start, err = strconv.Atoi(strings.TrimSpace(start)); err != nil {
+		return fmt.Errorf("无效的端口: %s", v)
+	}
+	p.end = 0
+	if isRange {
+		if p.end, err = strconv.Atoi(strings.TrimSpace(end)); err != nil {
+			return fmt.Errorf("无效的端口范围: %s", v)
+		}
+	}
+	return nil
+}
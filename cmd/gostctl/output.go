@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// printer 按输出格式打印结果
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// table 表格数据
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// add 添加一行
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// print 输出结果，table 格式打印 t，json/yaml 格式序列化 v
+// t 为 nil 时（无表格形式的数据）table 格式按 JSON 输出
+func (p *printer) print(v any, t *table) error {
+	format := p.format
+	if format == formatTable && t == nil {
+		format = formatJSON
+	}
+
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		// 先转为 JSON 结构，使字段名与 API 一致
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err = json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(out)
+		return err
+	default:
+		return p.table(t)
+	}
+}
+
+// table 打印表格
+func (p *printer) table(t *table) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if len(t.headers) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// formatBytes 格式化字节数
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatTime 格式化时间
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// formatTimePtr 格式化可能为空的时间
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return formatTime(*t)
+}
+
+// formatIDPtr 格式化可能为空的 ID
+func formatIDPtr(id *uint) string {
+	if id == nil {
+		return "-"
+	}
+	return fmt.Sprint(*id)
+}
+
+// orDash 空字符串显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// truncate 截断过长的文本
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
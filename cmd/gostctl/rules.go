@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/client"
+)
+
+// runRules 规则管理
+func runRules(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: gostctl rules list|get|targets|create|update|clone|migrate|delete|start|stop|restart")
+	}
+
+	fs, opts := newFlagSet("rules " + args[0])
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list", "ls":
+		req := &dto.RuleListReq{}
+		fs.IntVar(&req.Page, "page", 1, "页码")
+		fs.IntVar(&req.PageSize, "size", 20, "每页数量")
+		fs.UintVar(&req.NodeID, "node", 0, "节点 ID 筛选")
+		fs.UintVar(&req.TunnelID, "tunnel", 0, "隧道 ID 筛选")
+		fs.StringVar(&req.Type, "type", "", "类型筛选: forward | tunnel | proxy | reverse")
+		fs.StringVar(&req.Status, "status", "", "状态筛选: running | stopped | error")
+		fs.StringVar(&req.Keyword, "keyword", "", "关键词")
+		all := fs.Bool("all", false, "获取全部")
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		rules, total, err := listAll(*all, &req.Page, &req.PageSize, func() (*client.Page[client.Rule], error) {
+			return c.ListRules(ctx, req)
+		})
+		if err != nil {
+			return err
+		}
+		return p.print(rules, ruleTable(rules, total))
+
+	case "get":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		rule, err := c.GetRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(rule, ruleTable([]client.Rule{*rule}, 0))
+
+	case "targets":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		resp, err := c.GetRuleTargets(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(resp, ruleTargetTable(resp))
+
+	case "create":
+		req := &dto.CreateRuleReq{}
+		var targets stringList
+		nodeID := fs.Uint("node", 0, "入口节点 ID（端口转发）")
+		tunnelID := fs.Uint("tunnel", 0, "隧道 ID（隧道转发）")
+		fs.StringVar(&req.Name, "name", "", "规则名称")
+		fs.StringVar(&req.Type, "type", "", "规则类型: forward | tunnel | proxy | reverse（默认按 -node/-tunnel/-user/-host 推断）")
+		var listen portRange
+		fs.Var(&listen, "port", "监听端口或端口范围，如 8080、27015-27030（不指定时从入口节点端口池自动分配）")
+		fs.StringVar(&req.PortMapping, "map", "", "端口范围的目标映射: range（1:1，默认）| single（全部转发到目标端口）")
+		fs.StringVar(&req.Protocol, "protocol", "", "转发协议: tcp | udp | both（默认）")
+		fs.StringVar(&req.ProxyType, "proxy-type", "", "代理类型: http | socks5 | auto（默认）")
+		var users proxyUsers
+		fs.Var(&users, "user", "代理认证用户 user:password，可重复")
+		fs.StringVar(&req.Host, "host", "", "反向代理域名，如 example.com、*.example.com")
+		fs.Var(&targets, "target", "目标地址 host:port，可重复或逗号分隔")
+		fs.StringVar(&req.Strategy, "strategy", "", "负载均衡策略: round | rand | fifo | hash")
+		fs.BoolVar(&req.EnableTLS, "tls", false, "启用 TLS")
+		fs.IntVar(&req.MaxFails, "max-fails", 0, "目标连续失败多少次后标记失效（默认 3）")
+		fs.IntVar(&req.FailTimeout, "fail-timeout", 0, "失效目标多少秒后重新尝试（默认 30）")
+		var weights targetWeights
+		fs.Var(&weights, "weight", "目标权重 host:port=N，可重复")
+		var backups stringList
+		fs.Var(&backups, "backup", "备用目标 host:port，其他目标全部失效时才使用，可重复")
+		var allow, deny stringList
+		fs.Var(&allow, "allow", "允许的来源 IP 或 CIDR，可重复或逗号分隔")
+		fs.Var(&deny, "deny", "拒绝的来源 IP 或 CIDR，可重复或逗号分隔")
+		var allowLists, denyLists idList
+		fs.Var(&allowLists, "allow-list", "允许的命名 IP 列表 ID，可重复")
+		fs.Var(&denyLists, "deny-list", "拒绝的命名 IP 列表 ID，可重复")
+		fs.StringVar(&req.Remark, "remark", "", "备注")
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+
+		req.NodeID, req.TunnelID = optionalID(*nodeID), optionalID(*tunnelID)
+		req.ListenPort, req.ListenPortEnd = listen.start, listen.end
+		req.ProxyUsers = []dto.ProxyUser(users)
+		if req.Type == "" {
+			req.Type = string(model.RuleTypeForward)
+			if len(users) > 0 {
+				req.Type = string(model.RuleTypeProxy)
+			} else if req.Host != "" {
+				req.Type = string(model.RuleTypeReverse)
+			} else if req.TunnelID != nil {
+				req.Type = string(model.RuleTypeTunnel)
+			}
+		}
+		req.Targets = targets
+		req.TargetOptions = buildTargetOptions(weights, backups)
+		req.AllowSources, req.DenySources = allow, deny
+		req.AllowLists, req.DenyLists = allowLists, denyLists
+
+		rule, err := c.CreateRule(ctx, req)
+		if err != nil {
+			return err
+		}
+		return p.print(rule, ruleTable([]client.Rule{*rule}, 0))
+
+	case "clone":
+		req := &dto.CloneRuleReq{}
+		nodeID := fs.Uint("node", 0, "目标节点 ID（端口转发）")
+		tunnelID := fs.Uint("tunnel", 0, "目标隧道 ID（隧道转发）")
+		fs.StringVar(&req.Name, "name", "", "新规则名称（默认为 原名称-copy）")
+		fs.IntVar(&req.ListenPort, "port", 0, "监听端口（默认沿用原端口）")
+		fs.BoolVar(&req.AutoPort, "auto-port", false, "原端口在目标上冲突时自动分配")
+		fs.BoolVar(&req.Start, "start", false, "克隆后立即启动")
+		fs.BoolVar(&req.StopSource, "stop-source", false, "启动成功后停止原规则")
+		fs.BoolVar(&req.KeepStats, "keep-stats", false, "复制原规则的流量计数")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		req.NodeID, req.TunnelID = optionalID(*nodeID), optionalID(*tunnelID)
+
+		rule, err := c.CloneRule(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return p.print(rule, ruleTable([]client.Rule{*rule}, 0))
+
+	case "migrate", "mv":
+		req := &dto.MigrateRuleReq{}
+		nodeID := fs.Uint("node", 0, "目标节点 ID（端口转发）")
+		tunnelID := fs.Uint("tunnel", 0, "目标隧道 ID（隧道转发）")
+		fs.IntVar(&req.ListenPort, "port", 0, "监听端口（默认沿用原端口）")
+		fs.BoolVar(&req.AutoPort, "auto-port", false, "原端口在目标上冲突时自动分配")
+		fs.BoolVar(&req.Start, "start", false, "原规则未运行时也在目标上启动")
+		fs.BoolVar(&req.KeepStats, "keep-stats", false, "保留流量计数（默认清零）")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		req.NodeID, req.TunnelID = optionalID(*nodeID), optionalID(*tunnelID)
+
+		rule, err := c.MigrateRule(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return p.print(rule, ruleTable([]client.Rule{*rule}, 0))
+
+	case "update":
+		var targets stringList
+		name := fs.String("name", "", "规则名称")
+		var listen portRange
+		fs.Var(&listen, "port", "监听端口或端口范围，如 8080、27015-27030")
+		mapping := fs.String("map", "", "端口范围的目标映射: range | single")
+		protocol := fs.String("protocol", "", "转发协议: tcp | udp | both")
+		proxyType := fs.String("proxy-type", "", "代理类型: http | socks5 | auto")
+		var users proxyUsers
+		fs.Var(&users, "user", "代理认证用户 user:password，可重复（替换原有用户）")
+		host := fs.String("host", "", "反向代理域名")
+		fs.Var(&targets, "target", "目标地址 host:port，可重复或逗号分隔（替换原有目标）")
+		strategy := fs.String("strategy", "", "负载均衡策略")
+		enableTLS := fs.Bool("tls", false, "启用 TLS")
+		maxFails := fs.Int("max-fails", 0, "目标连续失败多少次后标记失效（0 为默认 3）")
+		failTimeout := fs.Int("fail-timeout", 0, "失效目标多少秒后重新尝试（0 为默认 30）")
+		var weights targetWeights
+		fs.Var(&weights, "weight", "目标权重 host:port=N，可重复（与 -backup 一起替换原有设置）")
+		var backups stringList
+		fs.Var(&backups, "backup", "备用目标 host:port，可重复（与 -weight 一起替换原有设置）")
+		var allow, deny stringList
+		fs.Var(&allow, "allow", "允许的来源 IP 或 CIDR，可重复（替换原有设置，传空字符串清空）")
+		fs.Var(&deny, "deny", "拒绝的来源 IP 或 CIDR，可重复（替换原有设置，传空字符串清空）")
+		var allowLists, denyLists idList
+		fs.Var(&allowLists, "allow-list", "允许的命名 IP 列表 ID，可重复（替换原有设置，传空字符串清空）")
+		fs.Var(&denyLists, "deny-list", "拒绝的命名 IP 列表 ID，可重复（替换原有设置，传空字符串清空）")
+		remark := fs.String("remark", "", "备注")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+
+		// 未指定的字段保持原值
+		rule, err := c.GetRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		req := &dto.UpdateRuleReq{
+			Name: rule.Name, ListenPort: rule.ListenPort, Targets: rule.Targets,
+			Strategy: rule.Strategy, EnableTLS: rule.EnableTLS, Remark: rule.Remark,
+			ListenPortEnd: rule.ListenPortEnd, PortMapping: rule.PortMapping,
+			Protocol: string(rule.Protocol), ProxyType: rule.ProxyType, Host: rule.Host,
+			MaxFails: rule.MaxFails, FailTimeout: rule.FailTimeout,
+			AllowSources: rule.AllowSources, DenySources: rule.DenySources,
+			AllowLists: rule.AllowLists, DenyLists: rule.DenyLists,
+		}
+		for _, user := range rule.ProxyUsers {
+			req.ProxyUsers = append(req.ProxyUsers, dto.ProxyUser{Username: user.Username, Password: user.Password})
+		}
+		set := setFlags(fs)
+		if set["name"] {
+			req.Name = *name
+		}
+		if set["port"] {
+			req.ListenPort, req.ListenPortEnd = listen.start, listen.end
+		}
+		if set["map"] {
+			req.PortMapping = *mapping
+		}
+		if set["protocol"] {
+			req.Protocol = *protocol
+		}
+		if set["proxy-type"] {
+			req.ProxyType = *proxyType
+		}
+		if set["user"] {
+			req.ProxyUsers = []dto.ProxyUser(users)
+		}
+		if set["host"] {
+			req.Host = *host
+		}
+		if set["target"] {
+			req.Targets = targets
+		}
+		if set["weight"] || set["backup"] {
+			req.TargetOptions = buildTargetOptions(weights, backups)
+		} else {
+			// 沿用原有设置，去掉已不在目标列表中的目标
+			for _, opt := range rule.TargetOptions {
+				if slices.Contains(req.Targets, opt.Target) {
+					req.TargetOptions = append(req.TargetOptions, dto.TargetOption{Target: opt.Target, Weight: opt.Weight, Backup: opt.Backup})
+				}
+			}
+		}
+		if set["max-fails"] {
+			req.MaxFails = *maxFails
+		}
+		if set["fail-timeout"] {
+			req.FailTimeout = *failTimeout
+		}
+		if set["allow"] {
+			req.AllowSources = allow
+		}
+		if set["deny"] {
+			req.DenySources = deny
+		}
+		if set["allow-list"] {
+			req.AllowLists = allowLists
+		}
+		if set["deny-list"] {
+			req.DenyLists = denyLists
+		}
+		if set["strategy"] {
+			req.Strategy = *strategy
+		}
+		if set["tls"] {
+			req.EnableTLS = *enableTLS
+		}
+		if set["remark"] {
+			req.Remark = *remark
+		}
+
+		rule, err = c.UpdateRule(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return p.print(rule, ruleTable([]client.Rule{*rule}, 0))
+
+	case "delete", "rm", "start", "stop", "restart":
+		// 指定 ID 或筛选条件，统一走批量接口
+		filter := &dto.RuleFilter{}
+		fs.UintVar(&filter.NodeID, "node", 0, "按节点 ID 选择")
+		fs.UintVar(&filter.TunnelID, "tunnel", 0, "按隧道 ID 选择")
+		fs.StringVar(&filter.Type, "type", "", "按类型选择: forward | tunnel")
+		fs.StringVar(&filter.Status, "status", "", "按状态选择: running | stopped | error")
+		fs.StringVar(&filter.Keyword, "keyword", "", "按名称关键词选择")
+		yes := fs.Bool("yes", false, "确认按筛选条件删除")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		req := &dto.BatchRuleReq{Action: batchAction(args[0])}
+		if len(positional) > 0 {
+			if req.IDs, err = parseIDs(positional); err != nil {
+				return err
+			}
+		} else {
+			if filter.IsEmpty() {
+				return fmt.Errorf("请指定规则 ID 或筛选条件（-node/-tunnel/-type/-status/-keyword）")
+			}
+			if req.Action == "delete" && !*yes {
+				return fmt.Errorf("将删除所有匹配的规则（删除前会自动创建快照），确认请加 -yes")
+			}
+			req.Filter = filter
+		}
+		result, err := c.BatchRules(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printBatch(p, result)
+
+	default:
+		return fmt.Errorf("未知子命令: %s", args[0])
+	}
+}
+
+// ruleTable 规则表格
+func ruleTable(rules []client.Rule, total int64) *table {
+	t := &table{headers: []string{"ID", "NAME", "TYPE", "ENTRY", "PORT", "TARGETS", "STATUS", "INPUT", "OUTPUT", "CONNS"}}
+	for _, r := range rules {
+		entry := "node:" + formatIDPtr(r.NodeID)
+		if r.TunnelID != nil {
+			entry = "tunnel:" + formatIDPtr(r.TunnelID)
+		}
+		port := fmt.Sprint(r.ListenPort)
+		if r.IsPortRange() {
+			port = fmt.Sprintf("%d-%d", r.ListenPort, r.ListenPortEnd)
+		}
+		if r.Type != model.RuleTypeProxy && r.Type != model.RuleTypeReverse && (r.Protocol == model.RuleProtocolTCP || r.Protocol == model.RuleProtocolUDP) {
+			port += "/" + string(r.Protocol)
+		}
+		targets := strings.Join(r.Targets, ",")
+		if r.Type == model.RuleTypeProxy {
+			targets = fmt.Sprintf("%s (%d 用户)", r.ProxyType, len(r.ProxyUsers))
+		} else if r.Type == model.RuleTypeReverse {
+			targets = r.Host + " -> " + targets
+		}
+		t.add(fmt.Sprint(r.ID), r.Name, string(r.Type), entry, port,
+			truncate(targets, 40), string(r.Status),
+			formatBytes(r.InputBytes), formatBytes(r.OutputBytes), fmt.Sprint(r.TotalRequests))
+	}
+	addTotal(t, len(rules), total)
+	return t
+}
+
+// ruleTargetTable 规则转发目标状态表格
+func ruleTargetTable(resp *dto.RuleTargetsResp) *table {
+	t := &table{headers: []string{"TARGET", "WEIGHT", "BACKUP", "STATE", "FAILS", "LAST CHECK", "LAST ERROR"}}
+	for _, target := range resp.Targets {
+		state := "正常"
+		if !resp.Checked || target.LastCheck == nil {
+			state = "-"
+		} else if target.Failed {
+			state = "失效"
+		}
+		weight := "-"
+		if target.Weight > 0 {
+			weight = fmt.Sprint(target.Weight)
+		}
+		backup := ""
+		if target.Backup {
+			backup = "是"
+		}
+		t.add(target.Target, weight, orDash(backup), state,
+			fmt.Sprintf("%d/%d", target.Fails, resp.MaxFails), formatTimePtr(target.LastCheck), truncate(orDash(target.LastError), 40))
+	}
+	if !resp.Checked {
+		t.add(fmt.Sprintf("（未探测：%s）", resp.Reason))
+	}
+	return t
+}
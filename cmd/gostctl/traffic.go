@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gost-panel/internal/dto"
+	"gost-panel/pkg/client"
+)
+
+// trafficRow 流量统计行
+type trafficRow struct {
+	Type        string `json:"type"`
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	InputBytes  int64  `json:"input_bytes"`
+	OutputBytes int64  `json:"output_bytes"`
+	TotalBytes  int64  `json:"total_bytes"`
+	Connections int64  `json:"connections,omitempty"`
+}
+
+// runTraffic 流量统计，按总流量从高到低排列
+//
+//	gostctl traffic [-by rules|nodes|tunnels] [-top 10]
+func runTraffic(args []string) error {
+	fs, opts := newFlagSet("traffic")
+	by := fs.String("by", "rules", "统计对象: rules | nodes | tunnels")
+	top := fs.Int("top", 0, "只显示前 N 项（0 为全部）")
+	_, c, p, err := setup(fs, opts, args)
+	if err != nil {
+		return err
+	}
+
+	rows, err := fetchTraffic(context.Background(), c, *by)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].TotalBytes > rows[j].TotalBytes
+	})
+	if *top > 0 && len(rows) > *top {
+		rows = rows[:*top]
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "STATUS", "INPUT", "OUTPUT", "TOTAL", "CONNS"}}
+	var in, out int64
+	for _, r := range rows {
+		conns := "-"
+		if r.Type == "rule" {
+			conns = fmt.Sprint(r.Connections)
+		}
+		t.add(fmt.Sprint(r.ID), r.Name, r.Status, formatBytes(r.InputBytes), formatBytes(r.OutputBytes), formatBytes(r.TotalBytes), conns)
+		in += r.InputBytes
+		out += r.OutputBytes
+	}
+	t.add("", "合计", "", formatBytes(in), formatBytes(out), formatBytes(in+out), "")
+	return p.print(rows, t)
+}
+
+// fetchTraffic 获取全部对象的流量
+func fetchTraffic(ctx context.Context, c *client.Client, by string) ([]trafficRow, error) {
+	var rows []trafficRow
+	switch by {
+	case "rules", "rule":
+		req := &dto.RuleListReq{}
+		rules, _, err := listAll(true, &req.Page, &req.PageSize, func() (*client.Page[client.Rule], error) {
+			return c.ListRules(ctx, req)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rules {
+			rows = append(rows, trafficRow{Type: "rule", ID: r.ID, Name: r.Name, Status: string(r.Status),
+				InputBytes: r.InputBytes, OutputBytes: r.OutputBytes, TotalBytes: r.InputBytes + r.OutputBytes, Connections: r.TotalRequests})
+		}
+	case "nodes", "node":
+		req := &dto.NodeListReq{}
+		nodes, _, err := listAll(true, &req.Page, &req.PageSize, func() (*client.Page[client.Node], error) {
+			return c.ListNodes(ctx, req)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nodes {
+			rows = append(rows, trafficRow{Type: "node", ID: n.ID, Name: n.Name, Status: string(n.Status),
+				InputBytes: n.InputBytes, OutputBytes: n.OutputBytes, TotalBytes: n.InputBytes + n.OutputBytes})
+		}
+	case "tunnels", "tunnel":
+		req := &dto.TunnelListReq{}
+		tunnels, _, err := listAll(true, &req.Page, &req.PageSize, func() (*client.Page[client.Tunnel], error) {
+			return c.ListTunnels(ctx, req)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tunnels {
+			rows = append(rows, trafficRow{Type: "tunnel", ID: t.ID, Name: t.Name, Status: string(t.Status),
+				InputBytes: t.InputBytes, OutputBytes: t.OutputBytes, TotalBytes: t.InputBytes + t.OutputBytes})
+		}
+	default:
+		return nil, fmt.Errorf("不支持的统计对象: %s", by)
+	}
+	return rows, nil
+}
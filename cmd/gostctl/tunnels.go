@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/client"
+)
+
+// runTunnels 隧道管理
+func runTunnels(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: gostctl tunnels list|get|create|update|rotate|delete|start|stop|restart")
+	}
+
+	fs, opts := newFlagSet("tunnels " + args[0])
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list", "ls":
+		req := &dto.TunnelListReq{}
+		fs.IntVar(&req.Page, "page", 1, "页码")
+		fs.IntVar(&req.PageSize, "size", 20, "每页数量")
+		fs.UintVar(&req.NodeID, "node", 0, "节点 ID 筛选（入口或出口）")
+		fs.StringVar(&req.Status, "status", "", "状态筛选: running | stopped | error")
+		fs.StringVar(&req.Keyword, "keyword", "", "关键词")
+		all := fs.Bool("all", false, "获取全部")
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		tunnels, total, err := listAll(*all, &req.Page, &req.PageSize, func() (*client.Page[client.Tunnel], error) {
+			return c.ListTunnels(ctx, req)
+		})
+		if err != nil {
+			return err
+		}
+		return p.print(tunnels, tunnelTable(tunnels, total))
+
+	case "get":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		tunnel, err := c.GetTunnel(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(tunnel, tunnelTable([]client.Tunnel{*tunnel}, 0))
+
+	case "create":
+		req := &dto.CreateTunnelReq{}
+		fs.StringVar(&req.Name, "name", "", "隧道名称")
+		fs.UintVar(&req.EntryNodeID, "entry", 0, "入口节点 ID")
+		fs.UintVar(&req.ExitNodeID, "exit", 0, "出口节点 ID")
+		fs.StringVar(&req.Protocol, "protocol", "tcp", "协议: tcp | udp | tls | mtls | ws | mws | wss | mwss | h2 | grpc | quic | kcp | ssh")
+		fs.IntVar(&req.RelayPort, "relay-port", 0, "出口节点 Relay 端口（不指定时从出口节点端口池自动分配）")
+		var extraExits idList
+		fs.Var(&extraExits, "extra-exit", "附加出口节点 ID，可重复（按顺序）")
+		fs.StringVar(&req.Strategy, "strategy", "", "多出口选择策略: round | rand | fifo（默认 round）")
+		fs.StringVar(&req.Remark, "remark", "", "备注")
+		optFlags := newTunnelOptionFlags(fs)
+		_, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		req.ExtraExitNodeIDs = extraExits
+		optFlags.apply(setFlags(fs), &req.Options)
+		tunnel, err := c.CreateTunnel(ctx, req)
+		if err != nil {
+			return err
+		}
+		return p.print(tunnel, tunnelTable([]client.Tunnel{*tunnel}, 0))
+
+	case "update":
+		name := fs.String("name", "", "隧道名称")
+		protocol := fs.String("protocol", "", "协议")
+		relayPort := fs.Int("relay-port", 0, "出口节点 Relay 端口")
+		var extraExits idList
+		fs.Var(&extraExits, "extra-exit", "附加出口节点 ID，可重复（替换原有设置，传空字符串清空）")
+		strategy := fs.String("strategy", "", "多出口选择策略: round | rand | fifo")
+		remark := fs.String("remark", "", "备注")
+		clearOptions := fs.Bool("clear-options", false, "清除全部传输参数")
+		optFlags := newTunnelOptionFlags(fs)
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+
+		// 未指定的字段保持原值
+		tunnel, err := c.GetTunnel(ctx, id)
+		if err != nil {
+			return err
+		}
+		req := &dto.UpdateTunnelReq{
+			Name: tunnel.Name, Protocol: tunnel.Protocol, RelayPort: tunnel.RelayPort, Remark: tunnel.Remark,
+			ExtraExitNodeIDs: tunnel.ExitNodeIDs()[1:], Strategy: tunnel.Strategy,
+		}
+		if !*clearOptions {
+			req.Options = tunnelOptionsReq(tunnel.Options)
+		}
+		set := setFlags(fs)
+		if set["name"] {
+			req.Name = *name
+		}
+		if set["protocol"] {
+			req.Protocol = *protocol
+			// 更换协议时丢弃新协议不支持的参数分组
+			req.Options = keepTunnelOptionGroups(req.Options, model.TunnelOptionGroups(req.Protocol))
+		}
+		optFlags.apply(set, &req.Options)
+		if set["relay-port"] {
+			req.RelayPort = *relayPort
+		}
+		if set["extra-exit"] {
+			req.ExtraExitNodeIDs = extraExits
+		}
+		if set["strategy"] {
+			req.Strategy = *strategy
+		}
+		if set["remark"] {
+			req.Remark = *remark
+		}
+
+		tunnel, err = c.UpdateTunnel(ctx, id, req)
+		if err != nil {
+			return err
+		}
+		return p.print(tunnel, tunnelTable([]client.Tunnel{*tunnel}, 0))
+
+	case "rotate":
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional)
+		if err != nil {
+			return err
+		}
+		tunnel, err := c.RotateTunnelCredentials(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.print(tunnel, tunnelTable([]client.Tunnel{*tunnel}, 0))
+
+	case "delete", "rm", "start", "stop", "restart":
+		// 指定 ID 或筛选条件，统一走批量接口
+		filter := &dto.TunnelFilter{}
+		fs.UintVar(&filter.NodeID, "node", 0, "按节点 ID 选择（入口或出口）")
+		fs.StringVar(&filter.Status, "status", "", "按状态选择: running | stopped | error")
+		fs.StringVar(&filter.Keyword, "keyword", "", "按名称关键词选择")
+		yes := fs.Bool("yes", false, "确认按筛选条件删除")
+		positional, c, p, err := setup(fs, opts, args[1:])
+		if err != nil {
+			return err
+		}
+		req := &dto.BatchTunnelReq{Action: batchAction(args[0])}
+		if len(positional) > 0 {
+			if req.IDs, err = parseIDs(positional); err != nil {
+				return err
+			}
+		} else {
+			if filter.IsEmpty() {
+				return fmt.Errorf("请指定隧道 ID 或筛选条件（-node/-status/-keyword）")
+			}
+			if req.Action == "delete" && !*yes {
+				return fmt.Errorf("将删除所有匹配的隧道（删除前会自动创建快照），确认请加 -yes")
+			}
+			req.Filter = filter
+		}
+		result, err := c.BatchTunnels(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printBatch(p, result)
+
+	default:
+		return fmt.Errorf("未知子命令: %s", args[0])
+	}
+}
+
+// tunnelTable 隧道表格
+func tunnelTable(tunnels []client.Tunnel, total int64) *table {
+	t := &table{headers: []string{"ID", "NAME", "ENTRY", "EXIT", "PROTOCOL", "RELAY PORT", "STATUS", "INPUT", "OUTPUT"}}
+	for _, tn := range tunnels {
+		t.add(fmt.Sprint(tn.ID), tn.Name, nodeLabel(tn.EntryNodeID, tn.EntryNode), exitLabel(&tn),
+			tn.Protocol, fmt.Sprint(tn.RelayPort), string(tn.Status), formatBytes(tn.InputBytes), formatBytes(tn.OutputBytes))
+	}
+	addTotal(t, len(tunnels), total)
+	return t
+}
+
+// exitLabel 出口节点显示名称，多出口隧道依次列出并附带选择策略
+func exitLabel(tn *client.Tunnel) string {
+	label := nodeLabel(tn.ExitNodeID, tn.ExitNode)
+	if len(tn.ExtraExits) == 0 {
+		return label
+	}
+	for _, exit := range tn.ExtraExits {
+		label += "," + nodeLabel(exit.NodeID, exit.Node)
+	}
+	return fmt.Sprintf("%s [%s]", label, tn.Strategy)
+}
+
+// nodeLabel 节点显示名称，关联数据未加载时显示 ID
+func nodeLabel(id uint, node *client.Node) string {
+	if node != nil && node.Name != "" {
+		return fmt.Sprintf("%s(%d)", node.Name, id)
+	}
+	return fmt.Sprint(id)
+}
+
+// tunnelOptionFlags 隧道传输参数命令行选项
+type tunnelOptionFlags struct {
+	opts dto.TunnelOptions
+}
+
+// newTunnelOptionFlags 注册传输参数选项
+func newTunnelOptionFlags(fs *flag.FlagSet) *tunnelOptionFlags {
+	f := &tunnelOptionFlags{opts: dto.TunnelOptions{
+		WS: &dto.TunnelWSOptions{}, TLS: &dto.TunnelTLSOptions{}, Mux: &dto.TunnelMuxOptions{},
+		QUIC: &dto.TunnelQUICOptions{}, KCP: &dto.TunnelKCPOptions{},
+	}}
+	o := f.opts
+	fs.StringVar(&o.WS.Path, "ws-path", "", "WebSocket 请求路径（ws/mws/wss/mwss）")
+	fs.StringVar(&o.WS.Host, "ws-host", "", "WebSocket Host 头")
+	fs.StringVar(&o.TLS.CertFile, "tls-cert", "", "出口节点证书文件路径")
+	fs.StringVar(&o.TLS.KeyFile, "tls-key", "", "出口节点私钥文件路径")
+	fs.StringVar(&o.TLS.ServerName, "tls-sni", "", "入口节点连接时的 SNI")
+	fs.BoolVar(&o.TLS.Secure, "tls-secure", false, "入口节点校验出口节点证书")
+	fs.StringVar(&o.TLS.CAFile, "tls-ca", "", "入口节点校验证书使用的 CA 文件路径")
+	fs.IntVar(&o.Mux.Version, "mux-version", 0, "多路复用协议版本: 1 | 2（mtls/mws/mwss）")
+	fs.IntVar(&o.Mux.KeepaliveInterval, "mux-keepalive", 0, "多路复用心跳间隔（秒）")
+	fs.IntVar(&o.Mux.KeepaliveTimeout, "mux-keepalive-timeout", 0, "多路复用心跳超时（秒）")
+	fs.IntVar(&o.Mux.MaxFrameSize, "mux-max-frame", 0, "多路复用最大帧大小（字节）")
+	fs.IntVar(&o.Mux.MaxReceiveBuffer, "mux-receive-buffer", 0, "多路复用连接接收缓冲区（字节）")
+	fs.IntVar(&o.Mux.MaxStreamBuffer, "mux-stream-buffer", 0, "多路复用单个流缓冲区（字节）")
+	fs.IntVar(&o.QUIC.KeepAlivePeriod, "quic-keepalive", 0, "QUIC 心跳间隔（秒）")
+	fs.IntVar(&o.QUIC.HandshakeTimeout, "quic-handshake-timeout", 0, "QUIC 握手超时（秒）")
+	fs.IntVar(&o.QUIC.MaxIdleTimeout, "quic-idle-timeout", 0, "QUIC 空闲超时（秒）")
+	fs.IntVar(&o.QUIC.MaxStreams, "quic-max-streams", 0, "QUIC 单连接最大并发流")
+	fs.StringVar(&o.KCP.Mode, "kcp-mode", "", "KCP 模式: normal | fast | fast2 | fast3")
+	fs.StringVar(&o.KCP.Crypt, "kcp-crypt", "", "KCP 加密方式，如 aes | salsa20 | none")
+	fs.StringVar(&o.KCP.Key, "kcp-key", "", "KCP 加密密钥")
+	fs.IntVar(&o.KCP.MTU, "kcp-mtu", 0, "KCP MTU")
+	fs.IntVar(&o.KCP.SndWnd, "kcp-sndwnd", 0, "KCP 发送窗口")
+	fs.IntVar(&o.KCP.RcvWnd, "kcp-rcvwnd", 0, "KCP 接收窗口")
+	fs.IntVar(&o.KCP.DataShard, "kcp-datashard", 0, "KCP 前向纠错数据分片")
+	fs.IntVar(&o.KCP.ParityShard, "kcp-parityshard", 0, "KCP 前向纠错校验分片")
+	fs.IntVar(&o.KCP.KeepAlive, "kcp-keepalive", 0, "KCP 心跳间隔（秒）")
+	fs.BoolVar(&o.KCP.NoComp, "kcp-nocomp", false, "KCP 关闭压缩")
+	return f
+}
+
+// apply 将已指定的选项合并到传输参数，未指定的参数保持原值
+func (f *tunnelOptionFlags) apply(set map[string]bool, dst *dto.TunnelOptions) {
+	src := f.opts
+	if set["ws-path"] || set["ws-host"] {
+		dst.WS = cloneOrNew(dst.WS)
+		setIf(set["ws-path"], &dst.WS.Path, src.WS.Path)
+		setIf(set["ws-host"], &dst.WS.Host, src.WS.Host)
+	}
+	if set["tls-cert"] || set["tls-key"] || set["tls-sni"] || set["tls-secure"] || set["tls-ca"] {
+		dst.TLS = cloneOrNew(dst.TLS)
+		setIf(set["tls-cert"], &dst.TLS.CertFile, src.TLS.CertFile)
+		setIf(set["tls-key"], &dst.TLS.KeyFile, src.TLS.KeyFile)
+		setIf(set["tls-sni"], &dst.TLS.ServerName, src.TLS.ServerName)
+		setIf(set["tls-secure"], &dst.TLS.Secure, src.TLS.Secure)
+		setIf(set["tls-ca"], &dst.TLS.CAFile, src.TLS.CAFile)
+	}
+	if set["mux-version"] || set["mux-keepalive"] || set["mux-keepalive-timeout"] || set["mux-max-frame"] ||
+		set["mux-receive-buffer"] || set["mux-stream-buffer"] {
+		dst.Mux = cloneOrNew(dst.Mux)
+		setIf(set["mux-version"], &dst.Mux.Version, src.Mux.Version)
+		setIf(set["mux-keepalive"], &dst.Mux.KeepaliveInterval, src.Mux.KeepaliveInterval)
+		setIf(set["mux-keepalive-timeout"], &dst.Mux.KeepaliveTimeout, src.Mux.KeepaliveTimeout)
+		setIf(set["mux-max-frame"], &dst.Mux.MaxFrameSize, src.Mux.MaxFrameSize)
+		setIf(set["mux-receive-buffer"], &dst.Mux.MaxReceiveBuffer, src.Mux.MaxReceiveBuffer)
+		setIf(set["mux-stream-buffer"], &dst.Mux.MaxStreamBuffer, src.Mux.MaxStreamBuffer)
+	}
+	if set["quic-keepalive"] || set["quic-handshake-timeout"] || set["quic-idle-timeout"] || set["quic-max-streams"] {
+		dst.QUIC = cloneOrNew(dst.QUIC)
+		setIf(set["quic-keepalive"], &dst.QUIC.KeepAlivePeriod, src.QUIC.KeepAlivePeriod)
+		setIf(set["quic-handshake-timeout"], &dst.QUIC.HandshakeTimeout, src.QUIC.HandshakeTimeout)
+		setIf(set["quic-idle-timeout"], &dst.QUIC.MaxIdleTimeout, src.QUIC.MaxIdleTimeout)
+		setIf(set["quic-max-streams"], &dst.QUIC.MaxStreams, src.QUIC.MaxStreams)
+	}
+	if set["kcp-mode"] || set["kcp-crypt"] || set["kcp-key"] || set["kcp-mtu"] || set["kcp-sndwnd"] || set["kcp-rcvwnd"] ||
+		set["kcp-datashard"] || set["kcp-parityshard"] || set["kcp-keepalive"] || set["kcp-nocomp"] {
+		dst.KCP = cloneOrNew(dst.KCP)
+		setIf(set["kcp-mode"], &dst.KCP.Mode, src.KCP.Mode)
+		setIf(set["kcp-crypt"], &dst.KCP.Crypt, src.KCP.Crypt)
+		setIf(set["kcp-key"], &dst.KCP.Key, src.KCP.Key)
+		setIf(set["kcp-mtu"], &dst.KCP.MTU, src.KCP.MTU)
+		setIf(set["kcp-sndwnd"], &dst.KCP.SndWnd, src.KCP.SndWnd)
+		setIf(set["kcp-rcvwnd"], &dst.KCP.RcvWnd, src.KCP.RcvWnd)
+		setIf(set["kcp-datashard"], &dst.KCP.DataShard, src.KCP.DataShard)
+		setIf(set["kcp-parityshard"], &dst.KCP.ParityShard, src.KCP.ParityShard)
+		setIf(set["kcp-keepalive"], &dst.KCP.KeepAlive, src.KCP.KeepAlive)
+		setIf(set["kcp-nocomp"], &dst.KCP.NoComp, src.KCP.NoComp)
+	}
+}
+
+// cloneOrNew 复制参数分组，为空时新建
+func cloneOrNew[T any](v *T) *T {
+	c := new(T)
+	if v != nil {
+		*c = *v
+	}
+	return c
+}
+
+// setIf 选项已指定时赋值
+func setIf[T any](ok bool, dst *T, v T) {
+	if ok {
+		*dst = v
+	}
+}
+
+// tunnelOptionsReq 将隧道当前的传输参数转换为请求参数
+func tunnelOptionsReq(o model.TunnelOptions) dto.TunnelOptions {
+	var req dto.TunnelOptions
+	if o.WS != nil {
+		ws := dto.TunnelWSOptions(*o.WS)
+		req.WS = &ws
+	}
+	if o.TLS != nil {
+		tls := dto.TunnelTLSOptions(*o.TLS)
+		req.TLS = &tls
+	}
+	if o.Mux != nil {
+		mux := dto.TunnelMuxOptions(*o.Mux)
+		req.Mux = &mux
+	}
+	if o.QUIC != nil {
+		quic := dto.TunnelQUICOptions(*o.QUIC)
+		req.QUIC = &quic
+	}
+	if o.KCP != nil {
+		kcp := dto.TunnelKCPOptions(*o.KCP)
+		req.KCP = &kcp
+	}
+	return req
+}
+
+// keepTunnelOptionGroups 只保留指定的参数分组
+func keepTunnelOptionGroups(o dto.TunnelOptions, groups []string) dto.TunnelOptions {
+	if !slices.Contains(groups, "ws") {
+		o.WS = nil
+	}
+	if !slices.Contains(groups, "tls") {
+		o.TLS = nil
+	}
+	if !slices.Contains(groups, "mux") {
+		o.Mux = nil
+	}
+	if !slices.Contains(groups, "quic") {
+		o.QUIC = nil
+	}
+	if !slices.Contains(groups, "kcp") {
+		o.KCP = nil
+	}
+	return o
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gost-panel/internal/config"
+	"gost-panel/internal/database"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// commandUser 命令行操作记录到操作日志时使用的用户名
+const commandUser = "cli"
+
+// runCommand 执行子命令，返回 false 表示不是子命令（按服务模式启动）
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	var err error
+	switch args[0] {
+	case "inventory":
+		err = runInventory(args[1:])
+	case "import-sqlite":
+		err = runImportSQLite(args[1:])
+	case "migrate":
+		err = runMigrate(args[1:])
+	case "openapi":
+		err = runOpenAPI(args[1:])
+	default:
+		return false
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// openForCommand 为子命令加载配置、打开数据库并执行迁移
+func openForCommand(configPath string) (*config.Config, *gorm.DB, error) {
+	cfg, db, err := openDatabaseForCommand(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = autoMigrate(db, cfg.Database.ManualMigrate); err != nil {
+		database.Close(db)
+		return nil, nil, fmt.Errorf("数据库迁移失败: %w", err)
+	}
+
+	return cfg, db, nil
+}
+
+// openDatabaseForCommand 为子命令加载配置并打开数据库，不执行迁移
+func openDatabaseForCommand(configPath string) (*config.Config, *gorm.DB, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	// 子命令只输出错误日志，避免干扰命令输出
+	if err = logger.Init(&logger.Config{
+		Level:  "error",
+		Format: "console",
+		Output: "stdout",
+	}); err != nil {
+		return nil, nil, fmt.Errorf("初始化日志失败: %w", err)
+	}
+
+	db, err := initDatabase(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化数据库失败: %w", err)
+	}
+
+	return cfg, db, nil
+}
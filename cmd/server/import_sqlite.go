@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"gost-panel/internal/database"
+	"gost-panel/internal/model"
+)
+
+// runImportSQLite 将 SQLite 数据库迁移到配置中的数据库（PostgreSQL/MySQL）
+//
+//	gost-panel import-sqlite [-c config] -from ./gost-panel.db [-force]
+//
+// 目标库已有数据时需要 -force，目标表中的现有数据会被清空
+func runImportSQLite(args []string) error {
+	fs := flag.NewFlagSet("import-sqlite", flag.ExitOnError)
+	configPath := fs.String("c", "", "配置文件路径（目标数据库）")
+	from := fs.String("from", "", "源 SQLite 数据库文件")
+	force := fs.Bool("force", false, "目标数据库已有数据时仍然导入（覆盖）")
+	_ = fs.Parse(args)
+
+	if *from == "" {
+		return fmt.Errorf("用法: gost-panel import-sqlite [-c config] -from ./gost-panel.db [-force]")
+	}
+	if _, err := os.Stat(*from); err != nil {
+		return fmt.Errorf("源数据库不存在: %s", *from)
+	}
+
+	cfg, db, err := openForCommand(*configPath)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	if db.Dialector.Name() == database.TypeSQLite {
+		return fmt.Errorf("目标数据库类型为 sqlite，请在配置中将 database.type 设置为 postgres 或 mysql")
+	}
+
+	// 目标库已有节点或规则时需确认覆盖
+	if !*force {
+		for _, m := range []any{&model.GostNode{}, &model.GostTunnel{}, &model.GostRule{}} {
+			var count int64
+			if err = db.Model(m).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("目标数据库已有数据，使用 -force 覆盖")
+			}
+		}
+	}
+
+	src, err := database.OpenSQLite(*from)
+	if err != nil {
+		return fmt.Errorf("打开源数据库失败: %w", err)
+	}
+	defer database.Close(src)
+
+	counts, err := database.Copy(db, src)
+	if err != nil {
+		return err
+	}
+
+	tables := make([]string, 0, len(counts))
+	for t := range counts {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	for _, t := range tables {
+		fmt.Printf("%-16s %d\n", t, counts[t])
+	}
+	fmt.Printf("已从 %s 导入到 %s 数据库\n", *from, cfg.Database.Type)
+	return nil
+}
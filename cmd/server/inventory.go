@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/service"
+)
+
+// runInventory 资源清单子命令
+//
+//	gost-panel inventory export [-c config] [-o file] [-format yaml|json] [-secrets omit|encrypt]
+//	gost-panel inventory import [-c config] -f file [-apply] [-prune]
+//
+// 加解密口令通过 -passphrase 或环境变量 GOST_PANEL_PASSPHRASE 提供
+func runInventory(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: gost-panel inventory <export|import> [参数]")
+	}
+
+	switch args[0] {
+	case "export":
+		return runInventoryExport(args[1:])
+	case "import":
+		return runInventoryImport(args[1:])
+	default:
+		return fmt.Errorf("未知的 inventory 子命令: %s", args[0])
+	}
+}
+
+// runInventoryExport 导出资源清单
+func runInventoryExport(args []string) error {
+	fs := flag.NewFlagSet("inventory export", flag.ExitOnError)
+	configPath := fs.String("c", "", "配置文件路径")
+	output := fs.String("o", "-", "输出文件路径，- 表示标准输出")
+	format := fs.String("format", "yaml", "输出格式 (yaml, json)")
+	secrets := fs.String("secrets", "omit", "节点密码处理方式 (omit, encrypt)")
+	passphrase := fs.String("passphrase", os.Getenv("GOST_PANEL_PASSPHRASE"), "加密口令")
+	_ = fs.Parse(args)
+
+	_, db, err := openForCommand(*configPath)
+	if err != nil {
+		return err
+	}
+
+	req := &dto.ExportInventoryReq{
+		Format:     *format,
+		Secrets:    *secrets,
+		Passphrase: *passphrase,
+	}
+	data, err := service.NewInventoryService(db).ExportWithLog(req, 0, commandUser, "", "")
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err = os.WriteFile(*output, data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "资源清单已导出到 %s\n", *output)
+	return nil
+}
+
+// runInventoryImport 导入资源清单（默认仅预览计划）
+func runInventoryImport(args []string) error {
+	fs := flag.NewFlagSet("inventory import", flag.ExitOnError)
+	configPath := fs.String("c", "", "配置文件路径")
+	file := fs.String("f", "", "清单文件路径")
+	format := fs.String("format", "yaml", "清单格式 (yaml, json)")
+	apply := fs.Bool("apply", false, "执行导入（默认仅预览）")
+	prune := fs.Bool("prune", false, "删除清单中不存在的资源")
+	passphrase := fs.String("passphrase", os.Getenv("GOST_PANEL_PASSPHRASE"), "解密口令")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("缺少清单文件参数 -f")
+	}
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	_, db, err := openForCommand(*configPath)
+	if err != nil {
+		return err
+	}
+
+	inv, err := service.ParseInventory(content, *format)
+	if err != nil {
+		return err
+	}
+
+	svc := service.NewInventoryService(db)
+	var plan *dto.InventoryPlan
+	if *apply {
+		plan, err = svc.Apply(inv, *passphrase, *prune, 0, commandUser, "", "")
+	} else {
+		plan, err = svc.Plan(inv, *passphrase, *prune)
+	}
+	if plan != nil {
+		printInventoryPlan(plan)
+	}
+	return err
+}
+
+// printInventoryPlan 输出导入计划
+func printInventoryPlan(plan *dto.InventoryPlan) {
+	if len(plan.Changes) == 0 {
+		fmt.Println("无变更")
+		return
+	}
+
+	symbols := map[string]string{
+		dto.InventoryActionCreate: "+",
+		dto.InventoryActionUpdate: "~",
+		dto.InventoryActionDelete: "-",
+	}
+	for _, c := range plan.Changes {
+		line := fmt.Sprintf("%s %s %q", symbols[c.Action], c.ResourceType, c.Name)
+		if len(c.Fields) > 0 {
+			fields, _ := json.Marshal(c.Fields)
+			line += " " + string(fields)
+		}
+		if c.Conflict != "" {
+			line += " [冲突: " + c.Conflict + "]"
+		}
+		fmt.Println(line)
+	}
+
+	status := "未执行"
+	if plan.Applied {
+		status = "已执行"
+	}
+	fmt.Printf("共 %d 项变更, %d 项冲突 (%s)\n", len(plan.Changes), plan.Conflicts, status)
+	if plan.Snapshot != "" {
+		fmt.Printf("导入前快照: %s\n", plan.Snapshot)
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"gost-panel/internal/config"
+	"gost-panel/internal/database"
+	"gost-panel/internal/migrate"
 	"gost-panel/internal/model"
 	"gost-panel/internal/router"
 	"gost-panel/internal/service"
@@ -14,12 +17,15 @@ import (
 	"gost-panel/pkg/logger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
-	gormlogger "gorm.io/gorm/logger"
 )
 
 func main() {
+	// 子命令（如 inventory）执行后直接退出
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	// 解析命令行参数
 	var configPath string
 	flag.StringVar(&configPath, "c", "", "配置文件路径")
@@ -59,7 +65,7 @@ func main() {
 	logger.Info("数据库初始化完成")
 
 	// 自动迁移
-	if err = autoMigrate(db); err != nil {
+	if err = autoMigrate(db, cfg.Database.ManualMigrate); err != nil {
 		logger.Fatalf("数据库迁移失败: %v", err)
 	}
 	logger.Info("数据库迁移完成")
@@ -74,6 +80,15 @@ func main() {
 		logger.Fatalf("初始化系统配置失败: %v", err)
 	}
 
+	// 后台服务：节点健康检测、规则状态同步、转发目标探测、延迟探测、自动备份
+	background := service.NewBackgroundManager(
+		service.NewNodeHealthService(db),
+		service.NewRuleSyncService(db),
+		service.NewTargetHealthService(db),
+		service.NewLatencyProbeService(db),
+		service.NewBackupService(db),
+	)
+
 	// 创建 Gin 引擎
 	engine := gin.New()
 
@@ -82,7 +97,7 @@ func main() {
 		Secret: cfg.JWT.Secret,
 		Expire: cfg.JWT.Expire,
 	}
-	r := router.NewRouter(db, jwtCfg)
+	r := router.NewRouter(db, jwtCfg, background)
 	r.Setup(engine)
 
 	// 启动服务器
@@ -93,17 +108,8 @@ func main() {
 		}
 	}()
 
-	// 启动节点健康检测服务
-	healthService := service.NewNodeHealthService(db)
-	healthService.Start()
-
-	// 启动规则状态同步服务
-	syncService := service.NewRuleSyncService(db)
-	syncService.Start()
-
-	// 启动自动备份服务
-	backupService := service.NewBackupService(db)
-	backupService.Start()
+	// 启动后台服务
+	background.StartAll()
 
 	// 优雅关闭
 	quit := make(chan os.Signal, 1)
@@ -113,52 +119,32 @@ func main() {
 	logger.Info("Gost Panel 正在关闭...")
 
 	// 停止相关的后台服务
-	healthService.Stop()
-	syncService.Stop()
-	backupService.Stop()
+	background.StopAll()
 }
 
-// initDatabase 初始化数据库
+// initDatabase 初始化数据库（sqlite / postgres / mysql）
 func initDatabase(cfg *config.Config) (*gorm.DB, error) {
-	// 配置 GORM 日志
-	var gormLogLevel gormlogger.LogLevel
-	switch cfg.Log.Level {
-	case "debug":
-		gormLogLevel = gormlogger.Info
-	case "info":
-		gormLogLevel = gormlogger.Warn
-	default:
-		gormLogLevel = gormlogger.Error
-	}
-
-	gormConfig := &gorm.Config{
-		Logger: gormlogger.Default.LogMode(gormLogLevel),
-	}
-
-	// 连接 SQLite 数据库
-	db, err := gorm.Open(sqlite.Open(cfg.Database.Path), gormConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	return db, nil
+	return database.Open(&cfg.Database, cfg.Log.Level)
 }
 
-// autoMigrate 自动迁移数据库表结构
-func autoMigrate(db *gorm.DB) error {
-	// 1. 执行自动迁移（添加新字段）
-	if err := db.AutoMigrate(
-		&model.User{},
-		&model.GostNode{},
-		&model.GostRule{},
-		&model.GostTunnel{},
-		&model.OperationLog{},
-		&model.SystemConfig{},
-	); err != nil {
+// autoMigrate 执行未执行的版本迁移
+// 数据库版本高于当前程序时拒绝启动，避免旧版本程序写坏新结构。
+// 升级会重新执行 migrate down 回滚的迁移；manual 为 true 时只初始化全新数据库，
+// 已有数据库版本落后时拒绝启动，由 migrate up 手动升级
+func autoMigrate(db *gorm.DB, manual bool) error {
+	current, err := migrate.Current(db)
+	if err != nil {
 		return err
 	}
+	if latest := migrate.Latest(); current > 0 && current < latest {
+		if manual {
+			return fmt.Errorf("数据库版本 (%d) 低于当前程序 (%d)，已开启 database.manual_migrate，请先执行 migrate up", current, latest)
+		}
+		logger.Warnf("数据库版本 (%d) 低于当前程序 (%d)，自动执行未执行的迁移（包括 migrate down 回滚的迁移，设置 database.manual_migrate 可关闭）", current, latest)
+	}
 
-	return nil
+	_, err = migrate.Up(db, 0)
+	return err
 }
 
 // initDefaultAdmin 初始化默认管理员
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"gost-panel/internal/database"
+	"gost-panel/internal/migrate"
+
+	"gorm.io/gorm"
+)
+
+// runMigrate 管理数据库结构版本
+//
+//	gost-panel migrate status [-c config]
+//	gost-panel migrate up [-c config] [-to 版本]
+//	gost-panel migrate down [-c config] [-steps 数量]
+func runMigrate(args []string) error {
+	usage := fmt.Errorf("用法: gost-panel migrate status|up|down [-c config] [-to 版本] [-steps 数量]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	configPath := fs.String("c", "", "配置文件路径")
+	to := fs.Int("to", 0, "升级到指定版本（默认最新）")
+	steps := fs.Int("steps", 1, "回滚的迁移数量")
+	_ = fs.Parse(args[1:])
+
+	// 迁移命令自行控制版本，不在打开数据库时自动升级
+	_, db, err := openDatabaseForCommand(*configPath)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	switch args[0] {
+	case "status":
+		return printMigrateStatus(db)
+	case "up":
+		done, err := migrate.Up(db, *to)
+		for _, m := range done {
+			fmt.Printf("已升级: %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("数据库已是最新版本")
+		}
+	case "down":
+		if *steps <= 0 {
+			return fmt.Errorf("-steps 必须大于 0")
+		}
+		done, err := migrate.Down(db, *steps)
+		for _, m := range done {
+			fmt.Printf("已回滚: %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		} else {
+			fmt.Println("提示: 面板启动时会重新执行回滚的迁移，如需保持当前版本请设置 database.manual_migrate: true")
+		}
+	default:
+		return usage
+	}
+
+	current, err := migrate.Current(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("当前版本: %d（最新 %d）\n", current, migrate.Latest())
+	return nil
+}
+
+// printMigrateStatus 输出迁移执行状态
+func printMigrateStatus(db *gorm.DB) error {
+	list, err := migrate.StatusList(db)
+	if err != nil {
+		return err
+	}
+	current, err := migrate.Current(db)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range list {
+		mark, appliedAt := " ", "未执行"
+		if st.Applied {
+			mark, appliedAt = "*", st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s %3d  %-30s %s\n", mark, st.Version, st.Name, appliedAt)
+	}
+	fmt.Printf("当前版本: %d（最新 %d）\n", current, migrate.Latest())
+	if current > migrate.Latest() {
+		fmt.Println("警告: 数据库版本高于当前程序，请升级面板")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"gost-panel/internal/router"
+)
+
+// runOpenAPI 输出 OpenAPI 文档，用于生成其他语言的客户端
+//
+//	gost-panel openapi [-o 文件]
+func runOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	output := fs.String("o", "", "输出文件（默认标准输出）")
+	_ = fs.Parse(args)
+
+	data, err := json.MarshalIndent(router.OpenAPISpec(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0644)
+}
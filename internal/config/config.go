@@ -19,6 +19,8 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
 	Admin    AdminConfig    `mapstructure:"admin"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Backup   BackupConfig   `mapstructure:"backup"`
 }
 
 // ServerConfig 服务器配置
@@ -29,8 +31,11 @@ type ServerConfig struct {
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Type string `mapstructure:"type"`
-	Path string `mapstructure:"path"`
+	Type string `mapstructure:"type"` // sqlite, postgres, mysql
+	Path string `mapstructure:"path"` // SQLite 数据库文件路径
+	DSN  string `mapstructure:"dsn"`  // PostgreSQL/MySQL 连接串
+
+	ManualMigrate bool `mapstructure:"manual_migrate"` // 启动时不自动升级已有数据库，由 migrate 命令管理版本
 }
 
 // JWTConfig JWT 配置
@@ -52,6 +57,17 @@ type AdminConfig struct {
 	Password string `mapstructure:"password"`
 }
 
+// MetricsConfig Prometheus 指标配置
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 是否开启 /metrics
+	Token   string `mapstructure:"token"`   // 抓取时需要的 Bearer Token，为空不校验
+}
+
+// BackupConfig 备份配置
+type BackupConfig struct {
+	MaxUploadMB int64 `mapstructure:"max_upload_mb"` // 上传备份文件的大小上限（MB）
+}
+
 // 全局配置实例
 var cfg *Config
 
@@ -143,6 +159,11 @@ func setDefaults(cfg *Config) {
 	if cfg.Admin.Password == "" {
 		cfg.Admin.Password = "admin123"
 	}
+
+	// 备份默认配置
+	if cfg.Backup.MaxUploadMB <= 0 {
+		cfg.Backup.MaxUploadMB = 1024
+	}
 }
 
 // Get 获取全局配置实例
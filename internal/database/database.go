@@ -0,0 +1,192 @@
+// Package database 负责按配置打开数据库，以及在不同数据库之间复制面板数据
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gost-panel/internal/config"
+	"gost-panel/internal/model"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// 支持的数据库类型
+const (
+	TypeSQLite   = "sqlite"
+	TypePostgres = "postgres"
+	TypeMySQL    = "mysql"
+)
+
+// copyBatchSize 复制数据时每批的行数
+const copyBatchSize = 500
+
+// Models 返回需要迁移和备份的全部模型
+// 顺序即写入顺序：被外键引用的表在前（节点、隧道先于规则），清空时按相反顺序
+func Models() []any {
+	return []any{
+		&model.User{},
+		&model.GostNode{},
+		&model.GostTunnel{},
+		&model.TunnelExit{},
+		&model.GostRule{},
+		&model.RuleServiceCounter{},
+		&model.OperationLog{},
+		&model.SystemConfig{},
+		&model.APIToken{},
+		&model.IPList{},
+		&model.LatencySample{},
+	}
+}
+
+// sqliteBusyTimeout 写锁等待时间，避免并发写入（如批量操作）时返回 database is locked
+const sqliteBusyTimeout = "_pragma=busy_timeout(5000)"
+
+// sqliteDSN 为 sqlite 路径附加连接参数，已带参数的路径保持不变
+func sqliteDSN(path string) string {
+	if strings.Contains(path, "?") {
+		return path
+	}
+	return path + "?" + sqliteBusyTimeout
+}
+
+// Dialector 根据配置创建 GORM 方言
+// sqlite 使用 path，postgres/mysql 使用 dsn
+func Dialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Type {
+	case "", TypeSQLite:
+		return sqlite.Open(sqliteDSN(cfg.Path)), nil
+	case TypePostgres, "postgresql":
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("postgres 需要配置 database.dsn")
+		}
+		return postgres.Open(cfg.DSN), nil
+	case TypeMySQL:
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("mysql 需要配置 database.dsn")
+		}
+		return mysql.Open(cfg.DSN), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型: %s", cfg.Type)
+	}
+}
+
+// Open 按配置打开数据库
+func Open(cfg *config.DatabaseConfig, logLevel string) (*gorm.DB, error) {
+	dialector, err := Dialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置 GORM 日志
+	var gormLogLevel gormlogger.LogLevel
+	switch logLevel {
+	case "debug":
+		gormLogLevel = gormlogger.Info
+	case "info":
+		gormLogLevel = gormlogger.Warn
+	default:
+		gormLogLevel = gormlogger.Error
+	}
+
+	return gorm.Open(dialector, &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormLogLevel),
+	})
+}
+
+// OpenSQLite 打开 SQLite 数据库文件（备份、迁移源等），不输出 SQL 日志
+func OpenSQLite(path string) (*gorm.DB, error) {
+	return gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+}
+
+// Close 关闭数据库连接
+func Close(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
+
+// Copy 将 src 中的全部面板数据复制到 dst，返回每张表复制的行数
+// dst 中对应表的现有数据会被清空；整个过程在 dst 的事务中完成，
+// src 在只读的可重复读事务中读取，保证复制的是一致的快照。
+// 先按 Models 的相反顺序清空全部表，再按顺序写入，满足 PostgreSQL/MySQL 的外键约束。
+// 源库缺少的表会被跳过，以兼容旧版本数据库。
+func Copy(dst, src *gorm.DB) (map[string]int64, error) {
+	counts := make(map[string]int64)
+
+	readOpts := &sql.TxOptions{ReadOnly: true}
+	if src.Dialector.Name() != TypeSQLite {
+		readOpts.Isolation = sql.LevelRepeatableRead
+	}
+
+	models := Models()
+	err := src.Transaction(func(srcTx *gorm.DB) error {
+		return dst.Transaction(func(dstTx *gorm.DB) error {
+			tables := make([]string, len(models))
+			for i, m := range models {
+				stmt := &gorm.Statement{DB: dstTx}
+				if err := stmt.Parse(m); err != nil {
+					return err
+				}
+				tables[i] = stmt.Schema.Table
+			}
+
+			// 子表先清空
+			for i := len(models) - 1; i >= 0; i-- {
+				if err := dstTx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(models[i]).Error; err != nil {
+					return fmt.Errorf("清空表 %s 失败: %w", tables[i], err)
+				}
+			}
+
+			for i, m := range models {
+				if !srcTx.Migrator().HasTable(tables[i]) {
+					continue
+				}
+
+				n, err := copyTable(dstTx, srcTx, m, tables[i])
+				if err != nil {
+					return fmt.Errorf("复制表 %s 失败: %w", tables[i], err)
+				}
+				counts[tables[i]] = n
+			}
+			return nil
+		})
+	}, readOpts)
+	return counts, err
+}
+
+// copyTable 复制单张表（包括软删除的记录）到已清空的目标表，保留主键
+func copyTable(dst, src *gorm.DB, m any, table string) (int64, error) {
+	// 跳过钩子（如用户密码加密），Select("*") 保证零值字段不会被列默认值覆盖
+	writer := dst.Session(&gorm.Session{SkipHooks: true}).Unscoped()
+
+	var total int64
+	batch := reflect.New(reflect.SliceOf(reflect.TypeOf(m).Elem())).Interface()
+	result := src.Unscoped().Model(m).Order("id").FindInBatches(batch, copyBatchSize, func(_ *gorm.DB, _ int) error {
+		if err := writer.Select("*").Create(batch).Error; err != nil {
+			return err
+		}
+		total += int64(reflect.ValueOf(batch).Elem().Len())
+		return nil
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// PostgreSQL 显式写入主键后需要同步自增序列
+	if dst.Dialector.Name() == TypePostgres && total > 0 {
+		if err := dst.Exec(fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%s', 'id'), (SELECT MAX(id) FROM %s))", table, table)).Error; err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gost-panel/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// openFKSQLite 打开启用外键检查的 SQLite 数据库，模拟 PostgreSQL/MySQL 的外键约束
+func openFKSQLite(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), name) + "?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { Close(db) })
+	if err = db.AutoMigrate(Models()...); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return db
+}
+
+// seed 写入一组有外键关联的数据：节点 <- 隧道 <- 规则，用户 <- API Token
+func seed(t *testing.T, db *gorm.DB, prefix string) {
+	t.Helper()
+	user := &model.User{Username: prefix + "-admin", Password: "x"}
+	entry := &model.GostNode{Name: prefix + "-entry", Address: "127.0.0.1", Port: 18080}
+	exit := &model.GostNode{Name: prefix + "-exit", Address: "127.0.0.2", Port: 18080}
+	for _, v := range []any{user, entry, exit} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("写入数据失败: %v", err)
+		}
+	}
+	tunnel := &model.GostTunnel{Name: prefix + "-tunnel", EntryNodeID: entry.ID, ExitNodeID: exit.ID, RelayPort: 20000}
+	if err := db.Create(tunnel).Error; err != nil {
+		t.Fatalf("写入隧道失败: %v", err)
+	}
+	rows := []any{
+		&model.TunnelExit{TunnelID: tunnel.ID, NodeID: entry.ID},
+		&model.GostRule{Name: prefix + "-rule", Type: model.RuleTypeTunnel, TunnelID: &tunnel.ID, ListenPort: 20001},
+		&model.APIToken{Name: prefix + "-token", UserID: user.ID, TokenHash: prefix + "-hash", Prefix: "gpt_" + prefix},
+		&model.OperationLog{UserID: 0, Username: "cli", Action: model.ActionCreate},
+	}
+	for _, v := range rows {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("写入 %T 失败: %v", v, err)
+		}
+	}
+}
+
+func TestCopyRespectsForeignKeys(t *testing.T) {
+	src := openFKSQLite(t, "src.db")
+	dst := openFKSQLite(t, "dst.db")
+	seed(t, src, "src")
+	seed(t, dst, "dst")
+
+	counts, err := Copy(dst, src)
+	if err != nil {
+		t.Fatalf("Copy 失败: %v", err)
+	}
+
+	want := map[string]int64{
+		"users":          1,
+		"nodes":          2,
+		"tunnels":        1,
+		"tunnel_exits":   1,
+		"rules":          1,
+		"api_tokens":     1,
+		"operation_logs": 1,
+	}
+	for table, n := range want {
+		if counts[table] != n {
+			t.Errorf("表 %s 复制 %d 行，期望 %d", table, counts[table], n)
+		}
+	}
+
+	// 目标库原有数据被替换
+	var names []string
+	if err = dst.Model(&model.GostNode{}).Order("id").Pluck("name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "src-entry" || names[1] != "src-exit" {
+		t.Errorf("节点 = %v，期望来自源库", names)
+	}
+}
@@ -26,3 +26,12 @@ type UserInfoResp struct {
 	Username string `json:"username"` // 用户名
 	Role     string `json:"role"`     // 角色
 }
+
+// ==================== API Token ====================
+
+// CreateAPITokenReq 创建 API Token 请求
+type CreateAPITokenReq struct {
+	Name          string   `json:"name" binding:"required,max=100"` // 名称
+	Scopes        []string `json:"scopes"`                          // 权限范围，为空表示全部权限
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0"` // 有效天数，0 表示永不过期
+}
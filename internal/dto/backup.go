@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// ==================== 备份管理相关 ====================
+
+// BackupFileResp 备份文件信息
+type BackupFileResp struct {
+	Name      string    `json:"name"`      // 文件名
+	Size      int64     `json:"size"`      // 文件大小 (bytes)
+	Format    string    `json:"format"`    // 格式: db / archive
+	Encrypted bool      `json:"encrypted"` // 是否加密
+	Trigger   string    `json:"trigger"`   // 触发来源: manual / auto / upload / pre_restore / pre_import / pre_delete
+	CreatedAt time.Time `json:"createdAt"` // 创建时间
+}
+
+// BackupManifest 备份归档清单
+type BackupManifest struct {
+	Format        string    `json:"format"`        // 归档格式标识
+	FormatVersion int       `json:"formatVersion"` // 归档格式版本
+	PanelVersion  string    `json:"panelVersion"`  // 生成备份的面板版本
+	SchemaVersion int       `json:"schemaVersion"` // 数据库结构版本
+	CreatedAt     time.Time `json:"createdAt"`     // 创建时间
+	Compression   string    `json:"compression"`   // 压缩算法
+	Encrypted     bool      `json:"encrypted"`     // 是否加密
+	Database      string    `json:"database"`      // 归档内数据库文件名
+	Size          int64     `json:"size"`          // 数据库大小 (bytes)
+	SHA256        string    `json:"sha256"`        // 数据库 SHA256 校验和
+}
+
+// RestoreBackupReq 恢复备份请求
+type RestoreBackupReq struct {
+	Passphrase string `json:"passphrase"` // 加密备份的口令，为空时使用系统配置中的口令
+}
+
+// RestoreBackupResp 恢复备份结果
+type RestoreBackupResp struct {
+	Restored     string `json:"restored"`     // 已恢复的备份文件名
+	SafetyBackup string `json:"safetyBackup"` // 恢复前自动创建的安全备份
+	Tables       int    `json:"tables"`       // 恢复的数据表数量
+	Tunnels      int    `json:"tunnels"`      // 重新启动的隧道数量
+	Rules        int    `json:"rules"`        // 重新启动的规则数量
+	Failed       int    `json:"failed"`       // 重新启动失败的数量
+
+	Manifest *BackupManifest `json:"manifest,omitempty"` // 归档清单（仅归档格式）
+}
@@ -0,0 +1,67 @@
+package dto
+
+// ==================== 批量操作相关 ====================
+
+// BatchRuleReq 规则批量操作请求
+// 操作对象：IDs 或 Filter 二选一，同时指定时以 IDs 为准
+type BatchRuleReq struct {
+	Action string      `json:"action" binding:"required,oneof=start stop restart delete"` // 操作类型
+	IDs    []uint      `json:"ids"`                                                       // 规则 ID 列表
+	Filter *RuleFilter `json:"filter"`                                                    // 筛选条件
+}
+
+// RuleFilter 规则筛选条件
+type RuleFilter struct {
+	NodeID   uint   `json:"node_id"`   // 节点 ID
+	TunnelID uint   `json:"tunnel_id"` // 隧道 ID
+	Type     string `json:"type"`      // 规则类型
+	Status   string `json:"status"`    // 状态
+	Keyword  string `json:"keyword"`   // 名称关键词
+}
+
+// IsEmpty 是否未设置任何条件
+func (f *RuleFilter) IsEmpty() bool {
+	return f == nil || (f.NodeID == 0 && f.TunnelID == 0 && f.Type == "" && f.Status == "" && f.Keyword == "")
+}
+
+// BatchTunnelReq 隧道批量操作请求
+// 操作对象：IDs 或 Filter 二选一，同时指定时以 IDs 为准
+type BatchTunnelReq struct {
+	Action string        `json:"action" binding:"required,oneof=start stop restart delete"` // 操作类型
+	IDs    []uint        `json:"ids"`                                                       // 隧道 ID 列表
+	Filter *TunnelFilter `json:"filter"`                                                    // 筛选条件
+}
+
+// TunnelFilter 隧道筛选条件
+type TunnelFilter struct {
+	NodeID  uint   `json:"node_id"` // 节点 ID（入口或出口）
+	Status  string `json:"status"`  // 状态
+	Keyword string `json:"keyword"` // 名称关键词
+}
+
+// IsEmpty 是否未设置任何条件
+func (f *TunnelFilter) IsEmpty() bool {
+	return f == nil || (f.NodeID == 0 && f.Status == "" && f.Keyword == "")
+}
+
+// BatchItemResult 单个对象的执行结果
+type BatchItemResult struct {
+	Resource string `json:"resource,omitempty"` // 资源类型（涉及多种资源时）
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Success  bool   `json:"success"`
+	Skipped  bool   `json:"skipped,omitempty"` // 无需执行或无法自动处理
+	Code     int    `json:"code,omitempty"`    // 失败时的错误码
+	Error    string `json:"error,omitempty"`   // 失败或跳过的原因
+}
+
+// BatchResp 批量操作结果
+type BatchResp struct {
+	Action    string            `json:"action"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Skipped   int               `json:"skipped"`
+	Failed    int               `json:"failed"`
+	Snapshot  string            `json:"snapshot,omitempty"` // 批量删除前的自动备份
+	Results   []BatchItemResult `json:"results"`
+}
@@ -0,0 +1,126 @@
+package dto
+
+// ==================== 资源清单导入导出相关 ====================
+
+// InventoryVersion 清单文档格式版本
+const InventoryVersion = "v1"
+
+// Inventory 面板资源清单
+// 节点、隧道、IP 列表、规则之间通过名称引用，不依赖数据库 ID
+type Inventory struct {
+	Version    string            `json:"version" yaml:"version"`                             // 清单格式版本
+	ExportedAt string            `json:"exported_at,omitempty" yaml:"exported_at,omitempty"` // 导出时间
+	Secrets    string            `json:"secrets,omitempty" yaml:"secrets,omitempty"`         // 密钥处理方式 (omit, encrypt)
+	Nodes      []InventoryNode   `json:"nodes" yaml:"nodes"`                                 // 节点列表
+	Tunnels    []InventoryTunnel `json:"tunnels" yaml:"tunnels"`                             // 隧道列表
+	IPLists    []InventoryIPList `json:"ip_lists,omitempty" yaml:"ip_lists,omitempty"`       // 命名 IP 列表
+	Rules      []InventoryRule   `json:"rules" yaml:"rules"`                                 // 规则列表
+}
+
+// InventoryNode 清单中的节点
+type InventoryNode struct {
+	Name     string `json:"name" yaml:"name"`                             // 节点名称
+	Address  string `json:"address" yaml:"address"`                       // IP 或域名
+	Port     int    `json:"port" yaml:"port"`                             // 端口
+	Username string `json:"username,omitempty" yaml:"username,omitempty"` // API 认证用户名
+	Password string `json:"password,omitempty" yaml:"password,omitempty"` // API 认证密码（省略或加密）
+	Remark   string `json:"remark,omitempty" yaml:"remark,omitempty"`     // 备注
+
+	PortRanges    string `json:"port_ranges,omitempty" yaml:"port_ranges,omitempty"`       // 端口池范围
+	ReservedPorts string `json:"reserved_ports,omitempty" yaml:"reserved_ports,omitempty"` // 保留端口
+}
+
+// InventoryTunnel 清单中的隧道
+type InventoryTunnel struct {
+	Name      string `json:"name" yaml:"name"`                         // 隧道名称
+	EntryNode string `json:"entry_node" yaml:"entry_node"`             // 入口节点名称
+	ExitNode  string `json:"exit_node" yaml:"exit_node"`               // 出口节点名称
+	Protocol  string `json:"protocol" yaml:"protocol"`                 // 协议类型
+	RelayPort int    `json:"relay_port" yaml:"relay_port"`             // 出口节点 Relay 端口
+	Remark    string `json:"remark,omitempty" yaml:"remark,omitempty"` // 备注
+
+	Options *TunnelOptions `json:"options,omitempty" yaml:"options,omitempty"` // 传输参数（KCP 密钥按 secrets 省略或加密）
+
+	ExtraExitNodes []string `json:"extra_exit_nodes,omitempty" yaml:"extra_exit_nodes,omitempty"` // 附加出口节点名称（按顺序）
+	Strategy       string   `json:"strategy,omitempty" yaml:"strategy,omitempty"`                 // 多出口选择策略
+}
+
+// InventoryIPList 清单中的命名 IP 列表
+type InventoryIPList struct {
+	Name    string   `json:"name" yaml:"name"`                         // 列表名称
+	Entries []string `json:"entries" yaml:"entries"`                   // IP 或 CIDR
+	Remark  string   `json:"remark,omitempty" yaml:"remark,omitempty"` // 备注
+}
+
+// InventoryRule 清单中的规则
+type InventoryRule struct {
+	Name          string   `json:"name" yaml:"name"`                                           // 规则名称
+	Type          string   `json:"type" yaml:"type"`                                           // 规则类型
+	Node          string   `json:"node,omitempty" yaml:"node,omitempty"`                       // 入口节点名称（端口转发、直接出网的代理/反向代理）
+	Tunnel        string   `json:"tunnel,omitempty" yaml:"tunnel,omitempty"`                   // 隧道名称（隧道转发、经隧道出口的代理/反向代理）
+	ListenPort    int      `json:"listen_port" yaml:"listen_port"`                             // 监听端口
+	Protocol      string   `json:"protocol,omitempty" yaml:"protocol,omitempty"`               // 转发协议：tcp | udp | both，默认 both
+	ListenPortEnd int      `json:"listen_port_end,omitempty" yaml:"listen_port_end,omitempty"` // 端口范围结束端口
+	PortMapping   string   `json:"port_mapping,omitempty" yaml:"port_mapping,omitempty"`       // 端口范围目标映射方式
+	Targets       []string `json:"targets" yaml:"targets"`                                     // 目标列表
+	Strategy      string   `json:"strategy,omitempty" yaml:"strategy,omitempty"`               // 负载均衡策略
+	EnableTLS     bool     `json:"enable_tls,omitempty" yaml:"enable_tls,omitempty"`           // 是否启用 TLS
+	Remark        string   `json:"remark,omitempty" yaml:"remark,omitempty"`                   // 备注
+
+	// 代理规则
+	ProxyType  string      `json:"proxy_type,omitempty" yaml:"proxy_type,omitempty"`   // 代理类型：http | socks5 | auto
+	ProxyUsers []ProxyUser `json:"proxy_users,omitempty" yaml:"proxy_users,omitempty"` // 代理认证用户，密码省略或加密
+
+	// 反向代理规则
+	Host string `json:"host,omitempty" yaml:"host,omitempty"` // 域名（HTTP Host / TLS SNI）
+
+	// 故障转移
+	MaxFails      int            `json:"max_fails,omitempty" yaml:"max_fails,omitempty"`           // 最大失败次数，不填为默认值
+	FailTimeout   int            `json:"fail_timeout,omitempty" yaml:"fail_timeout,omitempty"`     // 失败超时（秒），不填为默认值
+	TargetOptions []TargetOption `json:"target_options,omitempty" yaml:"target_options,omitempty"` // 目标权重与备用设置
+
+	// 来源 IP 访问控制
+	AllowSources []string `json:"allow_sources,omitempty" yaml:"allow_sources,omitempty"` // 允许的来源 IP / CIDR
+	DenySources  []string `json:"deny_sources,omitempty" yaml:"deny_sources,omitempty"`   // 拒绝的来源 IP / CIDR
+	AllowLists   []string `json:"allow_lists,omitempty" yaml:"allow_lists,omitempty"`     // 允许的 IP 列表名称
+	DenyLists    []string `json:"deny_lists,omitempty" yaml:"deny_lists,omitempty"`       // 拒绝的 IP 列表名称
+}
+
+// ExportInventoryReq 导出清单请求
+type ExportInventoryReq struct {
+	Format     string `json:"format" binding:"omitempty,oneof=yaml json"`     // 输出格式，默认 yaml
+	Secrets    string `json:"secrets" binding:"omitempty,oneof=omit encrypt"` // 密钥处理方式，默认 omit
+	Passphrase string `json:"passphrase"`                                     // 加密口令（secrets=encrypt 时必填）
+}
+
+// ImportInventoryReq 导入清单请求
+type ImportInventoryReq struct {
+	Content    string `json:"content" binding:"required"`                 // 清单内容
+	Format     string `json:"format" binding:"omitempty,oneof=yaml json"` // 内容格式，默认 yaml
+	Passphrase string `json:"passphrase"`                                 // 解密口令（清单包含加密密钥时必填）
+	Prune      bool   `json:"prune"`                                      // 是否删除清单中不存在的资源
+}
+
+// 清单变更动作
+const (
+	InventoryActionCreate = "create" // 新建
+	InventoryActionUpdate = "update" // 更新
+	InventoryActionDelete = "delete" // 删除
+)
+
+// InventoryChange 单项变更
+type InventoryChange struct {
+	Action       string   `json:"action"`             // 变更动作
+	ResourceType string   `json:"resource_type"`      // 资源类型
+	Name         string   `json:"name"`               // 资源名称
+	Fields       []string `json:"fields,omitempty"`   // 变更字段（更新时）
+	Conflict     string   `json:"conflict,omitempty"` // 无法执行的原因
+}
+
+// InventoryPlan 导入计划（差异预览）
+type InventoryPlan struct {
+	Changes   []InventoryChange `json:"changes"`   // 变更列表
+	Conflicts int               `json:"conflicts"` // 冲突数量
+	Applied   bool              `json:"applied"`   // 是否已执行
+	Snapshot  string            `json:"snapshot"`  // 执行前自动创建的备份
+}
@@ -0,0 +1,18 @@
+package dto
+
+// ==================== IP 列表相关 ====================
+
+// CreateIPListReq 创建 IP 列表请求
+type CreateIPListReq struct {
+	Name    string   `json:"name" binding:"required,min=1,max=100"` // 列表名称
+	Entries []string `json:"entries"`                               // IP 或 CIDR 条目
+	Remark  string   `json:"remark"`                                // 备注
+}
+
+// UpdateIPListReq 更新 IP 列表请求
+// 引用该列表的运行中规则会立即按新条目更新准入控制器
+type UpdateIPListReq struct {
+	Name    string   `json:"name" binding:"required,min=1,max=100"` // 列表名称
+	Entries []string `json:"entries"`                               // IP 或 CIDR 条目（替换原有条目）
+	Remark  string   `json:"remark"`                                // 备注
+}
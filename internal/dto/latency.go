@@ -0,0 +1,37 @@
+package dto
+
+// LatencyStatsReq 延迟统计请求
+type LatencyStatsReq struct {
+	Hours    int    `form:"hours" binding:"omitempty,min=1,max=168"`           // 统计窗口（小时），默认 1
+	Path     string `form:"path" binding:"omitempty,oneof=node target tunnel"` // 路径类型筛选
+	NodeID   uint   `form:"node_id"`                                           // 节点 ID 筛选（隧道路径为出口节点）
+	RuleID   uint   `form:"rule_id"`                                           // 规则 ID 筛选
+	TunnelID uint   `form:"tunnel_id"`                                         // 隧道 ID 筛选
+}
+
+// SetDefaults 设置默认值
+func (r *LatencyStatsReq) SetDefaults() {
+	if r.Hours == 0 {
+		r.Hours = 1
+	}
+}
+
+// LatencyPathStats 单条探测路径在统计窗口内的延迟与丢失率
+// 延迟只统计成功的探测，窗口内全部失败时为 null
+type LatencyPathStats struct {
+	Path       string   `json:"path"`                  // 路径类型：node、target、tunnel
+	NodeID     uint     `json:"node_id,omitempty"`     // 节点 ID（隧道路径为出口节点）
+	NodeName   string   `json:"node_name,omitempty"`   // 节点名称
+	RuleID     uint     `json:"rule_id,omitempty"`     // 规则 ID
+	RuleName   string   `json:"rule_name,omitempty"`   // 规则名称
+	TunnelID   uint     `json:"tunnel_id,omitempty"`   // 隧道 ID
+	TunnelName string   `json:"tunnel_name,omitempty"` // 隧道名称
+	Target     string   `json:"target"`                // 探测地址
+	Samples    int64    `json:"samples"`               // 探测次数
+	Lost       int64    `json:"lost"`                  // 失败次数
+	LossRate   float64  `json:"loss_rate"`             // 丢失率（百分比）
+	MinMs      *float64 `json:"min_ms"`                // 最小延迟（毫秒）
+	AvgMs      *float64 `json:"avg_ms"`                // 平均延迟（毫秒）
+	MaxMs      *float64 `json:"max_ms"`                // 最大延迟（毫秒）
+	Best       bool     `json:"best,omitempty"`        // 隧道路径中丢失率最低、平均延迟最低的出口
+}
@@ -11,6 +11,9 @@ type CreateNodeReq struct {
 	Username string `json:"username"`                                // API 认证用户名
 	Password string `json:"password"`                                // API 认证密码
 	Remark   string `json:"remark"`                                  // 备注
+
+	PortRanges    string `json:"port_ranges"`    // 端口池范围，如 "10000-20000,30000"，为空表示不限制
+	ReservedPorts string `json:"reserved_ports"` // 保留端口，如 "22,80,443"
 }
 
 // UpdateNodeReq 更新节点请求
@@ -21,6 +24,9 @@ type UpdateNodeReq struct {
 	Username string `json:"username"`                                // API 认证用户名
 	Password string `json:"password"`                                // API 认证密码
 	Remark   string `json:"remark"`                                  // 备注
+
+	PortRanges    string `json:"port_ranges"`    // 端口池范围，如 "10000-20000,30000"，为空表示不限制
+	ReservedPorts string `json:"reserved_ports"` // 保留端口，如 "22,80,443"
 }
 
 // NodeListReq 节点列表请求
@@ -40,3 +46,33 @@ func (r *NodeListReq) SetDefaults() {
 		r.PageSize = 10
 	}
 }
+
+// NodeMaintenanceReq 设置维护模式请求
+type NodeMaintenanceReq struct {
+	Enabled bool   `json:"enabled"`                            // 开启或关闭维护模式
+	Reason  string `json:"reason" binding:"omitempty,max=255"` // 维护原因
+}
+
+// DrainNodeReq 排空节点请求
+// 端口转发规则迁移到替换节点，以该节点为出口的隧道改用替换节点作为出口
+type DrainNodeReq struct {
+	TargetNodeID uint `json:"target_node_id" binding:"required"` // 替换节点 ID
+	KeepStats    bool `json:"keep_stats"`                        // 迁移规则时保留流量计数
+	AutoPort     bool `json:"auto_port"`                         // 端口在替换节点上冲突时从端口池自动分配
+}
+
+// NodePortUsage 节点端口占用
+type NodePortUsage struct {
+	Port  int    `json:"port"`  // 端口
+	Owner string `json:"owner"` // 占用者，如 "规则 web"、"隧道 t1 Relay"、"节点 API"
+}
+
+// NodePortsResp 节点端口池信息
+type NodePortsResp struct {
+	NodeID        uint            `json:"node_id"`        // 节点 ID
+	PortRanges    string          `json:"port_ranges"`    // 端口池范围，为空时自动分配使用默认范围
+	ReservedPorts string          `json:"reserved_ports"` // 保留端口
+	Live          bool            `json:"live"`           // 是否包含节点上实际运行的服务
+	Used          []NodePortUsage `json:"used"`           // 已占用端口
+	NextFree      int             `json:"next_free"`      // 下一个可分配的端口，0 表示端口池已用尽
+}
@@ -1,34 +1,96 @@
 package dto
 
+import "time"
+
 // ==================== 规则管理相关 ====================
 
+// ProxyUser 代理认证用户
+type ProxyUser struct {
+	Username string `json:"username" yaml:"username"`                     // 用户名
+	Password string `json:"password,omitempty" yaml:"password,omitempty"` // 密码
+}
+
+// TargetOption 转发目标的权重与备用设置，Target 须为规则目标列表中的地址
+type TargetOption struct {
+	Target string `json:"target" yaml:"target"`                     // 目标地址
+	Weight int    `json:"weight,omitempty" yaml:"weight,omitempty"` // 权重（1-100），不填为默认
+	Backup bool   `json:"backup,omitempty" yaml:"backup,omitempty"` // 备用目标：其他目标全部失效时才使用
+}
+
 // CreateRuleReq 创建规则请求
 // 入口选择：NodeID 或 TunnelID 二选一
 // - 端口转发 (forward)：NodeID 必填，直接在该节点上创建转发服务
 // - 隧道转发 (tunnel)：TunnelID 必填，在隧道的入口节点上创建转发服务
+// - 代理 (proxy)：指定 NodeID 时直接出网，指定 TunnelID 时经隧道出口
+// - 反向代理 (reverse)：入口同代理，按域名转发到目标，可与其他反向代理规则共用端口
 type CreateRuleReq struct {
-	NodeID     *uint  `json:"node_id"`                                        // 入口节点 ID（端口转发时必填）
-	TunnelID   *uint  `json:"tunnel_id"`                                      // 隧道 ID（隧道转发时必填）
-	Name       string `json:"name" binding:"required,min=1,max=100"`          // 规则名称
-	Type       string `json:"type" binding:"required,oneof=forward tunnel"`   // 规则类型
-	ListenPort int    `json:"listen_port" binding:"required,min=1,max=65535"` // 监听端口（TCP+UDP 全流量）
+	NodeID     *uint  `json:"node_id"`                                                    // 入口节点 ID（端口转发时必填）
+	TunnelID   *uint  `json:"tunnel_id"`                                                  // 隧道 ID（隧道转发时必填）
+	Name       string `json:"name" binding:"required,min=1,max=100"`                      // 规则名称
+	Type       string `json:"type" binding:"required,oneof=forward tunnel proxy reverse"` // 规则类型
+	ListenPort int    `json:"listen_port" binding:"omitempty,min=1,max=65535"`            // 监听端口，为 0 时从入口节点端口池自动分配
+	Protocol   string `json:"protocol" binding:"omitempty,oneof=tcp udp both"`            // 转发协议，默认 both（TCP+UDP）
+
+	// 端口范围：监听 ListenPort ~ ListenPortEnd；range 映射时目标端口随之偏移（目标地址中为起始端口），single 映射时全部转发到目标端口
+	ListenPortEnd int    `json:"listen_port_end" binding:"omitempty,min=1,max=65535"` // 结束端口，不填为单端口规则
+	PortMapping   string `json:"port_mapping" binding:"omitempty,oneof=range single"` // 目标端口映射方式，默认 range（1:1）
 
-	Targets   []string `json:"targets"`                                                 // 多目标列表
+	// 代理规则
+	ProxyType  string      `json:"proxy_type" binding:"omitempty,oneof=http socks5 auto"` // 代理类型，默认 auto
+	ProxyUsers []ProxyUser `json:"proxy_users"`                                           // 认证用户，至少一个
+
+	// 反向代理规则：同一入口节点同一端口的规则按域名分流
+	Host string `json:"host" binding:"omitempty,max=255"` // 域名（HTTP Host / TLS SNI），支持 *.example.com
+
+	Targets   []string `json:"targets"`                                                 // 多目标列表（代理规则不使用）
 	Strategy  string   `json:"strategy" binding:"omitempty,oneof=round rand fifo hash"` // 负载均衡策略
 	EnableTLS bool     `json:"enable_tls"`                                              // 是否启用 TLS
 
+	// 故障转移：目标连续失败 MaxFails 次后 FailTimeout 秒内不再分配流量，不填时为 3 次 / 30 秒
+	MaxFails      int            `json:"max_fails" binding:"omitempty,min=1,max=100"`      // 最大失败次数
+	FailTimeout   int            `json:"fail_timeout" binding:"omitempty,min=1,max=86400"` // 失败超时（秒）
+	TargetOptions []TargetOption `json:"target_options"`                                   // 目标权重与备用设置
+
+	// 来源 IP 访问控制：允许列表不为空时只接受匹配的来源，拒绝优先
+	AllowSources []string `json:"allow_sources"` // 允许的来源 IP / CIDR
+	DenySources  []string `json:"deny_sources"`  // 拒绝的来源 IP / CIDR
+	AllowLists   []uint   `json:"allow_lists"`   // 引用的命名 IP 列表 ID（允许）
+	DenyLists    []uint   `json:"deny_lists"`    // 引用的命名 IP 列表 ID（拒绝）
+
 	Remark string `json:"remark"` // 备注
 }
 
 // UpdateRuleReq 更新规则请求
 type UpdateRuleReq struct {
-	Name       string `json:"name" binding:"required,min=1,max=100"`          // 规则名称
-	ListenPort int    `json:"listen_port" binding:"required,min=1,max=65535"` // 监听端口（TCP+UDP 全流量）
+	Name       string `json:"name" binding:"required,min=1,max=100"`           // 规则名称
+	ListenPort int    `json:"listen_port" binding:"required,min=1,max=65535"`  // 监听端口
+	Protocol   string `json:"protocol" binding:"omitempty,oneof=tcp udp both"` // 转发协议，默认 both（TCP+UDP）
+
+	ListenPortEnd int    `json:"listen_port_end" binding:"omitempty,min=1,max=65535"` // 结束端口，不填为单端口规则
+	PortMapping   string `json:"port_mapping" binding:"omitempty,oneof=range single"` // 目标端口映射方式，默认 range（1:1）
+
+	// 代理规则
+	ProxyType  string      `json:"proxy_type" binding:"omitempty,oneof=http socks5 auto"` // 代理类型，默认 auto
+	ProxyUsers []ProxyUser `json:"proxy_users"`                                           // 认证用户（替换原有用户），至少一个
 
-	Targets   []string `json:"targets"`                                                 // 多目标列表
+	// 反向代理规则
+	Host string `json:"host" binding:"omitempty,max=255"` // 域名（HTTP Host / TLS SNI），支持 *.example.com
+
+	Targets   []string `json:"targets"`                                                 // 多目标列表（代理规则不使用）
 	Strategy  string   `json:"strategy" binding:"omitempty,oneof=round rand fifo hash"` // 负载均衡策略
 	EnableTLS bool     `json:"enable_tls"`                                              // 是否启用 TLS
 
+	// 故障转移：目标连续失败 MaxFails 次后 FailTimeout 秒内不再分配流量，不填时为 3 次 / 30 秒
+	MaxFails      int            `json:"max_fails" binding:"omitempty,min=1,max=100"`      // 最大失败次数
+	FailTimeout   int            `json:"fail_timeout" binding:"omitempty,min=1,max=86400"` // 失败超时（秒）
+	TargetOptions []TargetOption `json:"target_options"`                                   // 目标权重与备用设置
+
+	// 来源 IP 访问控制：允许列表不为空时只接受匹配的来源，拒绝优先
+	AllowSources []string `json:"allow_sources"` // 允许的来源 IP / CIDR
+	DenySources  []string `json:"deny_sources"`  // 拒绝的来源 IP / CIDR
+	AllowLists   []uint   `json:"allow_lists"`   // 引用的命名 IP 列表 ID（允许）
+	DenyLists    []uint   `json:"deny_lists"`    // 引用的命名 IP 列表 ID（拒绝）
+
 	Remark string `json:"remark"` // 备注
 }
 
@@ -52,3 +114,48 @@ func (r *RuleListReq) SetDefaults() {
 		r.PageSize = 10
 	}
 }
+
+// CloneRuleReq 克隆规则请求
+// 目标：NodeID 或 TunnelID 二选一，指定节点时为端口转发，指定隧道时为隧道转发
+type CloneRuleReq struct {
+	NodeID     *uint  `json:"node_id"`                                         // 目标节点 ID
+	TunnelID   *uint  `json:"tunnel_id"`                                       // 目标隧道 ID
+	Name       string `json:"name" binding:"omitempty,max=100"`                // 新规则名称，默认为 "原名称-copy"
+	ListenPort int    `json:"listen_port" binding:"omitempty,min=1,max=65535"` // 监听端口（端口范围规则为起始端口，范围大小不变），默认沿用原端口
+	AutoPort   bool   `json:"auto_port"`                                       // 未指定端口且原端口在目标上冲突时自动分配
+	Start      bool   `json:"start"`                                           // 创建后立即启动，启动失败时撤销克隆
+	StopSource bool   `json:"stop_source"`                                     // 启动成功后停止原规则
+	KeepStats  bool   `json:"keep_stats"`                                      // 复制原规则的流量计数
+}
+
+// MigrateRuleReq 迁移规则请求
+// 规则 ID 不变，入口改为目标节点或隧道；运行中的规则会在目标上重新启动，失败时恢复原入口
+type MigrateRuleReq struct {
+	NodeID     *uint `json:"node_id"`                                         // 目标节点 ID
+	TunnelID   *uint `json:"tunnel_id"`                                       // 目标隧道 ID
+	ListenPort int   `json:"listen_port" binding:"omitempty,min=1,max=65535"` // 监听端口（端口范围规则为起始端口，范围大小不变），默认沿用原端口
+	AutoPort   bool  `json:"auto_port"`                                       // 未指定端口且原端口在目标上冲突时自动分配
+	Start      bool  `json:"start"`                                           // 原规则未运行时也在目标上启动
+	KeepStats  bool  `json:"keep_stats"`                                      // 保留流量计数，否则清零
+}
+
+// RuleTargetStatus 转发目标状态
+type RuleTargetStatus struct {
+	Target    string     `json:"target"`               // 目标地址
+	Weight    int        `json:"weight,omitempty"`     // 权重，0 为默认
+	Backup    bool       `json:"backup,omitempty"`     // 是否为备用目标
+	Failed    bool       `json:"failed"`               // 是否被视为失效（不再分配流量）
+	Fails     int        `json:"fails"`                // 连续失败次数
+	LastError string     `json:"last_error,omitempty"` // 最近一次失败原因
+	LastCheck *time.Time `json:"last_check,omitempty"` // 最近一次探测时间，未探测时为空
+}
+
+// RuleTargetsResp 规则转发目标状态
+type RuleTargetsResp struct {
+	RuleID      uint               `json:"rule_id"`
+	MaxFails    int                `json:"max_fails"`        // 生效的最大失败次数
+	FailTimeout int                `json:"fail_timeout"`     // 生效的失败超时（秒）
+	Checked     bool               `json:"checked"`          // 是否由面板探测（规则运行中且目标可探测）
+	Reason      string             `json:"reason,omitempty"` // 未探测的原因
+	Targets     []RuleTargetStatus `json:"targets"`
+}
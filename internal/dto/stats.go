@@ -0,0 +1,36 @@
+package dto
+
+// ==================== 仪表盘统计相关 ====================
+
+// DashboardStats 仪表盘统计
+type DashboardStats struct {
+	Nodes   NodeStats   `json:"nodes"`
+	Rules   RuleStats   `json:"rules"`
+	Tunnels TunnelStats `json:"tunnels"`
+	Version string      `json:"version"`
+}
+
+// NodeStats 节点统计
+type NodeStats struct {
+	Total   int64 `json:"total"`
+	Online  int64 `json:"online"`
+	Offline int64 `json:"offline"`
+}
+
+// RuleStats 规则统计
+type RuleStats struct {
+	Total       int64 `json:"total"`
+	Running     int64 `json:"running"`
+	Stopped     int64 `json:"stopped"`
+	ForwardType int64 `json:"forward_type"` // 端口转发类型数量
+	TunnelType  int64 `json:"tunnel_type"`  // 隧道转发类型数量
+	ProxyType   int64 `json:"proxy_type"`   // 代理类型数量
+	ReverseType int64 `json:"reverse_type"` // 反向代理类型数量
+}
+
+// TunnelStats 隧道统计
+type TunnelStats struct {
+	Total   int64 `json:"total"`
+	Running int64 `json:"running"`
+	Stopped int64 `json:"stopped"`
+}
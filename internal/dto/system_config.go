@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // ==================== 系统配置相关 ====================
 
 // SystemConfigResp 系统配置响应
@@ -41,8 +43,24 @@ type LogConfigResp struct {
 }
 
 type BackupConfigResp struct {
-	AutoBackup     bool `json:"autoBackup"`
-	RetentionCount int  `json:"retentionCount"`
+	AutoBackup     bool         `json:"autoBackup"`
+	RetentionCount int          `json:"retentionCount"`
+	Schedule       string       `json:"schedule"`               // cron 表达式
+	NextBackupAt   *time.Time   `json:"nextBackupAt,omitempty"` // 下次自动备份时间
+	Compress       bool         `json:"compress"`               // 压缩为归档
+	Passphrase     string       `json:"passphrase"`             // 归档加密口令，已设置时返回占位符
+	S3             S3ConfigResp `json:"s3"`
+}
+
+type S3ConfigResp struct {
+	Enabled   bool   `json:"enabled"`
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"` // 已设置时返回占位符，提交占位符表示保持不变
+	Prefix    string `json:"prefix"`
+	PathStyle bool   `json:"pathStyle"`
 }
 
 // UpdateSystemConfigReq 更新系统配置请求
@@ -79,6 +97,21 @@ type LogConfigReq struct {
 }
 
 type BackupConfigReq struct {
-	AutoBackup     bool `json:"autoBackup"`
-	RetentionCount int  `json:"retentionCount"`
+	AutoBackup     bool        `json:"autoBackup"`
+	RetentionCount int         `json:"retentionCount"`
+	Schedule       string      `json:"schedule"`   // cron 表达式
+	Compress       bool        `json:"compress"`   // 压缩为归档
+	Passphrase     string      `json:"passphrase"` // 归档加密口令，为空不加密，占位符表示保持不变
+	S3             S3ConfigReq `json:"s3"`
+}
+
+type S3ConfigReq struct {
+	Enabled   bool   `json:"enabled"`
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"` // 已设置时返回占位符，提交占位符表示保持不变
+	Prefix    string `json:"prefix"`
+	PathStyle bool   `json:"pathStyle"`
 }
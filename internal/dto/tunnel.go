@@ -8,8 +8,13 @@ type CreateTunnelReq struct {
 	EntryNodeID uint   `json:"entry_node_id" binding:"required"`                                                        // 入口节点 ID
 	ExitNodeID  uint   `json:"exit_node_id" binding:"required"`                                                         // 出口节点 ID
 	Protocol    string `json:"protocol" binding:"required,oneof=tcp udp tls mtls ws mws wss mwss h2 grpc quic kcp ssh"` // 协议类型
-	RelayPort   int    `json:"relay_port" binding:"required,min=1,max=65535"`                                           // 出口节点 Relay 端口
+	RelayPort   int    `json:"relay_port" binding:"omitempty,min=1,max=65535"`                                          // 出口节点 Relay 端口，为 0 时从出口节点端口池自动分配
 	Remark      string `json:"remark"`                                                                                  // 备注
+
+	Options TunnelOptions `json:"options"` // 传输参数，按协议分组
+
+	ExtraExitNodeIDs []uint `json:"extra_exit_node_ids"`                                // 附加出口节点 ID（按顺序），使用与主出口相同的 Relay 端口
+	Strategy         string `json:"strategy" binding:"omitempty,oneof=round rand fifo"` // 多出口选择策略，默认 round
 }
 
 // UpdateTunnelReq 更新隧道请求
@@ -18,6 +23,67 @@ type UpdateTunnelReq struct {
 	Protocol  string `json:"protocol" binding:"required,oneof=tcp udp tls mtls ws mws wss mwss h2 grpc quic kcp ssh"` // 协议类型
 	RelayPort int    `json:"relay_port" binding:"required,min=1,max=65535"`                                           // 出口节点 Relay 端口
 	Remark    string `json:"remark"`                                                                                  // 备注
+
+	Options TunnelOptions `json:"options"` // 传输参数，按协议分组
+
+	ExtraExitNodeIDs []uint `json:"extra_exit_node_ids"`                                // 附加出口节点 ID（按顺序），使用与主出口相同的 Relay 端口
+	Strategy         string `json:"strategy" binding:"omitempty,oneof=round rand fifo"` // 多出口选择策略，默认 round
+}
+
+// TunnelOptions 隧道传输参数，每组只能用于对应的协议
+type TunnelOptions struct {
+	WS   *TunnelWSOptions   `json:"ws,omitempty" yaml:"ws,omitempty"`     // WebSocket：ws、mws、wss、mwss
+	TLS  *TunnelTLSOptions  `json:"tls,omitempty" yaml:"tls,omitempty"`   // TLS：tls、mtls、wss、mwss、h2、grpc、quic
+	Mux  *TunnelMuxOptions  `json:"mux,omitempty" yaml:"mux,omitempty"`   // 多路复用：mtls、mws、mwss
+	QUIC *TunnelQUICOptions `json:"quic,omitempty" yaml:"quic,omitempty"` // QUIC
+	KCP  *TunnelKCPOptions  `json:"kcp,omitempty" yaml:"kcp,omitempty"`   // KCP
+}
+
+// TunnelWSOptions WebSocket 参数
+type TunnelWSOptions struct {
+	Path string `json:"path,omitempty" yaml:"path,omitempty"` // 请求路径，默认 /ws
+	Host string `json:"host,omitempty" yaml:"host,omitempty"` // 入口节点请求时的 Host 头
+}
+
+// TunnelTLSOptions TLS 参数，证书文件路径均为节点上的路径
+type TunnelTLSOptions struct {
+	CertFile   string `json:"cert_file,omitempty" yaml:"cert_file,omitempty"`     // 出口节点证书文件
+	KeyFile    string `json:"key_file,omitempty" yaml:"key_file,omitempty"`       // 出口节点私钥文件
+	ServerName string `json:"server_name,omitempty" yaml:"server_name,omitempty"` // 入口节点连接时的 SNI
+	Secure     bool   `json:"secure,omitempty" yaml:"secure,omitempty"`           // 入口节点校验出口节点证书
+	CAFile     string `json:"ca_file,omitempty" yaml:"ca_file,omitempty"`         // 入口节点校验证书使用的 CA 文件
+}
+
+// TunnelMuxOptions 多路复用参数
+type TunnelMuxOptions struct {
+	Version           int `json:"version,omitempty" yaml:"version,omitempty"`                       // 协议版本 1 或 2
+	KeepaliveInterval int `json:"keepalive_interval,omitempty" yaml:"keepalive_interval,omitempty"` // 心跳间隔（秒）
+	KeepaliveTimeout  int `json:"keepalive_timeout,omitempty" yaml:"keepalive_timeout,omitempty"`   // 心跳超时（秒）
+	MaxFrameSize      int `json:"max_frame_size,omitempty" yaml:"max_frame_size,omitempty"`         // 最大帧大小（字节）
+	MaxReceiveBuffer  int `json:"max_receive_buffer,omitempty" yaml:"max_receive_buffer,omitempty"` // 连接接收缓冲区（字节）
+	MaxStreamBuffer   int `json:"max_stream_buffer,omitempty" yaml:"max_stream_buffer,omitempty"`   // 单个流缓冲区（字节）
+}
+
+// TunnelQUICOptions QUIC 参数
+type TunnelQUICOptions struct {
+	KeepAlivePeriod  int `json:"keepalive_period,omitempty" yaml:"keepalive_period,omitempty"`   // 心跳间隔（秒）
+	HandshakeTimeout int `json:"handshake_timeout,omitempty" yaml:"handshake_timeout,omitempty"` // 握手超时（秒）
+	MaxIdleTimeout   int `json:"max_idle_timeout,omitempty" yaml:"max_idle_timeout,omitempty"`   // 空闲超时（秒）
+	MaxStreams       int `json:"max_streams,omitempty" yaml:"max_streams,omitempty"`             // 单连接最大并发流
+}
+
+// TunnelKCPOptions KCP 参数
+type TunnelKCPOptions struct {
+	Mode        string `json:"mode,omitempty" yaml:"mode,omitempty"`               // 模式：normal | fast | fast2 | fast3
+	Crypt       string `json:"crypt,omitempty" yaml:"crypt,omitempty"`             // 加密方式
+	Key         string `json:"key,omitempty" yaml:"key,omitempty"`                 // 加密密钥（清单中省略或加密）
+	MTU         int    `json:"mtu,omitempty" yaml:"mtu,omitempty"`                 // MTU
+	SndWnd      int    `json:"sndwnd,omitempty" yaml:"sndwnd,omitempty"`           // 发送窗口
+	RcvWnd      int    `json:"rcvwnd,omitempty" yaml:"rcvwnd,omitempty"`           // 接收窗口
+	DataShard   int    `json:"datashard,omitempty" yaml:"datashard,omitempty"`     // 前向纠错数据分片
+	ParityShard int    `json:"parityshard,omitempty" yaml:"parityshard,omitempty"` // 前向纠错校验分片
+	KeepAlive   int    `json:"keepalive,omitempty" yaml:"keepalive,omitempty"`     // 心跳间隔（秒）
+	NoComp      bool   `json:"nocomp,omitempty" yaml:"nocomp,omitempty"`           // 关闭压缩
 }
 
 // TunnelListReq 隧道列表请求
@@ -2,7 +2,10 @@
 // 所有业务错误都应该在此包中定义，便于统一管理和维护
 package errors
 
-import "net/http"
+import (
+	"net/http"
+	"sort"
+)
 
 // BizError 业务错误结构
 // 用于封装业务逻辑中的错误，包含错误码、错误消息和 HTTP 状态码
@@ -17,13 +20,28 @@ func (e *BizError) Error() string {
 	return e.Message
 }
 
+// registry 已定义的全部业务错误
+var registry []*BizError
+
 // New 创建业务错误
 func New(code int, message string, httpCode int) *BizError {
-	return &BizError{
+	e := &BizError{
 		Code:     code,
 		Message:  message,
 		HTTPCode: httpCode,
 	}
+	registry = append(registry, e)
+	return e
+}
+
+// All 返回已定义的全部业务错误（按错误码排序），用于生成接口文档
+func All() []*BizError {
+	list := make([]*BizError, len(registry))
+	copy(list, registry)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
 }
 
 // ==================== 节点相关错误 (100xx) ====================
@@ -41,6 +59,20 @@ var (
 	ErrNodeHasObservers = New(10005, "节点下存在流量监控，无法删除", http.StatusBadRequest)
 	// ErrNodeOffline 节点已离线
 	ErrNodeOffline = New(10006, "节点已离线", http.StatusBadRequest)
+	// ErrNodeMaintenance 节点处于维护模式
+	ErrNodeMaintenance = New(10007, "节点处于维护模式，不能放置新的规则或隧道", http.StatusBadRequest)
+	// ErrNodeNotInMaintenance 排空前需进入维护模式
+	ErrNodeNotInMaintenance = New(10008, "请先将节点设为维护模式", http.StatusBadRequest)
+	// ErrDrainTargetInvalid 替换节点无效
+	ErrDrainTargetInvalid = New(10009, "替换节点不能是被排空的节点或处于维护模式的节点", http.StatusBadRequest)
+	// ErrPortRangeInvalid 端口范围格式错误
+	ErrPortRangeInvalid = New(10010, "端口范围格式错误，示例: 10000-20000,30000", http.StatusBadRequest)
+	// ErrPortReserved 端口为节点保留端口
+	ErrPortReserved = New(10011, "端口为节点保留端口", http.StatusBadRequest)
+	// ErrPortOutOfRange 端口不在节点允许的范围内
+	ErrPortOutOfRange = New(10012, "端口不在节点允许的端口范围内", http.StatusBadRequest)
+	// ErrNoFreePort 端口池已用尽
+	ErrNoFreePort = New(10013, "节点端口池中没有可用端口", http.StatusBadRequest)
 )
 
 // ==================== 规则相关错误 (101xx) ====================
@@ -64,6 +96,30 @@ var (
 	ErrRuleTypeInvalid = New(10108, "无效的规则类型", http.StatusBadRequest)
 	// ErrTunnelChainNotFound 隧道链不存在
 	ErrTunnelChainNotFound = New(10109, "隧道未启动或链路不存在", http.StatusBadRequest)
+	// ErrRuleTargetRequired 未指定克隆/迁移目标
+	ErrRuleTargetRequired = New(10110, "请指定目标节点或隧道（二选一）", http.StatusBadRequest)
+	// ErrRuleTargetSame 迁移目标与当前入口相同
+	ErrRuleTargetSame = New(10111, "目标与规则当前入口相同", http.StatusBadRequest)
+	// ErrRulePortRangeInvalid 端口范围无效
+	ErrRulePortRangeInvalid = New(10112, "端口范围无效：结束端口需大于起始端口，1:1 映射时目标端口不能超过 65535", http.StatusBadRequest)
+	// ErrRulePortRangeTooLarge 端口范围过大
+	ErrRulePortRangeTooLarge = New(10113, "端口范围过大，单条规则最多 1000 个端口", http.StatusBadRequest)
+	// ErrProxyUsersInvalid 代理认证用户无效
+	ErrProxyUsersInvalid = New(10114, "代理规则至少需要一个认证用户，用户名和密码不能为空，用户名不能重复", http.StatusBadRequest)
+	// ErrProxyPortRange 代理规则不支持端口范围
+	ErrProxyPortRange = New(10115, "代理规则不支持端口范围", http.StatusBadRequest)
+	// ErrReverseHostInvalid 反向代理规则域名无效
+	ErrReverseHostInvalid = New(10116, "反向代理规则需填写有效域名，如 example.com 或 *.example.com", http.StatusBadRequest)
+	// ErrReverseHostExists 同一入口节点同一端口上域名重复
+	ErrReverseHostExists = New(10117, "该端口上已有相同域名的反向代理规则", http.StatusBadRequest)
+	// ErrReversePortRange 反向代理规则不支持端口范围
+	ErrReversePortRange = New(10118, "反向代理规则不支持端口范围", http.StatusBadRequest)
+	// ErrTargetOptionsInvalid 目标权重或备用设置无效
+	ErrTargetOptionsInvalid = New(10119, "目标设置无效：目标须在目标列表中且不能重复，权重为 0~100，至少保留一个非备用目标", http.StatusBadRequest)
+	// ErrRuleSourcesInvalid 来源 IP 无效
+	ErrRuleSourcesInvalid = New(10120, "来源 IP 无效，请填写 IP 或 CIDR，如 192.168.1.10、10.0.0.0/8", http.StatusBadRequest)
+	// ErrReverseAdmission 反向代理规则不支持来源 IP 访问控制
+	ErrReverseAdmission = New(10121, "反向代理规则共用监听端口，不支持按规则设置来源 IP 访问控制", http.StatusBadRequest)
 )
 
 // ==================== 隧道相关错误 (102xx) ====================
@@ -87,6 +143,14 @@ var (
 	ErrExitNodeOffline = New(10217, "出口节点已离线", http.StatusBadRequest)
 	// ErrTunnelChainCreateFailed 创建隧道 Chain 失败
 	ErrTunnelChainCreateFailed = New(10218, "创建隧道Chain失败", http.StatusInternalServerError)
+	// ErrTunnelOptionsMismatch 传输参数分组与隧道协议不匹配
+	ErrTunnelOptionsMismatch = New(10219, "传输参数与隧道协议不匹配：ws 用于 ws/mws/wss/mwss，tls 用于 tls/mtls/wss/mwss/h2/grpc/quic，mux 用于 mtls/mws/mwss，quic、kcp 用于同名协议", http.StatusBadRequest)
+	// ErrTunnelOptionsInvalid 隧道传输参数取值无效
+	ErrTunnelOptionsInvalid = New(10220, "传输参数无效：路径须以 / 开头，证书与私钥须同时填写，mux 版本为 1 或 2，KCP 模式为 normal/fast/fast2/fast3、加密方式为 kcp-go 支持的算法，数值不能为负", http.StatusBadRequest)
+	// ErrTunnelCredentialsRotateFailed 轮换隧道凭据失败
+	ErrTunnelCredentialsRotateFailed = New(10221, "轮换隧道凭据失败，已恢复原凭据", http.StatusInternalServerError)
+	// ErrTunnelExitsInvalid 附加出口节点无效
+	ErrTunnelExitsInvalid = New(10222, "附加出口节点无效：节点须存在，且不能与入口、主出口或其他附加出口重复", http.StatusBadRequest)
 	// ErrTunnelObserverCreateFailed 创建观察器失败
 	ErrTunnelObserverCreateFailed = New(10213, "创建观察器失败", http.StatusInternalServerError)
 )
@@ -122,6 +186,12 @@ var (
 var (
 	// ErrTokenGenerationFailed Token 生成失败
 	ErrTokenGenerationFailed = New(10306, "Token 生成失败", http.StatusInternalServerError)
+	// ErrAPITokenNotFound API Token 不存在
+	ErrAPITokenNotFound = New(10307, "API Token 不存在", http.StatusNotFound)
+	// ErrAPITokenScopeInvalid 无效的权限范围
+	ErrAPITokenScopeInvalid = New(10308, "无效的 API Token 权限范围", http.StatusBadRequest)
+	// ErrAPITokenExpired API Token 已过期
+	ErrAPITokenExpired = New(10309, "API Token 已过期", http.StatusUnauthorized)
 )
 
 // ==================== 系统/配置相关错误 (104xx) ====================
@@ -159,6 +229,42 @@ var (
 	ErrObserverCreateFailed = New(10414, "创建流量监控失败", http.StatusInternalServerError)
 	// ErrExtractHostFailed 提取主机IP失败
 	ErrExtractHostFailed = New(10415, "无法从API地址提取主机IP", http.StatusInternalServerError)
+
+	// ErrInventoryParseFailed 解析资源清单失败
+	ErrInventoryParseFailed = New(10416, "解析资源清单失败", http.StatusBadRequest)
+	// ErrInventoryVersionUnsupported 不支持的清单版本
+	ErrInventoryVersionUnsupported = New(10417, "不支持的资源清单版本", http.StatusBadRequest)
+	// ErrInventoryPassphraseRequired 缺少加解密口令
+	ErrInventoryPassphraseRequired = New(10418, "清单包含加密字段，需要提供口令", http.StatusBadRequest)
+	// ErrInventoryDecryptFailed 解密清单字段失败
+	ErrInventoryDecryptFailed = New(10419, "解密清单字段失败，请检查口令", http.StatusBadRequest)
+	// ErrInventoryConflict 导入计划存在冲突
+	ErrInventoryConflict = New(10420, "导入计划存在冲突，请先处理冲突项", http.StatusConflict)
+
+	// ErrBackupNotFound 备份文件不存在
+	ErrBackupNotFound = New(10421, "备份文件不存在", http.StatusNotFound)
+	// ErrBackupNameInvalid 备份文件名无效
+	ErrBackupNameInvalid = New(10422, "备份文件名无效", http.StatusBadRequest)
+	// ErrBackupInvalid 备份文件校验失败
+	ErrBackupInvalid = New(10423, "备份文件校验失败，不是有效的面板数据库", http.StatusBadRequest)
+	// ErrBackupUploadFailed 上传备份失败
+	ErrBackupUploadFailed = New(10424, "上传备份失败", http.StatusInternalServerError)
+	// ErrBackupRestoreFailed 恢复备份失败
+	ErrBackupRestoreFailed = New(10425, "恢复备份失败", http.StatusInternalServerError)
+	// ErrBackupRestoreRunning 正在恢复备份
+	ErrBackupRestoreRunning = New(10426, "正在恢复备份，请稍后再试", http.StatusConflict)
+	// ErrBackupPassphraseRequired 备份已加密，需要口令
+	ErrBackupPassphraseRequired = New(10427, "备份已加密，请提供口令", http.StatusBadRequest)
+	// ErrBackupDecryptFailed 备份解密失败
+	ErrBackupDecryptFailed = New(10428, "备份解密失败，口令错误或文件已损坏", http.StatusBadRequest)
+	// ErrBackupChecksumMismatch 备份校验和不匹配
+	ErrBackupChecksumMismatch = New(10429, "备份校验和不匹配，文件可能已损坏", http.StatusBadRequest)
+	// ErrBackupSchemaTooNew 备份数据库版本过高
+	ErrBackupSchemaTooNew = New(10430, "备份的数据库版本高于当前面板，请先升级面板", http.StatusBadRequest)
+	// ErrBackupScheduleInvalid 备份计划表达式无效
+	ErrBackupScheduleInvalid = New(10431, "备份计划无效，请使用 5 段 cron 表达式（分 时 日 月 周）", http.StatusBadRequest)
+	// ErrBackupTooLarge 上传的备份文件过大
+	ErrBackupTooLarge = New(10432, "备份文件超过上传大小上限（backup.max_upload_mb）", http.StatusRequestEntityTooLarge)
 )
 
 // ==================== 隧道相关补全 (102xx) ====================
@@ -173,3 +279,27 @@ var (
 	// ErrTunnelNotRunning 隧道未运行
 	ErrTunnelNotRunning = New(10207, "隧道未运行", http.StatusBadRequest)
 )
+
+// ==================== 批量操作相关错误 (105xx) ====================
+
+var (
+	// ErrBatchTargetRequired 未指定操作对象
+	ErrBatchTargetRequired = New(10501, "请指定 ID 列表或筛选条件", http.StatusBadRequest)
+	// ErrBatchTooMany 操作对象过多
+	ErrBatchTooMany = New(10502, "单次批量操作的对象过多", http.StatusBadRequest)
+	// ErrBatchEmpty 没有匹配的对象
+	ErrBatchEmpty = New(10503, "没有匹配的操作对象", http.StatusBadRequest)
+)
+
+// ==================== IP 列表相关错误 (106xx) ====================
+
+var (
+	// ErrIPListNotFound IP 列表不存在
+	ErrIPListNotFound = New(10601, "IP 列表不存在", http.StatusNotFound)
+	// ErrIPListNameExists IP 列表名称已存在
+	ErrIPListNameExists = New(10602, "IP 列表名称已存在", http.StatusBadRequest)
+	// ErrIPListInUse IP 列表被规则引用
+	ErrIPListInUse = New(10603, "IP 列表正在被规则引用，无法删除", http.StatusBadRequest)
+	// ErrIPListEntryInvalid IP 列表条目无效
+	ErrIPListEntryInvalid = New(10604, "IP 列表条目无效，请填写 IP 或 CIDR，如 192.168.1.10、10.0.0.0/8", http.StatusBadRequest)
+)
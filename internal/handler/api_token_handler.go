@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"strconv"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/service"
+	"gost-panel/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenHandler 个人 API Token 控制器
+type APITokenHandler struct {
+	tokenService *service.APITokenService
+}
+
+// NewAPITokenHandler 创建 API Token 控制器
+func NewAPITokenHandler(tokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{tokenService: tokenService}
+}
+
+// List 获取当前用户的 API Token 列表
+// GET /api/v1/auth/tokens
+func (h *APITokenHandler) List(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	tokens, err := h.tokenService.List(userID.(uint))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// Create 创建 API Token，明文只在响应中返回一次
+// POST /api/v1/auth/tokens
+func (h *APITokenHandler) Create(c *gin.Context) {
+	var req dto.CreateAPITokenReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	token, raw, err := h.tokenService.Create(&req, userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "创建成功，请妥善保存 Token，关闭后将无法再次查看", gin.H{
+		"token":     raw,
+		"api_token": token,
+	})
+}
+
+// Delete 吊销 API Token
+// DELETE /api/v1/auth/tokens/:id
+func (h *APITokenHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 Token ID")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	if err = h.tokenService.Delete(uint(id), userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已吊销", nil)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gost-panel/internal/openapi"
+	"gost-panel/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion Swagger UI 版本（从 CDN 加载）
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIPage Swagger UI 页面
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>GOST Panel API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "/api/openapi.json",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true
+    });
+  </script>
+</body>
+</html>`
+
+// DocsHandler 接口文档控制器
+type DocsHandler struct {
+	spec []byte
+}
+
+// NewDocsHandler 创建接口文档控制器，文档在创建时序列化一次
+func NewDocsHandler(doc *openapi.Document) *DocsHandler {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		logger.Errorf("生成 OpenAPI 文档失败: %v", err)
+	}
+	return &DocsHandler{spec: spec}
+}
+
+// Spec 输出 OpenAPI 文档
+// GET /api/openapi.json
+func (h *DocsHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// UI 输出 Swagger UI 页面
+// GET /api/docs
+func (h *DocsHandler) UI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
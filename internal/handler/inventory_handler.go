@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/service"
+	"gost-panel/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryHandler 资源清单控制器
+// 处理资源清单的导出、导入预览和导入执行
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+// NewInventoryHandler 创建资源清单控制器
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+// Export 导出资源清单（文件下载）
+func (h *InventoryHandler) Export(c *gin.Context) {
+	var req dto.ExportInventoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	data, err := h.inventoryService.ExportWithLog(&req, userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	ext, contentType := "yaml", "application/x-yaml"
+	if req.Format == "json" {
+		ext, contentType = "json", "application/json"
+	}
+	filename := fmt.Sprintf("gost_panel_inventory_%s.%s", time.Now().Format("20060102_150405"), ext)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(200, contentType, data)
+}
+
+// Plan 预览导入计划
+func (h *InventoryHandler) Plan(c *gin.Context) {
+	var req dto.ImportInventoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	inv, err := service.ParseInventory([]byte(req.Content), req.Format)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	plan, err := h.inventoryService.Plan(inv, req.Passphrase, req.Prune)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, plan)
+}
+
+// Apply 执行导入
+func (h *InventoryHandler) Apply(c *gin.Context) {
+	var req dto.ImportInventoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	inv, err := service.ParseInventory([]byte(req.Content), req.Format)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	plan, err := h.inventoryService.Apply(inv, req.Passphrase, req.Prune, userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if plan != nil && plan.Conflicts > 0 {
+			// 冲突时返回计划，便于前端展示冲突项
+			bizErr := errors.ErrInventoryConflict
+			c.JSON(bizErr.HTTPCode, response.Response{Code: bizErr.Code, Message: bizErr.Message, Data: plan})
+			return
+		}
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "导入成功", plan)
+}
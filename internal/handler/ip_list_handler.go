@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"strconv"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/service"
+	"gost-panel/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPListHandler 命名 IP 列表控制器
+type IPListHandler struct {
+	ipListService *service.IPListService
+}
+
+// NewIPListHandler 创建 IP 列表控制器
+func NewIPListHandler(ipListService *service.IPListService) *IPListHandler {
+	return &IPListHandler{ipListService: ipListService}
+}
+
+// List 获取全部 IP 列表
+// GET /api/v1/ip-lists
+func (h *IPListHandler) List(c *gin.Context) {
+	lists, err := h.ipListService.List()
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, lists)
+}
+
+// GetByID 获取 IP 列表详情
+// GET /api/v1/ip-lists/:id
+func (h *IPListHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 IP 列表 ID")
+		return
+	}
+
+	list, err := h.ipListService.GetByID(uint(id))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Create 创建 IP 列表
+// POST /api/v1/ip-lists
+func (h *IPListHandler) Create(c *gin.Context) {
+	var req dto.CreateIPListReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	list, err := h.ipListService.Create(&req, userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Update 更新 IP 列表
+// PUT /api/v1/ip-lists/:id
+func (h *IPListHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 IP 列表 ID")
+		return
+	}
+
+	var req dto.UpdateIPListReq
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	list, err := h.ipListService.Update(uint(id), &req, userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Delete 删除 IP 列表
+// DELETE /api/v1/ip-lists/:id
+func (h *IPListHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 IP 列表 ID")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	if err = h.ipListService.Delete(uint(id), userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
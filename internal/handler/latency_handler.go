@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"gost-panel/internal/dto"
+	"gost-panel/internal/service"
+	"gost-panel/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LatencyHandler 延迟统计控制器
+type LatencyHandler struct {
+	latencyService *service.LatencyService
+}
+
+// NewLatencyHandler 创建延迟统计控制器
+func NewLatencyHandler(latencyService *service.LatencyService) *LatencyHandler {
+	return &LatencyHandler{latencyService: latencyService}
+}
+
+// Stats 获取各探测路径的延迟与丢失率
+// GET /api/v1/latency
+func (h *LatencyHandler) Stats(c *gin.Context) {
+	var req dto.LatencyStatsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	stats, err := h.latencyService.Stats(&req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, stats)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"gost-panel/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler Prometheus 指标控制器
+type MetricsHandler struct {
+	token   string
+	handler http.Handler
+}
+
+// NewMetricsHandler 创建指标控制器，token 为空时不校验
+func NewMetricsHandler(token string) *MetricsHandler {
+	return &MetricsHandler{
+		token:   token,
+		handler: metrics.Handler(),
+	}
+}
+
+// Metrics 输出 Prometheus 指标
+// GET /metrics
+func (h *MetricsHandler) Metrics(c *gin.Context) {
+	if h.token != "" {
+		expected := "Bearer " + h.token
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.String(http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+
+	h.handler.ServeHTTP(c.Writer, c.Request)
+}
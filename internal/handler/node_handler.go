@@ -145,3 +145,78 @@ func (h *NodeHandler) GetConfig(c *gin.Context) {
 
 	response.Success(c, config)
 }
+
+// Ports 查询节点端口池及占用情况
+func (h *NodeHandler) Ports(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的节点 ID")
+		return
+	}
+
+	ports, err := h.nodeService.Ports(uint(id))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, ports)
+}
+
+// SetMaintenance 开启或关闭节点维护模式
+func (h *NodeHandler) SetMaintenance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的节点 ID")
+		return
+	}
+
+	var req dto.NodeMaintenanceReq
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	node, err := h.nodeService.SetMaintenance(uint(id), &req, userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, node)
+}
+
+// Drain 排空节点
+func (h *NodeHandler) Drain(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的节点 ID")
+		return
+	}
+
+	var req dto.DrainNodeReq
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	result, err := h.nodeService.Drain(uint(id), &req, userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
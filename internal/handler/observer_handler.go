@@ -3,6 +3,7 @@ package handler
 import (
 	"bytes"
 	"gost-panel/internal/dto"
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/service"
 	"gost-panel/pkg/logger"
 	"io"
@@ -27,6 +28,7 @@ func (h *ObserverHandler) Report(c *gin.Context) {
 	bodyBytes, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		logger.Warnf("读取观察器上报数据失败: %v", err)
+		metrics.ObserveReport(metrics.ReportInvalid)
 		c.JSON(400, dto.ObserverReportResp{OK: false})
 		return
 	}
@@ -37,6 +39,7 @@ func (h *ObserverHandler) Report(c *gin.Context) {
 	var req dto.ObserverReportReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Warnf("解析观察器上报数据失败: %v", err)
+		metrics.ObserveReport(metrics.ReportInvalid)
 		c.JSON(400, dto.ObserverReportResp{OK: false})
 		return
 	}
@@ -53,10 +56,12 @@ func (h *ObserverHandler) Report(c *gin.Context) {
 	// 处理上报数据
 	if err := h.observerService.HandleReport(&req); err != nil {
 		logger.Warnf("处理观察器上报数据失败: %v", err)
+		metrics.ObserveReport(metrics.ReportError)
 		c.JSON(500, dto.ObserverReportResp{OK: false})
 		return
 	}
 
 	// 返回成功响应（GOST 需要 ok: true 才认为上报成功）
+	metrics.ObserveReport(metrics.ReportOK)
 	c.JSON(200, dto.ObserverReportResp{OK: true})
 }
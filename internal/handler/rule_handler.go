@@ -73,6 +73,64 @@ func (h *RuleHandler) Update(c *gin.Context) {
 	response.Success(c, rule)
 }
 
+// Clone 克隆规则到其他节点或隧道
+func (h *RuleHandler) Clone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的规则 ID")
+		return
+	}
+
+	var req dto.CloneRuleReq
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	rule, err := h.ruleService.Clone(uint(id), &req, userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// Migrate 迁移规则到其他节点或隧道
+func (h *RuleHandler) Migrate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的规则 ID")
+		return
+	}
+
+	var req dto.MigrateRuleReq
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	rule, err := h.ruleService.Migrate(uint(id), &req, userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
 // Delete 删除规则
 func (h *RuleHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -112,6 +170,23 @@ func (h *RuleHandler) GetByID(c *gin.Context) {
 	response.Success(c, rule)
 }
 
+// Targets 获取规则转发目标状态
+func (h *RuleHandler) Targets(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的规则 ID")
+		return
+	}
+
+	resp, err := h.ruleService.Targets(uint(id))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
 // List 获取规则列表
 func (h *RuleHandler) List(c *gin.Context) {
 	var req dto.RuleListReq
@@ -172,3 +247,26 @@ func (h *RuleHandler) Stop(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "停止成功", nil)
 }
+
+// Batch 批量操作规则
+func (h *RuleHandler) Batch(c *gin.Context) {
+	var req dto.BatchRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	result, err := h.ruleService.Batch(&req, userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -1,7 +1,11 @@
 package handler
 
 import (
+	stderrors "errors"
+	"net/http"
+
 	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
 	"gost-panel/internal/service"
 	"gost-panel/pkg/response"
 
@@ -85,3 +89,101 @@ func (h *SystemConfigHandler) Backup(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "备份成功", nil)
 }
+
+// TestS3 测试 S3 异地备份配置
+func (h *SystemConfigHandler) TestS3(c *gin.Context) {
+	var req dto.S3ConfigReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.backupService.TestS3Destination(&req); err != nil {
+		response.Error(c, 500, 50003, "连接失败: "+err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "连接成功", nil)
+}
+
+// ListBackups 获取备份列表
+func (h *SystemConfigHandler) ListBackups(c *gin.Context) {
+	list, err := h.backupService.ListBackups()
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// DownloadBackup 下载备份文件
+func (h *SystemConfigHandler) DownloadBackup(c *gin.Context) {
+	name := c.Param("name")
+	path, err := h.backupService.GetBackupPath(name)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	c.FileAttachment(path, name)
+}
+
+// UploadBackup 上传备份文件
+func (h *SystemConfigHandler) UploadBackup(c *gin.Context) {
+	// 表单边界和其他字段另外预留 1MB，文件本身的大小由服务层精确校验
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.backupService.MaxUploadSize()+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if stderrors.As(err, &maxErr) {
+			response.HandleError(c, errors.ErrBackupTooLarge)
+			return
+		}
+		response.BadRequest(c, "请选择要上传的备份文件")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "读取上传文件失败")
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	name, err := h.backupService.SaveUploadedBackup(file, c.PostForm("passphrase"), userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "上传成功", gin.H{"name": name})
+}
+
+// RestoreBackup 从备份恢复
+func (h *SystemConfigHandler) RestoreBackup(c *gin.Context) {
+	// 请求体可选，仅加密备份需要口令
+	var req dto.RestoreBackupReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误: "+err.Error())
+			return
+		}
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	result, err := h.backupService.Restore(c.Param("name"), req.Passphrase, userID.(uint), username.(string), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "恢复成功", result)
+}
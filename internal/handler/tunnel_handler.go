@@ -172,3 +172,49 @@ func (h *TunnelHandler) Stop(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "停止成功", nil)
 }
+
+// RotateCredentials 轮换隧道 Relay 认证凭据
+func (h *TunnelHandler) RotateCredentials(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的隧道 ID")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	tunnel, err := h.tunnelService.RotateCredentials(uint(id), userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, tunnel)
+}
+
+// Batch 批量操作隧道
+func (h *TunnelHandler) Batch(c *gin.Context) {
+	var req dto.BatchTunnelReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	username, _ := c.Get("username")
+
+	ip := c.ClientIP()
+	ua := c.GetHeader("User-Agent")
+
+	result, err := h.tunnelService.Batch(&req, userID.(uint), username.(string), ip, ua)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,233 @@
+// Package metrics 提供 Prometheus 指标
+// HTTP 接口、观察器上报、节点健康检测等过程指标在此记录；
+// 节点、规则、隧道的流量与状态在抓取时从数据库读取（见 service.ResourceCollector）。
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 指标名前缀
+const Namespace = "gost_panel"
+
+// 观察器上报结果
+const (
+	ReportOK      = "ok"      // 处理成功
+	ReportInvalid = "invalid" // 数据无法解析
+	ReportError   = "error"   // 处理失败
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP API 请求数",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP API 请求耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	observerReports = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "observer",
+		Name:      "reports_total",
+		Help:      "观察器上报次数",
+	}, []string{"result"})
+
+	observerEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "observer",
+		Name:      "events_total",
+		Help:      "观察器上报的事件数",
+	})
+
+	observerEventErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "observer",
+		Name:      "event_errors_total",
+		Help:      "处理失败的观察器事件数",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		observerReports,
+		observerEvents,
+		observerEventErrors,
+	)
+}
+
+var registerOnce sync.Once
+
+// RegisterCollector 注册额外的采集器（重复调用只注册第一次）
+func RegisterCollector(c prometheus.Collector) {
+	registerOnce.Do(func() {
+		registry.MustRegister(c)
+	})
+}
+
+// Handler 返回指标输出的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest 记录一次 HTTP 请求
+func ObserveHTTPRequest(method, route string, code int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveReport 记录一次观察器上报
+func ObserveReport(result string) {
+	observerReports.WithLabelValues(result).Inc()
+}
+
+// ObserveEvent 记录一个观察器事件的处理结果
+func ObserveEvent(err error) {
+	observerEvents.Inc()
+	if err != nil {
+		observerEventErrors.Inc()
+	}
+}
+
+// NodeHealth 节点最近一次健康检测结果
+type NodeHealth struct {
+	Latency   time.Duration // 检测耗时
+	Up        bool          // 是否可用
+	Checks    uint64        // 累计检测次数
+	Failures  uint64        // 累计失败次数
+	CheckedAt time.Time     // 检测时间
+}
+
+var nodeHealth = struct {
+	sync.RWMutex
+	m map[uint]NodeHealth
+}{m: make(map[uint]NodeHealth)}
+
+// ObserveNodeHealthCheck 记录节点健康检测结果
+func ObserveNodeHealthCheck(nodeID uint, latency time.Duration, up bool) {
+	nodeHealth.Lock()
+	defer nodeHealth.Unlock()
+
+	h := nodeHealth.m[nodeID]
+	h.Latency = latency
+	h.Up = up
+	h.Checks++
+	if !up {
+		h.Failures++
+	}
+	h.CheckedAt = time.Now()
+	nodeHealth.m[nodeID] = h
+}
+
+// GetNodeHealth 获取节点最近一次健康检测结果
+func GetNodeHealth(nodeID uint) (NodeHealth, bool) {
+	nodeHealth.RLock()
+	defer nodeHealth.RUnlock()
+	h, ok := nodeHealth.m[nodeID]
+	return h, ok
+}
+
+// ForgetNode 删除节点的健康检测记录（节点删除时调用）
+func ForgetNode(nodeID uint) {
+	nodeHealth.Lock()
+	defer nodeHealth.Unlock()
+	delete(nodeHealth.m, nodeID)
+}
+
+// connsReport 服务最近一次上报的当前连接数
+type connsReport struct {
+	current int64
+	at      time.Time
+}
+
+// resourceConns 观察器上报的当前连接数（资源 ID -> 服务名 -> 上报）
+// 规则的 TCP/UDP、端口范围服务与隧道各出口的 Relay 服务分别上报，读取时汇总
+type resourceConns struct {
+	sync.RWMutex
+	m map[uint]map[string]connsReport
+}
+
+var (
+	ruleConns   = &resourceConns{m: make(map[uint]map[string]connsReport)}
+	tunnelConns = &resourceConns{m: make(map[uint]map[string]connsReport)}
+)
+
+// observe 记录服务的当前连接数
+func (c *resourceConns) observe(id uint, service string, current int64) {
+	c.Lock()
+	defer c.Unlock()
+	if c.m[id] == nil {
+		c.m[id] = make(map[string]connsReport)
+	}
+	c.m[id][service] = connsReport{current: current, at: time.Now()}
+}
+
+// sum 汇总资源各服务的当前连接数，超过 maxAge 未上报的服务（已停止或已删除）不计入
+func (c *resourceConns) sum(id uint, maxAge time.Duration) int64 {
+	c.RLock()
+	defer c.RUnlock()
+	var total int64
+	for _, r := range c.m[id] {
+		if time.Since(r.at) <= maxAge {
+			total += r.current
+		}
+	}
+	return total
+}
+
+// forget 删除资源的全部上报
+func (c *resourceConns) forget(id uint) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.m, id)
+}
+
+// ObserveRuleConns 记录规则服务上报的当前连接数
+func ObserveRuleConns(ruleID uint, service string, current int64) {
+	ruleConns.observe(ruleID, service, current)
+}
+
+// GetRuleConns 获取规则当前连接数
+func GetRuleConns(ruleID uint, maxAge time.Duration) int64 {
+	return ruleConns.sum(ruleID, maxAge)
+}
+
+// ForgetRule 删除规则的连接数记录（规则删除时调用）
+func ForgetRule(ruleID uint) {
+	ruleConns.forget(ruleID)
+}
+
+// ObserveTunnelConns 记录隧道 Relay 服务上报的当前连接数
+func ObserveTunnelConns(tunnelID uint, service string, current int64) {
+	tunnelConns.observe(tunnelID, service, current)
+}
+
+// GetTunnelConns 获取隧道当前连接数
+func GetTunnelConns(tunnelID uint, maxAge time.Duration) int64 {
+	return tunnelConns.sum(tunnelID, maxAge)
+}
+
+// ForgetTunnel 删除隧道的连接数记录（隧道删除时调用）
+func ForgetTunnel(tunnelID uint) {
+	tunnelConns.forget(tunnelID)
+}
@@ -1,16 +1,33 @@
 package middleware
 
 import (
+	stderrors "errors"
+	"net/http"
 	"strings"
 
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
 	"gost-panel/pkg/jwt"
 	"gost-panel/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Auth JWT 认证中间件
-func Auth(jwtInstance *jwt.JWT) gin.HandlerFunc {
+// TokenAuthenticator API Token 校验
+type TokenAuthenticator interface {
+	Authenticate(raw, ip string) (*model.APIToken, error)
+}
+
+// scopeResources 需要对应写权限的资源路径（/api/v1/ 后的第一段）
+var scopeResources = map[string]string{
+	"nodes":    model.ScopeNodesWrite,
+	"rules":    model.ScopeRulesWrite,
+	"ip-lists": model.ScopeRulesWrite,
+	"tunnels":  model.ScopeTunnelsWrite,
+}
+
+// Auth 认证中间件，支持 JWT 与个人 API Token
+func Auth(jwtInstance *jwt.JWT, tokens TokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取 Authorization 头
 		authHeader := c.GetHeader("Authorization")
@@ -28,6 +45,12 @@ func Auth(jwtInstance *jwt.JWT) gin.HandlerFunc {
 			return
 		}
 
+		// API Token
+		if strings.HasPrefix(parts[1], model.APITokenPrefix) {
+			authAPIToken(c, tokens, parts[1])
+			return
+		}
+
 		// 解析 Token
 		claims, err := jwtInstance.ParseToken(parts[1])
 		if err != nil {
@@ -50,3 +73,57 @@ func Auth(jwtInstance *jwt.JWT) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// authAPIToken 校验 API Token 及其权限范围
+func authAPIToken(c *gin.Context, tokens TokenAuthenticator, raw string) {
+	token, err := tokens.Authenticate(raw, c.ClientIP())
+	if err != nil {
+		var bizErr *errors.BizError
+		if stderrors.As(err, &bizErr) && bizErr.HTTPCode == http.StatusUnauthorized {
+			response.Unauthorized(c, bizErr.Message)
+		} else {
+			response.HandleError(c, err)
+		}
+		c.Abort()
+		return
+	}
+
+	if scope := requiredScope(c.Request.Method, c.FullPath()); !token.HasScope(scope) {
+		response.Forbidden(c, "API Token 权限不足，需要 "+scope)
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", token.UserID)
+	c.Set("username", token.User.Username)
+	c.Set("apiTokenID", token.ID)
+	// 节点 API 密码、代理用户密码等认证信息只返回给登录用户
+	c.Set(response.MaskCredentialsKey, true)
+
+	c.Next()
+}
+
+// adminReadPaths 包含密钥或完整数据的读接口，需要全部权限
+var adminReadPaths = map[string]bool{
+	"nodes/:id/config": true, // 节点 GOST 配置（含认证信息）
+}
+
+// requiredScope 请求所需的权限范围
+// 系统设置与备份（含下载）、节点配置需要全部权限；其余读请求需要 read；
+// 节点、规则、隧道的写请求需要对应的写权限；其余写请求需要全部权限
+func requiredScope(method, fullPath string) string {
+	path := strings.TrimPrefix(fullPath, "/api/v1/")
+	resource, _, _ := strings.Cut(path, "/")
+	if resource == "system" || adminReadPaths[path] {
+		return model.ScopeAll
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		return model.ScopeRead
+	}
+
+	if scope, ok := scopeResources[resource]; ok {
+		return scope
+	}
+	return model.ScopeAll
+}
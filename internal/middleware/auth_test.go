@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gost-panel/internal/model"
+	"gost-panel/pkg/jwt"
+	"gost-panel/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequiredScope(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/rules", model.ScopeRead},
+		{http.MethodGet, "/api/v1/nodes/:id", model.ScopeRead},
+		{http.MethodGet, "/api/v1/nodes/:id/config", model.ScopeAll},
+		{http.MethodGet, "/api/v1/system/config", model.ScopeAll},
+		{http.MethodGet, "/api/v1/system/backups", model.ScopeAll},
+		{http.MethodGet, "/api/v1/system/backups/:name/download", model.ScopeAll},
+		{http.MethodPost, "/api/v1/nodes", model.ScopeNodesWrite},
+		{http.MethodPut, "/api/v1/ip-lists/:id", model.ScopeRulesWrite},
+		{http.MethodPost, "/api/v1/tunnels/:id/start", model.ScopeTunnelsWrite},
+		{http.MethodPost, "/api/v1/system/backups", model.ScopeAll},
+		{http.MethodPost, "/api/v1/auth/tokens", model.ScopeAll},
+	}
+	for _, c := range cases {
+		if got := requiredScope(c.method, c.path); got != c.want {
+			t.Errorf("requiredScope(%s %s) = %q，期望 %q", c.method, c.path, got, c.want)
+		}
+	}
+}
+
+// stubTokens 所有 API Token 均校验通过，权限范围固定
+type stubTokens struct {
+	scopes model.StringList
+}
+
+// Authenticate 实现 TokenAuthenticator
+func (s stubTokens) Authenticate(_, _ string) (*model.APIToken, error) {
+	return &model.APIToken{ID: 1, UserID: 1, User: &model.User{Username: "ci"}, Scopes: s.scopes}, nil
+}
+
+func TestReadTokenCannotReadCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secrets := []string{"node-secret", "proxy-secret"}
+	node := func() *model.GostNode {
+		return &model.GostNode{ID: 1, Name: "hk", Username: "gost", Password: "node-secret"}
+	}
+	tunnel := func() *model.GostTunnel {
+		return &model.GostTunnel{ID: 1, EntryNode: node(), ExitNode: node(), ExtraExits: []model.TunnelExit{{Node: node()}}}
+	}
+	rule := func() model.GostRule {
+		return model.GostRule{
+			ID: 1, Type: model.RuleTypeProxy, Node: node(), Tunnel: tunnel(),
+			ProxyUsers: model.ProxyUserList{{Username: "u1", Password: "proxy-secret"}},
+		}
+	}
+
+	jwtInstance := jwt.New(&jwt.Config{Secret: "test", Expire: 3600})
+	engine := gin.New()
+	api := engine.Group("/api/v1", Auth(jwtInstance, stubTokens{scopes: model.StringList{model.ScopeRead}}))
+	api.GET("/nodes", func(c *gin.Context) {
+		response.SuccessPage(c, []model.GostNode{*node()}, 1, 1, 10)
+	})
+	api.GET("/nodes/:id", func(c *gin.Context) { response.Success(c, node()) })
+	api.GET("/nodes/:id/config", func(c *gin.Context) { response.Success(c, gin.H{"password": "node-secret"}) })
+	api.GET("/rules", func(c *gin.Context) {
+		response.SuccessPage(c, []model.GostRule{rule()}, 1, 1, 10)
+	})
+	api.GET("/rules/:id", func(c *gin.Context) {
+		r := rule()
+		response.Success(c, &r)
+	})
+	api.GET("/tunnels", func(c *gin.Context) {
+		response.SuccessPage(c, []*model.GostTunnel{tunnel(), nil}, 1, 1, 10)
+	})
+	api.GET("/tunnels/:id", func(c *gin.Context) { response.Success(c, tunnel()) })
+
+	get := func(path, token string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	for _, path := range []string{
+		"/api/v1/nodes", "/api/v1/nodes/1", "/api/v1/nodes/1/config",
+		"/api/v1/rules", "/api/v1/rules/1", "/api/v1/tunnels", "/api/v1/tunnels/1",
+	} {
+		body := get(path, model.APITokenPrefix+"read")
+		for _, secret := range secrets {
+			if strings.Contains(body, secret) {
+				t.Errorf("read Token 读取 %s 返回了认证信息 %s: %s", path, secret, body)
+			}
+		}
+	}
+
+	// 登录用户（面板页面）仍返回完整数据
+	token, err := jwtInstance.GenerateToken(1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := get("/api/v1/rules/1", token); !strings.Contains(body, "node-secret") || !strings.Contains(body, "proxy-secret") {
+		t.Errorf("登录用户读取规则缺少认证信息: %s", body)
+	}
+}
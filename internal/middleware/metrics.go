@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"time"
+
+	"gost-panel/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics HTTP 请求指标中间件
+// 按路由模板（如 /api/v1/rules/:id）统计，未匹配路由的请求（静态文件等）不计入
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(startTime))
+	}
+}
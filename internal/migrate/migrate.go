@@ -0,0 +1,218 @@
+// Package migrate 提供带版本号的数据库结构迁移
+// 每个迁移包含递增的版本号和 up/down 两个方向，已执行的版本记录在 schema_migrations 表中。
+// 新增、重命名、删除字段或回填数据时，应在 migrations.go 中追加新的迁移，而不是修改已发布的迁移。
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// TableName 迁移记录表名
+const TableName = "schema_migrations"
+
+// Migration 单个迁移
+type Migration struct {
+	Version int                  // 版本号，从 1 开始递增
+	Name    string               // 迁移名称
+	Up      func(*gorm.DB) error // 升级
+	Down    func(*gorm.DB) error // 回滚，为 nil 表示不可回滚
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"size:100" json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return TableName
+}
+
+// Status 迁移状态
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// ErrSchemaTooNew 数据库结构版本高于当前程序
+type ErrSchemaTooNew struct {
+	Current int
+	Latest  int
+}
+
+// Error 实现 error 接口
+func (e *ErrSchemaTooNew) Error() string {
+	return fmt.Sprintf("数据库结构版本 (%d) 高于当前程序支持的版本 (%d)，请升级面板或使用对应版本执行 migrate down", e.Current, e.Latest)
+}
+
+// sorted 返回按版本排序的迁移列表
+func sorted() []Migration {
+	list := make([]Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list
+}
+
+// Latest 返回当前程序支持的最新版本
+func Latest() int {
+	list := sorted()
+	if len(list) == 0 {
+		return 0
+	}
+	return list[len(list)-1].Version
+}
+
+// ensureTable 确保 schema_migrations 表存在
+func ensureTable(db *gorm.DB) error {
+	return db.AutoMigrate(&SchemaMigration{})
+}
+
+// applied 获取已执行的迁移记录
+func applied(db *gorm.DB) (map[int]SchemaMigration, error) {
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	var records []SchemaMigration
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int]SchemaMigration, len(records))
+	for _, r := range records {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// Current 返回数据库当前版本（已执行的最大版本号）
+func Current(db *gorm.DB) (int, error) {
+	records, err := applied(db)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for v := range records {
+		if v > current {
+			current = v
+		}
+	}
+	return current, nil
+}
+
+// Check 检查数据库版本，高于程序支持的版本时返回 ErrSchemaTooNew
+func Check(db *gorm.DB) error {
+	current, err := Current(db)
+	if err != nil {
+		return err
+	}
+	if latest := Latest(); current > latest {
+		return &ErrSchemaTooNew{Current: current, Latest: latest}
+	}
+	return nil
+}
+
+// StatusList 返回所有迁移的执行状态
+func StatusList(db *gorm.DB) ([]Status, error) {
+	records, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Status
+	for _, m := range sorted() {
+		st := Status{Version: m.Version, Name: m.Name}
+		if r, ok := records[m.Version]; ok {
+			st.Applied = true
+			appliedAt := r.AppliedAt
+			st.AppliedAt = &appliedAt
+		}
+		list = append(list, st)
+	}
+	return list, nil
+}
+
+// Up 执行未执行的迁移直到 target 版本（0 表示最新），返回执行的迁移
+func Up(db *gorm.DB, target int) ([]Migration, error) {
+	if err := Check(db); err != nil {
+		return nil, err
+	}
+	records, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	if target <= 0 {
+		target = Latest()
+	}
+
+	var done []Migration
+	for _, m := range sorted() {
+		if m.Version > target {
+			break
+		}
+		if _, ok := records[m.Version]; ok {
+			continue
+		}
+
+		// 注意：MySQL 的 DDL 会隐式提交，事务只能保证迁移记录与数据变更一致
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+		}
+		logger.Infof("已执行数据库迁移: %d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down 回滚最近执行的 steps 个迁移，返回回滚的迁移
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	records, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	list := sorted()
+	var done []Migration
+	for i := len(list) - 1; i >= 0 && len(done) < steps; i-- {
+		m := list[i]
+		if _, ok := records[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("迁移 %d_%s 不支持回滚", m.Version, m.Name)
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+		}
+		logger.Infof("已回滚数据库迁移: %d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
@@ -0,0 +1,159 @@
+package migrate
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gost-panel/internal/database"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "error", Format: "console"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// openTestDB 创建空的临时 SQLite 数据库
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+	return db
+}
+
+// mustCurrent 返回当前版本
+func mustCurrent(t *testing.T, db *gorm.DB) int {
+	t.Helper()
+	current, err := Current(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return current
+}
+
+// assertSchema 检查全部模型的表存在
+func assertSchema(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, m := range database.Models() {
+		if !db.Migrator().HasTable(m) {
+			t.Errorf("缺少表 %T", m)
+		}
+	}
+	if !db.Migrator().HasIndex(&model.LatencySample{}, latencyLookupIndex) {
+		t.Errorf("缺少索引 %s", latencyLookupIndex)
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	db := openTestDB(t)
+	latest := Latest()
+
+	done, err := Up(db, 0)
+	if err != nil {
+		t.Fatalf("Up 失败: %v", err)
+	}
+	if len(done) != latest || mustCurrent(t, db) != latest {
+		t.Fatalf("Up 执行 %d 个迁移，当前版本 %d，期望 %d", len(done), mustCurrent(t, db), latest)
+	}
+	assertSchema(t, db)
+
+	// 已是最新版本时不再执行
+	if done, err = Up(db, 0); err != nil || len(done) != 0 {
+		t.Errorf("重复 Up = %d, %v，期望不执行", len(done), err)
+	}
+
+	// 回滚到基线后重新升级
+	if done, err = Down(db, latest-1); err != nil || len(done) != latest-1 {
+		t.Fatalf("Down = %d, %v，期望回滚 %d 个", len(done), err, latest-1)
+	}
+	if current := mustCurrent(t, db); current != 1 {
+		t.Errorf("Down 后版本 = %d，期望 1", current)
+	}
+	if db.Migrator().HasTable(&model.LatencySample{}) {
+		t.Error("Down 后 latency_samples 表仍存在")
+	}
+	if _, err = Up(db, 0); err != nil {
+		t.Fatalf("重新 Up 失败: %v", err)
+	}
+	if current := mustCurrent(t, db); current != latest {
+		t.Errorf("重新 Up 后版本 = %d，期望 %d", current, latest)
+	}
+	assertSchema(t, db)
+
+	// 基线不可回滚
+	if _, err = Down(db, latest); err == nil {
+		t.Error("回滚基线应返回错误")
+	}
+	if current := mustCurrent(t, db); current != 1 {
+		t.Errorf("回滚到基线失败后版本 = %d，期望 1", current)
+	}
+}
+
+func TestDownUpEachStep(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 逐个回滚并重新执行，每个迁移的 down/up 都应可重复
+	for v := Latest(); v > 1; v-- {
+		if _, err := Down(db, Latest()-v+1); err != nil {
+			t.Fatalf("回滚到 %d 失败: %v", v-1, err)
+		}
+		if current := mustCurrent(t, db); current != v-1 {
+			t.Fatalf("回滚后版本 = %d，期望 %d", current, v-1)
+		}
+		if _, err := Up(db, 0); err != nil {
+			t.Fatalf("从 %d 升级失败: %v", v-1, err)
+		}
+	}
+	assertSchema(t, db)
+}
+
+func TestUpTarget(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db, 1); err != nil {
+		t.Fatal(err)
+	}
+	if current := mustCurrent(t, db); current != 1 {
+		t.Errorf("Up(1) 后版本 = %d，期望 1", current)
+	}
+	list, err := StatusList(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range list {
+		if st.Applied != (st.Version <= 1) {
+			t.Errorf("版本 %d 已执行 = %v", st.Version, st.Applied)
+		}
+	}
+}
+
+func TestCheckSchemaTooNew(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&SchemaMigration{Version: Latest() + 1, Name: "future", AppliedAt: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var tooNew *ErrSchemaTooNew
+	if err := Check(db); !stderrors.As(err, &tooNew) || tooNew.Current != Latest()+1 {
+		t.Errorf("Check: err = %v，期望 ErrSchemaTooNew", err)
+	}
+	if _, err := Up(db, 0); !stderrors.As(err, &tooNew) {
+		t.Errorf("Up: err = %v，期望 ErrSchemaTooNew", err)
+	}
+}
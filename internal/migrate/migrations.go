@@ -0,0 +1,353 @@
+package migrate
+
+import (
+	"fmt"
+
+	"gost-panel/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// migrations 全部迁移，按版本号递增追加
+//
+// 版本 1 为基线：以当前模型执行 AutoMigrate，兼容此前由 AutoMigrate 创建的数据库。
+// 之后的迁移必须是幂等的（先检查表和列是否存在），因为全新数据库在版本 1
+// 中已按最新模型建表。
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&model.User{},
+				&model.GostNode{},
+				&model.GostRule{},
+				&model.GostTunnel{},
+				&model.OperationLog{},
+				&model.SystemConfig{},
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "drop_legacy_last_reported",
+		Up:      dropLegacyLastReportedUp,
+		Down:    dropLegacyLastReportedDown,
+	},
+	{
+		Version: 3,
+		Name:    "add_api_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.APIToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&model.APIToken{})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_node_maintenance",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostNode{}, nodeMaintenanceFields...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostNode{}, nodeMaintenanceFields...)
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_node_port_pool",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostNode{}, nodePortPoolFields...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostNode{}, nodePortPoolFields...)
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add_rule_port_range",
+		Up: func(tx *gorm.DB) error {
+			if err := addFieldsIfMissing(tx, &model.GostRule{}, rulePortRangeFields...); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&model.RuleServiceCounter{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&model.RuleServiceCounter{}); err != nil {
+				return err
+			}
+			return dropFieldsIfExist(tx, &model.GostRule{}, rulePortRangeFields...)
+		},
+	},
+	{
+		Version: 7,
+		Name:    "add_rule_protocol",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostRule{}, "Protocol")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostRule{}, "Protocol")
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_rule_proxy",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostRule{}, ruleProxyFields...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostRule{}, ruleProxyFields...)
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add_rule_reverse",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostRule{}, ruleReverseFields...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostRule{}, ruleReverseFields...)
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_rule_failover",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostRule{}, ruleFailoverFields...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostRule{}, ruleFailoverFields...)
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add_rule_admission",
+		Up: func(tx *gorm.DB) error {
+			if err := addFieldsIfMissing(tx, &model.GostRule{}, ruleAdmissionFields...); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&model.IPList{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&model.IPList{}); err != nil {
+				return err
+			}
+			return dropFieldsIfExist(tx, &model.GostRule{}, ruleAdmissionFields...)
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_tunnel_options",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostTunnel{}, "Options")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostTunnel{}, "Options")
+		},
+	},
+	{
+		Version: 13,
+		Name:    "add_tunnel_credentials",
+		Up: func(tx *gorm.DB) error {
+			return addFieldsIfMissing(tx, &model.GostTunnel{}, tunnelCredentialFields...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropFieldsIfExist(tx, &model.GostTunnel{}, tunnelCredentialFields...)
+		},
+	},
+	{
+		Version: 14,
+		Name:    "add_tunnel_exits",
+		Up: func(tx *gorm.DB) error {
+			if err := addFieldsIfMissing(tx, &model.GostTunnel{}, "Strategy"); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&model.TunnelExit{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&model.TunnelExit{}); err != nil {
+				return err
+			}
+			return dropFieldsIfExist(tx, &model.GostTunnel{}, "Strategy")
+		},
+	},
+	{
+		Version: 15,
+		Name:    "add_latency_samples",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.LatencySample{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&model.LatencySample{})
+		},
+	},
+	{
+		Version: 16,
+		Name:    "drop_operation_log_user_fk",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasConstraint(&model.OperationLog{}, operationLogUserFK) {
+				return nil
+			}
+			if err := tx.Migrator().DropConstraint(&model.OperationLog{}, operationLogUserFK); err != nil {
+				return err
+			}
+			// SQLite 通过重建表删除约束，索引需要重新创建
+			return tx.AutoMigrate(&model.OperationLog{})
+		},
+		// 命令行记录的日志 UserID 为 0，回滚时不再恢复外键
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+	{
+		Version: 17,
+		Name:    "add_latency_samples_lookup_index",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&model.LatencySample{}, latencyLookupIndex) {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&model.LatencySample{}, latencyLookupIndex)
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasIndex(&model.LatencySample{}, latencyLookupIndex) {
+				return nil
+			}
+			return tx.Migrator().DropIndex(&model.LatencySample{}, latencyLookupIndex)
+		},
+	},
+}
+
+// latencyLookupIndex 延迟探测记录按路径、关联 ID 和时间查询的复合索引
+const latencyLookupIndex = "idx_latency_samples_lookup"
+
+// operationLogUserFK 旧版本 AutoMigrate 为操作日志创建的用户外键
+const operationLogUserFK = "fk_operation_logs_user"
+
+// tunnelCredentialFields 隧道 Relay 认证字段
+var tunnelCredentialFields = []string{"RelayUsername", "RelayPassword", "CredentialsRotatedAt"}
+
+// ruleAdmissionFields 规则来源 IP 访问控制字段
+var ruleAdmissionFields = []string{"AllowSources", "DenySources", "AllowLists", "DenyLists"}
+
+// ruleFailoverFields 规则故障转移字段
+var ruleFailoverFields = []string{"MaxFails", "FailTimeout", "TargetOptions"}
+
+// ruleReverseFields 反向代理规则字段
+var ruleReverseFields = []string{"Host", "InnerPort"}
+
+// ruleProxyFields 代理规则字段
+var ruleProxyFields = []string{"ProxyType", "ProxyUsers"}
+
+// rulePortRangeFields 规则端口范围字段
+var rulePortRangeFields = []string{"ListenPortEnd", "PortMapping"}
+
+// nodePortPoolFields 节点端口池字段
+var nodePortPoolFields = []string{"PortRanges", "ReservedPorts"}
+
+// nodeMaintenanceFields 节点维护模式字段
+var nodeMaintenanceFields = []string{"Maintenance", "MaintenanceReason", "MaintenanceAt"}
+
+// legacyRuleColumns TCP/UDP 拆分前规则使用的累计值字段，及其回填目标
+var legacyRuleColumns = [][2]string{
+	{"last_reported_input_bytes", "last_reported_input_bytes_tcp"},
+	{"last_reported_output_bytes", "last_reported_output_bytes_tcp"},
+	{"last_reported_total_conns", "last_reported_total_conns_tcp"},
+}
+
+// legacyNodeColumns 节点上已不再使用的累计值字段（节点流量由规则增量累加）
+var legacyNodeColumns = []string{
+	"last_reported_input_bytes",
+	"last_reported_output_bytes",
+}
+
+// dropLegacyLastReportedUp 将规则旧累计值回填到 TCP 字段后删除旧字段
+// 未带协议后缀的旧服务上报时按 TCP 字段计算增量
+func dropLegacyLastReportedUp(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	if m.HasColumn("rules", legacyRuleColumns[0][0]) {
+		// 仅回填 TCP 字段尚无数据的规则
+		if err := tx.Exec(fmt.Sprintf(
+			"UPDATE rules SET %s = %s, %s = %s, %s = %s WHERE %s = 0 AND %s = 0 AND %s = 0",
+			legacyRuleColumns[0][1], legacyRuleColumns[0][0],
+			legacyRuleColumns[1][1], legacyRuleColumns[1][0],
+			legacyRuleColumns[2][1], legacyRuleColumns[2][0],
+			legacyRuleColumns[0][1], legacyRuleColumns[1][1], legacyRuleColumns[2][1],
+		)).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, cols := range legacyRuleColumns {
+		if err := dropColumnIfExists(tx, &model.GostRule{}, "rules", cols[0]); err != nil {
+			return err
+		}
+	}
+	for _, col := range legacyNodeColumns {
+		if err := dropColumnIfExists(tx, &model.GostNode{}, "nodes", col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropLegacyLastReportedDown 恢复旧字段，并从 TCP 字段回填
+func dropLegacyLastReportedDown(tx *gorm.DB) error {
+	for _, cols := range legacyRuleColumns {
+		if err := addBigIntColumnIfMissing(tx, "rules", cols[0]); err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("UPDATE rules SET %s = %s", cols[0], cols[1])).Error; err != nil {
+			return err
+		}
+	}
+	for _, col := range legacyNodeColumns {
+		if err := addBigIntColumnIfMissing(tx, "nodes", col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumnIfExists 删除存在的列
+func dropColumnIfExists(tx *gorm.DB, value any, table, column string) error {
+	if !tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	return tx.Migrator().DropColumn(value, column)
+}
+
+// addFieldsIfMissing 添加模型字段对应的列，已存在的跳过（按字段名解析列名）
+func addFieldsIfMissing(tx *gorm.DB, value any, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(value, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropFieldsIfExist 删除模型字段对应的列，不存在的跳过（按字段名解析列名）
+func dropFieldsIfExist(tx *gorm.DB, value any, fields ...string) error {
+	for _, field := range fields {
+		if !tx.Migrator().HasColumn(value, field) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addBigIntColumnIfMissing 添加默认值为 0 的 BIGINT 列
+func addBigIntColumnIfMissing(tx *gorm.DB, table, column string) error {
+	if tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s BIGINT DEFAULT 0", table, column)).Error
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenPrefix API Token 明文前缀，用于与 JWT 区分
+const APITokenPrefix = "gpt_"
+
+// API Token 权限范围
+const (
+	ScopeAll          = "*"             // 全部权限（等同于登录用户）
+	ScopeRead         = "read"          // 只读
+	ScopeNodesWrite   = "nodes:write"   // 管理节点
+	ScopeRulesWrite   = "rules:write"   // 管理规则
+	ScopeTunnelsWrite = "tunnels:write" // 管理隧道
+)
+
+// APITokenScopes 全部可用的权限范围
+var APITokenScopes = []string{ScopeAll, ScopeRead, ScopeNodesWrite, ScopeRulesWrite, ScopeTunnelsWrite}
+
+// APIToken 个人 API Token，用于脚本等自动化场景
+// 只保存 Token 的 SHA-256 哈希，明文仅在创建时返回一次
+type APIToken struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     uint           `gorm:"index;not null" json:"user_id"`         // 所属用户
+	Name       string         `gorm:"size:100;not null" json:"name"`         // 名称
+	Prefix     string         `gorm:"size:20" json:"prefix"`                 // 明文前几位，便于识别
+	TokenHash  string         `gorm:"size:64;uniqueIndex;not null" json:"-"` // SHA-256 哈希
+	Scopes     StringList     `json:"scopes"`                                // 权限范围，为空表示全部权限
+	ExpiresAt  *time.Time     `json:"expires_at"`                            // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time     `json:"last_used_at"`                          // 最后使用时间
+	LastUsedIP string         `gorm:"size:50" json:"last_used_ip"`           // 最后使用 IP
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 关联 - 用户
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 指定表名
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// IsExpired 是否已过期
+func (t *APIToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+// HasScope 是否拥有指定权限
+// 写权限同时包含只读权限
+func (t *APIToken) HasScope(scope string) bool {
+	if len(t.Scopes) == 0 {
+		return true
+	}
+	for _, s := range t.Scopes {
+		if s == ScopeAll || s == scope {
+			return true
+		}
+		if scope == ScopeRead && s != ScopeRead {
+			return true
+		}
+	}
+	return false
+}
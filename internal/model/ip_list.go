@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IPList 命名 IP 列表，可被多条规则的来源访问控制引用
+// 规则启动时将引用列表的条目合并到规则自己的准入控制器中
+type IPList struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"size:100;not null" json:"name"` // 列表名称
+	Entries   StringList     `json:"entries"`                       // IP 或 CIDR 条目
+	Remark    string         `gorm:"type:text" json:"remark"`       // 备注
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (IPList) TableName() string {
+	return "ip_lists"
+}
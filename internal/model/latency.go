@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// LatencyPath 延迟探测路径类型
+type LatencyPath string
+
+const (
+	LatencyPathNode   LatencyPath = "node"   // 面板到节点 API
+	LatencyPathTarget LatencyPath = "target" // 面板到规则转发目标
+	LatencyPathTunnel LatencyPath = "tunnel" // 面板经入口节点、隧道出口到出口节点
+)
+
+// LatencySample 一次 TCP 连接延迟探测的结果
+// 节点路径的 NodeID 为被探测节点；隧道路径每个出口单独探测，NodeID 为出口节点
+// 统计按路径和关联 ID 分组并限定时间窗口，复合索引 idx_latency_samples_lookup 与之对应
+type LatencySample struct {
+	ID        uint        `gorm:"primaryKey" json:"id"`
+	Path      LatencyPath `gorm:"size:20;not null;index:idx_latency_samples_lookup,priority:1" json:"path"` // 路径类型
+	NodeID    uint        `gorm:"default:0;index:idx_latency_samples_lookup,priority:2" json:"node_id"`     // 节点 ID（隧道路径为出口节点）
+	RuleID    uint        `gorm:"default:0;index:idx_latency_samples_lookup,priority:3" json:"rule_id"`     // 规则 ID（目标路径）
+	TunnelID  uint        `gorm:"default:0;index:idx_latency_samples_lookup,priority:4" json:"tunnel_id"`   // 隧道 ID（隧道路径）
+	Target    string      `gorm:"size:255" json:"target"`                                                   // 探测地址
+	Success   bool        `gorm:"default:false" json:"success"`                                             // 是否连接成功
+	LatencyMs float64     `gorm:"default:0" json:"latency_ms"`                                              // 连接耗时（毫秒），失败时为 0
+	Error     string      `gorm:"size:255" json:"error"`                                                    // 失败原因
+	CreatedAt time.Time   `gorm:"index;index:idx_latency_samples_lookup,priority:5" json:"created_at"`      // 探测时间
+}
+
+// TableName 指定表名
+func (LatencySample) TableName() string {
+	return "latency_samples"
+}
@@ -25,15 +25,20 @@ type GostNode struct {
 	Password string     `gorm:"size:255" json:"password"`              // API 认证密码
 	Status   NodeStatus `gorm:"size:20;default:offline" json:"status"` // 状态
 
+	// 维护模式：不再放置新的规则和隧道，状态变化不告警、不做恢复处理
+	Maintenance       bool       `gorm:"default:false" json:"maintenance"`   // 是否处于维护模式
+	MaintenanceReason string     `gorm:"size:255" json:"maintenance_reason"` // 维护原因
+	MaintenanceAt     *time.Time `json:"maintenance_at"`                     // 进入维护模式的时间
+
+	// 端口池：自动分配规则监听端口和隧道 Relay 端口时使用
+	PortRanges    string `gorm:"size:255" json:"port_ranges"`    // 允许使用的端口范围，如 "10000-20000,30000"，为空表示不限制
+	ReservedPorts string `gorm:"size:255" json:"reserved_ports"` // 保留端口，不会被分配或使用，如 "22,80,443"
+
 	// 流量统计
 	TotalBytes  int64 `gorm:"default:0" json:"total_bytes"`
 	InputBytes  int64 `gorm:"default:0" json:"input_bytes"`
 	OutputBytes int64 `gorm:"default:0" json:"output_bytes"`
 
-	// Gost上报的累计值（用于计算增量）
-	LastReportedInputBytes  int64 `gorm:"default:0" json:"-"` // 上次上报的入站累计值
-	LastReportedOutputBytes int64 `gorm:"default:0" json:"-"` // 上次上报的出站累计值
-
 	LastCheckAt *time.Time     `json:"last_check_at"`           // 最后检查时间
 	Remark      string         `gorm:"type:text" json:"remark"` // 备注
 	CreatedAt   time.Time      `json:"created_at"`
@@ -52,3 +57,16 @@ type GostNode struct {
 func (GostNode) TableName() string {
 	return "nodes"
 }
+
+// MaskCredentials 隐藏 API 认证密码，以及关联规则、隧道中的认证信息
+func (n *GostNode) MaskCredentials() {
+	n.Password = ""
+	for i := range n.Rules {
+		n.Rules[i].MaskCredentials()
+	}
+	for _, tunnels := range [][]GostTunnel{n.EntryTunnels, n.ExitTunnels} {
+		for i := range tunnels {
+			tunnels[i].MaskCredentials()
+		}
+	}
+}
@@ -17,8 +17,8 @@ type OperationLog struct {
 	UserAgent    string    `gorm:"size:255" json:"user_agent"`     // User-Agent
 	CreatedAt    time.Time `gorm:"index" json:"created_at"`
 
-	// 关联
-	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	// 关联（不建外键：命令行操作记录的 UserID 为 0）
+	User *User `gorm:"foreignKey:UserID;constraint:-" json:"user,omitempty"`
 }
 
 // TableName 指定表名
@@ -36,11 +36,24 @@ const (
 	ActionDelete         = "delete"          // 删除
 	ActionStart          = "start"           // 启动
 	ActionStop           = "stop"            // 停止
+	ActionRestart        = "restart"         // 重启
+	ActionClone          = "clone"           // 克隆
+	ActionMigrate        = "migrate"         // 迁移
+	ActionMaintenance    = "maintenance"     // 维护模式
+	ActionDrain          = "drain"           // 排空节点
+	ActionExport         = "export"          // 导出
+	ActionImport         = "import"          // 导入
+	ActionUpload         = "upload"          // 上传
+	ActionRestore        = "restore"         // 恢复
 )
 
 // 资源类型常量
 const (
-	ResourceTypeNode   = "node"   // 节点
-	ResourceTypeRule   = "rule"   // 规则
-	ResourceTypeTunnel = "tunnel" // 隧道
+	ResourceTypeNode      = "node"      // 节点
+	ResourceTypeRule      = "rule"      // 规则
+	ResourceTypeTunnel    = "tunnel"    // 隧道
+	ResourceTypeInventory = "inventory" // 资源清单
+	ResourceTypeBackup    = "backup"    // 备份
+	ResourceTypeAPIToken  = "api_token" // API Token
+	ResourceTypeIPList    = "ip_list"   // IP 列表
 )
@@ -21,25 +21,82 @@ type RuleType string
 const (
 	RuleTypeForward RuleType = "forward" // 端口转发（直连目标）
 	RuleTypeTunnel  RuleType = "tunnel"  // 隧道转发（通过隧道链路）
+	RuleTypeProxy   RuleType = "proxy"   // 代理（HTTP/SOCKS5，可经隧道链路出口）
+	RuleTypeReverse RuleType = "reverse" // 反向代理（按 HTTP Host/TLS SNI 分流，同端口的规则共享监听）
+)
+
+// 代理规则的处理器类型
+const (
+	ProxyTypeHTTP   = "http"   // HTTP 代理
+	ProxyTypeSOCKS5 = "socks5" // SOCKS5 代理
+	ProxyTypeAuto   = "auto"   // 自动识别 HTTP/SOCKS
+)
+
+// 转发目标失败判定的默认值，与 GOST 选择器一致
+const (
+	DefaultMaxFails    = 3  // 连续失败 3 次后标记目标失效
+	DefaultFailTimeout = 30 // 失效目标 30 秒后重新尝试
+)
+
+// 端口范围规则的目标端口映射方式
+const (
+	PortMappingRange  = "range"  // 1:1 映射，目标端口随监听端口偏移
+	PortMappingSingle = "single" // 全部转发到目标地址中的端口
+)
+
+// RuleProtocol 规则转发的协议
+type RuleProtocol string
+
+const (
+	RuleProtocolTCP  RuleProtocol = "tcp"  // 仅 TCP
+	RuleProtocolUDP  RuleProtocol = "udp"  // 仅 UDP
+	RuleProtocolBoth RuleProtocol = "both" // TCP+UDP 全流量
 )
 
 // GostRule 转发规则模型
 // 入口选择：NodeID 或 TunnelID 二选一
 // - 端口转发 (forward)：选择 NodeID，直接在该节点上创建转发服务
 // - 隧道转发 (tunnel)：选择 TunnelID，在隧道的入口节点上创建转发服务，使用隧道的 Chain
+// - 代理 (proxy)：选择 NodeID 时直接出网，选择 TunnelID 时在隧道入口节点上监听并经隧道出口
+// - 反向代理 (reverse)：入口同代理，同一入口节点同一端口的规则共享监听，按域名转发到各规则的内部服务
 type GostRule struct {
 	ID         uint     `gorm:"primaryKey" json:"id"`
 	NodeID     *uint    `gorm:"index" json:"node_id"`                         // 入口节点 ID（端口转发时使用）
 	Name       string   `gorm:"size:100;not null" json:"name"`                // 规则名称
 	Type       RuleType `gorm:"size:20;not null;default:forward" json:"type"` // 规则类型
 	TunnelID   *uint    `gorm:"index" json:"tunnel_id"`                       // 隧道 ID（隧道转发时使用）
-	ListenPort int      `gorm:"not null" json:"listen_port"`                  // 监听端口（TCP+UDP 全流量）
+	ListenPort int      `gorm:"not null" json:"listen_port"`                  // 监听端口，端口范围规则的起始端口
+
+	Protocol RuleProtocol `gorm:"size:10;default:both" json:"protocol"` // 转发协议：tcp | udp | both
+
+	// 端口范围：ListenPort ~ ListenPortEnd，每个端口一组服务
+	ListenPortEnd int    `gorm:"default:0" json:"listen_port_end"` // 结束端口，0 表示单端口规则
+	PortMapping   string `gorm:"size:20" json:"port_mapping"`      // 目标端口映射方式：range | single
 
-	Targets   []string   `gorm:"type:json;serializer:json" json:"targets"` // 多目标列表 (host:port)
-	Strategy  string     `gorm:"size:20;default:round" json:"strategy"`    // 负载均衡策略 (round, random, fifo)
-	EnableTLS bool       `gorm:"default:false" json:"enable_tls"`          // 是否启用 TLS
-	Status    RuleStatus `gorm:"size:20;default:stopped" json:"status"`    // 状态
-	ServiceID string     `gorm:"size:100" json:"service_id"`               // Gost 服务 ID
+	// 代理规则：处理器类型与认证用户
+	ProxyType  string        `gorm:"size:20" json:"proxy_type"` // 代理类型：http | socks5 | auto
+	ProxyUsers ProxyUserList `json:"proxy_users"`               // 认证用户
+
+	// 反向代理规则：匹配的域名与规则服务的内部端口
+	Host      string `gorm:"size:255" json:"host"`        // 域名（HTTP Host / TLS SNI），支持 *.example.com
+	InnerPort int    `gorm:"default:0" json:"inner_port"` // 规则服务监听的 127.0.0.1 端口，首次启动时从端口池分配
+
+	Targets   StringList `json:"targets"`                               // 多目标列表 (host:port)
+	Strategy  string     `gorm:"size:20;default:round" json:"strategy"` // 负载均衡策略 (round, random, fifo)
+	EnableTLS bool       `gorm:"default:false" json:"enable_tls"`       // 是否启用 TLS
+	Status    RuleStatus `gorm:"size:20;default:stopped" json:"status"` // 状态
+	ServiceID string     `gorm:"size:100" json:"service_id"`            // Gost 服务 ID
+
+	// 故障转移：目标失败判定，以及各目标的权重与备用设置
+	MaxFails      int              `gorm:"default:0" json:"max_fails"`    // 连续失败多少次后标记目标失效，0 为默认值
+	FailTimeout   int              `gorm:"default:0" json:"fail_timeout"` // 失效目标多少秒后重新尝试，0 为默认值
+	TargetOptions TargetOptionList `json:"target_options"`                // 目标设置，只记录非默认值
+
+	// 来源 IP 访问控制：允许列表不为空时只接受匹配的来源，拒绝列表优先
+	AllowSources StringList `json:"allow_sources"` // 允许的来源 IP / CIDR
+	DenySources  StringList `json:"deny_sources"`  // 拒绝的来源 IP / CIDR
+	AllowLists   IDList     `json:"allow_lists"`   // 引用的命名 IP 列表（允许）
+	DenyLists    IDList     `json:"deny_lists"`    // 引用的命名 IP 列表（拒绝）
 
 	// 流量监控配置
 	ObserverID string `gorm:"size:100" json:"observer_id"` // 观察器 ID
@@ -50,12 +107,8 @@ type GostRule struct {
 	TotalBytes    int64 `gorm:"default:0" json:"total_bytes"`    // 总流量 (Input + Output)
 	TotalRequests int64 `gorm:"default:0" json:"total_requests"` // 总请求数
 
-	// Gost上报的累计值（用于计算增量）
-	LastReportedInputBytes  int64 `gorm:"default:0" json:"-"` // 上次上报的入站累计值
-	LastReportedOutputBytes int64 `gorm:"default:0" json:"-"` // 上次上报的出站累计值
-	LastReportedTotalConns  int64 `gorm:"default:0" json:"-"` // 上次上报的总连接数
-
-	// TCP/UDP 分离累计值（全流量转发时每个协议独立计数）
+	// Gost 上报的累计值（用于计算增量），TCP/UDP 服务独立计数
+	// 未带协议后缀的旧服务名按 TCP 计算
 	LastReportedInputBytesTCP  int64 `gorm:"default:0" json:"-"`
 	LastReportedOutputBytesTCP int64 `gorm:"default:0" json:"-"`
 	LastReportedTotalConnsTCP  int64 `gorm:"default:0" json:"-"`
@@ -78,3 +131,91 @@ type GostRule struct {
 func (GostRule) TableName() string {
 	return "rules"
 }
+
+// MaskCredentials 隐藏代理用户密码，以及关联节点、隧道中的认证信息
+func (r *GostRule) MaskCredentials() {
+	if r.ProxyUsers != nil {
+		users := make(ProxyUserList, len(r.ProxyUsers))
+		for i, user := range r.ProxyUsers {
+			users[i] = ProxyUser{Username: user.Username}
+		}
+		r.ProxyUsers = users
+	}
+	if r.Node != nil {
+		r.Node.MaskCredentials()
+	}
+	if r.Tunnel != nil {
+		r.Tunnel.MaskCredentials()
+	}
+}
+
+// IsPortRange 是否为端口范围规则
+func (r *GostRule) IsPortRange() bool {
+	return r.ListenPortEnd > r.ListenPort
+}
+
+// ListenPorts 规则监听的全部端口
+func (r *GostRule) ListenPorts() []int {
+	if !r.IsPortRange() {
+		return []int{r.ListenPort}
+	}
+	ports := make([]int, 0, r.ListenPortEnd-r.ListenPort+1)
+	for port := r.ListenPort; port <= r.ListenPortEnd; port++ {
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// Protocols 规则需要创建服务的协议，未设置时为 TCP+UDP；代理、反向代理规则只有 TCP
+func (r *GostRule) Protocols() []string {
+	if r.Type == RuleTypeProxy || r.Type == RuleTypeReverse {
+		return []string{"tcp"}
+	}
+	switch r.Protocol {
+	case RuleProtocolTCP:
+		return []string{"tcp"}
+	case RuleProtocolUDP:
+		return []string{"udp"}
+	default:
+		return []string{"tcp", "udp"}
+	}
+}
+
+// SelectorMaxFails 目标失效前允许的连续失败次数
+func (r *GostRule) SelectorMaxFails() int {
+	if r.MaxFails > 0 {
+		return r.MaxFails
+	}
+	return DefaultMaxFails
+}
+
+// SelectorFailTimeout 失效目标重新尝试前的等待秒数
+func (r *GostRule) SelectorFailTimeout() int {
+	if r.FailTimeout > 0 {
+		return r.FailTimeout
+	}
+	return DefaultFailTimeout
+}
+
+// HasAdmission 是否配置了来源 IP 访问控制
+func (r *GostRule) HasAdmission() bool {
+	return len(r.AllowSources)+len(r.DenySources)+len(r.AllowLists)+len(r.DenyLists) > 0
+}
+
+// RuleServiceCounter 端口范围规则各服务上报的累计值（用于计算增量）
+// 单端口规则的累计值仍记录在规则的 LastReported* 字段上
+type RuleServiceCounter struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	RuleID  uint   `gorm:"uniqueIndex:idx_rule_service;not null" json:"rule_id"`
+	Service string `gorm:"size:100;uniqueIndex:idx_rule_service;not null" json:"service"` // GOST 服务名
+
+	LastReportedInputBytes  int64     `gorm:"default:0" json:"last_reported_input_bytes"`
+	LastReportedOutputBytes int64     `gorm:"default:0" json:"last_reported_output_bytes"`
+	LastReportedTotalConns  int64     `gorm:"default:0" json:"last_reported_total_conns"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RuleServiceCounter) TableName() string {
+	return "rule_service_counters"
+}
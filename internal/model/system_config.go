@@ -28,8 +28,23 @@ type SystemConfig struct {
 	LogLevel         string `gorm:"size:20;default:info" json:"log_level"`
 
 	// 备份与恢复
-	AutoBackup           bool `gorm:"default:false" json:"auto_backup"`
-	BackupRetentionCount int  `gorm:"default:7" json:"backup_retention_count"`
+	AutoBackup           bool   `gorm:"default:false" json:"auto_backup"`
+	BackupRetentionCount int    `gorm:"default:7" json:"backup_retention_count"`
+	BackupSchedule       string `gorm:"size:100" json:"backup_schedule"` // cron 表达式，为空时每天 0 点
+
+	// 备份归档：压缩为 .gpbak，设置口令时同时加密
+	BackupCompress   bool   `gorm:"default:false" json:"backup_compress"`
+	BackupPassphrase string `gorm:"size:255" json:"backup_passphrase"`
+
+	// 异地备份（S3 兼容对象存储）
+	BackupS3Enabled   bool   `gorm:"default:false" json:"backup_s3_enabled"`
+	BackupS3Endpoint  string `gorm:"size:255" json:"backup_s3_endpoint"`
+	BackupS3Region    string `gorm:"size:50" json:"backup_s3_region"`
+	BackupS3Bucket    string `gorm:"size:100" json:"backup_s3_bucket"`
+	BackupS3AccessKey string `gorm:"size:255" json:"backup_s3_access_key"`
+	BackupS3SecretKey string `gorm:"size:255" json:"backup_s3_secret_key"`
+	BackupS3Prefix    string `gorm:"size:255" json:"backup_s3_prefix"`
+	BackupS3PathStyle bool   `gorm:"default:false" json:"backup_s3_path_style"` // MinIO 等需开启路径风格
 
 	// 面板配置
 	SiteTitle string `gorm:"size:100;default:Gost Panel" json:"site_title"`
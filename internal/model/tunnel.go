@@ -1,6 +1,7 @@
 package model
 
 import (
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,6 +18,7 @@ const (
 
 // GostTunnel 隧道模型 - 管理入口节点与出口节点的链路关系
 // 启动隧道时：在出口节点创建 Relay 服务，在入口节点创建 Chain 连接到出口节点
+// 配置附加出口时，Chain 的跳点包含全部在线出口，按 Strategy 选择
 type GostTunnel struct {
 	ID          uint         `gorm:"primaryKey" json:"id"`
 	Name        string       `gorm:"size:100;not null" json:"name"`       // 隧道名称
@@ -26,6 +28,17 @@ type GostTunnel struct {
 	RelayPort   int          `gorm:"default:8443" json:"relay_port"`      // 出口节点 Relay 服务端口
 	Status      TunnelStatus `gorm:"size:20;default:stopped" json:"status"`
 
+	Options TunnelOptions `json:"options"` // 传输参数（按协议分组）
+
+	// 多出口：附加出口节点与主出口使用相同的 Relay 端口
+	Strategy   string       `gorm:"size:20;default:round" json:"strategy"`            // 出口选择策略 (round, rand, fifo)
+	ExtraExits []TunnelExit `gorm:"foreignKey:TunnelID" json:"extra_exits,omitempty"` // 附加出口节点（按顺序）
+
+	// Relay 认证凭据，由面板生成，不在接口中返回
+	RelayUsername        string     `gorm:"size:64" json:"-"`
+	RelayPassword        string     `gorm:"size:128" json:"-"`
+	CredentialsRotatedAt *time.Time `json:"credentials_rotated_at"` // 凭据最近生成/轮换时间
+
 	// Gost 服务相关 ID（启动时创建）
 	ServiceID string `gorm:"size:100" json:"service_id"` // 出口节点 Relay 服务 ID
 	ChainID   string `gorm:"size:100" json:"chain_id"`   // 入口节点 Chain ID
@@ -56,3 +69,53 @@ type GostTunnel struct {
 func (GostTunnel) TableName() string {
 	return "tunnels"
 }
+
+// MaskCredentials 隐藏关联节点和规则中的认证信息
+func (t *GostTunnel) MaskCredentials() {
+	for _, node := range []*GostNode{t.EntryNode, t.ExitNode} {
+		if node != nil {
+			node.MaskCredentials()
+		}
+	}
+	for i := range t.ExtraExits {
+		if node := t.ExtraExits[i].Node; node != nil {
+			node.MaskCredentials()
+		}
+	}
+	for i := range t.Rules {
+		t.Rules[i].MaskCredentials()
+	}
+}
+
+// ExitNodeIDs 全部出口节点 ID，主出口在前
+func (t *GostTunnel) ExitNodeIDs() []uint {
+	ids := []uint{t.ExitNodeID}
+	for _, exit := range t.ExtraExits {
+		ids = append(ids, exit.NodeID)
+	}
+	return ids
+}
+
+// HasExit 节点是否为隧道的出口（主出口或附加出口）
+func (t *GostTunnel) HasExit(nodeID uint) bool {
+	return slices.Contains(t.ExitNodeIDs(), nodeID)
+}
+
+// TunnelExit 隧道附加出口，每个出口上的 Relay 服务单独统计流量
+type TunnelExit struct {
+	ID       uint `gorm:"primaryKey" json:"-"`
+	TunnelID uint `gorm:"not null;index" json:"-"`
+	NodeID   uint `gorm:"not null;index" json:"node_id"`
+	Position int  `gorm:"default:0" json:"-"` // 出口顺序，fifo 策略按此顺序选择
+
+	// Gost上报的累计值（用于计算增量）
+	LastReportedInputBytes  int64 `gorm:"default:0" json:"-"`
+	LastReportedOutputBytes int64 `gorm:"default:0" json:"-"`
+
+	Node *GostNode `gorm:"foreignKey:NodeID" json:"node,omitempty"`
+}
+
+// TableName 指定表名
+func (TunnelExit) TableName() string {
+	return "tunnel_exits"
+}
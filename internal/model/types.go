@@ -0,0 +1,268 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSONList 以 JSON 数组形式存储的列表
+// 按数据库类型选择列类型，兼容 SQLite、PostgreSQL 与 MySQL
+type JSONList[T any] []T
+
+// Value 实现 driver.Valuer
+func (l JSONList[T]) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]T(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (l *JSONList[T]) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 %T", value, *l)
+	}
+
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	var list []T
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// GormDataType 通用数据类型
+func (JSONList[T]) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 按数据库返回列类型
+func (JSONList[T]) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// StringList 字符串列表
+type StringList = JSONList[string]
+
+// IDList ID 列表
+type IDList = JSONList[uint]
+
+// ProxyUser 代理认证用户
+type ProxyUser struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ProxyUserList 代理用户列表
+type ProxyUserList = JSONList[ProxyUser]
+
+// TargetOption 转发目标的权重与备用设置
+type TargetOption struct {
+	Target string `json:"target"`           // 目标地址，对应规则目标列表中的一项
+	Weight int    `json:"weight,omitempty"` // 权重，0 为默认权重 1
+	Backup bool   `json:"backup,omitempty"` // 备用目标：其他目标全部失效时才使用
+}
+
+// TargetOptionList 目标设置列表
+type TargetOptionList = JSONList[TargetOption]
+
+// FindTargetOption 目标地址对应的设置，未设置时返回零值
+func FindTargetOption(options []TargetOption, target string) TargetOption {
+	for _, opt := range options {
+		if opt.Target == target {
+			return opt
+		}
+	}
+	return TargetOption{Target: target}
+}
+
+// TunnelOptions 隧道传输参数，按协议分组，每组只能用于对应的协议
+type TunnelOptions struct {
+	WS   *TunnelWSOptions   `json:"ws,omitempty"`   // WebSocket：ws、mws、wss、mwss
+	TLS  *TunnelTLSOptions  `json:"tls,omitempty"`  // TLS：tls、mtls、wss、mwss、h2、grpc、quic
+	Mux  *TunnelMuxOptions  `json:"mux,omitempty"`  // 多路复用：mtls、mws、mwss
+	QUIC *TunnelQUICOptions `json:"quic,omitempty"` // QUIC
+	KCP  *TunnelKCPOptions  `json:"kcp,omitempty"`  // KCP
+}
+
+// TunnelWSOptions WebSocket 参数
+type TunnelWSOptions struct {
+	Path string `json:"path,omitempty"` // 请求路径，默认 /ws
+	Host string `json:"host,omitempty"` // 入口节点请求时的 Host 头
+}
+
+// TunnelTLSOptions TLS 参数，证书文件路径均为节点上的路径
+type TunnelTLSOptions struct {
+	CertFile   string `json:"cert_file,omitempty"`   // 出口节点证书文件，不填时使用 GOST 自签名证书
+	KeyFile    string `json:"key_file,omitempty"`    // 出口节点私钥文件
+	ServerName string `json:"server_name,omitempty"` // 入口节点连接时的 SNI
+	Secure     bool   `json:"secure,omitempty"`      // 入口节点校验出口节点证书
+	CAFile     string `json:"ca_file,omitempty"`     // 入口节点校验证书使用的 CA 文件
+}
+
+// TunnelMuxOptions 多路复用（smux）参数
+type TunnelMuxOptions struct {
+	Version           int `json:"version,omitempty"`            // 协议版本 1 或 2，默认 1
+	KeepaliveInterval int `json:"keepalive_interval,omitempty"` // 心跳间隔（秒）
+	KeepaliveTimeout  int `json:"keepalive_timeout,omitempty"`  // 心跳超时（秒）
+	MaxFrameSize      int `json:"max_frame_size,omitempty"`     // 最大帧大小（字节）
+	MaxReceiveBuffer  int `json:"max_receive_buffer,omitempty"` // 连接接收缓冲区（字节）
+	MaxStreamBuffer   int `json:"max_stream_buffer,omitempty"`  // 单个流缓冲区（字节）
+}
+
+// TunnelQUICOptions QUIC 参数
+type TunnelQUICOptions struct {
+	KeepAlivePeriod  int `json:"keepalive_period,omitempty"`  // 心跳间隔（秒），0 为不发送心跳
+	HandshakeTimeout int `json:"handshake_timeout,omitempty"` // 握手超时（秒）
+	MaxIdleTimeout   int `json:"max_idle_timeout,omitempty"`  // 空闲超时（秒）
+	MaxStreams       int `json:"max_streams,omitempty"`       // 单连接最大并发流
+}
+
+// TunnelKCPOptions KCP 参数，入口与出口使用相同配置
+type TunnelKCPOptions struct {
+	Mode        string `json:"mode,omitempty"`        // 模式：normal | fast | fast2 | fast3，默认 fast
+	Crypt       string `json:"crypt,omitempty"`       // 加密方式，如 aes、salsa20、none
+	Key         string `json:"-"`                     // 加密密钥，不在响应中返回
+	MTU         int    `json:"mtu,omitempty"`         // MTU
+	SndWnd      int    `json:"sndwnd,omitempty"`      // 发送窗口
+	RcvWnd      int    `json:"rcvwnd,omitempty"`      // 接收窗口
+	DataShard   int    `json:"datashard,omitempty"`   // 前向纠错数据分片
+	ParityShard int    `json:"parityshard,omitempty"` // 前向纠错校验分片
+	KeepAlive   int    `json:"keepalive,omitempty"`   // 心跳间隔（秒）
+	NoComp      bool   `json:"nocomp,omitempty"`      // 关闭压缩
+}
+
+// Groups 已设置的参数分组名称
+func (o TunnelOptions) Groups() []string {
+	var groups []string
+	if o.WS != nil {
+		groups = append(groups, "ws")
+	}
+	if o.TLS != nil {
+		groups = append(groups, "tls")
+	}
+	if o.Mux != nil {
+		groups = append(groups, "mux")
+	}
+	if o.QUIC != nil {
+		groups = append(groups, "quic")
+	}
+	if o.KCP != nil {
+		groups = append(groups, "kcp")
+	}
+	return groups
+}
+
+// TunnelOptionGroups 隧道协议可用的参数分组
+func TunnelOptionGroups(protocol string) []string {
+	switch protocol {
+	case "ws":
+		return []string{"ws"}
+	case "mws":
+		return []string{"ws", "mux"}
+	case "wss":
+		return []string{"ws", "tls"}
+	case "mwss":
+		return []string{"ws", "tls", "mux"}
+	case "tls", "h2", "grpc":
+		return []string{"tls"}
+	case "mtls":
+		return []string{"tls", "mux"}
+	case "quic":
+		return []string{"tls", "quic"}
+	case "kcp":
+		return []string{"kcp"}
+	}
+	return nil
+}
+
+// storedTunnelOptions 数据库中存储的传输参数，包含响应中隐藏的 KCP 密钥
+type storedTunnelOptions struct {
+	TunnelOptions
+	KCP *storedKCPOptions `json:"kcp,omitempty"`
+}
+
+// storedKCPOptions 数据库中存储的 KCP 参数
+type storedKCPOptions struct {
+	TunnelKCPOptions
+	Key string `json:"key,omitempty"`
+}
+
+// Value 实现 driver.Valuer
+func (o TunnelOptions) Value() (driver.Value, error) {
+	stored := storedTunnelOptions{TunnelOptions: o}
+	if o.KCP != nil {
+		stored.KCP = &storedKCPOptions{TunnelKCPOptions: *o.KCP, Key: o.KCP.Key}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (o *TunnelOptions) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*o = TunnelOptions{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 TunnelOptions", value)
+	}
+
+	*o = TunnelOptions{}
+	if len(data) == 0 {
+		return nil
+	}
+	var stored storedTunnelOptions
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*o = stored.TunnelOptions
+	if stored.KCP != nil {
+		kcp := stored.KCP.TunnelKCPOptions
+		kcp.Key = stored.KCP.Key
+		o.KCP = &kcp
+	}
+	return nil
+}
+
+// GormDataType 通用数据类型
+func (TunnelOptions) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 按数据库返回列类型
+func (TunnelOptions) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return StringList(nil).GormDBDataType(db, field)
+}
@@ -0,0 +1,311 @@
+// Package openapi 根据路由表和 DTO 类型生成 OpenAPI 3 文档
+// 请求与响应结构通过反射 json/form/binding 标签生成，路由说明由 router 包维护。
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gost-panel/internal/errors"
+)
+
+// Version OpenAPI 规范版本
+const Version = "3.0.3"
+
+// Document OpenAPI 文档
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers,omitempty"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info 文档信息
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server 服务地址
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag 接口分组
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components 可复用组件
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme 认证方式
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Operation 接口操作
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter 路径或查询参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType 内容类型
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route 接口说明
+type Route struct {
+	Method      string // HTTP 方法
+	Path        string // gin 路由路径，如 /api/v1/rules/:id
+	Tag         string // 分组
+	Summary     string // 摘要
+	Description string // 详细说明
+	Public      bool   // 无需认证
+	Query       any    // 查询参数结构（form 标签）
+	Body        any    // JSON 请求体
+	Form        any    // multipart 表单（form 标签，*multipart.FileHeader 为文件）
+	Data        any    // 响应 data 字段
+	Paged       bool   // data 为分页结构，Data 为列表元素类型
+	Raw         string // 非 JSON 响应的内容类型（文件下载等）
+	RawEnvelope bool   // 响应不使用统一响应结构（如观察器上报）
+}
+
+// apiPrefix 生成操作 ID 时去掉的路径前缀
+const apiPrefix = "/api/v1"
+
+// pathParam gin 路径参数
+var pathParam = regexp.MustCompile(`[:*](\w+)`)
+
+// Build 生成文档
+func Build(info Info, tags []Tag, routes []Route) *Document {
+	g := newGenerator()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: []Server{{URL: "/"}},
+		Tags:    tags,
+		Paths:   make(map[string]map[string]Operation),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "登录获得的 JWT，或以 gpt_ 开头的个人 API Token",
+				},
+			},
+		},
+	}
+	doc.Info.Description = strings.TrimSpace(doc.Info.Description + "\n\n" + errorCodeTable())
+
+	g.schemas["Response"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Description: "业务状态码，0 表示成功"},
+			"message": {Type: "string", Description: "响应消息"},
+			"data":    {Description: "响应数据"},
+		},
+		Required: []string{"code", "message", "data"},
+	}
+
+	ids := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		path := pathParam.ReplaceAllString(r.Path, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]Operation)
+		}
+		op := g.operation(r, path)
+		if ids[op.OperationID] {
+			// 去掉前缀后重名时使用完整路径
+			op.OperationID = operationID(r.Method, path)
+		}
+		ids[op.OperationID] = true
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+// operation 生成单个接口操作
+func (g *generator) operation(r Route, path string) Operation {
+	op := Operation{
+		Summary:     r.Summary,
+		Description: r.Description,
+		OperationID: operationID(r.Method, strings.TrimPrefix(path, apiPrefix)),
+		Responses:   make(map[string]Response),
+	}
+	if r.Tag != "" {
+		op.Tags = []string{r.Tag}
+	}
+	if !r.Public {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   pathParamSchema(m[1]),
+		})
+	}
+	if r.Query != nil {
+		op.Parameters = append(op.Parameters, g.queryParams(reflect.TypeOf(r.Query))...)
+	}
+
+	switch {
+	case r.Body != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: g.schemaOf(reflect.TypeOf(r.Body))}},
+		}
+	case r.Form != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: g.formSchema(reflect.TypeOf(r.Form))}},
+		}
+	}
+
+	switch {
+	case r.Raw != "":
+		op.Responses["200"] = Response{
+			Description: "文件内容",
+			Content:     map[string]MediaType{r.Raw: {Schema: &Schema{Type: "string", Format: "binary"}}},
+		}
+	case r.RawEnvelope:
+		op.Responses["200"] = Response{
+			Description: "成功",
+			Content:     map[string]MediaType{"application/json": {Schema: g.schemaOf(reflect.TypeOf(r.Data))}},
+		}
+	default:
+		op.Responses["200"] = Response{
+			Description: "成功",
+			Content:     map[string]MediaType{"application/json": {Schema: g.envelope(r)}},
+		}
+	}
+	if !r.RawEnvelope {
+		op.Responses["default"] = Response{
+			Description: "错误，code 为业务错误码",
+			Content:     map[string]MediaType{"application/json": {Schema: &Schema{Ref: refPrefix + "Response"}}},
+		}
+	}
+	return op
+}
+
+// envelope 统一响应结构，data 替换为具体类型
+func (g *generator) envelope(r Route) *Schema {
+	var data *Schema
+	switch {
+	case r.Paged:
+		data = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"list":     {Type: "array", Items: g.schemaOf(reflect.TypeOf(r.Data))},
+				"total":    {Type: "integer", Format: "int64"},
+				"page":     {Type: "integer"},
+				"pageSize": {Type: "integer"},
+			},
+		}
+	case r.Data != nil:
+		data = g.schemaOf(reflect.TypeOf(r.Data))
+	default:
+		data = &Schema{Nullable: true, Description: "无数据"}
+	}
+
+	return &Schema{AllOf: []*Schema{
+		{Ref: refPrefix + "Response"},
+		{Type: "object", Properties: map[string]*Schema{"data": data}},
+	}}
+}
+
+// pathParamSchema 路径参数类型，id 为整数，其余为字符串
+func pathParamSchema(name string) *Schema {
+	if name == "id" {
+		return &Schema{Type: "integer", Minimum: float(1)}
+	}
+	return &Schema{Type: "string"}
+}
+
+// operationID 由方法和路径生成操作 ID，如 POST /rules/{id}/start -> postRulesIdStart
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.Split(path, "/") {
+		part = strings.Trim(part, "{}")
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// errorCodeTable 业务错误码说明（Markdown 表格）
+func errorCodeTable() string {
+	var b strings.Builder
+	b.WriteString("## 业务错误码\n\n| code | HTTP | 说明 |\n|------|------|------|\n")
+	for _, e := range errors.All() {
+		fmt.Fprintf(&b, "| %d | %d | %s |\n", e.Code, e.HTTPCode, e.Message)
+	}
+	return b.String()
+}
+
+// Undocumented 返回未在文档中声明的路由（method + path），用于检查路由表是否完整
+func Undocumented(routes []Route, registered [][2]string) []string {
+	documented := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		documented[r.Method+" "+r.Path] = true
+	}
+
+	var missing []string
+	for _, r := range registered {
+		if key := r[0] + " " + r[1]; !documented[key] && r[0] != http.MethodHead {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"gost-panel/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// APITokenRepository API Token 仓库
+type APITokenRepository struct {
+	*BaseRepository
+}
+
+// NewAPITokenRepository 创建 API Token 仓库
+func NewAPITokenRepository(db *gorm.DB) *APITokenRepository {
+	return &APITokenRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create 创建 Token
+func (r *APITokenRepository) Create(token *model.APIToken) error {
+	return r.DB.Create(token).Error
+}
+
+// Delete 删除 Token
+func (r *APITokenRepository) Delete(id uint) error {
+	return r.DB.Delete(&model.APIToken{}, id).Error
+}
+
+// FindByID 根据 ID 查询 Token
+func (r *APITokenRepository) FindByID(id uint) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.DB.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindByHash 根据哈希查询 Token（包含所属用户）
+func (r *APITokenRepository) FindByHash(hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.DB.Preload("User").Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByUserID 查询用户的全部 Token
+func (r *APITokenRepository) ListByUserID(userID uint) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// UpdateLastUsed 更新最后使用时间和 IP
+func (r *APITokenRepository) UpdateLastUsed(id uint, at time.Time, ip string) error {
+	return r.DB.Model(&model.APIToken{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
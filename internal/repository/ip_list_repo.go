@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"gost-panel/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// IPListRepository IP 列表仓库
+type IPListRepository struct {
+	*BaseRepository
+}
+
+// NewIPListRepository 创建 IP 列表仓库
+func NewIPListRepository(db *gorm.DB) *IPListRepository {
+	return &IPListRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create 创建 IP 列表
+func (r *IPListRepository) Create(list *model.IPList) error {
+	return r.DB.Create(list).Error
+}
+
+// Update 更新 IP 列表
+func (r *IPListRepository) Update(list *model.IPList) error {
+	return r.DB.Save(list).Error
+}
+
+// Delete 删除 IP 列表
+func (r *IPListRepository) Delete(id uint) error {
+	return r.DB.Delete(&model.IPList{}, id).Error
+}
+
+// FindByID 根据 ID 查询 IP 列表
+func (r *IPListRepository) FindByID(id uint) (*model.IPList, error) {
+	var list model.IPList
+	if err := r.DB.First(&list, id).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// FindByIDs 根据 ID 列表查询 IP 列表
+func (r *IPListRepository) FindByIDs(ids []uint) ([]model.IPList, error) {
+	var lists []model.IPList
+	if len(ids) == 0 {
+		return lists, nil
+	}
+	err := r.DB.Where("id IN ?", ids).Find(&lists).Error
+	return lists, err
+}
+
+// List 查询全部 IP 列表（按名称排序）
+func (r *IPListRepository) List() ([]model.IPList, error) {
+	var lists []model.IPList
+	err := r.DB.Order("name ASC").Find(&lists).Error
+	return lists, err
+}
+
+// ExistsByName 检查名称是否存在
+func (r *IPListRepository) ExistsByName(name string, excludeID ...uint) (bool, error) {
+	var count int64
+	db := r.DB.Model(&model.IPList{}).Where("name = ?", name)
+	if len(excludeID) > 0 {
+		db = db.Where("id != ?", excludeID[0])
+	}
+	if err := db.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
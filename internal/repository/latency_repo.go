@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"gost-panel/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// LatencyStat 单条探测路径在统计窗口内的汇总，延迟只统计成功的探测
+type LatencyStat struct {
+	Path      model.LatencyPath
+	NodeID    uint
+	RuleID    uint
+	TunnelID  uint
+	Target    string
+	Samples   int64
+	Succeeded int64
+	MinMs     *float64
+	AvgMs     *float64
+	MaxMs     *float64
+}
+
+// LatencyRepository 延迟探测记录仓库
+type LatencyRepository struct {
+	*BaseRepository
+}
+
+// NewLatencyRepository 创建延迟探测记录仓库
+func NewLatencyRepository(db *gorm.DB) *LatencyRepository {
+	return &LatencyRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// CreateBatch 批量写入探测结果
+func (r *LatencyRepository) CreateBatch(samples []model.LatencySample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	return r.DB.CreateInBatches(samples, 100).Error
+}
+
+// Stats 按路径汇总 since 之后的探测结果，conditions 为附加筛选条件
+func (r *LatencyRepository) Stats(since time.Time, conditions map[string]any) ([]LatencyStat, error) {
+	var stats []LatencyStat
+	db := r.DB.Model(&model.LatencySample{}).
+		Select("path, node_id, rule_id, tunnel_id, target, COUNT(*) AS samples, "+
+			"SUM(CASE WHEN success THEN 1 ELSE 0 END) AS succeeded, "+
+			"MIN(CASE WHEN success THEN latency_ms END) AS min_ms, "+
+			"AVG(CASE WHEN success THEN latency_ms END) AS avg_ms, "+
+			"MAX(CASE WHEN success THEN latency_ms END) AS max_ms").
+		Where("created_at >= ?", since)
+	db = ApplyConditions(db, &QueryOption{Conditions: conditions})
+	err := db.Group("path, node_id, rule_id, tunnel_id, target").
+		Order("path, node_id, rule_id, tunnel_id, target").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// DeleteBefore 删除 before 之前的探测记录
+func (r *LatencyRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("created_at < ?", before).Delete(&model.LatencySample{})
+	return result.RowsAffected, result.Error
+}
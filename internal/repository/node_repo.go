@@ -139,49 +139,6 @@ func (r *NodeRepository) CountAll() (int64, error) {
 	return count, err
 }
 
-// UpdateStats 更新节点流量统计（计算增量）
-// Gost observer 上报的是累计总量，需要计算增量后再累加
-func (r *NodeRepository) UpdateStats(id uint, reportedInputBytes, reportedOutputBytes int64) error {
-	// 先查询当前值
-	var node model.GostNode
-	if err := r.DB.Select("id", "last_reported_input_bytes", "last_reported_output_bytes").
-		Where("id = ?", id).First(&node).Error; err != nil {
-		return err
-	}
-
-	// 计算增量（如果是第一次上报或重启后，上报值可能小于上次值，此时重置为上报值）
-	var inputDelta, outputDelta int64
-	if reportedInputBytes >= node.LastReportedInputBytes {
-		inputDelta = reportedInputBytes - node.LastReportedInputBytes
-	} else {
-		// Gost 重启后计数器重置，直接使用新值作为增量
-		inputDelta = reportedInputBytes
-	}
-
-	if reportedOutputBytes >= node.LastReportedOutputBytes {
-		outputDelta = reportedOutputBytes - node.LastReportedOutputBytes
-	} else {
-		outputDelta = reportedOutputBytes
-	}
-
-	// 只有增量大于0时才更新（避免无效更新）
-	if inputDelta > 0 || outputDelta > 0 {
-		return r.DB.Model(&model.GostNode{}).Where("id = ?", id).Updates(map[string]interface{}{
-			"input_bytes":                gorm.Expr("input_bytes + ?", inputDelta),
-			"output_bytes":               gorm.Expr("output_bytes + ?", outputDelta),
-			"total_bytes":                gorm.Expr("total_bytes + ?", inputDelta+outputDelta),
-			"last_reported_input_bytes":  reportedInputBytes,
-			"last_reported_output_bytes": reportedOutputBytes,
-		}).Error
-	}
-
-	// 没有增量，只更新上次上报值
-	return r.DB.Model(&model.GostNode{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"last_reported_input_bytes":  reportedInputBytes,
-		"last_reported_output_bytes": reportedOutputBytes,
-	}).Error
-}
-
 // AddStatsDelta 按增量累加节点流量统计
 func (r *NodeRepository) AddStatsDelta(id uint, inputDelta, outputDelta int64) error {
 	if inputDelta == 0 && outputDelta == 0 {
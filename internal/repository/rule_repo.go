@@ -91,19 +91,18 @@ func (r *RuleRepository) FindByTunnelID(tunnelID uint) ([]model.GostRule, error)
 	return rules, err
 }
 
-// ExistsByPort 检查端口是否已被使用
-func (r *RuleRepository) ExistsByPort(nodeID uint, port int, excludeID ...uint) (bool, error) {
-	var count int64
-	db := r.DB.Model(&model.GostRule{}).
-		Where("node_id = ? AND listen_port = ?", nodeID, port)
-	if len(excludeID) > 0 {
-		db = db.Where("id != ?", excludeID[0])
-	}
-	err := db.Count(&count).Error
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
+// FindByEntryNodeID 查询监听在节点上的规则（包括以该节点为入口的隧道转发规则）
+func (r *RuleRepository) FindByEntryNodeID(nodeID uint) ([]model.GostRule, error) {
+	var rules []model.GostRule
+	// 隧道转发规则监听在隧道的入口节点上
+	tunnelIDs := r.DB.Model(&model.GostTunnel{}).Select("id").Where("entry_node_id = ?", nodeID)
+	err := r.DB.Where("node_id = ? OR tunnel_id IN (?)", nodeID, tunnelIDs).Find(&rules).Error
+	return rules, err
+}
+
+// DeleteServiceCounters 删除规则各服务的上报累计值
+func (r *RuleRepository) DeleteServiceCounters(ruleID uint) error {
+	return r.DB.Where("rule_id = ?", ruleID).Delete(&model.RuleServiceCounter{}).Error
 }
 
 // UpdateStatus 更新规则状态
@@ -124,6 +123,11 @@ func (r *RuleRepository) UpdateServiceInfo(id uint, serviceID, chainID string) e
 	})
 }
 
+// UpdateInnerPort 更新反向代理规则的内部端口
+func (r *RuleRepository) UpdateInnerPort(id uint, port int) error {
+	return r.UpdateField(&model.GostRule{}, id, "inner_port", port)
+}
+
 // UpdateObserverID 更新观察器 ID
 func (r *RuleRepository) UpdateObserverID(id uint, observerID string) error {
 	return r.UpdateField(&model.GostRule{}, id, "observer_id", observerID)
@@ -168,16 +172,12 @@ func (r *RuleRepository) StopByNodeID(nodeID uint) error {
 // Gost observer 上报的是累计总量，需要计算增量后再累加
 // 返回本次增量值 (inputDelta, outputDelta, connsDelta)
 func (r *RuleRepository) UpdateStats(id uint, serviceName string, reportedInputBytes, reportedOutputBytes, reportedTotalConns int64) (int64, int64, int64, error) {
-	// 选择对应协议的累计字段
-	inputField := "last_reported_input_bytes"
-	outputField := "last_reported_output_bytes"
-	connsField := "last_reported_total_conns"
-
-	if strings.HasSuffix(serviceName, "-tcp") {
-		inputField = "last_reported_input_bytes_tcp"
-		outputField = "last_reported_output_bytes_tcp"
-		connsField = "last_reported_total_conns_tcp"
-	} else if strings.HasSuffix(serviceName, "-udp") {
+	// 选择对应协议的累计字段（未带后缀的旧服务名按 TCP 计算）
+	inputField := "last_reported_input_bytes_tcp"
+	outputField := "last_reported_output_bytes_tcp"
+	connsField := "last_reported_total_conns_tcp"
+
+	if strings.HasSuffix(serviceName, "-udp") {
 		inputField = "last_reported_input_bytes_udp"
 		outputField = "last_reported_output_bytes_udp"
 		connsField = "last_reported_total_conns_udp"
@@ -190,84 +190,91 @@ func (r *RuleRepository) UpdateStats(id uint, serviceName string, reportedInputB
 		inputField,
 		outputField,
 		connsField,
-		"last_reported_input_bytes",
-		"last_reported_output_bytes",
-		"last_reported_total_conns",
 	).
 		Where("id = ?", id).First(&rule).Error; err != nil {
 		return 0, 0, 0, err
 	}
 
-	var lastInput, lastOutput, lastConns int64
-	switch {
-	case strings.HasSuffix(serviceName, "-tcp"):
-		lastInput = rule.LastReportedInputBytesTCP
-		lastOutput = rule.LastReportedOutputBytesTCP
-		lastConns = rule.LastReportedTotalConnsTCP
-	case strings.HasSuffix(serviceName, "-udp"):
+	lastInput := rule.LastReportedInputBytesTCP
+	lastOutput := rule.LastReportedOutputBytesTCP
+	lastConns := rule.LastReportedTotalConnsTCP
+	if strings.HasSuffix(serviceName, "-udp") {
 		lastInput = rule.LastReportedInputBytesUDP
 		lastOutput = rule.LastReportedOutputBytesUDP
 		lastConns = rule.LastReportedTotalConnsUDP
-	default:
-		lastInput = rule.LastReportedInputBytes
-		lastOutput = rule.LastReportedOutputBytes
-		lastConns = rule.LastReportedTotalConns
-	}
-
-	legacyHasData := rule.LastReportedInputBytes > 0 || rule.LastReportedOutputBytes > 0 || rule.LastReportedTotalConns > 0
-	protocolIsNew := strings.HasSuffix(serviceName, "-tcp") || strings.HasSuffix(serviceName, "-udp")
-	if protocolIsNew && lastInput == 0 && lastOutput == 0 && lastConns == 0 && legacyHasData {
-		updates := map[string]interface{}{
-			inputField:  reportedInputBytes,
-			outputField: reportedOutputBytes,
-			connsField:  reportedTotalConns,
-		}
-		if err := r.DB.Model(&model.GostRule{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-			return 0, 0, 0, err
-		}
-		return 0, 0, 0, nil
 	}
 
 	// 计算增量（如果是第一次上报或重启后，上报值可能小于上次值，此时重置为上报值）
-	var inputDelta, outputDelta, connsDelta int64
-	if reportedInputBytes >= lastInput {
-		inputDelta = reportedInputBytes - lastInput
-	} else {
-		// Gost 重启后计数器重置，直接使用新值作为增量
-		inputDelta = reportedInputBytes
+	inputDelta := reportedDelta(reportedInputBytes, lastInput)
+	outputDelta := reportedDelta(reportedOutputBytes, lastOutput)
+	connsDelta := reportedDelta(reportedTotalConns, lastConns)
+
+	updates := map[string]interface{}{
+		inputField:  reportedInputBytes,
+		outputField: reportedOutputBytes,
+		connsField:  reportedTotalConns,
 	}
+	addRuleStatsDelta(updates, inputDelta, outputDelta, connsDelta)
 
-	if reportedOutputBytes >= lastOutput {
-		outputDelta = reportedOutputBytes - lastOutput
-	} else {
-		outputDelta = reportedOutputBytes
+	if err := r.DB.Model(&model.GostRule{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return 0, 0, 0, err
 	}
 
-	if reportedTotalConns >= lastConns {
-		connsDelta = reportedTotalConns - lastConns
-	} else {
-		connsDelta = reportedTotalConns
+	return inputDelta, outputDelta, connsDelta, nil
+}
+
+// UpdateServiceStats 按服务记录上报累计值并累加规则流量（端口范围规则，每个端口的服务独立计数）
+// 返回本次增量
+func (r *RuleRepository) UpdateServiceStats(id uint, serviceName string, reportedInputBytes, reportedOutputBytes, reportedTotalConns int64) (int64, int64, int64, error) {
+	var inputDelta, outputDelta, connsDelta int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		counter := model.RuleServiceCounter{RuleID: id, Service: serviceName}
+		if err := tx.Where(&counter).FirstOrCreate(&counter).Error; err != nil {
+			return err
+		}
+
+		inputDelta = reportedDelta(reportedInputBytes, counter.LastReportedInputBytes)
+		outputDelta = reportedDelta(reportedOutputBytes, counter.LastReportedOutputBytes)
+		connsDelta = reportedDelta(reportedTotalConns, counter.LastReportedTotalConns)
+
+		if err := tx.Model(&counter).Updates(map[string]interface{}{
+			"last_reported_input_bytes":  reportedInputBytes,
+			"last_reported_output_bytes": reportedOutputBytes,
+			"last_reported_total_conns":  reportedTotalConns,
+		}).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		addRuleStatsDelta(updates, inputDelta, outputDelta, connsDelta)
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&model.GostRule{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
+		return 0, 0, 0, err
 	}
+	return inputDelta, outputDelta, connsDelta, nil
+}
 
-	updates := map[string]interface{}{
-		inputField:  reportedInputBytes,
-		outputField: reportedOutputBytes,
-		connsField:  reportedTotalConns,
+// reportedDelta 由上报的累计值计算增量
+// 上报值小于上次值说明 Gost 重启后计数器已重置，直接使用新值作为增量
+func reportedDelta(reported, last int64) int64 {
+	if reported >= last {
+		return reported - last
 	}
+	return reported
+}
 
-	// 只有增量大于0时才更新（避免无效更新）
+// addRuleStatsDelta 将增量累加写入规则流量字段，增量全为 0 时不写入（避免无效更新）
+func addRuleStatsDelta(updates map[string]interface{}, inputDelta, outputDelta, connsDelta int64) {
 	if inputDelta > 0 || outputDelta > 0 || connsDelta > 0 {
 		updates["input_bytes"] = gorm.Expr("input_bytes + ?", inputDelta)
 		updates["output_bytes"] = gorm.Expr("output_bytes + ?", outputDelta)
 		updates["total_bytes"] = gorm.Expr("total_bytes + ?", inputDelta+outputDelta)
 		updates["total_requests"] = gorm.Expr("total_requests + ?", connsDelta)
 	}
-
-	if err := r.DB.Model(&model.GostRule{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-		return 0, 0, 0, err
-	}
-
-	return inputDelta, outputDelta, connsDelta, nil
 }
 
 // StopByTunnelIDs 停止指定隧道列表关联的所有规则
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"gost-panel/internal/model"
 
 	"gorm.io/gorm"
@@ -28,15 +30,50 @@ func (r *TunnelRepository) Update(tunnel *model.GostTunnel) error {
 	return r.DB.Save(tunnel).Error
 }
 
-// Delete 删除隧道
+// Delete 删除隧道及其附加出口
 func (r *TunnelRepository) Delete(id uint) error {
-	return r.DB.Delete(&model.GostTunnel{}, id).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tunnel_id = ?", id).Delete(&model.TunnelExit{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.GostTunnel{}, id).Error
+	})
+}
+
+// ReplaceExtraExits 按顺序替换隧道的附加出口，仍保留的出口沿用原有流量计数
+func (r *TunnelRepository) ReplaceExtraExits(tunnelID uint, nodeIDs []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tunnel_id = ? AND node_id NOT IN ?", tunnelID, append([]uint{0}, nodeIDs...)).
+			Delete(&model.TunnelExit{}).Error; err != nil {
+			return err
+		}
+		for i, nodeID := range nodeIDs {
+			exit := model.TunnelExit{TunnelID: tunnelID, NodeID: nodeID}
+			if err := tx.Where(&exit).FirstOrCreate(&exit).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&exit).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// preloadExtraExits 按顺序预加载附加出口及其节点
+func preloadExtraExits(db *gorm.DB) *gorm.DB {
+	return db.Preload("ExtraExits", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}).Preload("ExtraExits.Node")
 }
 
+// exitNodeCondition 以节点为出口（主出口或附加出口）的隧道条件
+const exitNodeCondition = "(exit_node_id = ? OR id IN (SELECT tunnel_id FROM tunnel_exits WHERE node_id = ?))"
+
 // FindByID 根据 ID 查询隧道（包含关联节点）
 func (r *TunnelRepository) FindByID(id uint) (*model.GostTunnel, error) {
 	var tunnel model.GostTunnel
-	err := r.DB.Preload("EntryNode").Preload("ExitNode").First(&tunnel, id).Error
+	err := preloadExtraExits(r.DB.Preload("EntryNode").Preload("ExitNode")).First(&tunnel, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +96,7 @@ func (r *TunnelRepository) List(opt *QueryOption) ([]model.GostTunnel, int64, er
 	}
 
 	// 预加载节点和关联规则
-	db = db.Preload("EntryNode").Preload("ExitNode")
+	db = preloadExtraExits(db.Preload("EntryNode").Preload("ExitNode"))
 
 	// 默认按创建时间倒序
 	if opt == nil || len(opt.Orders) == 0 {
@@ -88,17 +125,27 @@ func (r *TunnelRepository) CountAll() (int64, error) {
 	return count, err
 }
 
-// FindByNodeID 查找节点相关的隧道
+// FindByNodeID 查找节点相关的隧道（入口、主出口或附加出口）
 func (r *TunnelRepository) FindByNodeID(nodeID uint) ([]model.GostTunnel, error) {
 	var tunnels []model.GostTunnel
-	err := r.DB.Where("entry_node_id = ? OR exit_node_id = ?", nodeID, nodeID).Find(&tunnels).Error
+	err := preloadExtraExits(r.DB).Where("entry_node_id = ? OR "+exitNodeCondition, nodeID, nodeID, nodeID).Find(&tunnels).Error
 	return tunnels, err
 }
 
-// StopByNodeID 停止与该节点相关的所有隧道
-func (r *TunnelRepository) StopByNodeID(nodeID uint) error {
+// FindByExitNodeID 查询以节点为出口（主出口或附加出口）的隧道
+func (r *TunnelRepository) FindByExitNodeID(nodeID uint) ([]model.GostTunnel, error) {
+	var tunnels []model.GostTunnel
+	err := preloadExtraExits(r.DB).Where(exitNodeCondition, nodeID, nodeID).Find(&tunnels).Error
+	return tunnels, err
+}
+
+// StopByIDs 停止指定的运行中隧道
+func (r *TunnelRepository) StopByIDs(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
 	return r.DB.Model(&model.GostTunnel{}).
-		Where("(entry_node_id = ? OR exit_node_id = ?) AND status = ?", nodeID, nodeID, model.TunnelStatusRunning).
+		Where("id IN ? AND status = ?", ids, model.TunnelStatusRunning).
 		Update("status", model.TunnelStatusStopped).Error
 }
 
@@ -130,6 +177,16 @@ func (r *TunnelRepository) UpdateServiceInfo(id uint, serviceID, chainID string)
 		}).Error
 }
 
+// UpdateCredentials 更新隧道 Relay 认证凭据
+func (r *TunnelRepository) UpdateCredentials(id uint, username, password string, rotatedAt time.Time) error {
+	return r.DB.Model(&model.GostTunnel{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"relay_username":         username,
+			"relay_password":         password,
+			"credentials_rotated_at": rotatedAt,
+		}).Error
+}
+
 // UpdateStats 更新隧道流量统计（计算增量）
 // Gost observer 上报的是累计总量，需要计算增量后再累加
 // 返回本次增量值 (inputDelta, outputDelta)
@@ -180,3 +237,38 @@ func (r *TunnelRepository) UpdateStats(id uint, reportedInputBytes, reportedOutp
 
 	return inputDelta, outputDelta, nil
 }
+
+// UpdateExitStats 更新附加出口的流量统计（计算增量），增量累加到隧道
+// 返回本次增量值 (inputDelta, outputDelta)
+func (r *TunnelRepository) UpdateExitStats(id, nodeID uint, reportedInputBytes, reportedOutputBytes int64) (int64, int64, error) {
+	var inputDelta, outputDelta int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var exit model.TunnelExit
+		if err := tx.Where("tunnel_id = ? AND node_id = ?", id, nodeID).First(&exit).Error; err != nil {
+			return err
+		}
+
+		inputDelta = reportedDelta(reportedInputBytes, exit.LastReportedInputBytes)
+		outputDelta = reportedDelta(reportedOutputBytes, exit.LastReportedOutputBytes)
+
+		if err := tx.Model(&exit).Updates(map[string]interface{}{
+			"last_reported_input_bytes":  reportedInputBytes,
+			"last_reported_output_bytes": reportedOutputBytes,
+		}).Error; err != nil {
+			return err
+		}
+
+		if inputDelta == 0 && outputDelta == 0 {
+			return nil
+		}
+		return tx.Model(&model.GostTunnel{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"input_bytes":  gorm.Expr("input_bytes + ?", inputDelta),
+			"output_bytes": gorm.Expr("output_bytes + ?", outputDelta),
+			"total_bytes":  gorm.Expr("total_bytes + ?", inputDelta+outputDelta),
+		}).Error
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return inputDelta, outputDelta, nil
+}
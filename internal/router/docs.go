@@ -0,0 +1,196 @@
+package router
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"gost-panel/internal/config"
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+	"gost-panel/internal/openapi"
+	"gost-panel/internal/service"
+	"gost-panel/pkg/gost"
+	"gost-panel/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 接口分组
+const (
+	tagHealth    = "health"
+	tagAuth      = "auth"
+	tagDashboard = "dashboard"
+	tagLatency   = "latency"
+	tagNodes     = "nodes"
+	tagRules     = "rules"
+	tagIPLists   = "ip-lists"
+	tagTunnels   = "tunnels"
+	tagLogs      = "logs"
+	tagInventory = "inventory"
+	tagSystem    = "system"
+	tagBackups   = "backups"
+	tagObserver  = "observer"
+)
+
+var apiTags = []openapi.Tag{
+	{Name: tagHealth, Description: "健康检查"},
+	{Name: tagAuth, Description: "登录、用户信息与个人 API Token"},
+	{Name: tagDashboard, Description: "仪表盘统计"},
+	{Name: tagLatency, Description: "节点、转发目标与隧道的延迟探测"},
+	{Name: tagNodes, Description: "节点管理"},
+	{Name: tagRules, Description: "转发规则管理"},
+	{Name: tagIPLists, Description: "规则共用的命名 IP 列表"},
+	{Name: tagTunnels, Description: "隧道管理"},
+	{Name: tagLogs, Description: "操作日志"},
+	{Name: tagInventory, Description: "资源清单导入导出"},
+	{Name: tagSystem, Description: "系统设置"},
+	{Name: tagBackups, Description: "备份管理"},
+	{Name: tagObserver, Description: "GOST 观察器上报"},
+}
+
+// healthResp 健康检查响应
+type healthResp struct {
+	Status    string `json:"status"`
+	Version   string `json:"version,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// tokenResp 刷新 Token 响应
+type tokenResp struct {
+	Token string `json:"token"`
+}
+
+// createdAPITokenResp 创建 API Token 响应
+type createdAPITokenResp struct {
+	Token    string          `json:"token"` // Token 明文，只返回一次
+	APIToken *model.APIToken `json:"api_token"`
+}
+
+// uploadBackupForm 上传备份表单
+type uploadBackupForm struct {
+	File       *multipart.FileHeader `form:"file" binding:"required"` // 备份文件
+	Passphrase string                `form:"passphrase"`              // 加密备份的口令
+}
+
+// uploadBackupResp 上传备份响应
+type uploadBackupResp struct {
+	Name string `json:"name"` // 保存的文件名
+}
+
+// apiRoutes 接口说明，与 Setup 中注册的路由一一对应
+var apiRoutes = []openapi.Route{
+	// 健康检查
+	{Method: http.MethodGet, Path: "/health", Tag: tagHealth, Summary: "健康检查", Public: true, Data: healthResp{}, RawEnvelope: true},
+	{Method: http.MethodGet, Path: "/api/v1/health", Tag: tagHealth, Summary: "健康检查（含版本）", Public: true, Data: healthResp{}, RawEnvelope: true},
+
+	// 认证
+	{Method: http.MethodPost, Path: "/api/v1/auth/login", Tag: tagAuth, Summary: "登录", Public: true, Body: dto.LoginReq{}, Data: service.LoginResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/auth/info", Tag: tagAuth, Summary: "当前用户信息", Data: dto.UserInfoResp{}},
+	{Method: http.MethodPut, Path: "/api/v1/auth/password", Tag: tagAuth, Summary: "修改密码", Body: dto.ChangePasswordReq{}},
+	{Method: http.MethodPost, Path: "/api/v1/auth/refresh", Tag: tagAuth, Summary: "刷新 JWT", Data: tokenResp{}},
+	{Method: http.MethodGet, Path: "/api/v1/auth/tokens", Tag: tagAuth, Summary: "API Token 列表", Data: []model.APIToken{}},
+	{Method: http.MethodPost, Path: "/api/v1/auth/tokens", Tag: tagAuth, Summary: "创建 API Token", Description: "Token 明文只在创建时返回一次", Body: dto.CreateAPITokenReq{}, Data: createdAPITokenResp{}},
+	{Method: http.MethodDelete, Path: "/api/v1/auth/tokens/:id", Tag: tagAuth, Summary: "吊销 API Token"},
+
+	// 仪表盘
+	{Method: http.MethodGet, Path: "/api/v1/dashboard/stats", Tag: tagDashboard, Summary: "仪表盘统计", Data: dto.DashboardStats{}},
+
+	// 延迟探测
+	{Method: http.MethodGet, Path: "/api/v1/latency", Tag: tagLatency, Summary: "延迟统计", Description: "面板每 30 秒探测一次到节点 API、运行中规则目标的 TCP 连接耗时，以及经运行中隧道每个出口的往返耗时；按路径返回窗口内的最小、平均、最大延迟与丢失率，隧道中表现最好的出口标记为 best。记录保留 7 天", Query: dto.LatencyStatsReq{}, Data: []dto.LatencyPathStats{}},
+
+	// 节点
+	{Method: http.MethodGet, Path: "/api/v1/nodes", Tag: tagNodes, Summary: "节点列表", Query: dto.NodeListReq{}, Data: model.GostNode{}, Paged: true},
+	{Method: http.MethodGet, Path: "/api/v1/nodes/:id", Tag: tagNodes, Summary: "节点详情", Data: model.GostNode{}},
+	{Method: http.MethodPost, Path: "/api/v1/nodes", Tag: tagNodes, Summary: "创建节点", Body: dto.CreateNodeReq{}, Data: model.GostNode{}},
+	{Method: http.MethodPut, Path: "/api/v1/nodes/:id", Tag: tagNodes, Summary: "更新节点", Body: dto.UpdateNodeReq{}, Data: model.GostNode{}},
+	{Method: http.MethodDelete, Path: "/api/v1/nodes/:id", Tag: tagNodes, Summary: "删除节点"},
+	{Method: http.MethodGet, Path: "/api/v1/nodes/:id/config", Tag: tagNodes, Summary: "节点上的 GOST 配置", Data: gost.GostConfig{}},
+	{Method: http.MethodGet, Path: "/api/v1/nodes/:id/ports", Tag: tagNodes, Summary: "端口池与占用", Description: "汇总规则监听端口、隧道 Relay 端口、节点 API 端口，节点在线时包含实际运行的服务，并给出下一个可分配端口", Data: dto.NodePortsResp{}},
+	{Method: http.MethodPut, Path: "/api/v1/nodes/:id/maintenance", Tag: tagNodes, Summary: "设置维护模式", Description: "维护中的节点不能放置新的规则和隧道，状态变化不告警", Body: dto.NodeMaintenanceReq{}, Data: model.GostNode{}},
+	{Method: http.MethodPost, Path: "/api/v1/nodes/:id/drain", Tag: tagNodes, Summary: "排空节点", Description: "节点需处于维护模式；端口转发规则迁移到替换节点，以该节点为出口的隧道改用替换节点，返回逐项结果", Body: dto.DrainNodeReq{}, Data: dto.BatchResp{}},
+
+	// 规则
+	{Method: http.MethodGet, Path: "/api/v1/rules", Tag: tagRules, Summary: "规则列表", Query: dto.RuleListReq{}, Data: model.GostRule{}, Paged: true},
+	{Method: http.MethodGet, Path: "/api/v1/rules/:id", Tag: tagRules, Summary: "规则详情", Data: model.GostRule{}},
+	{Method: http.MethodGet, Path: "/api/v1/rules/:id/targets", Tag: tagRules, Summary: "转发目标状态", Description: "返回各目标的权重、备用设置和探测状态；状态由面板定期 TCP 探测运行中规则的目标，按规则的最大失败次数和失败超时判断是否失效", Data: dto.RuleTargetsResp{}},
+	{Method: http.MethodPost, Path: "/api/v1/rules", Tag: tagRules, Summary: "创建规则", Body: dto.CreateRuleReq{}, Data: model.GostRule{}},
+	{Method: http.MethodPut, Path: "/api/v1/rules/:id", Tag: tagRules, Summary: "更新规则", Body: dto.UpdateRuleReq{}, Data: model.GostRule{}},
+	{Method: http.MethodDelete, Path: "/api/v1/rules/:id", Tag: tagRules, Summary: "删除规则"},
+	{Method: http.MethodPost, Path: "/api/v1/rules/:id/start", Tag: tagRules, Summary: "启动规则"},
+	{Method: http.MethodPost, Path: "/api/v1/rules/:id/stop", Tag: tagRules, Summary: "停止规则"},
+	{Method: http.MethodPost, Path: "/api/v1/rules/:id/clone", Tag: tagRules, Summary: "克隆规则", Description: "复制规则到其他节点或隧道；start 为 true 时立即启动，启动失败则撤销克隆", Body: dto.CloneRuleReq{}, Data: model.GostRule{}},
+	{Method: http.MethodPost, Path: "/api/v1/rules/:id/migrate", Tag: tagRules, Summary: "迁移规则", Description: "将规则的入口改为其他节点或隧道，运行中的规则会在目标上重新启动，失败时恢复原入口", Body: dto.MigrateRuleReq{}, Data: model.GostRule{}},
+	{Method: http.MethodPost, Path: "/api/v1/rules/batch", Tag: tagRules, Summary: "批量操作规则", Description: "按 ID 列表或筛选条件批量启动、停止、重启或删除规则，返回逐项结果；批量删除前自动创建快照", Body: dto.BatchRuleReq{}, Data: dto.BatchResp{}},
+
+	// 命名 IP 列表
+	{Method: http.MethodGet, Path: "/api/v1/ip-lists", Tag: tagIPLists, Summary: "IP 列表", Data: []model.IPList{}},
+	{Method: http.MethodGet, Path: "/api/v1/ip-lists/:id", Tag: tagIPLists, Summary: "IP 列表详情", Data: model.IPList{}},
+	{Method: http.MethodPost, Path: "/api/v1/ip-lists", Tag: tagIPLists, Summary: "创建 IP 列表", Description: "条目为 IP 或 CIDR，保存时去重并规范化", Body: dto.CreateIPListReq{}, Data: model.IPList{}},
+	{Method: http.MethodPut, Path: "/api/v1/ip-lists/:id", Tag: tagIPLists, Summary: "更新 IP 列表", Description: "同步更新引用该列表的运行中规则的准入控制器", Body: dto.UpdateIPListReq{}, Data: model.IPList{}},
+	{Method: http.MethodDelete, Path: "/api/v1/ip-lists/:id", Tag: tagIPLists, Summary: "删除 IP 列表", Description: "被规则引用的列表不能删除"},
+
+	// 隧道
+	{Method: http.MethodGet, Path: "/api/v1/tunnels", Tag: tagTunnels, Summary: "隧道列表", Query: dto.TunnelListReq{}, Data: model.GostTunnel{}, Paged: true},
+	{Method: http.MethodGet, Path: "/api/v1/tunnels/:id", Tag: tagTunnels, Summary: "隧道详情", Data: model.GostTunnel{}},
+	{Method: http.MethodPost, Path: "/api/v1/tunnels", Tag: tagTunnels, Summary: "创建隧道", Description: "options 按协议分组设置传输参数（ws、tls、mux、quic、kcp），与协议不匹配的分组会被拒绝；extra_exit_node_ids 设置附加出口，与主出口使用同一 Relay 端口，入口按 strategy（round、rand、fifo）在出口间选择", Body: dto.CreateTunnelReq{}, Data: model.GostTunnel{}},
+	{Method: http.MethodPut, Path: "/api/v1/tunnels/:id", Tag: tagTunnels, Summary: "更新隧道", Description: "仅支持已停止的隧道，options 整体替换原有传输参数，extra_exit_node_ids 整体替换附加出口；入口和主出口不能修改", Body: dto.UpdateTunnelReq{}, Data: model.GostTunnel{}},
+	{Method: http.MethodDelete, Path: "/api/v1/tunnels/:id", Tag: tagTunnels, Summary: "删除隧道"},
+	{Method: http.MethodPost, Path: "/api/v1/tunnels/:id/start", Tag: tagTunnels, Summary: "启动隧道"},
+	{Method: http.MethodPost, Path: "/api/v1/tunnels/:id/stop", Tag: tagTunnels, Summary: "停止隧道"},
+	{Method: http.MethodPost, Path: "/api/v1/tunnels/:id/rotate-credentials", Tag: tagTunnels, Summary: "轮换隧道凭据", Description: "重新生成出口 Relay 服务的认证凭据；运行中的隧道先让出口同时接受新旧凭据，入口 Chain 切换后再移除旧凭据，转发不中断。凭据不在接口中返回", Data: model.GostTunnel{}},
+	{Method: http.MethodPost, Path: "/api/v1/tunnels/batch", Tag: tagTunnels, Summary: "批量操作隧道", Description: "按 ID 列表或筛选条件批量启动、停止、重启或删除隧道，返回逐项结果；批量删除前自动创建快照", Body: dto.BatchTunnelReq{}, Data: dto.BatchResp{}},
+
+	// 操作日志
+	{Method: http.MethodGet, Path: "/api/v1/logs", Tag: tagLogs, Summary: "操作日志列表", Query: dto.LogListReq{}, Data: model.OperationLog{}, Paged: true},
+
+	// 资源清单
+	{Method: http.MethodPost, Path: "/api/v1/inventory/export", Tag: tagInventory, Summary: "导出资源清单", Description: "返回 YAML 或 JSON 文件", Body: dto.ExportInventoryReq{}, Raw: "application/x-yaml"},
+	{Method: http.MethodPost, Path: "/api/v1/inventory/plan", Tag: tagInventory, Summary: "预览导入变更", Body: dto.ImportInventoryReq{}, Data: dto.InventoryPlan{}},
+	{Method: http.MethodPost, Path: "/api/v1/inventory/apply", Tag: tagInventory, Summary: "执行导入", Description: "存在冲突时返回错误码，data 为包含冲突项的计划", Body: dto.ImportInventoryReq{}, Data: dto.InventoryPlan{}},
+
+	// 系统设置
+	{Method: http.MethodGet, Path: "/api/v1/system/public-config", Tag: tagSystem, Summary: "公开系统配置", Public: true, Data: dto.PublicSystemConfigResp{}},
+	{Method: http.MethodGet, Path: "/api/v1/system/config", Tag: tagSystem, Summary: "系统配置", Data: dto.SystemConfigResp{}},
+	{Method: http.MethodPut, Path: "/api/v1/system/config", Tag: tagSystem, Summary: "更新系统配置", Body: dto.UpdateSystemConfigReq{}},
+	{Method: http.MethodPost, Path: "/api/v1/system/email/test", Tag: tagSystem, Summary: "发送测试邮件", Body: dto.EmailConfigReq{}},
+
+	// 备份
+	{Method: http.MethodPost, Path: "/api/v1/system/backup", Tag: tagBackups, Summary: "立即备份"},
+	{Method: http.MethodPost, Path: "/api/v1/system/backup/s3/test", Tag: tagBackups, Summary: "测试 S3 连接", Body: dto.S3ConfigReq{}},
+	{Method: http.MethodGet, Path: "/api/v1/system/backups", Tag: tagBackups, Summary: "备份文件列表", Data: []dto.BackupFileResp{}},
+	{Method: http.MethodPost, Path: "/api/v1/system/backups/upload", Tag: tagBackups, Summary: "上传备份文件", Form: uploadBackupForm{}, Data: uploadBackupResp{}},
+	{Method: http.MethodGet, Path: "/api/v1/system/backups/:name/download", Tag: tagBackups, Summary: "下载备份文件", Raw: "application/octet-stream"},
+	{Method: http.MethodPost, Path: "/api/v1/system/backups/:name/restore", Tag: tagBackups, Summary: "从备份恢复", Description: "请求体可选，仅加密备份需要口令", Body: dto.RestoreBackupReq{}, Data: dto.RestoreBackupResp{}},
+
+	// 观察器
+	{Method: http.MethodPost, Path: "/api/v1/observer/report", Tag: tagObserver, Summary: "GOST 观察器上报", Description: "由 GOST 节点调用，响应不使用统一响应结构", Public: true, Body: dto.ObserverReportReq{}, Data: dto.ObserverReportResp{}, RawEnvelope: true},
+}
+
+// 不写入文档的路由（文档自身与 Prometheus 指标）
+var undocumentedRoutes = map[string]bool{
+	"GET /api/openapi.json": true,
+	"GET /api/docs":         true,
+	"GET /metrics":          true,
+}
+
+// OpenAPISpec 生成 OpenAPI 文档
+func OpenAPISpec() *openapi.Document {
+	return openapi.Build(openapi.Info{
+		Title:       "GOST Panel API",
+		Description: "GOST Panel 管理接口。除特别说明外，响应均为 `{code, message, data}` 结构，code 为 0 表示成功；通用错误码 40000 参数错误、40100 未登录、40300 无权限、50000 服务器错误。",
+		Version:     config.Version,
+	}, apiTags, apiRoutes)
+}
+
+// checkDocumented 检查已注册路由是否都写入了文档
+func checkDocumented(engine *gin.Engine) {
+	var registered [][2]string
+	for _, r := range engine.Routes() {
+		if !undocumentedRoutes[r.Method+" "+r.Path] {
+			registered = append(registered, [2]string{r.Method, r.Path})
+		}
+	}
+	for _, route := range openapi.Undocumented(apiRoutes, registered) {
+		logger.Warnf("接口未写入 OpenAPI 文档: %s", route)
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"gost-panel/internal/config"
 	"gost-panel/internal/handler"
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/middleware"
 	"gost-panel/internal/repository"
 	"gost-panel/internal/service"
@@ -14,15 +15,17 @@ import (
 
 // Router 路由配置
 type Router struct {
-	db     *gorm.DB
-	jwtCfg *jwt.Config
+	db         *gorm.DB
+	jwtCfg     *jwt.Config
+	background *service.BackgroundManager
 }
 
 // NewRouter 创建路由实例
-func NewRouter(db *gorm.DB, jwtCfg *jwt.Config) *Router {
+func NewRouter(db *gorm.DB, jwtCfg *jwt.Config, background *service.BackgroundManager) *Router {
 	return &Router{
-		db:     db,
-		jwtCfg: jwtCfg,
+		db:         db,
+		jwtCfg:     jwtCfg,
+		background: background,
 	}
 }
 
@@ -36,12 +39,24 @@ func (r *Router) Setup(engine *gin.Engine) {
 	engine.Use(middleware.Logger())
 	engine.Use(middleware.Recovery())
 	engine.Use(middleware.ErrorHandler())
+	engine.Use(middleware.Metrics())
 
 	// 健康检查
 	engine.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Prometheus 指标
+	if metricsCfg := config.Get().Metrics; metricsCfg.Enabled {
+		metrics.RegisterCollector(service.NewResourceCollector(r.db))
+		engine.GET("/metrics", handler.NewMetricsHandler(metricsCfg.Token).Metrics)
+	}
+
+	// 接口文档
+	docsHandler := handler.NewDocsHandler(OpenAPISpec())
+	engine.GET("/api/openapi.json", docsHandler.Spec)
+	engine.GET("/api/docs", docsHandler.UI)
+
 	// API v1 路由组
 	apiV1 := engine.Group("/api/v1")
 
@@ -58,24 +73,33 @@ func (r *Router) Setup(engine *gin.Engine) {
 	authService := service.NewAuthService(r.db, r.jwtCfg)
 	nodeService := service.NewNodeService(r.db)
 	ruleService := service.NewRuleService(r.db)
+	ipListService := service.NewIPListService(r.db)
 	tunnelService := service.NewTunnelService(r.db)
 	statsService := service.NewStatsService(r.db)
+	latencyService := service.NewLatencyService(r.db)
 	logService := service.NewLogService(r.db)
 	observerService := service.NewObserverService(r.db)
+	inventoryService := service.NewInventoryService(r.db)
+	apiTokenService := service.NewAPITokenService(r.db)
 
 	// 初始化系统配置
 	systemConfigRepo := repository.NewSystemConfigRepository(r.db)
 	systemConfigService := service.NewSystemConfigService(systemConfigRepo)
 	backupService := service.NewBackupService(r.db)
+	backupService.SetBackgroundManager(r.background)
 
 	// 初始化控制器
 	authHandler := handler.NewAuthHandler(authService)
 	nodeHandler := handler.NewNodeHandler(nodeService)
 	ruleHandler := handler.NewRuleHandler(ruleService)
+	ipListHandler := handler.NewIPListHandler(ipListService)
 	tunnelHandler := handler.NewTunnelHandler(tunnelService)
 	statsHandler := handler.NewStatsHandler(statsService)
+	latencyHandler := handler.NewLatencyHandler(latencyService)
 	logHandler := handler.NewLogHandler(logService)
 	observerHandler := handler.NewObserverHandler(observerService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
 	systemConfigHandler := handler.NewSystemConfigHandler(systemConfigService, backupService)
 
 	// 公开路由（无需认证）
@@ -89,16 +113,24 @@ func (r *Router) Setup(engine *gin.Engine) {
 
 	// 需要认证的路由
 	authRoutes := apiV1.Group("")
-	authRoutes.Use(middleware.Auth(jwtInstance))
+	authRoutes.Use(middleware.Auth(jwtInstance, apiTokenService))
 	{
 		// 认证相关
 		authRoutes.GET("/auth/info", authHandler.GetUserInfo)
 		authRoutes.PUT("/auth/password", authHandler.ChangePassword)
 		authRoutes.POST("/auth/refresh", authHandler.RefreshToken)
 
+		// 个人 API Token
+		authRoutes.GET("/auth/tokens", apiTokenHandler.List)
+		authRoutes.POST("/auth/tokens", apiTokenHandler.Create)
+		authRoutes.DELETE("/auth/tokens/:id", apiTokenHandler.Delete)
+
 		// 仪表盘统计
 		authRoutes.GET("/dashboard/stats", statsHandler.GetDashboard)
 
+		// 延迟探测统计
+		authRoutes.GET("/latency", latencyHandler.Stats)
+
 		// 节点管理
 		authRoutes.GET("/nodes", nodeHandler.List)
 		authRoutes.GET("/nodes/:id", nodeHandler.GetByID)
@@ -106,15 +138,29 @@ func (r *Router) Setup(engine *gin.Engine) {
 		authRoutes.PUT("/nodes/:id", nodeHandler.Update)
 		authRoutes.DELETE("/nodes/:id", nodeHandler.Delete)
 		authRoutes.GET("/nodes/:id/config", nodeHandler.GetConfig)
+		authRoutes.GET("/nodes/:id/ports", nodeHandler.Ports)
+		authRoutes.PUT("/nodes/:id/maintenance", nodeHandler.SetMaintenance)
+		authRoutes.POST("/nodes/:id/drain", nodeHandler.Drain)
 
 		// 规则管理
 		authRoutes.GET("/rules", ruleHandler.List)
 		authRoutes.GET("/rules/:id", ruleHandler.GetByID)
+		authRoutes.GET("/rules/:id/targets", ruleHandler.Targets)
 		authRoutes.POST("/rules", ruleHandler.Create)
 		authRoutes.PUT("/rules/:id", ruleHandler.Update)
 		authRoutes.DELETE("/rules/:id", ruleHandler.Delete)
 		authRoutes.POST("/rules/:id/start", ruleHandler.Start)
 		authRoutes.POST("/rules/:id/stop", ruleHandler.Stop)
+		authRoutes.POST("/rules/:id/clone", ruleHandler.Clone)
+		authRoutes.POST("/rules/:id/migrate", ruleHandler.Migrate)
+		authRoutes.POST("/rules/batch", ruleHandler.Batch)
+
+		// 命名 IP 列表
+		authRoutes.GET("/ip-lists", ipListHandler.List)
+		authRoutes.GET("/ip-lists/:id", ipListHandler.GetByID)
+		authRoutes.POST("/ip-lists", ipListHandler.Create)
+		authRoutes.PUT("/ip-lists/:id", ipListHandler.Update)
+		authRoutes.DELETE("/ip-lists/:id", ipListHandler.Delete)
 
 		// 隧道管理
 		authRoutes.GET("/tunnels", tunnelHandler.List)
@@ -124,17 +170,31 @@ func (r *Router) Setup(engine *gin.Engine) {
 		authRoutes.DELETE("/tunnels/:id", tunnelHandler.Delete)
 		authRoutes.POST("/tunnels/:id/start", tunnelHandler.Start)
 		authRoutes.POST("/tunnels/:id/stop", tunnelHandler.Stop)
+		authRoutes.POST("/tunnels/:id/rotate-credentials", tunnelHandler.RotateCredentials)
+		authRoutes.POST("/tunnels/batch", tunnelHandler.Batch)
 
 		// 操作日志
 		authRoutes.GET("/logs", logHandler.List)
 
+		// 资源清单导入导出
+		authRoutes.POST("/inventory/export", inventoryHandler.Export)
+		authRoutes.POST("/inventory/plan", inventoryHandler.Plan)
+		authRoutes.POST("/inventory/apply", inventoryHandler.Apply)
+
 		// 系统设置
 		authRoutes.GET("/system/config", systemConfigHandler.GetConfig)
 		authRoutes.PUT("/system/config", systemConfigHandler.UpdateConfig)
 		authRoutes.POST("/system/email/test", systemConfigHandler.TestEmail)
 		authRoutes.POST("/system/backup", systemConfigHandler.Backup)
+		authRoutes.POST("/system/backup/s3/test", systemConfigHandler.TestS3)
+		authRoutes.GET("/system/backups", systemConfigHandler.ListBackups)
+		authRoutes.POST("/system/backups/upload", systemConfigHandler.UploadBackup)
+		authRoutes.GET("/system/backups/:name/download", systemConfigHandler.DownloadBackup)
+		authRoutes.POST("/system/backups/:name/restore", systemConfigHandler.RestoreBackup)
 	}
 
 	// 静态文件
 	r.setupStatic(engine)
+
+	checkDocumented(engine)
 }
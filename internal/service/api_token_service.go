@@ -0,0 +1,189 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiTokenBytes Token 随机部分字节数
+	apiTokenBytes = 32
+	// apiTokenDisplayLen 保存用于识别的明文长度（含前缀）
+	apiTokenDisplayLen = 12
+	// apiTokenTouchInterval 最后使用时间的更新间隔，避免每个请求都写库
+	apiTokenTouchInterval = time.Minute
+)
+
+// APITokenService API Token 服务
+type APITokenService struct {
+	tokenRepo  *repository.APITokenRepository
+	logService *LogService
+}
+
+// NewAPITokenService 创建 API Token 服务
+func NewAPITokenService(db *gorm.DB) *APITokenService {
+	return &APITokenService{
+		tokenRepo:  repository.NewAPITokenRepository(db),
+		logService: NewLogService(db),
+	}
+}
+
+// List 获取用户的 Token 列表
+func (s *APITokenService) List(userID uint) ([]model.APIToken, error) {
+	return s.tokenRepo.ListByUserID(userID)
+}
+
+// Create 创建 Token，返回 Token 信息和明文，明文只在此时返回一次
+func (s *APITokenService) Create(req *dto.CreateAPITokenReq, userID uint, username, ip, userAgent string) (*model.APIToken, string, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	raw, err := generateAPIToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &model.APIToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    raw[:apiTokenDisplayLen],
+		TokenHash: hashAPIToken(raw),
+		Scopes:    scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err = s.tokenRepo.Create(token); err != nil {
+		return nil, "", err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionCreate,
+		model.ResourceTypeAPIToken,
+		token.ID,
+		fmt.Sprintf("创建 API Token: %s (%s)", token.Name, strings.Join(scopes, ",")),
+		ip,
+		userAgent)
+
+	return token, raw, nil
+}
+
+// Delete 吊销 Token，只能吊销自己的 Token
+func (s *APITokenService) Delete(id, userID uint, username, ip, userAgent string) error {
+	token, err := s.tokenRepo.FindByID(id)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrAPITokenNotFound
+		}
+		return err
+	}
+	if token.UserID != userID {
+		return errors.ErrAPITokenNotFound
+	}
+
+	if err = s.tokenRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionDelete,
+		model.ResourceTypeAPIToken,
+		id,
+		fmt.Sprintf("吊销 API Token: %s", token.Name),
+		ip,
+		userAgent)
+
+	return nil
+}
+
+// Authenticate 校验 Token 明文，成功时记录最后使用时间和 IP
+func (s *APITokenService) Authenticate(raw, ip string) (*model.APIToken, error) {
+	token, err := s.tokenRepo.FindByHash(hashAPIToken(raw))
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrTokenInvalid
+		}
+		return nil, err
+	}
+	if token.User == nil {
+		return nil, errors.ErrTokenInvalid
+	}
+	if token.IsExpired() {
+		return nil, errors.ErrAPITokenExpired
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != ip {
+		if err = s.tokenRepo.UpdateLastUsed(token.ID, now, ip); err != nil {
+			logger.Warnf("更新 API Token 使用记录失败: %v", err)
+		}
+	}
+
+	return token, nil
+}
+
+// generateAPIToken 生成 Token 明文
+func generateAPIToken() (string, error) {
+	buf := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return model.APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashAPIToken 计算 Token 哈希
+// Token 本身是高强度随机数，使用 SHA-256 即可，且便于按哈希查询
+func hashAPIToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeScopes 校验并去重权限范围，未指定或包含 * 时为全部权限
+func normalizeScopes(scopes []string) (model.StringList, error) {
+	valid := make(map[string]bool, len(model.APITokenScopes))
+	for _, s := range model.APITokenScopes {
+		valid[s] = true
+	}
+
+	var result model.StringList
+	seen := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		s = strings.TrimSpace(s)
+		if !valid[s] {
+			return nil, errors.ErrAPITokenScopeInvalid
+		}
+		if s == model.ScopeAll {
+			return model.StringList{model.ScopeAll}, nil
+		}
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		return model.StringList{model.ScopeAll}, nil
+	}
+	return result, nil
+}
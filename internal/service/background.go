@@ -0,0 +1,74 @@
+package service
+
+import (
+	"sync"
+
+	"gost-panel/pkg/logger"
+)
+
+// BackgroundService 可启停的后台服务
+type BackgroundService interface {
+	Start()
+	Stop()
+}
+
+// BackgroundManager 后台服务管理器
+// 统一管理健康检测、状态同步、自动备份等后台任务的启停，
+// 供数据库恢复等需要暂停后台任务的操作使用
+type BackgroundManager struct {
+	mu       sync.Mutex
+	services []BackgroundService
+	running  bool
+}
+
+// NewBackgroundManager 创建后台服务管理器
+func NewBackgroundManager(services ...BackgroundService) *BackgroundManager {
+	return &BackgroundManager{services: services}
+}
+
+// StartAll 启动所有后台服务
+func (m *BackgroundManager) StartAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return
+	}
+	for _, svc := range m.services {
+		svc.Start()
+	}
+	m.running = true
+}
+
+// StopAll 停止所有后台服务（按注册的逆序）
+func (m *BackgroundManager) StopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return
+	}
+	for i := len(m.services) - 1; i >= 0; i-- {
+		m.services[i].Stop()
+	}
+	m.running = false
+}
+
+// Pause 暂停所有后台服务，返回恢复函数
+// 若服务原本未运行，恢复函数不做任何事
+func (m *BackgroundManager) Pause() (resume func()) {
+	m.mu.Lock()
+	wasRunning := m.running
+	m.mu.Unlock()
+
+	if !wasRunning {
+		return func() {}
+	}
+
+	logger.Info("暂停后台服务")
+	m.StopAll()
+	return func() {
+		logger.Info("恢复后台服务")
+		m.StartAll()
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gost-panel/internal/config"
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/migrate"
+	"gost-panel/pkg/logger"
+	"gost-panel/pkg/secret"
+)
+
+// 备份归档格式：
+//   - 未加密: gzip(tar{manifest.json, gost_panel.db})
+//   - 已加密: encryptedArchiveMagic | secret.NewEncryptWriter(gzip(tar{...}))
+//
+// 读写均为流式处理，内存占用与数据库大小无关
+const (
+	// backupArchiveExt 归档备份扩展名
+	backupArchiveExt = ".gpbak"
+	// archiveFormat 归档格式标识
+	archiveFormat = "gost-panel-backup"
+	// archiveFormatVersion 归档格式版本
+	archiveFormatVersion = 1
+	// archiveManifestName 归档内清单文件名
+	archiveManifestName = "manifest.json"
+	// archiveDatabaseName 归档内数据库文件名
+	archiveDatabaseName = "gost_panel.db"
+
+	// 备份文件格式
+	backupFormatDB      = "db"
+	backupFormatArchive = "archive"
+)
+
+var (
+	// encryptedArchiveMagic 加密归档文件头
+	encryptedArchiveMagic = []byte("GPBAKENC2\n")
+	// gzipMagic gzip 文件头
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// detectBackupFormat 根据文件头识别备份格式
+func detectBackupFormat(path string) (format string, encrypted bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	header := make([]byte, len(sqliteHeader))
+	n, _ := io.ReadFull(f, header)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, encryptedArchiveMagic):
+		return backupFormatArchive, true, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		return backupFormatArchive, false, nil
+	case bytes.Equal(header, sqliteHeader):
+		return backupFormatDB, false, nil
+	}
+	return "", false, errors.ErrBackupInvalid
+}
+
+// writeBackupArchive 将数据库文件打包为压缩归档，passphrase 非空时加密
+func writeBackupArchive(dbPath, archivePath, passphrase string) error {
+	db, err := os.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	// 计算校验和
+	h := sha256.New()
+	size, err := io.Copy(h, db)
+	if err != nil {
+		return err
+	}
+	if _, err = db.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	manifest := &dto.BackupManifest{
+		Format:        archiveFormat,
+		FormatVersion: archiveFormatVersion,
+		PanelVersion:  config.Version,
+		SchemaVersion: migrate.Latest(),
+		CreatedAt:     time.Now(),
+		Compression:   "gzip",
+		Encrypted:     passphrase != "",
+		Database:      archiveDatabaseName,
+		Size:          size,
+		SHA256:        hex.EncodeToString(h.Sum(nil)),
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免生成不完整的备份
+	tmpPath := archivePath + ".tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(out)
+	err = writeArchiveStream(bw, manifestData, db, size, passphrase)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, archivePath)
+}
+
+// writeArchiveStream 将清单和数据库写入 tar，经 gzip 压缩（passphrase 非空时再分块加密）后输出到 w
+func writeArchiveStream(w io.Writer, manifest []byte, db io.Reader, size int64, passphrase string) error {
+	var enc io.WriteCloser
+	if passphrase != "" {
+		if _, err := w.Write(encryptedArchiveMagic); err != nil {
+			return err
+		}
+		var err error
+		if enc, err = secret.NewEncryptWriter(w, passphrase); err != nil {
+			return err
+		}
+		w = enc
+	}
+
+	// 清单在前，恢复时可先检查版本再解出数据库
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeTarEntry(tw, archiveManifestName, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		return err
+	}
+	if err := writeTarEntry(tw, archiveDatabaseName, size, db); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if enc != nil {
+		return enc.Close()
+	}
+	return nil
+}
+
+// writeTarEntry 写入一个 tar 文件条目
+func writeTarEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, r)
+	return err
+}
+
+// extractBackupArchive 解密并解压归档，校验清单后将数据库写入 destPath
+func extractBackupArchive(archivePath, passphrase, destPath string) (*dto.BackupManifest, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, errors.ErrBackupNotFound
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if header, _ := br.Peek(len(encryptedArchiveMagic)); bytes.Equal(header, encryptedArchiveMagic) {
+		if passphrase == "" {
+			return nil, errors.ErrBackupPassphraseRequired
+		}
+		_, _ = br.Discard(len(encryptedArchiveMagic))
+		if r, err = secret.NewDecryptReader(br, passphrase); err != nil {
+			return nil, errors.ErrBackupDecryptFailed
+		}
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, archiveReadError(err)
+	}
+	defer func() {
+		_ = gz.Close()
+	}()
+
+	var manifest *dto.BackupManifest
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, archiveReadError(err)
+		}
+
+		switch filepath.Base(hdr.Name) {
+		case archiveManifestName:
+			manifest = &dto.BackupManifest{}
+			if err = json.NewDecoder(tr).Decode(manifest); err != nil || manifest.Format != archiveFormat {
+				return nil, errors.ErrBackupInvalid
+			}
+			if manifest.FormatVersion > archiveFormatVersion || manifest.SchemaVersion > migrate.Latest() {
+				logger.Warnf("备份版本过高: 格式 %d, 数据库 %d (面板 %s)",
+					manifest.FormatVersion, manifest.SchemaVersion, manifest.PanelVersion)
+				return nil, errors.ErrBackupSchemaTooNew
+			}
+		case archiveDatabaseName:
+			if manifest == nil {
+				return nil, errors.ErrBackupInvalid
+			}
+			sum, size, err := writeAndHash(tr, destPath)
+			if err != nil {
+				if stderrors.Is(err, secret.ErrDecryptFailed) {
+					return nil, errors.ErrBackupDecryptFailed
+				}
+				return nil, err
+			}
+			if size != manifest.Size || sum != manifest.SHA256 {
+				return nil, errors.ErrBackupChecksumMismatch
+			}
+			return manifest, nil
+		}
+	}
+	return nil, errors.ErrBackupInvalid
+}
+
+// archiveReadError 转换读取归档时的错误，分块解密失败说明文件被篡改或截断
+func archiveReadError(err error) error {
+	if stderrors.Is(err, secret.ErrDecryptFailed) {
+		return errors.ErrBackupDecryptFailed
+	}
+	return errors.ErrBackupInvalid
+}
+
+// writeAndHash 写入文件并计算 SHA256
+func writeAndHash(r io.Reader, path string) (string, int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, h), r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
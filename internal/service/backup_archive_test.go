@@ -0,0 +1,184 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/migrate"
+)
+
+// testDatabase 以 SQLite 文件头开头的测试数据
+var testDatabase = append(bytes.Clone(sqliteHeader), []byte("gost-panel backup payload")...)
+
+// writeTestFile 在临时目录写入文件并返回路径
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// buildArchive 按给定清单打包归档，用于构造损坏或版本过高的备份
+func buildArchive(t *testing.T, manifest *dto.BackupManifest, db []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if manifest != nil {
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = writeTarEntry(tw, archiveManifestName, int64(len(data)), bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeTarEntry(tw, archiveDatabaseName, int64(len(db)), bytes.NewReader(db)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// validManifest 与 testDatabase 匹配的清单
+func validManifest() *dto.BackupManifest {
+	sum := sha256.Sum256(testDatabase)
+	return &dto.BackupManifest{
+		Format:        archiveFormat,
+		FormatVersion: archiveFormatVersion,
+		SchemaVersion: migrate.Latest(),
+		Database:      archiveDatabaseName,
+		Size:          int64(len(testDatabase)),
+		SHA256:        hex.EncodeToString(sum[:]),
+	}
+}
+
+func TestBackupArchiveRoundTrip(t *testing.T) {
+	dbPath := writeTestFile(t, "src.db", testDatabase)
+
+	for _, passphrase := range []string{"", "pass"} {
+		archivePath := filepath.Join(t.TempDir(), "backup"+backupArchiveExt)
+		if err := writeBackupArchive(dbPath, archivePath, passphrase); err != nil {
+			t.Fatalf("writeBackupArchive 失败: %v", err)
+		}
+
+		format, encrypted, err := detectBackupFormat(archivePath)
+		if err != nil || format != backupFormatArchive || encrypted != (passphrase != "") {
+			t.Errorf("detectBackupFormat = %s, %v, %v", format, encrypted, err)
+		}
+
+		destPath := filepath.Join(t.TempDir(), "restored.db")
+		manifest, err := extractBackupArchive(archivePath, passphrase, destPath)
+		if err != nil {
+			t.Fatalf("extractBackupArchive 失败: %v", err)
+		}
+		want := validManifest()
+		if manifest.Format != want.Format || manifest.SchemaVersion != want.SchemaVersion ||
+			manifest.Size != want.Size || manifest.SHA256 != want.SHA256 || manifest.Encrypted != (passphrase != "") {
+			t.Errorf("清单 = %+v，期望 %+v", manifest, want)
+		}
+		got, err := os.ReadFile(destPath)
+		if err != nil || !bytes.Equal(got, testDatabase) {
+			t.Errorf("解出的数据库与原文件不一致: %v", err)
+		}
+	}
+}
+
+func TestBackupArchiveEncryptedPassphrase(t *testing.T) {
+	// 数据库跨越多个加密块
+	large := make([]byte, 300*1024)
+	rand.NewChaCha8([32]byte{1}).Read(large)
+	copy(large, sqliteHeader)
+	dbPath := writeTestFile(t, "src.db", large)
+	archivePath := filepath.Join(t.TempDir(), "backup"+backupArchiveExt)
+	if err := writeBackupArchive(dbPath, archivePath, "pass"); err != nil {
+		t.Fatal(err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "restored.db")
+	if _, err := extractBackupArchive(archivePath, "pass", destPath); err != nil {
+		t.Fatalf("extractBackupArchive 失败: %v", err)
+	}
+	if got, err := os.ReadFile(destPath); err != nil || !bytes.Equal(got, large) {
+		t.Errorf("解出的数据库与原文件不一致: %v", err)
+	}
+
+	// 截断的加密归档
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := writeTestFile(t, "truncated"+backupArchiveExt, data[:len(data)-100])
+	if _, err = extractBackupArchive(truncated, "pass", filepath.Join(t.TempDir(), "out.db")); !stderrors.Is(err, errors.ErrBackupDecryptFailed) {
+		t.Errorf("截断: err = %v，期望 ErrBackupDecryptFailed", err)
+	}
+
+	cases := map[string]error{
+		"":      errors.ErrBackupPassphraseRequired,
+		"wrong": errors.ErrBackupDecryptFailed,
+	}
+	for passphrase, want := range cases {
+		_, err := extractBackupArchive(archivePath, passphrase, filepath.Join(t.TempDir(), "out.db"))
+		if !stderrors.Is(err, want) {
+			t.Errorf("口令 %q: err = %v，期望 %v", passphrase, err, want)
+		}
+	}
+}
+
+func TestBackupArchiveValidation(t *testing.T) {
+	tooNew := validManifest()
+	tooNew.SchemaVersion = migrate.Latest() + 1
+	newerFormat := validManifest()
+	newerFormat.FormatVersion = archiveFormatVersion + 1
+	badSum := validManifest()
+	badSum.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	badSize := validManifest()
+	badSize.Size++
+	badFormat := validManifest()
+	badFormat.Format = "other"
+
+	cases := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"数据库版本过高", buildArchive(t, tooNew, testDatabase), errors.ErrBackupSchemaTooNew},
+		{"格式版本过高", buildArchive(t, newerFormat, testDatabase), errors.ErrBackupSchemaTooNew},
+		{"校验和不一致", buildArchive(t, badSum, testDatabase), errors.ErrBackupChecksumMismatch},
+		{"大小不一致", buildArchive(t, badSize, testDatabase), errors.ErrBackupChecksumMismatch},
+		{"格式标识错误", buildArchive(t, badFormat, testDatabase), errors.ErrBackupInvalid},
+		{"缺少清单", buildArchive(t, nil, testDatabase), errors.ErrBackupInvalid},
+		{"不是 gzip", []byte("not a backup"), errors.ErrBackupInvalid},
+	}
+	for _, c := range cases {
+		path := writeTestFile(t, "backup"+backupArchiveExt, c.data)
+		_, err := extractBackupArchive(path, "", filepath.Join(t.TempDir(), "out.db"))
+		if !stderrors.Is(err, c.want) {
+			t.Errorf("%s: err = %v，期望 %v", c.name, err, c.want)
+		}
+	}
+
+	// 无法识别的文件头
+	path := writeTestFile(t, "backup.db", []byte("plain text"))
+	if _, _, err := detectBackupFormat(path); !stderrors.Is(err, errors.ErrBackupInvalid) {
+		t.Errorf("detectBackupFormat: err = %v，期望 ErrBackupInvalid", err)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/s3"
+)
+
+// BackupDestination 异地备份存储目标
+// 本地备份目录始终保留，备份生成后再上传到各个启用的目标
+type BackupDestination interface {
+	// Name 目标名称，用于日志
+	Name() string
+	// Upload 上传本地备份文件
+	Upload(name, localPath string) error
+	// List 列出目标中的备份文件名
+	List() ([]string, error)
+	// Delete 删除目标中的备份文件
+	Delete(name string) error
+	// Test 测试目标是否可写
+	Test() error
+}
+
+// newBackupDestinations 根据系统配置创建启用的异地备份目标
+func newBackupDestinations(cfg *model.SystemConfig) ([]BackupDestination, error) {
+	var dests []BackupDestination
+	if cfg.BackupS3Enabled {
+		dest, err := NewS3BackupDestination(&dto.S3ConfigReq{
+			Endpoint:  cfg.BackupS3Endpoint,
+			Region:    cfg.BackupS3Region,
+			Bucket:    cfg.BackupS3Bucket,
+			AccessKey: cfg.BackupS3AccessKey,
+			SecretKey: cfg.BackupS3SecretKey,
+			Prefix:    cfg.BackupS3Prefix,
+			PathStyle: cfg.BackupS3PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, dest)
+	}
+	return dests, nil
+}
+
+// S3BackupDestination S3 兼容对象存储备份目标
+type S3BackupDestination struct {
+	client *s3.Client
+	prefix string
+}
+
+// NewS3BackupDestination 创建 S3 备份目标
+func NewS3BackupDestination(cfg *dto.S3ConfigReq) (*S3BackupDestination, error) {
+	client, err := s3.NewClient(&s3.Config{
+		Endpoint:  cfg.Endpoint,
+		Region:    cfg.Region,
+		Bucket:    cfg.Bucket,
+		AccessKey: cfg.AccessKey,
+		SecretKey: cfg.SecretKey,
+		PathStyle: cfg.PathStyle,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 前缀统一为 "dir/" 形式
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3BackupDestination{client: client, prefix: prefix}, nil
+}
+
+// Name 目标名称
+func (d *S3BackupDestination) Name() string {
+	return "s3"
+}
+
+// Upload 上传备份文件
+func (d *S3BackupDestination) Upload(name, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return d.client.PutObject(d.prefix+name, f, info.Size())
+}
+
+// List 列出备份文件名（不含前缀）
+func (d *S3BackupDestination) List() ([]string, error) {
+	objects, err := d.client.ListObjects(d.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		name := strings.TrimPrefix(obj.Key, d.prefix)
+		// 只处理前缀下第一层的备份文件
+		if name == path.Base(name) && isBackupFile(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Delete 删除备份文件
+func (d *S3BackupDestination) Delete(name string) error {
+	return d.client.DeleteObject(d.prefix + name)
+}
+
+// Test 写入并删除一个探测对象
+func (d *S3BackupDestination) Test() error {
+	key := fmt.Sprintf("%s.gost-panel-test-%d", d.prefix, time.Now().UnixNano())
+	data := []byte("gost-panel")
+	if err := d.client.PutObject(key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return err
+	}
+	return d.client.DeleteObject(key)
+}
@@ -1,28 +1,66 @@
 package service
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"gost-panel/internal/config"
+	"gost-panel/internal/database"
+	"gost-panel/internal/dto"
 	"gost-panel/internal/errors"
+	"gost-panel/internal/migrate"
+	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
+	"gost-panel/pkg/cron"
 	"gost-panel/pkg/logger"
 
 	"gorm.io/gorm"
 )
 
+const (
+	// backupDir 备份目录
+	backupDir = "backups"
+	// backupPrefix 备份文件名前缀
+	backupPrefix = "gost_panel_"
+	// backupExt 备份文件扩展名
+	backupExt = ".db"
+	// defaultBackupSchedule 未配置计划时的默认自动备份时间（每天 0 点）
+	defaultBackupSchedule = "0 0 * * *"
+)
+
+// 备份触发来源，作为文件名后缀标记备份的生成原因
+const (
+	BackupTriggerManual     = "manual"      // 手动备份
+	BackupTriggerAuto       = "auto"        // 定时备份
+	BackupTriggerUpload     = "upload"      // 上传的备份
+	BackupTriggerPreRestore = "pre_restore" // 恢复前快照
+	BackupTriggerPreImport  = "pre_import"  // 导入清单前快照
+	BackupTriggerPreDelete  = "pre_delete"  // 批量删除前快照
+)
+
+// requiredBackupTables 有效备份中必须存在的数据表
+var requiredBackupTables = []string{"users", "nodes", "rules", "tunnels", "operation_logs", "system_configs"}
+
+// sqliteHeader SQLite 数据库文件头
+var sqliteHeader = []byte("SQLite format 3\x00")
+
 // BackupService 备份服务
 type BackupService struct {
-	db      *gorm.DB
-	sysRepo *repository.SystemConfigRepository
+	db         *gorm.DB
+	sysRepo    *repository.SystemConfigRepository
+	background *BackgroundManager
 
-	stopChan chan struct{}
+	restoreMu     sync.Mutex
+	stopChan      chan struct{}
+	wg            sync.WaitGroup // 调度循环和进行中的上传、清理任务
+	lastScheduled time.Time      // 上一次定时备份对应的计划时间（分钟）
 }
 
 // NewBackupService 创建备份服务
@@ -34,20 +72,32 @@ func NewBackupService(db *gorm.DB) *BackupService {
 	}
 }
 
-// Start 启动自动备份任务 (按小时检查)
+// SetBackgroundManager 设置后台服务管理器（恢复备份时用于暂停后台任务）
+func (s *BackupService) SetBackgroundManager(m *BackgroundManager) {
+	s.background = m
+}
+
+// Start 启动自动备份任务 (按 cron 计划执行)
 func (s *BackupService) Start() {
+	s.stopChan = make(chan struct{})
+	stopChan := s.stopChan
+	s.wg.Add(1)
+
 	go func() {
-		// 启动时先检查一次
-		s.processAutoBackup()
+		defer s.wg.Done()
+
+		// 启动时补做当天遗漏的默认备份
+		s.catchUpDailyBackup()
 
-		ticker := time.NewTicker(1 * time.Hour)
+		// 每 30 秒检查一次，保证每个计划分钟都能被覆盖
+		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
 
 		for {
 			select {
-			case <-ticker.C:
-				s.processAutoBackup()
-			case <-s.stopChan:
+			case now := <-ticker.C:
+				s.processAutoBackup(now)
+			case <-stopChan:
 				return
 			}
 		}
@@ -55,14 +105,15 @@ func (s *BackupService) Start() {
 	logger.Info("自动备份服务已启动")
 }
 
-// Stop 停止自动备份任务
+// Stop 停止自动备份任务，等待进行中的备份及其上传、清理完成后返回
 func (s *BackupService) Stop() {
 	close(s.stopChan)
+	s.wg.Wait()
 	logger.Info("自动备份服务已停止")
 }
 
 // processAutoBackup 执行自动备份逻辑
-func (s *BackupService) processAutoBackup() {
+func (s *BackupService) processAutoBackup(now time.Time) {
 	cfg, err := s.sysRepo.Get()
 	if err != nil {
 		logger.Errorf("自动备份检查失败: 获取系统配置错误: %v", err)
@@ -73,25 +124,44 @@ func (s *BackupService) processAutoBackup() {
 		return
 	}
 
-	backupDir := "backups"
-	// 确保目录存在
-	if err = os.MkdirAll(backupDir, 0755); err != nil {
-		logger.Errorf("自动备份失败: 无法创建目录: %v", err)
+	schedule, err := cron.Parse(backupSchedule(cfg))
+	if err != nil {
+		logger.Errorf("自动备份计划无效: %v", err)
 		return
 	}
 
-	// 检查今天是否已经备份过
-	// 简单策略: 每天生成一个文件名含日期的文件
-	// 如果存在今天的文件，则跳过
-	today := time.Now().Format("20060102")
-	prefix := fmt.Sprintf("gost_panel_%s", today)
+	// 同一计划分钟只执行一次
+	minute := now.Truncate(time.Minute)
+	if !schedule.Matches(minute) || !minute.After(s.lastScheduled) {
+		return
+	}
+	s.lastScheduled = minute
 
-	files, err := os.ReadDir(backupDir)
+	logger.Infof("开始执行自动备份...")
+	if _, err = s.backup(BackupTriggerAuto); err != nil {
+		logger.Errorf("自动备份执行失败: %v", err)
+	}
+}
+
+// catchUpDailyBackup 未配置计划时保持每天一份的行为：当天没有备份则立即补做
+func (s *BackupService) catchUpDailyBackup() {
+	cfg, err := s.sysRepo.Get()
 	if err != nil {
-		logger.Errorf("自动备份失败: 读取目录错误: %v", err)
+		logger.Errorf("自动备份检查失败: 获取系统配置错误: %v", err)
+		return
+	}
+
+	if !cfg.AutoBackup || cfg.BackupSchedule != "" {
 		return
 	}
 
+	// 检查今天是否已经备份过
+	prefix := fmt.Sprintf("%s%s", backupPrefix, time.Now().Format("20060102"))
+	files, err := os.ReadDir(backupDir)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Errorf("自动备份失败: 读取目录错误: %v", err)
+		return
+	}
 	for _, file := range files {
 		if !file.IsDir() && strings.HasPrefix(file.Name(), prefix) {
 			// 今天已备份
@@ -99,49 +169,321 @@ func (s *BackupService) processAutoBackup() {
 		}
 	}
 
-	// 执行备份
 	logger.Infof("开始执行自动备份...")
-	if err := s.CreateBackup(); err != nil {
+	if _, err = s.backup(BackupTriggerAuto); err != nil {
 		logger.Errorf("自动备份执行失败: %v", err)
 	}
 }
 
+// backupSchedule 获取自动备份计划表达式
+func backupSchedule(cfg *model.SystemConfig) string {
+	if cfg.BackupSchedule == "" {
+		return defaultBackupSchedule
+	}
+	return cfg.BackupSchedule
+}
+
+// nextBackupAt 计算下次自动备份时间，未开启自动备份时返回 nil
+func nextBackupAt(cfg *model.SystemConfig) *time.Time {
+	if !cfg.AutoBackup {
+		return nil
+	}
+	schedule, err := cron.Parse(backupSchedule(cfg))
+	if err != nil {
+		return nil
+	}
+	next := schedule.Next(time.Now())
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
 // CreateBackup 创建备份
 func (s *BackupService) CreateBackup() error {
-	// 获取数据库路径
+	_, err := s.backup(BackupTriggerManual)
+	return err
+}
+
+// Snapshot 在高风险操作（批量删除、导入、恢复）前创建快照，返回备份文件名
+func (s *BackupService) Snapshot(trigger string) (string, error) {
+	name, err := s.backup(trigger)
+	if err != nil {
+		logger.Errorf("创建变更前快照失败 (%s): %v", trigger, err)
+		return "", err
+	}
+	logger.Infof("已创建变更前快照: %s", name)
+	return name, nil
+}
+
+// backup 创建备份文件，并在后台上传异地目标、按保留数量清理旧备份
+func (s *BackupService) backup(trigger string) (string, error) {
+	filename, err := s.createBackupFile(trigger)
+	if err != nil {
+		return "", err
+	}
+	s.goAfterBackup(filename)
+	return filename, nil
+}
+
+// createBackupFile 创建备份文件，返回文件名，不上传也不清理旧备份
+// trigger 附加在时间戳之后，标记备份的生成原因
+func (s *BackupService) createBackupFile(trigger string) (string, error) {
+	// SQLite 需要数据库路径作为 VACUUM INTO 失败时的复制源
 	dbPath := config.Get().Database.Path
-	if dbPath == "" {
-		return errors.ErrDBPathNotConfigured
+	if s.isSQLite() && dbPath == "" {
+		return "", errors.ErrDBPathNotConfigured
 	}
 
 	// 确保备份目录存在
-	backupDir := "backups"
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return errors.ErrBackupDirCreateFailed
+		return "", errors.ErrBackupDirCreateFailed
+	}
+
+	cfg, err := s.sysRepo.Get()
+	if err != nil {
+		return "", err
+	}
+
+	// 未开启压缩和加密时直接保存数据库文件
+	if !cfg.BackupCompress && cfg.BackupPassphrase == "" {
+		filename := newBackupName(trigger, backupExt)
+		targetPath := filepath.Join(backupDir, filename)
+		if err = s.snapshotDatabase(dbPath, targetPath); err != nil {
+			return "", errors.ErrBackupFailed
+		}
+		logger.Infof("数据库备份成功: %s", targetPath)
+		return filename, nil
 	}
 
-	// 生成备份文件名
-	filename := fmt.Sprintf("gost_panel_%s.db", time.Now().Format("20060102_150405"))
+	// 先生成临时快照，再打包为归档
+	filename := newBackupName(trigger, backupArchiveExt)
 	targetPath := filepath.Join(backupDir, filename)
+	tmpPath := filepath.Join(backupDir, fmt.Sprintf(".snapshot-%d.db", time.Now().UnixNano()))
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	if err = s.snapshotDatabase(dbPath, tmpPath); err != nil {
+		return "", errors.ErrBackupFailed
+	}
+	if err = writeBackupArchive(tmpPath, targetPath, cfg.BackupPassphrase); err != nil {
+		logger.Errorf("生成备份归档失败: %v", err)
+		return "", errors.ErrBackupFailed
+	}
+
+	logger.Infof("数据库备份成功: %s (加密: %v)", targetPath, cfg.BackupPassphrase != "")
+	return filename, nil
+}
+
+// goAfterBackup 在后台执行 afterBackup，Stop 会等待其完成
+func (s *BackupService) goAfterBackup(filename string) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.afterBackup(filename)
+	}()
+}
+
+// afterBackup 备份完成后上传到异地目标，并按保留数量清理本地和远端旧备份
+func (s *BackupService) afterBackup(filename string) {
+	cfg, err := s.sysRepo.Get()
+	if err != nil {
+		logger.Errorf("获取系统配置失败: %v", err)
+		return
+	}
+
+	dests, err := newBackupDestinations(cfg)
+	if err != nil {
+		logger.Errorf("异地备份配置无效: %v", err)
+	}
+	for _, dest := range dests {
+		if err := dest.Upload(filename, filepath.Join(backupDir, filename)); err != nil {
+			logger.Errorf("上传备份到 %s 失败 %s: %v", dest.Name(), filename, err)
+			continue
+		}
+		logger.Infof("备份已上传到 %s: %s", dest.Name(), filename)
+		s.cleanRemoteBackups(dest, cfg.BackupRetentionCount)
+	}
+
+	s.cleanOldBackups(backupDir)
+}
+
+// cleanRemoteBackups 按保留数量清理异地备份
+func (s *BackupService) cleanRemoteBackups(dest BackupDestination, retentionCount int) {
+	if retentionCount <= 0 {
+		return
+	}
+
+	names, err := dest.List()
+	if err != nil {
+		logger.Errorf("获取 %s 备份列表失败: %v", dest.Name(), err)
+		return
+	}
+
+	for _, name := range expiredBackups(names, retentionCount) {
+		if err := dest.Delete(name); err != nil {
+			logger.Errorf("删除 %s 旧备份失败 %s: %v", dest.Name(), name, err)
+		} else {
+			logger.Infof("已删除 %s 旧备份: %s", dest.Name(), name)
+		}
+	}
+}
+
+// expiredBackups 按触发来源分别保留最新的 retentionCount 个备份，返回超出的备份
+// 变更前快照不会挤占定时、手动备份的保留数量
+func expiredBackups(names []string, retentionCount int) []string {
+	groups := make(map[string][]string)
+	for _, name := range names {
+		trigger := backupTrigger(name)
+		groups[trigger] = append(groups[trigger], name)
+	}
+
+	var expired []string
+	for _, group := range groups {
+		if len(group) <= retentionCount {
+			continue
+		}
+		// 文件名包含时间戳，降序即新的在前
+		sort.Sort(sort.Reverse(sort.StringSlice(group)))
+		expired = append(expired, group[retentionCount:]...)
+	}
+	sort.Strings(expired)
+	return expired
+}
+
+// TestS3Destination 测试 S3 异地备份配置，Secret Key 为占位符时使用已保存的值
+func (s *BackupService) TestS3Destination(req *dto.S3ConfigReq) error {
+	if req.SecretKey == secretMask {
+		cfg, err := s.sysRepo.Get()
+		if err != nil {
+			return err
+		}
+		req.SecretKey = cfg.BackupS3SecretKey
+	}
+	dest, err := NewS3BackupDestination(req)
+	if err != nil {
+		return err
+	}
+	return dest.Test()
+}
+
+// isSQLite 当前是否使用 SQLite 存储
+func (s *BackupService) isSQLite() bool {
+	return s.db.Dialector.Name() == database.TypeSQLite
+}
+
+// snapshotDatabase 生成 SQLite 格式的数据库快照
+// SQLite 使用 VACUUM INTO 在线备份；PostgreSQL/MySQL 在一致性读事务中将数据导出到新的 SQLite 文件，
+// 因此所有后端的备份格式相同，可以跨后端恢复
+func (s *BackupService) snapshotDatabase(dbPath, targetPath string) error {
+	if !s.isSQLite() {
+		return s.exportToSQLite(targetPath)
+	}
 
-	// 使用 VACUUM INTO 进行在线备份 (SQLite)
 	err := s.db.Exec("VACUUM INTO ?", targetPath).Error
 	if err != nil {
 		logger.Warnf("VACUUM INTO 备份失败 (%v)，尝试直接文件复制", err)
-		if err := copyFile(dbPath, targetPath); err != nil {
-			return errors.ErrBackupFailed
+		return copyFile(dbPath, targetPath)
+	}
+	return nil
+}
+
+// exportToSQLite 将当前数据库导出为 SQLite 文件
+func (s *BackupService) exportToSQLite(targetPath string) error {
+	dst, err := database.OpenSQLite(targetPath)
+	if err != nil {
+		return err
+	}
+	defer database.Close(dst)
+
+	if err = dst.AutoMigrate(database.Models()...); err != nil {
+		logger.Errorf("创建备份表结构失败: %v", err)
+		return err
+	}
+	if _, err = database.Copy(dst, s.db); err != nil {
+		logger.Errorf("导出数据库失败: %v", err)
+		return err
+	}
+	return nil
+}
+
+// newBackupName 生成备份文件名: gost_panel_<时间>_<触发来源><扩展名>
+// 同一秒内重名时追加序号，避免覆盖已有备份
+func newBackupName(trigger, ext string) string {
+	base := fmt.Sprintf("%s%s_%s", backupPrefix, time.Now().Format("20060102_150405"), trigger)
+	name := base + ext
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(backupDir, name)); os.IsNotExist(err) {
+			return name
 		}
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
 	}
+}
 
-	logger.Infof("数据库备份成功: %s", targetPath)
+// backupTrigger 从备份文件名中解析触发来源，旧版本文件名无来源时返回空
+func backupTrigger(name string) string {
+	name = strings.TrimPrefix(name, backupPrefix)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, backupExt), backupArchiveExt)
+	// 跳过时间戳 20060102_150405
+	if len(name) <= len("20060102_150405_") {
+		return ""
+	}
+	trigger := name[len("20060102_150405_"):]
+	if i := strings.LastIndex(trigger, "-"); i > 0 {
+		trigger = trigger[:i]
+	}
+	return trigger
+}
 
-	// 清理旧备份
-	go s.cleanOldBackups(backupDir)
+// isBackupFile 判断文件名是否为备份文件
+func isBackupFile(name string) bool {
+	return strings.HasPrefix(name, backupPrefix) &&
+		(strings.HasSuffix(name, backupExt) || strings.HasSuffix(name, backupArchiveExt))
+}
 
-	return nil
+// backupPassphrase 获取备份口令，未指定时使用系统配置中的口令
+func (s *BackupService) backupPassphrase(passphrase string) string {
+	if passphrase != "" {
+		return passphrase
+	}
+	cfg, err := s.sysRepo.Get()
+	if err != nil {
+		return ""
+	}
+	return cfg.BackupPassphrase
 }
 
-// cleanOldBackups 清理旧备份
+// openBackupDatabase 校验备份并返回可直接读取的临时数据库文件路径，使用完毕后调用 cleanup 删除
+// 数据库文件会被复制、归档备份会被解密解压到临时文件，读取期间备份本身被清理也不受影响
+func openBackupDatabase(path, passphrase string) (dbPath string, manifest *dto.BackupManifest, cleanup func(), err error) {
+	cleanup = func() {}
+
+	format, _, err := detectBackupFormat(path)
+	if err != nil {
+		return "", nil, cleanup, errors.ErrBackupInvalid
+	}
+
+	tmpPath := filepath.Join(filepath.Dir(path), fmt.Sprintf(".extract-%d.db", time.Now().UnixNano()))
+	cleanup = func() {
+		_ = os.Remove(tmpPath)
+	}
+	if format == backupFormatDB {
+		err = copyFile(path, tmpPath)
+	} else {
+		manifest, err = extractBackupArchive(path, passphrase, tmpPath)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, func() {}, err
+	}
+	if err = validateBackupFile(tmpPath); err != nil {
+		cleanup()
+		return "", nil, func() {}, err
+	}
+	return tmpPath, manifest, cleanup, nil
+}
+
+// cleanOldBackups 按触发来源分别保留最新的备份，清理旧备份
 func (s *BackupService) cleanOldBackups(backupDir string) {
 	cfg, err := s.sysRepo.Get()
 	if err != nil {
@@ -161,26 +503,16 @@ func (s *BackupService) cleanOldBackups(backupDir string) {
 		return
 	}
 
-	var backupFiles []os.DirEntry
+	var names []string
 	for _, file := range files {
-		if !file.IsDir() && strings.HasPrefix(file.Name(), "gost_panel_") && strings.HasSuffix(file.Name(), ".db") {
-			backupFiles = append(backupFiles, file)
+		if !file.IsDir() && isBackupFile(file.Name()) {
+			names = append(names, file.Name())
 		}
 	}
 
-	// 如果备份数量未超过保留数量，直接返回
-	if len(backupFiles) <= retentionCount {
-		return
-	}
-
-	// 按修改时间排序（从新到旧） (按文件名排序即可，因包含时间戳)
-	sort.Slice(backupFiles, func(i, j int) bool {
-		return backupFiles[i].Name() > backupFiles[j].Name() // 降序，新的在前
-	})
-
 	// 删除多余的备份
-	for i := retentionCount; i < len(backupFiles); i++ {
-		filePath := filepath.Join(backupDir, backupFiles[i].Name())
+	for _, name := range expiredBackups(names, retentionCount) {
+		filePath := filepath.Join(backupDir, name)
 		if err := os.Remove(filePath); err != nil {
 			logger.Errorf("删除旧备份失败 %s: %v", filePath, err)
 		} else {
@@ -189,6 +521,487 @@ func (s *BackupService) cleanOldBackups(backupDir string) {
 	}
 }
 
+// ListBackups 获取备份文件列表（新的在前）
+func (s *BackupService) ListBackups() ([]dto.BackupFileResp, error) {
+	files, err := os.ReadDir(backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []dto.BackupFileResp{}, nil
+		}
+		return nil, err
+	}
+
+	list := make([]dto.BackupFileResp, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !isBackupFile(file.Name()) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		format, encrypted, _ := detectBackupFormat(filepath.Join(backupDir, file.Name()))
+		list = append(list, dto.BackupFileResp{
+			Name:      file.Name(),
+			Size:      info.Size(),
+			Format:    format,
+			Encrypted: encrypted,
+			Trigger:   backupTrigger(file.Name()),
+			CreatedAt: info.ModTime(),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name > list[j].Name
+	})
+	return list, nil
+}
+
+// GetBackupPath 获取备份文件路径（校验文件名，防止路径穿越）
+func (s *BackupService) GetBackupPath(name string) (string, error) {
+	if name != filepath.Base(name) || !isBackupFile(name) {
+		return "", errors.ErrBackupNameInvalid
+	}
+
+	path := filepath.Join(backupDir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", errors.ErrBackupNotFound
+	}
+	return path, nil
+}
+
+// MaxUploadSize 上传备份文件的大小上限（字节）
+func (s *BackupService) MaxUploadSize() int64 {
+	return config.Get().Backup.MaxUploadMB << 20
+}
+
+// SaveUploadedBackup 保存上传的备份文件，校验通过后返回文件名
+// 加密归档使用 passphrase 校验，为空时使用系统配置中的口令
+func (s *BackupService) SaveUploadedBackup(src io.Reader, passphrase string, userID uint, username, ip, userAgent string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", errors.ErrBackupDirCreateFailed
+	}
+
+	// 先写入临时文件，校验通过后再重命名
+	tmp, err := os.CreateTemp(backupDir, ".upload-*")
+	if err != nil {
+		return "", errors.ErrBackupUploadFailed
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	// 多读一个字节判断是否超过上限，超限时不再解密校验
+	limit := s.MaxUploadSize()
+	n, err := io.Copy(tmp, io.LimitReader(src, limit+1))
+	if err != nil {
+		_ = tmp.Close()
+		logger.Errorf("写入上传备份失败: %v", err)
+		return "", errors.ErrBackupUploadFailed
+	}
+	if err = tmp.Close(); err != nil {
+		return "", errors.ErrBackupUploadFailed
+	}
+	if n > limit {
+		return "", errors.ErrBackupTooLarge
+	}
+
+	format, _, err := detectBackupFormat(tmpPath)
+	if err != nil {
+		return "", errors.ErrBackupInvalid
+	}
+	_, _, cleanup, err := openBackupDatabase(tmpPath, s.backupPassphrase(passphrase))
+	if err != nil {
+		return "", err
+	}
+	cleanup()
+
+	ext := backupExt
+	if format == backupFormatArchive {
+		ext = backupArchiveExt
+	}
+	filename := newBackupName(BackupTriggerUpload, ext)
+	if err = os.Rename(tmpPath, filepath.Join(backupDir, filename)); err != nil {
+		logger.Errorf("保存上传备份失败: %v", err)
+		return "", errors.ErrBackupUploadFailed
+	}
+
+	NewLogService(s.db).Record(
+		userID,
+		username,
+		model.ActionUpload,
+		model.ResourceTypeBackup,
+		0,
+		fmt.Sprintf("上传备份: %s", filename),
+		ip,
+		userAgent)
+
+	logger.Infof("上传备份成功: %s", filename)
+	return filename, nil
+}
+
+// validateBackupFile 校验备份文件是有效的面板 SQLite 数据库
+func validateBackupFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.ErrBackupNotFound
+	}
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(f, header)
+	_ = f.Close()
+	if err != nil || !bytes.Equal(header, sqliteHeader) {
+		return errors.ErrBackupInvalid
+	}
+
+	db, err := database.OpenSQLite(path)
+	if err != nil {
+		return errors.ErrBackupInvalid
+	}
+	defer database.Close(db)
+
+	// 完整性检查
+	var result string
+	if err = db.Raw("PRAGMA integrity_check").Scan(&result).Error; err != nil || result != "ok" {
+		logger.Warnf("备份完整性检查失败: %s %v", result, err)
+		return errors.ErrBackupInvalid
+	}
+
+	// 检查必要的数据表
+	var tables []string
+	if err = db.Raw("SELECT name FROM sqlite_master WHERE type = 'table'").Scan(&tables).Error; err != nil {
+		return errors.ErrBackupInvalid
+	}
+	existing := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		existing[t] = true
+	}
+	for _, t := range requiredBackupTables {
+		if !existing[t] {
+			logger.Warnf("备份缺少数据表: %s", t)
+			return errors.ErrBackupInvalid
+		}
+	}
+
+	// 拒绝恢复高于当前程序版本的数据库结构
+	if existing[migrate.TableName] {
+		var version int
+		if err = db.Raw("SELECT COALESCE(MAX(version), 0) FROM " + migrate.TableName).Scan(&version).Error; err != nil {
+			return errors.ErrBackupInvalid
+		}
+		if version > migrate.Latest() {
+			logger.Warnf("备份数据库版本 %d 高于当前程序版本 %d", version, migrate.Latest())
+			return errors.ErrBackupSchemaTooNew
+		}
+	}
+
+	return nil
+}
+
+// Restore 从备份恢复数据库
+// 流程：校验（解密）备份 -> 创建安全备份 -> 暂停后台服务 -> 替换数据 -> 重新同步节点状态 -> 恢复后台服务
+func (s *BackupService) Restore(name, passphrase string, userID uint, username, ip, userAgent string) (*dto.RestoreBackupResp, error) {
+	if !s.restoreMu.TryLock() {
+		return nil, errors.ErrBackupRestoreRunning
+	}
+	defer s.restoreMu.Unlock()
+
+	path, err := s.GetBackupPath(name)
+	if err != nil {
+		return nil, err
+	}
+	dbPath, manifest, cleanup, err := openBackupDatabase(path, s.backupPassphrase(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// 先暂停后台服务，等待进行中的自动备份完成，安全备份和恢复不与其并发
+	if s.background != nil {
+		resume := s.background.Pause()
+		defer resume()
+	}
+
+	// 恢复前先备份当前数据库，恢复完成后再上传并清理旧备份，避免清理掉正在恢复的备份
+	safety, err := s.createBackupFile(BackupTriggerPreRestore)
+	if err != nil {
+		return nil, err
+	}
+	defer s.goAfterBackup(safety)
+
+	// 先停止当前运行的规则和隧道，恢复后只启动备份中运行的，节点上不会遗留备份中已停止或不存在的服务
+	stoppedTunnels, stoppedRules := s.stopAllBeforeRestore()
+
+	tables, err := s.restoreFrom(dbPath)
+	if err != nil {
+		logger.Errorf("恢复备份 %s 失败: %v", name, err)
+		s.restartAfterFailedRestore(stoppedTunnels, stoppedRules, userID, username, ip, userAgent)
+		return nil, errors.ErrBackupRestoreFailed
+	}
+	logger.Infof("数据库已从备份恢复: %s (%d 张表)", name, tables)
+
+	resp := &dto.RestoreBackupResp{
+		Restored:     name,
+		SafetyBackup: safety,
+		Tables:       tables,
+		Manifest:     manifest,
+	}
+	resp.Tunnels, resp.Rules, resp.Failed = s.resyncAfterRestore(userID, username, ip, userAgent)
+
+	NewLogService(s.db).Record(
+		userID,
+		username,
+		model.ActionRestore,
+		model.ResourceTypeBackup,
+		0,
+		fmt.Sprintf("恢复备份: %s (安全备份: %s, 重启隧道 %d, 规则 %d, 失败 %d)",
+			name, safety, resp.Tunnels, resp.Rules, resp.Failed),
+		ip,
+		userAgent)
+
+	return resp, nil
+}
+
+// stopAllBeforeRestore 停止当前数据库中运行的规则和隧道，删除其在节点上的服务
+// 返回被停止的隧道和规则 ID，恢复失败时用于重新启动
+func (s *BackupService) stopAllBeforeRestore() (tunnelIDs, ruleIDs []uint) {
+	tunnelRepo := repository.NewTunnelRepository(s.db)
+	ruleRepo := repository.NewRuleRepository(s.db)
+	tunnelService := NewTunnelService(s.db)
+	ruleService := NewRuleService(s.db)
+
+	// 规则依赖隧道，需先停止
+	rules, _, err := ruleRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.RuleStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("恢复前获取规则列表失败: %v", err)
+	}
+	for i := range rules {
+		if err = ruleService.stop(&rules[i]); err != nil {
+			logger.Warnf("恢复前停止规则 %s 失败: %v", rules[i].Name, err)
+		}
+		ruleIDs = append(ruleIDs, rules[i].ID)
+	}
+
+	tunnels, _, err := tunnelRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.TunnelStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("恢复前获取隧道列表失败: %v", err)
+	}
+	for i := range tunnels {
+		tunnelService.stop(&tunnels[i])
+		tunnelIDs = append(tunnelIDs, tunnels[i].ID)
+	}
+	return
+}
+
+// restartAfterFailedRestore 恢复失败时重新启动恢复前停止的隧道和规则
+func (s *BackupService) restartAfterFailedRestore(tunnelIDs, ruleIDs []uint, userID uint, username, ip, userAgent string) {
+	tunnelService := NewTunnelService(s.db)
+	for _, id := range tunnelIDs {
+		if err := tunnelService.Start(id, userID, username, ip, userAgent); err != nil {
+			logger.Warnf("恢复失败后重启隧道 %d 失败: %v", id, err)
+		}
+	}
+	ruleService := NewRuleService(s.db)
+	for _, id := range ruleIDs {
+		if err := ruleService.Start(id, userID, username, ip, userAgent); err != nil {
+			logger.Warnf("恢复失败后重启规则 %d 失败: %v", id, err)
+		}
+	}
+}
+
+// restoreFrom 将备份中的数据复制到当前数据库
+// SQLite 通过 ATTACH 在同一连接内完成，保持现有 *gorm.DB 可用；
+// 当前数据库的每张表都会被清空，只复制两边都存在的表和列，旧版本备份中没有的表恢复后为空
+func (s *BackupService) restoreFrom(path string) (int, error) {
+	if !s.isSQLite() {
+		return s.importFromSQLite(path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	err = s.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("ATTACH DATABASE ? AS restore_src", absPath).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("DETACH DATABASE restore_src")
+
+		var srcTables, tables []string
+		if err := conn.Raw("SELECT name FROM restore_src.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").
+			Scan(&srcTables).Error; err != nil {
+			return err
+		}
+		if err := conn.Raw("SELECT name FROM main.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").
+			Scan(&tables).Error; err != nil {
+			return err
+		}
+		inSrc := make(map[string]bool, len(srcTables))
+		for _, table := range srcTables {
+			inSrc[table] = true
+		}
+		inMain := make(map[string]bool, len(tables))
+		for _, table := range tables {
+			inMain[table] = true
+		}
+		for _, table := range srcTables {
+			if !inMain[table] {
+				logger.Warnf("当前数据库不存在表 %s，跳过恢复", table)
+			}
+		}
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			for _, table := range tables {
+				// 迁移记录以当前数据库为准
+				if table == migrate.TableName {
+					continue
+				}
+				if err := tx.Exec(fmt.Sprintf("DELETE FROM main.%s", quoteIdent(table))).Error; err != nil {
+					return err
+				}
+				if !inSrc[table] {
+					continue
+				}
+
+				mainCols, err := tableColumns(tx, "main", table)
+				if err != nil {
+					return err
+				}
+				srcCols, err := tableColumns(tx, "restore_src", table)
+				if err != nil {
+					return err
+				}
+
+				common := make([]string, 0, len(srcCols))
+				mainSet := make(map[string]bool, len(mainCols))
+				for _, c := range mainCols {
+					mainSet[c] = true
+				}
+				for _, c := range srcCols {
+					if mainSet[c] {
+						common = append(common, quoteIdent(c))
+					}
+				}
+
+				cols := strings.Join(common, ", ")
+				if err = tx.Exec(fmt.Sprintf("INSERT INTO main.%s (%s) SELECT %s FROM restore_src.%s",
+					quoteIdent(table), cols, cols, quoteIdent(table))).Error; err != nil {
+					return err
+				}
+				restored++
+			}
+			return nil
+		})
+	})
+	return restored, err
+}
+
+// importFromSQLite 将 SQLite 备份中的数据按模型复制到 PostgreSQL/MySQL
+func (s *BackupService) importFromSQLite(path string) (int, error) {
+	src, err := database.OpenSQLite(path)
+	if err != nil {
+		return 0, err
+	}
+	defer database.Close(src)
+
+	counts, err := database.Copy(s.db, src)
+	if err != nil {
+		return 0, err
+	}
+	return len(counts), nil
+}
+
+// tableColumns 获取指定库中表的列名，表不存在时返回空
+func tableColumns(db *gorm.DB, schema, table string) ([]string, error) {
+	var columns []struct {
+		Name string `gorm:"column:name"`
+	}
+	if err := db.Raw(fmt.Sprintf("PRAGMA %s.table_info(%s)", schema, quoteIdent(table))).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	return names, nil
+}
+
+// quoteIdent 转义 SQL 标识符
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// resyncAfterRestore 恢复后重新同步节点状态
+// 先检测节点在线状态，再将备份中记录为运行中的隧道和规则重新下发到节点
+func (s *BackupService) resyncAfterRestore(userID uint, username, ip, userAgent string) (tunnels, rules, failed int) {
+	nodeRepo := repository.NewNodeRepository(s.db)
+	tunnelRepo := repository.NewTunnelRepository(s.db)
+	ruleRepo := repository.NewRuleRepository(s.db)
+
+	// 1. 刷新节点在线状态
+	health := NewNodeHealthService(s.db)
+	nodes, _, err := nodeRepo.List(nil)
+	if err != nil {
+		logger.Errorf("恢复后获取节点列表失败: %v", err)
+		return
+	}
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(n model.GostNode) {
+			defer wg.Done()
+			_ = nodeRepo.UpdateStatus(n.ID, health.checkNodeHealth(n))
+			_ = nodeRepo.UpdateLastCheck(n.ID)
+		}(node)
+	}
+	wg.Wait()
+
+	// 2. 重新启动隧道（规则依赖隧道，需先启动）
+	tunnelService := NewTunnelService(s.db)
+	runningTunnels, _, err := tunnelRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.TunnelStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("恢复后获取隧道列表失败: %v", err)
+	}
+	for _, t := range runningTunnels {
+		_ = tunnelRepo.UpdateStatus(t.ID, model.TunnelStatusStopped)
+		if err = tunnelService.Start(t.ID, userID, username, ip, userAgent); err != nil {
+			logger.Warnf("恢复后重启隧道 %s 失败: %v", t.Name, err)
+			failed++
+			continue
+		}
+		tunnels++
+	}
+
+	// 3. 重新启动规则
+	ruleService := NewRuleService(s.db)
+	runningRules, _, err := ruleRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.RuleStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("恢复后获取规则列表失败: %v", err)
+	}
+	for _, r := range runningRules {
+		_ = ruleRepo.UpdateStatus(r.ID, model.RuleStatusStopped)
+		if err = ruleService.Start(r.ID, userID, username, ip, userAgent); err != nil {
+			logger.Warnf("恢复后重启规则 %s 失败: %v", r.Name, err)
+			failed++
+			continue
+		}
+		rules++
+	}
+
+	return
+}
+
 // copyFile 复制文件
 func copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gost-panel/internal/config"
+	"gost-panel/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// newBackupTestService 在临时目录中创建备份服务，备份文件写入该目录下的 backups
+func newBackupTestService(t *testing.T, sysConfig *model.SystemConfig) (*gorm.DB, *BackupService) {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	configPath := filepath.Join(dir, "config.yaml")
+	if err = os.WriteFile(configPath, []byte("database:\n  path: test.db\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = config.Load(configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	db := newTestDB(t)
+	sysConfig.ID = 1
+	mustCreate(t, db, sysConfig)
+	return db, NewBackupService(db)
+}
+
+// backupOps 记录备份（VACUUM INTO）和恢复（ATTACH 至 DETACH）的执行情况
+type backupOps struct {
+	active   atomic.Int32 // 进行中的备份和恢复
+	overlaps atomic.Int32 // 开始时已有其他备份或恢复在进行的次数
+}
+
+func (o *backupOps) begin() {
+	if o.active.Add(1) > 1 {
+		o.overlaps.Add(1)
+	}
+}
+
+// trackBackupOps 跟踪数据库上的备份和恢复，每次备份延长 delay 以暴露并发
+func trackBackupOps(t *testing.T, db *gorm.DB, delay time.Duration) *backupOps {
+	t.Helper()
+	ops := &backupOps{}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("test:backup_begin", func(tx *gorm.DB) {
+		switch sql := tx.Statement.SQL.String(); {
+		case strings.HasPrefix(sql, "VACUUM INTO"):
+			ops.begin()
+			time.Sleep(delay)
+		case strings.HasPrefix(sql, "ATTACH DATABASE"):
+			ops.begin()
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:backup_end", func(tx *gorm.DB) {
+		if sql := tx.Statement.SQL.String(); strings.HasPrefix(sql, "VACUUM INTO") || strings.HasPrefix(sql, "DETACH DATABASE") {
+			ops.active.Add(-1)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ops
+}
+
+// waitFor 等待条件成立，超时则失败
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBackupTrigger(t *testing.T) {
+	cases := map[string]string{
+		"gost_panel_20261019_104500_auto.db":           BackupTriggerAuto,
+		"gost_panel_20261019_104500_pre_restore.gpbak": BackupTriggerPreRestore,
+		"gost_panel_20261019_104500_pre_delete-2.db":   BackupTriggerPreDelete,
+		"gost_panel_20261019_104500.db":                "",
+	}
+	for name, want := range cases {
+		if got := backupTrigger(name); got != want {
+			t.Errorf("backupTrigger(%q) = %q，期望 %q", name, got, want)
+		}
+	}
+}
+
+func TestExpiredBackupsPerTrigger(t *testing.T) {
+	names := []string{
+		"gost_panel_20261019_010000_auto.db",
+		"gost_panel_20261019_020000_pre_delete.db",
+		"gost_panel_20261019_030000_pre_delete.db",
+		"gost_panel_20261019_040000_pre_delete.db",
+		"gost_panel_20261019_050000_auto.db",
+		"gost_panel_20261019_060000_manual.gpbak",
+		"gost_panel_20261018_000000.db",
+		"gost_panel_20261017_000000.db",
+	}
+	want := []string{
+		"gost_panel_20261017_000000.db",
+		"gost_panel_20261019_010000_auto.db",
+		"gost_panel_20261019_020000_pre_delete.db",
+		"gost_panel_20261019_030000_pre_delete.db",
+	}
+
+	// 快照再多也不挤占定时备份的保留数量
+	if got := expiredBackups(names, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expiredBackups = %v，期望 %v", got, want)
+	}
+	if got := expiredBackups(names, 3); len(got) != 0 {
+		t.Errorf("expiredBackups = %v，期望为空", got)
+	}
+}
+
+func TestBackupStopWaitsForRunningBackup(t *testing.T) {
+	// 未配置计划时启动即补做当天的备份
+	db, s := newBackupTestService(t, &model.SystemConfig{AutoBackup: true})
+	ops := trackBackupOps(t, db, 300*time.Millisecond)
+
+	s.Start()
+	waitFor(t, func() bool { return ops.active.Load() > 0 })
+	s.Stop()
+
+	if n := ops.active.Load(); n != 0 {
+		t.Errorf("Stop 返回时仍有 %d 个备份在进行", n)
+	}
+	files, err := os.ReadDir(backupDir)
+	if err != nil || len(files) != 1 || !strings.HasSuffix(files[0].Name(), "_auto"+backupExt) {
+		t.Errorf("备份目录 = %v, %v，期望一个自动备份", files, err)
+	}
+}
+
+func TestRestoreWaitsForScheduledBackup(t *testing.T) {
+	db, s := newBackupTestService(t, &model.SystemConfig{AutoBackup: true})
+	// 恢复源使用过去的日期，启动时仍会补做当天的自动备份
+	name, err := s.createBackupFile(BackupTriggerManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := backupPrefix + "20000101_000000_manual" + backupExt
+	if err = os.Rename(filepath.Join(backupDir, name), filepath.Join(backupDir, source)); err != nil {
+		t.Fatal(err)
+	}
+	ops := trackBackupOps(t, db, 300*time.Millisecond)
+
+	m := NewBackgroundManager(s)
+	s.SetBackgroundManager(m)
+	m.StartAll()
+	defer m.StopAll()
+	waitFor(t, func() bool { return ops.active.Load() > 0 })
+
+	resp, err := s.Restore(source, "", 1, "admin", "", "")
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if resp.SafetyBackup == "" {
+		t.Error("未创建安全备份")
+	}
+	if n := ops.overlaps.Load(); n != 0 {
+		t.Errorf("备份与恢复重叠 %d 次", n)
+	}
+}
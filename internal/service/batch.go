@@ -0,0 +1,175 @@
+package service
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+)
+
+const (
+	// batchConcurrency 批量操作的并发数，避免同时向节点发起过多请求
+	batchConcurrency = 4
+	// batchMaxItems 单次批量操作的最大对象数
+	batchMaxItems = 500
+	// batchLogMaxIDs 操作日志中最多列出的 ID 数
+	batchLogMaxIDs = 50
+)
+
+// 批量操作类型
+const (
+	BatchActionStart   = "start"
+	BatchActionStop    = "stop"
+	BatchActionRestart = "restart"
+	BatchActionDelete  = "delete"
+)
+
+// batchSkip 跳过对象，值为跳过原因
+type batchSkip string
+
+// Error 实现 error 接口
+func (e batchSkip) Error() string {
+	return string(e)
+}
+
+// errBatchSkipped 对象状态已满足，无需执行
+var errBatchSkipped = batchSkip("")
+
+// batchItem 批量操作对象
+type batchItem struct {
+	Resource string // 资源类型，仅在涉及多种资源时设置
+	ID       uint
+	Name     string
+	Run      func() error // 返回 batchSkip 表示跳过
+}
+
+// runBatch 以有限并发执行批量操作，结果顺序与输入一致
+func runBatch(action string, items []batchItem) *dto.BatchResp {
+	results := make([]dto.BatchItemResult, len(items))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			item := items[i]
+			result := dto.BatchItemResult{Resource: item.Resource, ID: item.ID, Name: item.Name}
+			err := item.Run()
+			var skip batchSkip
+			switch {
+			case err == nil:
+				result.Success = true
+			case stderrors.As(err, &skip):
+				result.Success = true
+				result.Skipped = true
+				result.Error = string(skip)
+			default:
+				result.Code = errors.ErrInternal.Code
+				var bizErr *errors.BizError
+				if stderrors.As(err, &bizErr) {
+					result.Code = bizErr.Code
+				}
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	resp := &dto.BatchResp{Action: action, Total: len(results), Results: results}
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			resp.Skipped++
+		case r.Success:
+			resp.Succeeded++
+		default:
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+// batchIDs 去重并校验 ID 列表
+func batchIDs(ids []uint) ([]uint, error) {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return nil, errors.ErrBatchTargetRequired
+	}
+	if len(out) > batchMaxItems {
+		return nil, errors.ErrBatchTooMany
+	}
+	return out, nil
+}
+
+// batchLogAction 批量操作对应的日志操作类型
+func batchLogAction(action string) string {
+	switch action {
+	case BatchActionStart:
+		return model.ActionStart
+	case BatchActionStop:
+		return model.ActionStop
+	case BatchActionRestart:
+		return model.ActionRestart
+	default:
+		return model.ActionDelete
+	}
+}
+
+// batchTitle 批量操作日志标题，如 "批量停止规则"
+func batchTitle(action, resourceName string) string {
+	verb := map[string]string{
+		BatchActionStart:   "启动",
+		BatchActionStop:    "停止",
+		BatchActionRestart: "重启",
+		BatchActionDelete:  "删除",
+	}[action]
+	return "批量" + verb + resourceName
+}
+
+// batchLogDetails 批量操作日志详情，如 "批量停止规则: 成功 3, 跳过 1, 失败 1; ID: 1,2,3,4,5; 失败: web(5) 节点已离线"
+func batchLogDetails(resp *dto.BatchResp, title string) string {
+	var ids, failures []string
+	for _, r := range resp.Results {
+		id := fmt.Sprint(r.ID)
+		if r.Resource != "" {
+			id = r.Resource + ":" + id
+		}
+		if len(ids) < batchLogMaxIDs {
+			ids = append(ids, id)
+		}
+		if !r.Success {
+			failures = append(failures, fmt.Sprintf("%s(%s) %s", r.Name, id, r.Error))
+		}
+	}
+	if len(resp.Results) > batchLogMaxIDs {
+		ids = append(ids, "...")
+	}
+
+	details := fmt.Sprintf("%s: 成功 %d, 跳过 %d, 失败 %d; ID: %s",
+		title, resp.Succeeded, resp.Skipped, resp.Failed, strings.Join(ids, ","))
+	if resp.Snapshot != "" {
+		details += "; 快照: " + resp.Snapshot
+	}
+	if len(failures) > 0 {
+		details += "; 失败: " + strings.Join(failures, "; ")
+	}
+	return details
+}
@@ -0,0 +1,1424 @@
+// Package service 提供业务逻辑层服务
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+	"gost-panel/pkg/logger"
+	"gost-panel/pkg/secret"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// InventoryService 资源清单服务
+// 负责将节点、隧道、IP 列表、规则导出为按名称引用的声明式文档，
+// 以及按文档内容创建/更新/删除资源使面板与文档保持一致
+type InventoryService struct {
+	db *gorm.DB
+}
+
+// NewInventoryService 创建资源清单服务
+func NewInventoryService(db *gorm.DB) *InventoryService {
+	return &InventoryService{db: db}
+}
+
+// Export 导出资源清单
+func (s *InventoryService) Export(req *dto.ExportInventoryReq) (*dto.Inventory, error) {
+	secrets := req.Secrets
+	if secrets == "" {
+		secrets = "omit"
+	}
+	if secrets == "encrypt" && req.Passphrase == "" {
+		return nil, errors.ErrInventoryPassphraseRequired
+	}
+
+	state, err := loadInventoryState(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &dto.Inventory{
+		Version:    dto.InventoryVersion,
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Secrets:    secrets,
+		Nodes:      make([]dto.InventoryNode, 0, len(state.nodes)),
+		Tunnels:    make([]dto.InventoryTunnel, 0, len(state.tunnels)),
+		IPLists:    make([]dto.InventoryIPList, 0, len(state.ipLists)),
+		Rules:      make([]dto.InventoryRule, 0, len(state.rules)),
+	}
+
+	for _, n := range state.nodes {
+		item := dto.InventoryNode{
+			Name:     n.Name,
+			Address:  n.Address,
+			Port:     n.Port,
+			Username: n.Username,
+			Remark:   n.Remark,
+
+			PortRanges:    n.PortRanges,
+			ReservedPorts: n.ReservedPorts,
+		}
+		if secrets == "encrypt" && n.Password != "" {
+			if item.Password, err = secret.EncryptString(n.Password, req.Passphrase); err != nil {
+				return nil, err
+			}
+		}
+		inv.Nodes = append(inv.Nodes, item)
+	}
+
+	for _, t := range state.tunnels {
+		item := dto.InventoryTunnel{
+			Name:      t.Name,
+			EntryNode: state.nodeName(t.EntryNodeID),
+			ExitNode:  state.nodeName(t.ExitNodeID),
+			Protocol:  t.Protocol,
+			RelayPort: t.RelayPort,
+			Remark:    t.Remark,
+
+			Options: tunnelOptionsDTO(t.Options),
+		}
+		item.ExtraExitNodes = state.extraExitNames(&t)
+		if strategy := normalizeStrategy(t.Strategy); strategy != "round" {
+			item.Strategy = strategy
+		}
+		if kcp := item.Options; kcp != nil && kcp.KCP != nil && kcp.KCP.Key != "" {
+			if secrets == "encrypt" {
+				if kcp.KCP.Key, err = secret.EncryptString(kcp.KCP.Key, req.Passphrase); err != nil {
+					return nil, err
+				}
+			} else {
+				kcp.KCP.Key = ""
+			}
+		}
+		inv.Tunnels = append(inv.Tunnels, item)
+	}
+
+	for _, l := range state.ipLists {
+		inv.IPLists = append(inv.IPLists, dto.InventoryIPList{
+			Name:    l.Name,
+			Entries: l.Entries,
+			Remark:  l.Remark,
+		})
+	}
+
+	for _, r := range state.rules {
+		item := dto.InventoryRule{
+			Name:          r.Name,
+			Type:          string(r.Type),
+			ListenPort:    r.ListenPort,
+			Protocol:      string(r.Protocol),
+			ListenPortEnd: r.ListenPortEnd,
+			PortMapping:   r.PortMapping,
+			Targets:       r.Targets,
+			Strategy:      r.Strategy,
+			EnableTLS:     r.EnableTLS,
+			Remark:        r.Remark,
+			ProxyType:     r.ProxyType,
+			Host:          r.Host,
+			MaxFails:      r.MaxFails,
+			FailTimeout:   r.FailTimeout,
+
+			AllowSources: r.AllowSources,
+			DenySources:  r.DenySources,
+			AllowLists:   state.ipListNames(r.AllowLists),
+			DenyLists:    state.ipListNames(r.DenyLists),
+		}
+		for _, opt := range r.TargetOptions {
+			item.TargetOptions = append(item.TargetOptions, dto.TargetOption{Target: opt.Target, Weight: opt.Weight, Backup: opt.Backup})
+		}
+		for _, user := range r.ProxyUsers {
+			exported := dto.ProxyUser{Username: user.Username}
+			if secrets == "encrypt" {
+				if exported.Password, err = secret.EncryptString(user.Password, req.Passphrase); err != nil {
+					return nil, err
+				}
+			}
+			item.ProxyUsers = append(item.ProxyUsers, exported)
+		}
+		if r.NodeID != nil {
+			item.Node = state.nodeName(*r.NodeID)
+		}
+		if r.TunnelID != nil {
+			item.Tunnel = state.tunnelName(*r.TunnelID)
+		}
+		inv.Rules = append(inv.Rules, item)
+	}
+
+	sort.Slice(inv.Nodes, func(i, j int) bool { return inv.Nodes[i].Name < inv.Nodes[j].Name })
+	sort.Slice(inv.Tunnels, func(i, j int) bool { return inv.Tunnels[i].Name < inv.Tunnels[j].Name })
+	sort.Slice(inv.IPLists, func(i, j int) bool { return inv.IPLists[i].Name < inv.IPLists[j].Name })
+	sort.Slice(inv.Rules, func(i, j int) bool { return inv.Rules[i].Name < inv.Rules[j].Name })
+
+	return inv, nil
+}
+
+// ExportWithLog 导出资源清单并记录操作日志
+func (s *InventoryService) ExportWithLog(req *dto.ExportInventoryReq, userID uint, username string, ip, userAgent string) ([]byte, error) {
+	inv, err := s.Export(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := MarshalInventory(inv, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	NewLogService(s.db).Record(
+		userID,
+		username,
+		model.ActionExport,
+		model.ResourceTypeInventory,
+		0,
+		fmt.Sprintf("导出资源清单: %d 个节点, %d 个隧道, %d 个 IP 列表, %d 条规则 (密钥: %s)",
+			len(inv.Nodes), len(inv.Tunnels), len(inv.IPLists), len(inv.Rules), inv.Secrets),
+		ip,
+		userAgent)
+
+	return data, nil
+}
+
+// MarshalInventory 将清单序列化为指定格式 (yaml/json)
+func MarshalInventory(inv *dto.Inventory, format string) ([]byte, error) {
+	if format == "json" {
+		return json.MarshalIndent(inv, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(inv); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseInventory 解析清单文档
+func ParseInventory(content []byte, format string) (*dto.Inventory, error) {
+	var inv dto.Inventory
+	var err error
+	if format == "json" {
+		err = json.Unmarshal(content, &inv)
+	} else {
+		// YAML 是 JSON 的超集，默认按 YAML 解析
+		err = yaml.Unmarshal(content, &inv)
+	}
+	if err != nil {
+		logger.Warnf("解析资源清单失败: %v", err)
+		return nil, errors.ErrInventoryParseFailed
+	}
+
+	if inv.Version != "" && inv.Version != dto.InventoryVersion {
+		return nil, errors.ErrInventoryVersionUnsupported
+	}
+	return &inv, nil
+}
+
+// Plan 生成导入计划（不执行）
+func (s *InventoryService) Plan(inv *dto.Inventory, passphrase string, prune bool) (*dto.InventoryPlan, error) {
+	d, err := s.diff(s.db, inv, passphrase, prune)
+	if err != nil {
+		return nil, err
+	}
+	return d.plan(), nil
+}
+
+// Apply 按清单执行导入，所有变更在同一事务中完成并逐项记录操作日志
+// 计划中存在冲突时不做任何修改，返回计划与 ErrInventoryConflict
+// 存在变更时会先创建快照备份，便于回滚
+func (s *InventoryService) Apply(inv *dto.Inventory, passphrase string, prune bool, userID uint, username string, ip, userAgent string) (*dto.InventoryPlan, error) {
+	preview, err := s.Plan(inv, passphrase, prune)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Conflicts > 0 {
+		return preview, errors.ErrInventoryConflict
+	}
+	if len(preview.Changes) == 0 {
+		return preview, nil
+	}
+
+	snapshot, err := NewBackupService(s.db).Snapshot(BackupTriggerPreImport)
+	if err != nil {
+		return preview, err
+	}
+
+	var plan *dto.InventoryPlan
+	var applied *inventoryDiff
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		d, err := s.diff(tx, inv, passphrase, prune)
+		if err != nil {
+			return err
+		}
+
+		plan = d.plan()
+		if plan.Conflicts > 0 {
+			return errors.ErrInventoryConflict
+		}
+
+		if err = d.apply(tx, &LogAction{UserID: userID, Username: username, IP: ip, UserAgent: userAgent}); err != nil {
+			return err
+		}
+		plan.Applied = true
+		plan.Snapshot = snapshot
+		applied = d
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	// 更新过的 IP 列表同步到引用它的运行中规则
+	ruleService := NewRuleService(s.db)
+	for _, op := range applied.ipLists {
+		if op.change.Action != dto.InventoryActionUpdate {
+			continue
+		}
+		if _, err = ruleService.RefreshAdmissions(op.existing.ID); err != nil {
+			logger.Warnf("刷新 IP 列表 %s 的规则准入控制器失败: %v", op.existing.Name, err)
+		}
+	}
+
+	NewLogService(s.db).Record(
+		userID,
+		username,
+		model.ActionImport,
+		model.ResourceTypeInventory,
+		0,
+		fmt.Sprintf("导入资源清单: %d 项变更 (prune: %v, 快照: %s)", len(plan.Changes), prune, snapshot),
+		ip,
+		userAgent)
+
+	logger.Infof("导入资源清单完成: %d 项变更", len(plan.Changes))
+	return plan, nil
+}
+
+// ==================== 差异计算 ====================
+
+// inventoryState 数据库中的现有资源
+type inventoryState struct {
+	nodes   []model.GostNode
+	tunnels []model.GostTunnel
+	ipLists []model.IPList
+	rules   []model.GostRule
+}
+
+// loadInventoryState 读取全部节点、隧道、IP 列表和规则
+func loadInventoryState(db *gorm.DB) (*inventoryState, error) {
+	state := &inventoryState{}
+	var err error
+	if state.nodes, _, err = repository.NewNodeRepository(db).List(nil); err != nil {
+		return nil, err
+	}
+	if state.tunnels, _, err = repository.NewTunnelRepository(db).List(nil); err != nil {
+		return nil, err
+	}
+	if state.ipLists, err = repository.NewIPListRepository(db).List(); err != nil {
+		return nil, err
+	}
+	if state.rules, _, err = repository.NewRuleRepository(db).List(nil); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// nodeName 根据 ID 获取节点名称
+func (st *inventoryState) nodeName(id uint) string {
+	for _, n := range st.nodes {
+		if n.ID == id {
+			return n.Name
+		}
+	}
+	return ""
+}
+
+// extraExitNames 隧道附加出口的节点名称
+func (st *inventoryState) extraExitNames(t *model.GostTunnel) []string {
+	var names []string
+	for _, exit := range t.ExtraExits {
+		names = append(names, st.nodeName(exit.NodeID))
+	}
+	return names
+}
+
+// tunnelName 根据 ID 获取隧道名称
+func (st *inventoryState) tunnelName(id uint) string {
+	for _, t := range st.tunnels {
+		if t.ID == id {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// ipListNames 根据 ID 列表获取 IP 列表名称
+func (st *inventoryState) ipListNames(ids []uint) []string {
+	var names []string
+	for _, id := range ids {
+		for _, l := range st.ipLists {
+			if l.ID == id {
+				names = append(names, l.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// nodeOp 节点变更
+type nodeOp struct {
+	change   dto.InventoryChange
+	desired  *dto.InventoryNode
+	existing *model.GostNode
+}
+
+// tunnelOp 隧道变更
+type tunnelOp struct {
+	change   dto.InventoryChange
+	desired  *dto.InventoryTunnel
+	existing *model.GostTunnel
+	options  model.TunnelOptions // 规范化后的传输参数
+}
+
+// ipListOp IP 列表变更
+type ipListOp struct {
+	change   dto.InventoryChange
+	desired  *dto.InventoryIPList
+	existing *model.IPList
+	entries  model.StringList // 规范化后的条目
+}
+
+// ruleOp 规则变更
+type ruleOp struct {
+	change     dto.InventoryChange
+	desired    *dto.InventoryRule
+	existing   *model.GostRule
+	proxyUsers model.ProxyUserList // 规范化后的代理用户（密码已补全）
+
+	targetOptions model.TargetOptionList // 规范化后的目标设置
+
+	allowSources model.StringList // 规范化后的允许来源
+	denySources  model.StringList // 规范化后的拒绝来源
+}
+
+// inventoryDiff 清单与数据库之间的差异
+type inventoryDiff struct {
+	nodes   []*nodeOp
+	tunnels []*tunnelOp
+	ipLists []*ipListOp
+	rules   []*ruleOp
+}
+
+// plan 将差异转换为对外展示的计划
+func (d *inventoryDiff) plan() *dto.InventoryPlan {
+	plan := &dto.InventoryPlan{Changes: make([]dto.InventoryChange, 0)}
+	add := func(c dto.InventoryChange) {
+		if c.Conflict != "" {
+			plan.Conflicts++
+		}
+		plan.Changes = append(plan.Changes, c)
+	}
+	for _, op := range d.nodes {
+		add(op.change)
+	}
+	for _, op := range d.tunnels {
+		add(op.change)
+	}
+	for _, op := range d.ipLists {
+		add(op.change)
+	}
+	for _, op := range d.rules {
+		add(op.change)
+	}
+	return plan
+}
+
+// diff 计算清单与数据库之间的差异
+func (s *InventoryService) diff(db *gorm.DB, inv *dto.Inventory, passphrase string, prune bool) (*inventoryDiff, error) {
+	state, err := loadInventoryState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解密节点密码
+	nodes := make([]dto.InventoryNode, len(inv.Nodes))
+	copy(nodes, inv.Nodes)
+	for i := range nodes {
+		if !secret.IsEncryptedString(nodes[i].Password) {
+			continue
+		}
+		if passphrase == "" {
+			return nil, errors.ErrInventoryPassphraseRequired
+		}
+		if nodes[i].Password, err = secret.DecryptString(nodes[i].Password, passphrase); err != nil {
+			return nil, errors.ErrInventoryDecryptFailed
+		}
+	}
+
+	// 解密隧道 KCP 密钥
+	for i := range inv.Tunnels {
+		opts := inv.Tunnels[i].Options
+		if opts == nil || opts.KCP == nil || !secret.IsEncryptedString(opts.KCP.Key) {
+			continue
+		}
+		if passphrase == "" {
+			return nil, errors.ErrInventoryPassphraseRequired
+		}
+		kcp := *opts.KCP
+		if kcp.Key, err = secret.DecryptString(kcp.Key, passphrase); err != nil {
+			return nil, errors.ErrInventoryDecryptFailed
+		}
+		inv.Tunnels[i].Options = &dto.TunnelOptions{WS: opts.WS, TLS: opts.TLS, Mux: opts.Mux, QUIC: opts.QUIC, KCP: &kcp}
+	}
+
+	// 解密代理用户密码
+	for i := range inv.Rules {
+		users := make([]dto.ProxyUser, len(inv.Rules[i].ProxyUsers))
+		copy(users, inv.Rules[i].ProxyUsers)
+		for j := range users {
+			if !secret.IsEncryptedString(users[j].Password) {
+				continue
+			}
+			if passphrase == "" {
+				return nil, errors.ErrInventoryPassphraseRequired
+			}
+			if users[j].Password, err = secret.DecryptString(users[j].Password, passphrase); err != nil {
+				return nil, errors.ErrInventoryDecryptFailed
+			}
+		}
+		inv.Rules[i].ProxyUsers = users
+	}
+
+	d := &inventoryDiff{}
+
+	// 索引现有资源（按名称）
+	existingNodes := make(map[string][]*model.GostNode)
+	for i := range state.nodes {
+		existingNodes[state.nodes[i].Name] = append(existingNodes[state.nodes[i].Name], &state.nodes[i])
+	}
+	existingTunnels := make(map[string][]*model.GostTunnel)
+	for i := range state.tunnels {
+		existingTunnels[state.tunnels[i].Name] = append(existingTunnels[state.tunnels[i].Name], &state.tunnels[i])
+	}
+	existingIPLists := make(map[string]*model.IPList)
+	for i := range state.ipLists {
+		existingIPLists[state.ipLists[i].Name] = &state.ipLists[i]
+	}
+	existingRules := make(map[string][]*model.GostRule)
+	for i := range state.rules {
+		existingRules[state.rules[i].Name] = append(existingRules[state.rules[i].Name], &state.rules[i])
+	}
+
+	// 文档中的资源名称集合
+	docNodes := make(map[string]*dto.InventoryNode)
+	docTunnels := make(map[string]*dto.InventoryTunnel)
+	docIPLists := make(map[string]*dto.InventoryIPList)
+	docRules := make(map[string]*dto.InventoryRule)
+
+	// ---------- 节点 ----------
+	for i := range nodes {
+		n := &nodes[i]
+		op := &nodeOp{desired: n, change: dto.InventoryChange{ResourceType: model.ResourceTypeNode, Name: n.Name}}
+		d.nodes = append(d.nodes, op)
+
+		if _, dup := docNodes[n.Name]; dup {
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "清单中存在同名节点"
+			continue
+		}
+		docNodes[n.Name] = n
+
+		if n.Name == "" || n.Address == "" || n.Port < 1 || n.Port > 65535 {
+			op.change.Action = dto.InventoryActionCreate
+			op.change.Conflict = "节点名称、地址或端口无效"
+			continue
+		}
+		portRanges, reservedPorts, err := normalizePortPool(n.PortRanges, n.ReservedPorts)
+		if err != nil {
+			op.change.Action = dto.InventoryActionCreate
+			op.change.Conflict = "节点端口池格式无效"
+			continue
+		}
+		n.PortRanges, n.ReservedPorts = portRanges, reservedPorts
+
+		matches := existingNodes[n.Name]
+		switch len(matches) {
+		case 0:
+			op.change.Action = dto.InventoryActionCreate
+		case 1:
+			op.existing = matches[0]
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Fields = diffFields(map[string][2]any{
+				"address":        {matches[0].Address, n.Address},
+				"port":           {matches[0].Port, n.Port},
+				"username":       {matches[0].Username, n.Username},
+				"remark":         {matches[0].Remark, n.Remark},
+				"port_ranges":    {matches[0].PortRanges, n.PortRanges},
+				"reserved_ports": {matches[0].ReservedPorts, n.ReservedPorts},
+			})
+			// 密码省略时保留原值
+			if n.Password != "" && n.Password != matches[0].Password {
+				op.change.Fields = append(op.change.Fields, "password")
+			}
+		default:
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "数据库中存在多个同名节点"
+		}
+	}
+
+	// ---------- 隧道 ----------
+	for i := range inv.Tunnels {
+		t := &inv.Tunnels[i]
+		op := &tunnelOp{desired: t, change: dto.InventoryChange{ResourceType: model.ResourceTypeTunnel, Name: t.Name}}
+		d.tunnels = append(d.tunnels, op)
+
+		if _, dup := docTunnels[t.Name]; dup {
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "清单中存在同名隧道"
+			continue
+		}
+		docTunnels[t.Name] = t
+
+		matches := existingTunnels[t.Name]
+
+		// 传输参数：省略的 KCP 密钥沿用现有隧道的密钥
+		var desiredOptions dto.TunnelOptions
+		if t.Options != nil {
+			desiredOptions = *t.Options
+		}
+		if kcp := desiredOptions.KCP; kcp != nil && kcp.Key == "" && len(matches) == 1 && matches[0].Options.KCP != nil {
+			filled := *kcp
+			filled.Key = matches[0].Options.KCP.Key
+			desiredOptions.KCP = &filled
+		}
+		options, optionsErr := normalizeTunnelOptions(t.Protocol, &desiredOptions)
+		op.options = options
+
+		switch len(matches) {
+		case 0:
+			op.change.Action = dto.InventoryActionCreate
+		case 1:
+			op.existing = matches[0]
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Fields = diffFields(map[string][2]any{
+				"entry_node": {state.nodeName(matches[0].EntryNodeID), t.EntryNode},
+				"exit_node":  {state.nodeName(matches[0].ExitNodeID), t.ExitNode},
+				"protocol":   {matches[0].Protocol, t.Protocol},
+				"relay_port": {matches[0].RelayPort, t.RelayPort},
+				"remark":     {matches[0].Remark, t.Remark},
+				"options":    {matches[0].Options, options},
+
+				"extra_exit_nodes": {strings.Join(state.extraExitNames(matches[0]), ","), strings.Join(t.ExtraExitNodes, ",")},
+				"strategy":         {normalizeStrategy(matches[0].Strategy), normalizeStrategy(t.Strategy)},
+			})
+			if len(op.change.Fields) > 0 && matches[0].Status == model.TunnelStatusRunning {
+				op.change.Conflict = "隧道正在运行中，请先停止"
+			}
+		default:
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "数据库中存在多个同名隧道"
+		}
+		if op.change.Conflict != "" {
+			continue
+		}
+
+		switch {
+		case docNodes[t.EntryNode] == nil:
+			op.change.Conflict = fmt.Sprintf("入口节点 %q 不在清单中", t.EntryNode)
+		case docNodes[t.ExitNode] == nil:
+			op.change.Conflict = fmt.Sprintf("出口节点 %q 不在清单中", t.ExitNode)
+		case t.EntryNode == t.ExitNode:
+			op.change.Conflict = "入口和出口节点不能相同"
+		case t.RelayPort < 1 || t.RelayPort > 65535:
+			op.change.Conflict = "Relay 端口无效"
+		case optionsErr != nil:
+			op.change.Conflict = optionsErr.Error()
+		case !slices.Contains([]string{"", "round", "rand", "fifo"}, t.Strategy):
+			op.change.Conflict = "出口选择策略无效，可选 round、rand、fifo"
+		}
+		if op.change.Conflict != "" {
+			continue
+		}
+
+		seen := []string{t.EntryNode, t.ExitNode}
+		for _, name := range t.ExtraExitNodes {
+			switch {
+			case docNodes[name] == nil:
+				op.change.Conflict = fmt.Sprintf("附加出口节点 %q 不在清单中", name)
+			case slices.Contains(seen, name):
+				op.change.Conflict = fmt.Sprintf("附加出口节点 %q 与入口或其他出口重复", name)
+			}
+			if op.change.Conflict != "" {
+				break
+			}
+			seen = append(seen, name)
+		}
+	}
+
+	// ---------- IP 列表 ----------
+	for i := range inv.IPLists {
+		l := &inv.IPLists[i]
+		op := &ipListOp{desired: l, change: dto.InventoryChange{ResourceType: model.ResourceTypeIPList, Name: l.Name}}
+		d.ipLists = append(d.ipLists, op)
+
+		if _, dup := docIPLists[l.Name]; dup {
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "清单中存在同名 IP 列表"
+			continue
+		}
+		docIPLists[l.Name] = l
+
+		// IP 列表名称唯一，不会有多个同名记录
+		op.existing = existingIPLists[l.Name]
+		op.change.Action = dto.InventoryActionCreate
+		if op.existing != nil {
+			op.change.Action = dto.InventoryActionUpdate
+		}
+		entries, err := normalizeIPEntries(l.Entries)
+		switch {
+		case l.Name == "" || len(l.Name) > 100:
+			op.change.Conflict = "IP 列表名称无效"
+			continue
+		case err != nil:
+			op.change.Conflict = errors.ErrIPListEntryInvalid.Error()
+			continue
+		}
+		op.entries = entries
+		if op.existing != nil {
+			op.change.Fields = diffFields(map[string][2]any{
+				"entries": {normalizeTargets(op.existing.Entries), normalizeTargets(entries)},
+				"remark":  {op.existing.Remark, l.Remark},
+			})
+		}
+	}
+
+	// ---------- 规则 ----------
+	// 入口节点名称:端口 -> 规则名称，用于检测端口冲突；反向代理规则共用的端口另按域名记录
+	portOwners := make(map[string]string)
+	sharedPorts := make(map[string]bool)
+	hostOwners := make(map[string]string)
+	for i := range inv.Rules {
+		r := &inv.Rules[i]
+		op := &ruleOp{desired: r, change: dto.InventoryChange{ResourceType: model.ResourceTypeRule, Name: r.Name}}
+		d.rules = append(d.rules, op)
+
+		if _, dup := docRules[r.Name]; dup {
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "清单中存在同名规则"
+			continue
+		}
+		docRules[r.Name] = r
+
+		// 规范化端口范围，便于与现有规则比较
+		var portRangeErr error
+		r.ListenPortEnd, r.PortMapping, portRangeErr = normalizePortRange(r.ListenPort, r.ListenPortEnd, r.PortMapping, r.Targets)
+		r.Protocol = string(ruleProtocol(r.Protocol))
+
+		matches := existingRules[r.Name]
+
+		// 代理配置：省略的用户密码沿用现有规则中同名用户的密码
+		if len(matches) == 1 {
+			fillProxyPasswords(r.ProxyUsers, matches[0].ProxyUsers)
+		}
+		proxyType, proxyUsers, proxyErr := normalizeProxy(model.RuleType(r.Type), r.ProxyType, r.ProxyUsers, r.ListenPortEnd)
+		if proxyErr == nil {
+			r.ProxyType, op.proxyUsers = proxyType, proxyUsers
+		}
+		if model.RuleType(r.Type) == model.RuleTypeProxy {
+			r.Protocol, r.Targets, r.Strategy = string(model.RuleProtocolTCP), nil, ""
+		}
+		host, reverseErr := normalizeReverse(model.RuleType(r.Type), r.Host, r.ListenPortEnd)
+		if reverseErr == nil {
+			r.Host = host
+		}
+		if model.RuleType(r.Type) == model.RuleTypeReverse {
+			r.Protocol = string(model.RuleProtocolTCP)
+		}
+		targetOptions, targetOptionsErr := normalizeTargetOptions(model.RuleType(r.Type), normalizeTargets(r.Targets), r.TargetOptions)
+		if targetOptionsErr == nil {
+			op.targetOptions = targetOptions
+		}
+		var sourcesErr error
+		if op.allowSources, sourcesErr = normalizeIPEntries(r.AllowSources); sourcesErr == nil {
+			op.denySources, sourcesErr = normalizeIPEntries(r.DenySources)
+		}
+		r.AllowLists, r.DenyLists = normalizeListNames(r.AllowLists), normalizeListNames(r.DenyLists)
+
+		switch len(matches) {
+		case 0:
+			op.change.Action = dto.InventoryActionCreate
+		case 1:
+			op.existing = matches[0]
+			op.change.Action = dto.InventoryActionUpdate
+			var nodeName, tunnelName string
+			if matches[0].NodeID != nil {
+				nodeName = state.nodeName(*matches[0].NodeID)
+			}
+			if matches[0].TunnelID != nil {
+				tunnelName = state.tunnelName(*matches[0].TunnelID)
+			}
+			op.change.Fields = diffFields(map[string][2]any{
+				"type":            {string(matches[0].Type), r.Type},
+				"node":            {nodeName, r.Node},
+				"tunnel":          {tunnelName, r.Tunnel},
+				"listen_port":     {matches[0].ListenPort, r.ListenPort},
+				"protocol":        {string(ruleProtocol(string(matches[0].Protocol))), r.Protocol},
+				"listen_port_end": {matches[0].ListenPortEnd, r.ListenPortEnd},
+				"port_mapping":    {matches[0].PortMapping, r.PortMapping},
+				"targets":         {normalizeTargets(matches[0].Targets), normalizeTargets(r.Targets)},
+				"strategy":        {normalizeStrategy(matches[0].Strategy), normalizeStrategy(r.Strategy)},
+				"enable_tls":      {matches[0].EnableTLS, r.EnableTLS},
+				"remark":          {matches[0].Remark, r.Remark},
+				"proxy_type":      {matches[0].ProxyType, r.ProxyType},
+				"proxy_users":     {normalizeProxyUsers(matches[0].ProxyUsers), normalizeProxyUsers(op.proxyUsers)},
+				"host":            {matches[0].Host, r.Host},
+				"max_fails":       {matches[0].MaxFails, r.MaxFails},
+				"fail_timeout":    {matches[0].FailTimeout, r.FailTimeout},
+				"target_options":  {normalizeTargetOptionList(matches[0].TargetOptions), normalizeTargetOptionList(op.targetOptions)},
+				"allow_sources":   {normalizeTargets(matches[0].AllowSources), normalizeTargets(op.allowSources)},
+				"deny_sources":    {normalizeTargets(matches[0].DenySources), normalizeTargets(op.denySources)},
+				"allow_lists":     {normalizeTargets(state.ipListNames(matches[0].AllowLists)), normalizeTargets(r.AllowLists)},
+				"deny_lists":      {normalizeTargets(state.ipListNames(matches[0].DenyLists)), normalizeTargets(r.DenyLists)},
+			})
+			if len(op.change.Fields) > 0 && matches[0].Status == model.RuleStatusRunning {
+				op.change.Conflict = "规则正在运行中，请先停止"
+			}
+		default:
+			op.change.Action = dto.InventoryActionUpdate
+			op.change.Conflict = "数据库中存在多个同名规则"
+		}
+		if op.change.Conflict != "" {
+			continue
+		}
+
+		var entryNode string
+		switch model.RuleType(r.Type) {
+		case model.RuleTypeForward:
+			if docNodes[r.Node] == nil {
+				op.change.Conflict = fmt.Sprintf("节点 %q 不在清单中", r.Node)
+				continue
+			}
+			entryNode = r.Node
+		case model.RuleTypeTunnel:
+			if docTunnels[r.Tunnel] == nil {
+				op.change.Conflict = fmt.Sprintf("隧道 %q 不在清单中", r.Tunnel)
+				continue
+			}
+			entryNode = docTunnels[r.Tunnel].EntryNode
+		case model.RuleTypeProxy, model.RuleTypeReverse:
+			switch {
+			case (r.Node == "") == (r.Tunnel == ""):
+				op.change.Conflict = "代理、反向代理规则需指定节点或隧道（二选一）"
+				continue
+			case r.Tunnel != "" && docTunnels[r.Tunnel] == nil:
+				op.change.Conflict = fmt.Sprintf("隧道 %q 不在清单中", r.Tunnel)
+				continue
+			case r.Node != "" && docNodes[r.Node] == nil:
+				op.change.Conflict = fmt.Sprintf("节点 %q 不在清单中", r.Node)
+				continue
+			}
+			entryNode = r.Node
+			if r.Tunnel != "" {
+				entryNode = docTunnels[r.Tunnel].EntryNode
+			}
+		default:
+			op.change.Conflict = "无效的规则类型"
+			continue
+		}
+
+		if r.ListenPort < 1 || r.ListenPort > 65535 {
+			op.change.Conflict = "监听端口无效"
+			continue
+		}
+		if portRangeErr != nil {
+			op.change.Conflict = portRangeErr.Error()
+			continue
+		}
+		if proxyErr != nil {
+			op.change.Conflict = proxyErr.Error()
+			continue
+		}
+		if reverseErr != nil {
+			op.change.Conflict = reverseErr.Error()
+			continue
+		}
+		if targetOptionsErr != nil {
+			op.change.Conflict = targetOptionsErr.Error()
+			continue
+		}
+		if r.MaxFails < 0 || r.MaxFails > 100 || r.FailTimeout < 0 || r.FailTimeout > 86400 {
+			op.change.Conflict = "故障转移设置无效：最大失败次数为 1~100，失败超时为 1~86400 秒"
+			continue
+		}
+		if sourcesErr != nil {
+			op.change.Conflict = errors.ErrRuleSourcesInvalid.Error()
+			continue
+		}
+		for _, name := range append(slices.Clone(r.AllowLists), r.DenyLists...) {
+			if docIPLists[name] == nil {
+				op.change.Conflict = fmt.Sprintf("IP 列表 %q 不在清单中", name)
+				break
+			}
+		}
+		if op.change.Conflict != "" {
+			continue
+		}
+		if model.RuleType(r.Type) == model.RuleTypeReverse && len(op.allowSources)+len(op.denySources)+len(r.AllowLists)+len(r.DenyLists) > 0 {
+			op.change.Conflict = errors.ErrReverseAdmission.Error()
+			continue
+		}
+		switch model.RuleProtocol(r.Protocol) {
+		case model.RuleProtocolTCP, model.RuleProtocolUDP, model.RuleProtocolBoth:
+		default:
+			op.change.Conflict = "无效的转发协议"
+			continue
+		}
+		ports := (&model.GostRule{ListenPort: r.ListenPort, ListenPortEnd: r.ListenPortEnd}).ListenPorts()
+		for _, port := range ports {
+			key := fmt.Sprintf("%s:%d", entryNode, port)
+			owner, ok := portOwners[key]
+			if !ok {
+				continue
+			}
+			// 反向代理规则之间共用端口，域名不能重复
+			if r.Host != "" && sharedPorts[key] {
+				if owner, ok = hostOwners[key+"/"+r.Host]; !ok {
+					continue
+				}
+				op.change.Conflict = fmt.Sprintf("端口 %d 上的域名 %s 与规则 %q 冲突", port, r.Host, owner)
+				break
+			}
+			op.change.Conflict = fmt.Sprintf("端口 %d 与规则 %q 冲突", port, owner)
+			break
+		}
+		if op.change.Conflict != "" {
+			continue
+		}
+		for _, port := range ports {
+			key := fmt.Sprintf("%s:%d", entryNode, port)
+			if _, ok := portOwners[key]; !ok {
+				portOwners[key] = r.Name
+				sharedPorts[key] = r.Host != ""
+			}
+			if r.Host != "" {
+				hostOwners[key+"/"+r.Host] = r.Name
+			}
+		}
+	}
+
+	// ---------- 删除（prune） ----------
+	// 删除顺序：规则 -> 隧道 -> 节点，计划中按资源类型分组展示
+	for i := range state.rules {
+		r := &state.rules[i]
+		if docRules[r.Name] != nil {
+			continue
+		}
+		if !prune {
+			// 保留的规则仍占用端口
+			entryNode := ""
+			if r.TunnelID != nil && r.Tunnel != nil {
+				entryNode = state.nodeName(r.Tunnel.EntryNodeID)
+			} else if r.NodeID != nil {
+				entryNode = state.nodeName(*r.NodeID)
+			}
+			for _, port := range r.ListenPorts() {
+				key := fmt.Sprintf("%s:%d", entryNode, port)
+				owner, ok := portOwners[key]
+				if ok && r.Type == model.RuleTypeReverse && sharedPorts[key] {
+					owner, ok = hostOwners[key+"/"+r.Host]
+				}
+				if !ok {
+					continue
+				}
+				for _, op := range d.rules {
+					if op.desired.Name == owner && op.change.Conflict == "" {
+						op.change.Conflict = fmt.Sprintf("端口 %d 与未纳入清单的规则 %q 冲突", port, r.Name)
+					}
+				}
+			}
+			continue
+		}
+		op := &ruleOp{existing: r, change: dto.InventoryChange{
+			Action:       dto.InventoryActionDelete,
+			ResourceType: model.ResourceTypeRule,
+			Name:         r.Name,
+		}}
+		if r.Status == model.RuleStatusRunning {
+			op.change.Conflict = "规则正在运行中，请先停止"
+		}
+		d.rules = append(d.rules, op)
+	}
+
+	for i := range state.tunnels {
+		t := &state.tunnels[i]
+		if docTunnels[t.Name] != nil {
+			continue
+		}
+		if !prune {
+			continue
+		}
+		op := &tunnelOp{existing: t, change: dto.InventoryChange{
+			Action:       dto.InventoryActionDelete,
+			ResourceType: model.ResourceTypeTunnel,
+			Name:         t.Name,
+		}}
+		if t.Status == model.TunnelStatusRunning {
+			op.change.Conflict = "隧道正在运行中，请先停止"
+		}
+		d.tunnels = append(d.tunnels, op)
+	}
+
+	if prune {
+		// 规则已全部纳入清单，清单中的规则只引用清单中的 IP 列表
+		for i := range state.ipLists {
+			l := &state.ipLists[i]
+			if docIPLists[l.Name] != nil {
+				continue
+			}
+			d.ipLists = append(d.ipLists, &ipListOp{existing: l, change: dto.InventoryChange{
+				Action:       dto.InventoryActionDelete,
+				ResourceType: model.ResourceTypeIPList,
+				Name:         l.Name,
+			}})
+		}
+
+		for i := range state.nodes {
+			n := &state.nodes[i]
+			if docNodes[n.Name] != nil {
+				continue
+			}
+			d.nodes = append(d.nodes, &nodeOp{existing: n, change: dto.InventoryChange{
+				Action:       dto.InventoryActionDelete,
+				ResourceType: model.ResourceTypeNode,
+				Name:         n.Name,
+			}})
+		}
+	}
+
+	// 去掉无实际变更的更新项
+	d.nodes = filterOps(d.nodes, func(op *nodeOp) dto.InventoryChange { return op.change })
+	d.tunnels = filterOps(d.tunnels, func(op *tunnelOp) dto.InventoryChange { return op.change })
+	d.ipLists = filterOps(d.ipLists, func(op *ipListOp) dto.InventoryChange { return op.change })
+	d.rules = filterOps(d.rules, func(op *ruleOp) dto.InventoryChange { return op.change })
+
+	return d, nil
+}
+
+// filterOps 过滤掉没有字段变化且无冲突的更新项
+func filterOps[T any](ops []T, change func(T) dto.InventoryChange) []T {
+	result := make([]T, 0, len(ops))
+	for _, op := range ops {
+		c := change(op)
+		if c.Action == dto.InventoryActionUpdate && len(c.Fields) == 0 && c.Conflict == "" {
+			continue
+		}
+		result = append(result, op)
+	}
+	return result
+}
+
+// diffFields 比较字段，返回发生变化的字段名（已排序）
+func diffFields(fields map[string][2]any) []string {
+	var changed []string
+	for name, pair := range fields {
+		if !reflect.DeepEqual(pair[0], pair[1]) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// normalizeTargets 规范化目标列表，nil 与空列表视为相同
+func normalizeTargets(targets []string) []string {
+	result := make([]string, 0, len(targets))
+	for _, t := range targets {
+		if t = strings.TrimSpace(t); t != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// normalizeListNames 规范化引用的 IP 列表名称（去除空白与重复）
+func normalizeListNames(names []string) []string {
+	var result []string
+	for _, name := range normalizeTargets(names) {
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// fillProxyPasswords 省略密码的代理用户沿用现有同名用户的密码
+func fillProxyPasswords(users []dto.ProxyUser, existing model.ProxyUserList) {
+	for i := range users {
+		if users[i].Password != "" {
+			continue
+		}
+		for _, user := range existing {
+			if user.Username == users[i].Username {
+				users[i].Password = user.Password
+				break
+			}
+		}
+	}
+}
+
+// normalizeProxyUsers 规范化代理用户列表，nil 与空列表视为相同
+func normalizeProxyUsers(users model.ProxyUserList) model.ProxyUserList {
+	return append(model.ProxyUserList{}, users...)
+}
+
+// normalizeTargetOptionList 规范化目标设置，nil 与空列表视为相同
+func normalizeTargetOptionList(options model.TargetOptionList) model.TargetOptionList {
+	return append(model.TargetOptionList{}, options...)
+}
+
+// sameUintPtr 两个可空 ID 是否相同
+func sameUintPtr(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// normalizeStrategy 规范化负载均衡策略，空值与默认值 round 视为相同
+func normalizeStrategy(strategy string) string {
+	if strategy == "" {
+		return "round"
+	}
+	return strategy
+}
+
+// extraExitIDs 清单隧道附加出口对应的节点 ID
+func extraExitIDs(t *dto.InventoryTunnel, nodeIDs map[string]uint) []uint {
+	ids := make([]uint, 0, len(t.ExtraExitNodes))
+	for _, name := range t.ExtraExitNodes {
+		ids = append(ids, nodeIDs[name])
+	}
+	return ids
+}
+
+// ==================== 执行导入 ====================
+
+// apply 在事务中执行差异
+func (d *inventoryDiff) apply(tx *gorm.DB, actor *LogAction) error {
+	nodeRepo := repository.NewNodeRepository(tx)
+	tunnelRepo := repository.NewTunnelRepository(tx)
+	ruleRepo := repository.NewRuleRepository(tx)
+	ipListRepo := repository.NewIPListRepository(tx)
+	logService := NewLogService(tx)
+
+	record := func(action, resourceType string, id uint, details string) {
+		logService.RecordAction(&LogAction{
+			UserID:       actor.UserID,
+			Username:     actor.Username,
+			Action:       action,
+			ResourceType: resourceType,
+			ResourceID:   id,
+			Details:      details,
+			IP:           actor.IP,
+			UserAgent:    actor.UserAgent,
+		})
+	}
+
+	// 1. 删除规则
+	for _, op := range d.rules {
+		if op.change.Action != dto.InventoryActionDelete {
+			continue
+		}
+		if err := ruleRepo.Delete(op.existing.ID); err != nil {
+			return err
+		}
+		record(model.ActionDelete, model.ResourceTypeRule, op.existing.ID, fmt.Sprintf("导入清单删除规则: %s", op.existing.Name))
+	}
+
+	// 2. 删除隧道
+	for _, op := range d.tunnels {
+		if op.change.Action != dto.InventoryActionDelete {
+			continue
+		}
+		if err := tunnelRepo.Delete(op.existing.ID); err != nil {
+			return err
+		}
+		record(model.ActionDelete, model.ResourceTypeTunnel, op.existing.ID, fmt.Sprintf("导入清单删除隧道: %s", op.existing.Name))
+	}
+
+	// 3. 创建/更新节点
+	nodeIDs := make(map[string]uint)
+	for _, op := range d.nodes {
+		switch op.change.Action {
+		case dto.InventoryActionCreate:
+			node := &model.GostNode{
+				Name:     op.desired.Name,
+				Address:  op.desired.Address,
+				Port:     op.desired.Port,
+				Username: op.desired.Username,
+				Password: op.desired.Password,
+				Remark:   op.desired.Remark,
+				Status:   model.NodeStatusOffline,
+
+				PortRanges:    op.desired.PortRanges,
+				ReservedPorts: op.desired.ReservedPorts,
+			}
+			if err := nodeRepo.Create(node); err != nil {
+				return err
+			}
+			nodeIDs[node.Name] = node.ID
+			record(model.ActionCreate, model.ResourceTypeNode, node.ID, fmt.Sprintf("导入清单创建节点: %s", node.Name))
+		case dto.InventoryActionUpdate:
+			node := op.existing
+			node.Address = op.desired.Address
+			node.Port = op.desired.Port
+			node.Username = op.desired.Username
+			if op.desired.Password != "" {
+				node.Password = op.desired.Password
+			}
+			node.Remark = op.desired.Remark
+			node.PortRanges = op.desired.PortRanges
+			node.ReservedPorts = op.desired.ReservedPorts
+			if err := nodeRepo.Update(node); err != nil {
+				return err
+			}
+			record(model.ActionUpdate, model.ResourceTypeNode, node.ID,
+				fmt.Sprintf("导入清单更新节点: %s (%s)", node.Name, strings.Join(op.change.Fields, ", ")))
+		}
+	}
+
+	// 名称 -> ID 映射需包含未变更的资源
+	allNodes, _, err := nodeRepo.List(nil)
+	if err != nil {
+		return err
+	}
+	for _, n := range allNodes {
+		if _, ok := nodeIDs[n.Name]; !ok {
+			nodeIDs[n.Name] = n.ID
+		}
+	}
+
+	// 4. 创建/更新隧道
+	tunnelIDs := make(map[string]uint)
+	for _, op := range d.tunnels {
+		switch op.change.Action {
+		case dto.InventoryActionCreate:
+			tunnel := &model.GostTunnel{
+				Name:        op.desired.Name,
+				EntryNodeID: nodeIDs[op.desired.EntryNode],
+				ExitNodeID:  nodeIDs[op.desired.ExitNode],
+				Protocol:    op.desired.Protocol,
+				RelayPort:   op.desired.RelayPort,
+				Remark:      op.desired.Remark,
+				Status:      model.TunnelStatusStopped,
+				Options:     op.options,
+				Strategy:    normalizeStrategy(op.desired.Strategy),
+			}
+			if err = tunnelRepo.Create(tunnel); err != nil {
+				return err
+			}
+			if err = tunnelRepo.ReplaceExtraExits(tunnel.ID, extraExitIDs(op.desired, nodeIDs)); err != nil {
+				return err
+			}
+			tunnelIDs[tunnel.Name] = tunnel.ID
+			record(model.ActionCreate, model.ResourceTypeTunnel, tunnel.ID, fmt.Sprintf("导入清单创建隧道: %s", tunnel.Name))
+		case dto.InventoryActionUpdate:
+			tunnel := op.existing
+			tunnel.EntryNodeID = nodeIDs[op.desired.EntryNode]
+			tunnel.ExitNodeID = nodeIDs[op.desired.ExitNode]
+			tunnel.Protocol = op.desired.Protocol
+			tunnel.RelayPort = op.desired.RelayPort
+			tunnel.Remark = op.desired.Remark
+			tunnel.Options = op.options
+			tunnel.Strategy = normalizeStrategy(op.desired.Strategy)
+			// 避免 Save 时级联更新预加载的关联节点
+			tunnel.EntryNode, tunnel.ExitNode, tunnel.ExtraExits = nil, nil, nil
+			if err = tunnelRepo.Update(tunnel); err != nil {
+				return err
+			}
+			if err = tunnelRepo.ReplaceExtraExits(tunnel.ID, extraExitIDs(op.desired, nodeIDs)); err != nil {
+				return err
+			}
+			record(model.ActionUpdate, model.ResourceTypeTunnel, tunnel.ID,
+				fmt.Sprintf("导入清单更新隧道: %s (%s)", tunnel.Name, strings.Join(op.change.Fields, ", ")))
+		}
+	}
+
+	allTunnels, _, err := tunnelRepo.List(nil)
+	if err != nil {
+		return err
+	}
+	for _, t := range allTunnels {
+		if _, ok := tunnelIDs[t.Name]; !ok {
+			tunnelIDs[t.Name] = t.ID
+		}
+	}
+
+	// 5. 创建/更新 IP 列表
+	for _, op := range d.ipLists {
+		switch op.change.Action {
+		case dto.InventoryActionCreate:
+			list := &model.IPList{Name: op.desired.Name, Entries: op.entries, Remark: op.desired.Remark}
+			if err = ipListRepo.Create(list); err != nil {
+				return err
+			}
+			record(model.ActionCreate, model.ResourceTypeIPList, list.ID, fmt.Sprintf("导入清单创建 IP 列表: %s", list.Name))
+		case dto.InventoryActionUpdate:
+			list := op.existing
+			list.Entries = op.entries
+			list.Remark = op.desired.Remark
+			if err = ipListRepo.Update(list); err != nil {
+				return err
+			}
+			record(model.ActionUpdate, model.ResourceTypeIPList, list.ID,
+				fmt.Sprintf("导入清单更新 IP 列表: %s (%s)", list.Name, strings.Join(op.change.Fields, ", ")))
+		}
+	}
+
+	allIPLists, err := ipListRepo.List()
+	if err != nil {
+		return err
+	}
+	ipListIDs := make(map[string]uint, len(allIPLists))
+	for _, l := range allIPLists {
+		ipListIDs[l.Name] = l.ID
+	}
+	listIDs := func(names []string) model.IDList {
+		var ids model.IDList
+		for _, name := range names {
+			ids = append(ids, ipListIDs[name])
+		}
+		return ids
+	}
+
+	// 6. 创建/更新规则
+	for _, op := range d.rules {
+		if op.change.Action == dto.InventoryActionDelete {
+			continue
+		}
+
+		var nodeID, tunnelID *uint
+		if model.RuleType(op.desired.Type) == model.RuleTypeTunnel || op.desired.Tunnel != "" {
+			id := tunnelIDs[op.desired.Tunnel]
+			tunnelID = &id
+		} else {
+			id := nodeIDs[op.desired.Node]
+			nodeID = &id
+		}
+
+		switch op.change.Action {
+		case dto.InventoryActionCreate:
+			rule := &model.GostRule{
+				NodeID:     nodeID,
+				TunnelID:   tunnelID,
+				Name:       op.desired.Name,
+				Type:       model.RuleType(op.desired.Type),
+				ListenPort: op.desired.ListenPort,
+				Targets:    normalizeTargets(op.desired.Targets),
+
+				ListenPortEnd: op.desired.ListenPortEnd,
+				PortMapping:   op.desired.PortMapping,
+				Protocol:      model.RuleProtocol(op.desired.Protocol),
+				Strategy:      normalizeStrategy(op.desired.Strategy),
+				EnableTLS:     op.desired.EnableTLS,
+				Remark:        op.desired.Remark,
+				Status:        model.RuleStatusStopped,
+				MaxFails:      op.desired.MaxFails,
+				FailTimeout:   op.desired.FailTimeout,
+				TargetOptions: op.targetOptions,
+
+				AllowSources: op.allowSources,
+				DenySources:  op.denySources,
+				AllowLists:   listIDs(op.desired.AllowLists),
+				DenyLists:    listIDs(op.desired.DenyLists),
+			}
+			setRuleProxy(rule, op.desired.ProxyType, op.proxyUsers)
+			setRuleReverse(rule, op.desired.Host)
+			if err = ruleRepo.Create(rule); err != nil {
+				return err
+			}
+			record(model.ActionCreate, model.ResourceTypeRule, rule.ID, fmt.Sprintf("导入清单创建规则: %s (类型: %s)", rule.Name, rule.Type))
+		case dto.InventoryActionUpdate:
+			rule := op.existing
+			rule.Type = model.RuleType(op.desired.Type)
+			rule.ListenPort = op.desired.ListenPort
+			rule.ListenPortEnd = op.desired.ListenPortEnd
+			rule.PortMapping = op.desired.PortMapping
+			rule.Protocol = model.RuleProtocol(op.desired.Protocol)
+			rule.Targets = normalizeTargets(op.desired.Targets)
+			rule.Strategy = normalizeStrategy(op.desired.Strategy)
+			rule.EnableTLS = op.desired.EnableTLS
+			rule.Remark = op.desired.Remark
+			rule.MaxFails = op.desired.MaxFails
+			rule.FailTimeout = op.desired.FailTimeout
+			rule.TargetOptions = op.targetOptions
+			rule.AllowSources, rule.DenySources = op.allowSources, op.denySources
+			rule.AllowLists, rule.DenyLists = listIDs(op.desired.AllowLists), listIDs(op.desired.DenyLists)
+			setRuleProxy(rule, op.desired.ProxyType, op.proxyUsers)
+			setRuleReverse(rule, op.desired.Host)
+			// 更换入口后内部端口在新入口节点上首次启动时重新分配
+			if !sameUintPtr(rule.NodeID, nodeID) || !sameUintPtr(rule.TunnelID, tunnelID) {
+				rule.InnerPort = 0
+			}
+			rule.NodeID = nodeID
+			rule.TunnelID = tunnelID
+			rule.Node, rule.Tunnel = nil, nil
+			if err = ruleRepo.Update(rule); err != nil {
+				return err
+			}
+			record(model.ActionUpdate, model.ResourceTypeRule, rule.ID,
+				fmt.Sprintf("导入清单更新规则: %s (%s)", rule.Name, strings.Join(op.change.Fields, ", ")))
+		}
+	}
+
+	// 7. 删除 IP 列表（此时已无规则引用）
+	for _, op := range d.ipLists {
+		if op.change.Action != dto.InventoryActionDelete {
+			continue
+		}
+		if err = ipListRepo.Delete(op.existing.ID); err != nil {
+			return err
+		}
+		record(model.ActionDelete, model.ResourceTypeIPList, op.existing.ID, fmt.Sprintf("导入清单删除 IP 列表: %s", op.existing.Name))
+	}
+
+	// 8. 删除节点（此时已无规则/隧道引用）
+	for _, op := range d.nodes {
+		if op.change.Action != dto.InventoryActionDelete {
+			continue
+		}
+		var refs int64
+		if err = tx.Model(&model.GostRule{}).Where("node_id = ?", op.existing.ID).Count(&refs).Error; err != nil {
+			return err
+		}
+		if refs == 0 {
+			if err = tx.Model(&model.GostTunnel{}).Where("entry_node_id = ? OR exit_node_id = ? OR id IN (SELECT tunnel_id FROM tunnel_exits WHERE node_id = ?)", op.existing.ID, op.existing.ID, op.existing.ID).Count(&refs).Error; err != nil {
+				return err
+			}
+		}
+		if refs > 0 {
+			return errors.ErrNodeHasRules
+		}
+		if err = nodeRepo.Delete(op.existing.ID); err != nil {
+			return err
+		}
+		record(model.ActionDelete, model.ResourceTypeNode, op.existing.ID, fmt.Sprintf("导入清单删除节点: %s", op.existing.Name))
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	stderrors "errors"
+	"slices"
+	"testing"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/secret"
+)
+
+// findChange 按资源类型和名称查找计划中的变更
+func findChange(plan *dto.InventoryPlan, resourceType, name string) *dto.InventoryChange {
+	for i := range plan.Changes {
+		if plan.Changes[i].ResourceType == resourceType && plan.Changes[i].Name == name {
+			return &plan.Changes[i]
+		}
+	}
+	return nil
+}
+
+func TestInventoryPlanDiff(t *testing.T) {
+	db := newTestDB(t)
+	mustCreate(t, db,
+		&model.GostNode{Name: "hk", Address: "1.1.1.1", Port: 18080, Password: "old"},
+		&model.GostNode{Name: "sg", Address: "2.2.2.2", Port: 18080},
+		&model.GostNode{Name: "stale", Address: "3.3.3.3", Port: 18080},
+	)
+	s := NewInventoryService(db)
+
+	inv := &dto.Inventory{
+		Version: dto.InventoryVersion,
+		Nodes: []dto.InventoryNode{
+			{Name: "hk", Address: "1.1.1.9", Port: 18080, Remark: "香港"}, // 地址、备注变更，省略密码
+			{Name: "sg", Address: "2.2.2.2", Port: 18080},               // 无变更
+			{Name: "jp", Address: "4.4.4.4", Port: 18080},               // 新建
+			{Name: "bad", Address: "", Port: 18080},                     // 无效
+		},
+	}
+
+	plan, err := s.Plan(inv, "", false)
+	if err != nil {
+		t.Fatalf("Plan 失败: %v", err)
+	}
+
+	hk := findChange(plan, model.ResourceTypeNode, "hk")
+	if hk == nil || hk.Action != dto.InventoryActionUpdate {
+		t.Fatalf("hk 变更 = %+v，期望 update", hk)
+	}
+	slices.Sort(hk.Fields)
+	if !slices.Equal(hk.Fields, []string{"address", "remark"}) {
+		t.Errorf("hk 变更字段 = %v，期望 [address remark]（省略的密码保留原值）", hk.Fields)
+	}
+	if sg := findChange(plan, model.ResourceTypeNode, "sg"); sg != nil && len(sg.Fields) > 0 {
+		t.Errorf("sg 无变更，实际 %+v", sg)
+	}
+	if jp := findChange(plan, model.ResourceTypeNode, "jp"); jp == nil || jp.Action != dto.InventoryActionCreate {
+		t.Errorf("jp 变更 = %+v，期望 create", jp)
+	}
+	if bad := findChange(plan, model.ResourceTypeNode, "bad"); bad == nil || bad.Conflict == "" {
+		t.Errorf("bad 变更 = %+v，期望冲突", bad)
+	}
+	if plan.Conflicts != 1 {
+		t.Errorf("冲突数 = %d，期望 1", plan.Conflicts)
+	}
+	if findChange(plan, model.ResourceTypeNode, "stale") != nil {
+		t.Error("未开启 prune 时不应删除清单外的节点")
+	}
+
+	// prune 删除清单中不存在的资源
+	plan, err = s.Plan(inv, "", true)
+	if err != nil {
+		t.Fatalf("Plan(prune) 失败: %v", err)
+	}
+	if stale := findChange(plan, model.ResourceTypeNode, "stale"); stale == nil || stale.Action != dto.InventoryActionDelete {
+		t.Errorf("stale 变更 = %+v，期望 delete", stale)
+	}
+
+	// 只生成计划，不修改数据库
+	var node model.GostNode
+	if err = db.Where("name = ?", "hk").First(&node).Error; err != nil {
+		t.Fatal(err)
+	}
+	if node.Address != "1.1.1.1" {
+		t.Errorf("Plan 修改了数据库: address = %s", node.Address)
+	}
+}
+
+func TestInventoryPlanEncryptedSecrets(t *testing.T) {
+	db := newTestDB(t)
+	mustCreate(t, db, &model.GostNode{Name: "hk", Address: "1.1.1.1", Port: 18080, Password: "old"})
+	s := NewInventoryService(db)
+
+	encrypted, err := secret.EncryptString("new", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newInv := func() *dto.Inventory {
+		return &dto.Inventory{
+			Version: dto.InventoryVersion,
+			Nodes:   []dto.InventoryNode{{Name: "hk", Address: "1.1.1.1", Port: 18080, Password: encrypted}},
+		}
+	}
+
+	if _, err = s.Plan(newInv(), "", false); !stderrors.Is(err, errors.ErrInventoryPassphraseRequired) {
+		t.Errorf("缺少口令: err = %v，期望 ErrInventoryPassphraseRequired", err)
+	}
+	if _, err = s.Plan(newInv(), "wrong", false); !stderrors.Is(err, errors.ErrInventoryDecryptFailed) {
+		t.Errorf("口令错误: err = %v，期望 ErrInventoryDecryptFailed", err)
+	}
+
+	plan, err := s.Plan(newInv(), "pass", false)
+	if err != nil {
+		t.Fatalf("Plan 失败: %v", err)
+	}
+	hk := findChange(plan, model.ResourceTypeNode, "hk")
+	if hk == nil || !slices.Equal(hk.Fields, []string{"password"}) {
+		t.Errorf("hk 变更 = %+v，期望只更新 password", hk)
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// IPListService 命名 IP 列表服务
+// 列表本身只保存在面板中，规则启动时将引用列表的条目合并到规则的准入控制器
+type IPListService struct {
+	ipListRepo  *repository.IPListRepository
+	ruleRepo    *repository.RuleRepository
+	ruleService *RuleService
+	logService  *LogService
+}
+
+// NewIPListService 创建 IP 列表服务
+func NewIPListService(db *gorm.DB) *IPListService {
+	return &IPListService{
+		ipListRepo:  repository.NewIPListRepository(db),
+		ruleRepo:    repository.NewRuleRepository(db),
+		ruleService: NewRuleService(db),
+		logService:  NewLogService(db),
+	}
+}
+
+// List 获取全部 IP 列表
+func (s *IPListService) List() ([]model.IPList, error) {
+	return s.ipListRepo.List()
+}
+
+// GetByID 获取 IP 列表详情
+func (s *IPListService) GetByID(id uint) (*model.IPList, error) {
+	list, err := s.ipListRepo.FindByID(id)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrIPListNotFound
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// Create 创建 IP 列表
+func (s *IPListService) Create(req *dto.CreateIPListReq, userID uint, username, ip, userAgent string) (*model.IPList, error) {
+	exists, err := s.ipListRepo.ExistsByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.ErrIPListNameExists
+	}
+	entries, err := normalizeIPEntries(req.Entries)
+	if err != nil {
+		return nil, errors.ErrIPListEntryInvalid
+	}
+
+	list := &model.IPList{Name: req.Name, Entries: entries, Remark: req.Remark}
+	if err = s.ipListRepo.Create(list); err != nil {
+		return nil, err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionCreate,
+		model.ResourceTypeIPList,
+		list.ID,
+		fmt.Sprintf("创建 IP 列表: %s (%d 条)", list.Name, len(list.Entries)),
+		ip,
+		userAgent)
+
+	return list, nil
+}
+
+// Update 更新 IP 列表，并刷新引用它的运行中规则的准入控制器
+func (s *IPListService) Update(id uint, req *dto.UpdateIPListReq, userID uint, username, ip, userAgent string) (*model.IPList, error) {
+	list, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.ipListRepo.ExistsByName(req.Name, id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.ErrIPListNameExists
+	}
+	entries, err := normalizeIPEntries(req.Entries)
+	if err != nil {
+		return nil, errors.ErrIPListEntryInvalid
+	}
+
+	list.Name = req.Name
+	list.Entries = entries
+	list.Remark = req.Remark
+	if err = s.ipListRepo.Update(list); err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("更新 IP 列表: %s (%d 条)", list.Name, len(list.Entries))
+	refreshed, err := s.ruleService.RefreshAdmissions(list.ID)
+	if err != nil {
+		logger.Warnf("刷新规则准入控制器失败: %v", err)
+	} else if refreshed > 0 {
+		details += fmt.Sprintf("，已更新 %d 条运行中规则", refreshed)
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionUpdate,
+		model.ResourceTypeIPList,
+		list.ID,
+		details,
+		ip,
+		userAgent)
+
+	return list, nil
+}
+
+// Delete 删除 IP 列表，被规则引用时不能删除
+func (s *IPListService) Delete(id uint, userID uint, username, ip, userAgent string) error {
+	list, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	rules, _, err := s.ruleRepo.List(nil)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if slices.Contains(rule.AllowLists, id) || slices.Contains(rule.DenyLists, id) {
+			return errors.ErrIPListInUse
+		}
+	}
+
+	if err = s.ipListRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionDelete,
+		model.ResourceTypeIPList,
+		id,
+		fmt.Sprintf("删除 IP 列表: %s", list.Name),
+		ip,
+		userAgent)
+
+	return nil
+}
+
+// normalizeIPEntries 校验并规范化 IP / CIDR 条目（去重，CIDR 取网络地址）
+func normalizeIPEntries(entries []string) (model.StringList, error) {
+	var result model.StringList
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			entry = prefix.Masked().String()
+		} else {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, err
+			}
+			entry = addr.String()
+		}
+		if !slices.Contains(result, entry) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
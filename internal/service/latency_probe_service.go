@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bufio"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+	"gost-panel/internal/utils"
+	"gost-panel/pkg/gost"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 隧道出口不可探测时记录的失败原因，计入丢失率
+var (
+	errLatencyEntryOffline = stderrors.New("入口节点离线")
+	errLatencyExitOffline  = stderrors.New("出口节点离线")
+)
+
+// 延迟探测参数
+const (
+	latencyProbeInterval = 30 * time.Second   // 探测间隔
+	latencyTunnelEvery   = 5 * time.Minute    // 隧道出口探测间隔，每次需要在入口节点上创建临时服务
+	latencyDialTimeout   = 5 * time.Second    // 单次探测超时
+	latencyRetention     = 7 * 24 * time.Hour // 探测记录保留时间
+	latencyPruneInterval = time.Hour          // 清理过期记录的间隔
+	latencyErrorMaxLen   = 255                // 失败原因最大长度
+	latencyProbePrefix   = "latency-probe-"   // 隧道探测临时服务与 Chain 的名称前缀
+	latencyProbeRequest  = "HEAD / HTTP/1.0\r\n\r\n"
+)
+
+// LatencyProbeService 延迟探测服务
+// 定期从面板发起 TCP 连接，记录到各节点 API、运行中规则的转发目标的连接耗时；
+// 运行中的隧道以较低频率对每个出口单独探测：在入口节点上临时创建只经过该出口的转发服务，
+// 经隧道访问出口节点自身的 API 端口，以收到首个响应字节的耗时作为整条路径的延迟；
+// 入口或出口节点离线时记为丢失
+type LatencyProbeService struct {
+	latencyRepo *repository.LatencyRepository
+	nodeRepo    *repository.NodeRepository
+	ruleRepo    *repository.RuleRepository
+	tunnelRepo  *repository.TunnelRepository
+	portService *PortService
+	lastPrune   time.Time
+	lastTunnel  time.Time
+	ticker      *time.Ticker
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewLatencyProbeService 创建延迟探测服务
+func NewLatencyProbeService(db *gorm.DB) *LatencyProbeService {
+	return &LatencyProbeService{
+		latencyRepo: repository.NewLatencyRepository(db),
+		nodeRepo:    repository.NewNodeRepository(db),
+		ruleRepo:    repository.NewRuleRepository(db),
+		tunnelRepo:  repository.NewTunnelRepository(db),
+		portService: NewPortService(db),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start 启动定时探测（每 30 秒）
+func (s *LatencyProbeService) Start() {
+	s.stopChan = make(chan struct{})
+	s.ticker = time.NewTicker(latencyProbeInterval)
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		logger.Info("延迟探测服务已启动")
+
+		s.probeAll()
+
+		for {
+			select {
+			case <-s.ticker.C:
+				s.probeAll()
+			case <-s.stopChan:
+				logger.Info("延迟探测服务已停止")
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止探测
+func (s *LatencyProbeService) Stop() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// probeAll 探测全部路径并保存结果，等待本轮探测结束后返回
+func (s *LatencyProbeService) probeAll() {
+	nodes, _, err := s.nodeRepo.List(nil)
+	if err != nil {
+		logger.Errorf("获取节点列表失败: %v", err)
+		return
+	}
+	rules, _, err := s.ruleRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.RuleStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("获取运行中规则失败: %v", err)
+		return
+	}
+	tunnels, _, err := s.tunnelRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.TunnelStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("获取运行中隧道失败: %v", err)
+		return
+	}
+
+	var (
+		mu      sync.Mutex
+		samples []model.LatencySample
+		wg      sync.WaitGroup
+	)
+	record := func(sample model.LatencySample, elapsed time.Duration, err error) {
+		if err != nil {
+			sample.Error = truncateError(err)
+		} else {
+			sample.Success = true
+			sample.LatencyMs = float64(elapsed.Microseconds()) / 1000
+		}
+		mu.Lock()
+		samples = append(samples, sample)
+		mu.Unlock()
+	}
+	probe := func(sample model.LatencySample, measure func() (time.Duration, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			elapsed, err := measure()
+			record(sample, elapsed, err)
+		}()
+	}
+
+	// 面板到节点 API
+	nodesByID := make(map[uint]*model.GostNode, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		nodesByID[node.ID] = node
+		if node.Address == "" || node.Port == 0 {
+			continue
+		}
+		addr := net.JoinHostPort(node.Address, strconv.Itoa(node.Port))
+		probe(model.LatencySample{Path: model.LatencyPathNode, NodeID: node.ID, Target: addr}, func() (time.Duration, error) {
+			return dialLatency(addr)
+		})
+	}
+
+	// 面板到规则转发目标
+	for i := range rules {
+		rule := &rules[i]
+		if targetCheckSkipReason(rule) != "" {
+			continue
+		}
+		for _, target := range rule.Targets {
+			probe(model.LatencySample{Path: model.LatencyPathTarget, RuleID: rule.ID, Target: target}, func() (time.Duration, error) {
+				return dialLatency(target)
+			})
+		}
+	}
+
+	// 面板经隧道入口到各出口，同一隧道的出口依次探测，避免同时占用入口节点的多个端口
+	if time.Since(s.lastTunnel) < latencyTunnelEvery {
+		tunnels = nil
+	} else {
+		s.lastTunnel = time.Now()
+	}
+	for i := range tunnels {
+		tunnel := &tunnels[i]
+		entryNode := nodesByID[tunnel.EntryNodeID]
+		if entryNode == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, exitID := range tunnel.ExitNodeIDs() {
+				exitNode := nodesByID[exitID]
+				if exitNode == nil {
+					continue
+				}
+				sample := model.LatencySample{
+					Path:     model.LatencyPathTunnel,
+					NodeID:   exitID,
+					TunnelID: tunnel.ID,
+					Target:   fmt.Sprintf("%s -> %s", entryNode.Name, exitNode.Name),
+				}
+				switch {
+				case entryNode.Status != model.NodeStatusOnline || entryNode.Address == "":
+					record(sample, 0, errLatencyEntryOffline)
+				case exitNode.Status != model.NodeStatusOnline || exitNode.Address == "":
+					record(sample, 0, errLatencyExitOffline)
+				default:
+					elapsed, err := s.probeTunnel(tunnel, entryNode, exitNode)
+					record(sample, elapsed, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err = s.latencyRepo.CreateBatch(samples); err != nil {
+		logger.Errorf("保存延迟探测结果失败: %v", err)
+	}
+	s.prune()
+}
+
+// probeTunnel 在入口节点上临时创建只经过指定出口的转发服务，经隧道访问出口节点的 API 端口
+// 探测结束后删除临时服务与 Chain 并释放预留端口；名称固定，面板异常退出遗留的临时配置会在下一轮探测时被覆盖并删除
+func (s *LatencyProbeService) probeTunnel(tunnel *model.GostTunnel, entryNode, exitNode *model.GostNode) (time.Duration, error) {
+	name := fmt.Sprintf("%stunnel-%d-n%d", latencyProbePrefix, tunnel.ID, exitNode.ID)
+	client := utils.GetGostClient(entryNode)
+
+	// 只包含单个出口的 Chain，不使用隧道的选择器
+	single := *tunnel
+	single.ExtraExits = nil
+	chain := tunnelChain(&single, []*model.GostNode{exitNode})
+	chain.Name = name
+	if err := client.ApplyChain(chain); err != nil {
+		return 0, fmt.Errorf("创建探测 Chain 失败: %w", err)
+	}
+	defer func() {
+		if err := client.DeleteChain(name); err != nil {
+			logger.Warnf("删除隧道探测 Chain %s 失败: %v", name, err)
+		}
+	}()
+
+	// 分配后预留端口即释放分配锁，创建服务期间不阻塞规则、隧道的端口分配
+	portAllocMu.Lock()
+	port, err := s.portService.Allocate(entryNode, PortExclude{})
+	if err == nil {
+		reservePort(entryNode.ID, port, "隧道探测 "+name)
+	}
+	portAllocMu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("分配探测端口失败: %w", err)
+	}
+	defer releasePort(entryNode.ID, port)
+
+	svc := gost.BuildTCPForwardService(name, port, []string{fmt.Sprintf("127.0.0.1:%d", exitNode.Port)}, "")
+	svc.Handler.Chain = name
+	if err = client.ApplyService(svc); err != nil {
+		return 0, fmt.Errorf("创建探测服务失败: %w", err)
+	}
+	defer func() {
+		if err := client.DeleteService(name); err != nil {
+			logger.Warnf("删除隧道探测服务 %s 失败: %v", name, err)
+		}
+	}()
+
+	return requestLatency(net.JoinHostPort(entryNode.Address, strconv.Itoa(port)))
+}
+
+// prune 定期删除超过保留时间的探测记录
+func (s *LatencyProbeService) prune() {
+	if time.Since(s.lastPrune) < latencyPruneInterval {
+		return
+	}
+	s.lastPrune = time.Now()
+	deleted, err := s.latencyRepo.DeleteBefore(time.Now().Add(-latencyRetention))
+	if err != nil {
+		logger.Errorf("清理过期延迟探测记录失败: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Debugf("已清理 %d 条过期延迟探测记录", deleted)
+	}
+}
+
+// dialLatency TCP 连接耗时
+func dialLatency(addr string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, latencyDialTimeout)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start)
+	_ = conn.Close()
+	return elapsed, nil
+}
+
+// requestLatency 连接后发送 HTTP 请求，返回从发起连接到收到首个响应字节的耗时
+// 转发服务在入口节点上接受连接后才经隧道连接目标，只有收到响应才说明整条路径可达
+func requestLatency(addr string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, latencyDialTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(start.Add(latencyDialTimeout))
+	if _, err = conn.Write([]byte(latencyProbeRequest)); err != nil {
+		return 0, err
+	}
+	if _, err = bufio.NewReader(conn).ReadByte(); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// truncateError 截断失败原因，避免超出字段长度
+func truncateError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > latencyErrorMaxLen {
+		msg = msg[:latencyErrorMaxLen]
+	}
+	return string(msg)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// LatencyService 延迟统计服务
+// 汇总 LatencyProbeService 保存的探测记录，按路径给出延迟与丢失率
+type LatencyService struct {
+	latencyRepo *repository.LatencyRepository
+	nodeRepo    *repository.NodeRepository
+	ruleRepo    *repository.RuleRepository
+	tunnelRepo  *repository.TunnelRepository
+}
+
+// NewLatencyService 创建延迟统计服务
+func NewLatencyService(db *gorm.DB) *LatencyService {
+	return &LatencyService{
+		latencyRepo: repository.NewLatencyRepository(db),
+		nodeRepo:    repository.NewNodeRepository(db),
+		ruleRepo:    repository.NewRuleRepository(db),
+		tunnelRepo:  repository.NewTunnelRepository(db),
+	}
+}
+
+// Stats 统计窗口内每条探测路径的最小、平均、最大延迟与丢失率
+// 隧道的多个出口中丢失率最低、平均延迟最低的出口标记为 best
+func (s *LatencyService) Stats(req *dto.LatencyStatsReq) ([]dto.LatencyPathStats, error) {
+	req.SetDefaults()
+
+	conditions := make(map[string]any)
+	if req.Path != "" {
+		conditions["path = ?"] = req.Path
+	}
+	if req.NodeID > 0 {
+		conditions["node_id = ?"] = req.NodeID
+	}
+	if req.RuleID > 0 {
+		conditions["rule_id = ?"] = req.RuleID
+	}
+	if req.TunnelID > 0 {
+		conditions["tunnel_id = ?"] = req.TunnelID
+	}
+
+	since := time.Now().Add(-time.Duration(req.Hours) * time.Hour)
+	stats, err := s.latencyRepo.Stats(since, conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := s.resourceNames()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.LatencyPathStats, 0, len(stats))
+	best := make(map[uint]int) // 隧道 ID -> 最佳出口在 result 中的下标
+	exits := make(map[uint]int)
+	for _, stat := range stats {
+		item := dto.LatencyPathStats{
+			Path:       string(stat.Path),
+			NodeID:     stat.NodeID,
+			NodeName:   names.nodes[stat.NodeID],
+			RuleID:     stat.RuleID,
+			RuleName:   names.rules[stat.RuleID],
+			TunnelID:   stat.TunnelID,
+			TunnelName: names.tunnels[stat.TunnelID],
+			Target:     stat.Target,
+			Samples:    stat.Samples,
+			Lost:       stat.Samples - stat.Succeeded,
+			MinMs:      roundMs(stat.MinMs),
+			AvgMs:      roundMs(stat.AvgMs),
+			MaxMs:      roundMs(stat.MaxMs),
+		}
+		if item.Samples > 0 {
+			item.LossRate = math.Round(float64(item.Lost)*10000/float64(item.Samples)) / 100
+		}
+		result = append(result, item)
+
+		if stat.Path != model.LatencyPathTunnel {
+			continue
+		}
+		exits[stat.TunnelID]++
+		if item.AvgMs == nil {
+			continue
+		}
+		if i, ok := best[stat.TunnelID]; !ok || betterExit(&item, &result[i]) {
+			best[stat.TunnelID] = len(result) - 1
+		}
+	}
+	for tunnelID, i := range best {
+		if exits[tunnelID] > 1 {
+			result[i].Best = true
+		}
+	}
+
+	return result, nil
+}
+
+// latencyNames 探测路径关联资源的名称
+type latencyNames struct {
+	nodes   map[uint]string
+	rules   map[uint]string
+	tunnels map[uint]string
+}
+
+// resourceNames 查询节点、规则、隧道的名称
+func (s *LatencyService) resourceNames() (*latencyNames, error) {
+	names := &latencyNames{
+		nodes:   make(map[uint]string),
+		rules:   make(map[uint]string),
+		tunnels: make(map[uint]string),
+	}
+
+	nodes, _, err := s.nodeRepo.List(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		names.nodes[node.ID] = node.Name
+	}
+	rules, _, err := s.ruleRepo.List(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		names.rules[rule.ID] = rule.Name
+	}
+	tunnels, _, err := s.tunnelRepo.List(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, tunnel := range tunnels {
+		names.tunnels[tunnel.ID] = tunnel.Name
+	}
+	return names, nil
+}
+
+// betterExit 丢失率更低，或丢失率相同时平均延迟更低
+func betterExit(a, b *dto.LatencyPathStats) bool {
+	if a.LossRate != b.LossRate {
+		return a.LossRate < b.LossRate
+	}
+	return *a.AvgMs < *b.AvgMs
+}
+
+// roundMs 延迟保留两位小数
+func roundMs(ms *float64) *float64 {
+	if ms == nil {
+		return nil
+	}
+	v := math.Round(*ms*100) / 100
+	return &v
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"gost-panel/internal/metrics"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// connsReportMaxAge 当前连接数的有效期，观察器每 5 秒上报一次，超过该时间未上报的服务视为已停止
+const connsReportMaxAge = 30 * time.Second
+
+// 状态指标输出的全部取值，每个取值一条序列，当前状态为 1
+var (
+	nodeStatuses   = []model.NodeStatus{model.NodeStatusOnline, model.NodeStatusOffline, model.NodeStatusError}
+	ruleStatuses   = []model.RuleStatus{model.RuleStatusRunning, model.RuleStatusStopped, model.RuleStatusError}
+	tunnelStatuses = []model.TunnelStatus{model.TunnelStatusRunning, model.TunnelStatusStopped, model.TunnelStatusError}
+)
+
+// newDesc 创建指标描述
+func newDesc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, subsystem, name), help, labels, nil)
+}
+
+var (
+	nodeLabels   = []string{"node_id", "node"}
+	ruleLabels   = []string{"rule_id", "rule", "type"}
+	tunnelLabels = []string{"tunnel_id", "tunnel"}
+
+	nodeUpDesc            = newDesc("node", "up", "节点是否在线", nodeLabels...)
+	nodeStatusDesc        = newDesc("node", "status", "节点状态", append(nodeLabels, "status")...)
+	nodeMaintenanceDesc   = newDesc("node", "maintenance", "节点是否处于维护模式", nodeLabels...)
+	nodeInputDesc         = newDesc("node", "input_bytes_total", "节点入站流量", nodeLabels...)
+	nodeOutputDesc        = newDesc("node", "output_bytes_total", "节点出站流量", nodeLabels...)
+	nodeCheckLatencyDesc  = newDesc("node", "health_check_duration_seconds", "最近一次健康检测耗时", nodeLabels...)
+	nodeChecksDesc        = newDesc("node", "health_checks_total", "健康检测次数", nodeLabels...)
+	nodeCheckFailuresDesc = newDesc("node", "health_check_failures_total", "健康检测失败次数", nodeLabels...)
+	nodeLastCheckDesc     = newDesc("node", "last_check_timestamp_seconds", "最近一次健康检测时间", nodeLabels...)
+
+	ruleStatusDesc  = newDesc("rule", "status", "规则状态", append(ruleLabels, "status")...)
+	ruleInputDesc   = newDesc("rule", "input_bytes_total", "规则入站流量", ruleLabels...)
+	ruleOutputDesc  = newDesc("rule", "output_bytes_total", "规则出站流量", ruleLabels...)
+	ruleConnsDesc   = newDesc("rule", "connections_total", "规则累计连接数", ruleLabels...)
+	ruleCurrentDesc = newDesc("rule", "current_connections", "规则当前连接数", ruleLabels...)
+
+	tunnelStatusDesc  = newDesc("tunnel", "status", "隧道状态", append(tunnelLabels, "status")...)
+	tunnelInputDesc   = newDesc("tunnel", "input_bytes_total", "隧道入站流量", tunnelLabels...)
+	tunnelOutputDesc  = newDesc("tunnel", "output_bytes_total", "隧道出站流量", tunnelLabels...)
+	tunnelCurrentDesc = newDesc("tunnel", "current_connections", "隧道当前连接数", tunnelLabels...)
+)
+
+// ResourceCollector 节点、规则、隧道指标采集器
+// 流量与状态在每次抓取时从数据库读取，即观察器与健康检测写入的数据；当前连接数取自内存中观察器最近的上报
+type ResourceCollector struct {
+	db *gorm.DB
+}
+
+// NewResourceCollector 创建资源指标采集器
+func NewResourceCollector(db *gorm.DB) *ResourceCollector {
+	return &ResourceCollector{db: db}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *ResourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		nodeUpDesc, nodeStatusDesc, nodeMaintenanceDesc, nodeInputDesc, nodeOutputDesc,
+		nodeCheckLatencyDesc, nodeChecksDesc, nodeCheckFailuresDesc, nodeLastCheckDesc,
+		ruleStatusDesc, ruleInputDesc, ruleOutputDesc, ruleConnsDesc, ruleCurrentDesc,
+		tunnelStatusDesc, tunnelInputDesc, tunnelOutputDesc, tunnelCurrentDesc,
+	} {
+		ch <- d
+	}
+}
+
+// Collect 实现 prometheus.Collector
+func (c *ResourceCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collectNodes(ch)
+	c.collectRules(ch)
+	c.collectTunnels(ch)
+}
+
+// collectNodes 采集节点指标
+func (c *ResourceCollector) collectNodes(ch chan<- prometheus.Metric) {
+	var nodes []model.GostNode
+	if err := c.db.Select("id", "name", "status", "maintenance", "input_bytes", "output_bytes").Find(&nodes).Error; err != nil {
+		logger.Warnf("采集节点指标失败: %v", err)
+		return
+	}
+
+	for _, n := range nodes {
+		labels := []string{formatID(n.ID), n.Name}
+		ch <- prometheus.MustNewConstMetric(nodeUpDesc, prometheus.GaugeValue, boolValue(n.Status == model.NodeStatusOnline), labels...)
+		for _, st := range nodeStatuses {
+			ch <- prometheus.MustNewConstMetric(nodeStatusDesc, prometheus.GaugeValue, boolValue(n.Status == st), append(labels, string(st))...)
+		}
+		ch <- prometheus.MustNewConstMetric(nodeMaintenanceDesc, prometheus.GaugeValue, boolValue(n.Maintenance), labels...)
+		ch <- prometheus.MustNewConstMetric(nodeInputDesc, prometheus.CounterValue, float64(n.InputBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(nodeOutputDesc, prometheus.CounterValue, float64(n.OutputBytes), labels...)
+
+		if h, ok := metrics.GetNodeHealth(n.ID); ok {
+			ch <- prometheus.MustNewConstMetric(nodeCheckLatencyDesc, prometheus.GaugeValue, h.Latency.Seconds(), labels...)
+			ch <- prometheus.MustNewConstMetric(nodeChecksDesc, prometheus.CounterValue, float64(h.Checks), labels...)
+			ch <- prometheus.MustNewConstMetric(nodeCheckFailuresDesc, prometheus.CounterValue, float64(h.Failures), labels...)
+			ch <- prometheus.MustNewConstMetric(nodeLastCheckDesc, prometheus.GaugeValue, float64(h.CheckedAt.Unix()), labels...)
+		}
+	}
+}
+
+// collectRules 采集规则指标
+func (c *ResourceCollector) collectRules(ch chan<- prometheus.Metric) {
+	var rules []model.GostRule
+	if err := c.db.Select("id", "name", "type", "status", "input_bytes", "output_bytes", "total_requests").
+		Find(&rules).Error; err != nil {
+		logger.Warnf("采集规则指标失败: %v", err)
+		return
+	}
+
+	for _, r := range rules {
+		labels := []string{formatID(r.ID), r.Name, string(r.Type)}
+		for _, st := range ruleStatuses {
+			ch <- prometheus.MustNewConstMetric(ruleStatusDesc, prometheus.GaugeValue, boolValue(r.Status == st), append(labels, string(st))...)
+		}
+		ch <- prometheus.MustNewConstMetric(ruleInputDesc, prometheus.CounterValue, float64(r.InputBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(ruleOutputDesc, prometheus.CounterValue, float64(r.OutputBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(ruleConnsDesc, prometheus.CounterValue, float64(r.TotalRequests), labels...)
+		ch <- prometheus.MustNewConstMetric(ruleCurrentDesc, prometheus.GaugeValue, currentConns(r.Status == model.RuleStatusRunning, metrics.GetRuleConns, r.ID), labels...)
+	}
+}
+
+// collectTunnels 采集隧道指标
+func (c *ResourceCollector) collectTunnels(ch chan<- prometheus.Metric) {
+	var tunnels []model.GostTunnel
+	if err := c.db.Select("id", "name", "status", "input_bytes", "output_bytes").Find(&tunnels).Error; err != nil {
+		logger.Warnf("采集隧道指标失败: %v", err)
+		return
+	}
+
+	for _, t := range tunnels {
+		labels := []string{formatID(t.ID), t.Name}
+		for _, st := range tunnelStatuses {
+			ch <- prometheus.MustNewConstMetric(tunnelStatusDesc, prometheus.GaugeValue, boolValue(t.Status == st), append(labels, string(st))...)
+		}
+		ch <- prometheus.MustNewConstMetric(tunnelInputDesc, prometheus.CounterValue, float64(t.InputBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(tunnelOutputDesc, prometheus.CounterValue, float64(t.OutputBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(tunnelCurrentDesc, prometheus.GaugeValue, currentConns(t.Status == model.TunnelStatusRunning, metrics.GetTunnelConns, t.ID), labels...)
+	}
+}
+
+// currentConns 运行中资源的当前连接数，未运行时为 0
+func currentConns(running bool, get func(uint, time.Duration) int64, id uint) float64 {
+	if !running {
+		return 0
+	}
+	return float64(get(id, connsReportMaxAge))
+}
+
+// formatID 将 ID 格式化为标签值
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// boolValue 将布尔值转换为指标值
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -4,9 +4,11 @@ package service
 import (
 	stderrors "errors"
 	"fmt"
+	"time"
 
 	"gost-panel/internal/dto"
 	"gost-panel/internal/errors"
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
 	"gost-panel/internal/utils"
@@ -19,6 +21,7 @@ import (
 // NodeService 节点服务
 // 负责节点的 CRUD 操作和业务逻辑处理
 type NodeService struct {
+	db         *gorm.DB
 	nodeRepo   *repository.NodeRepository
 	logService *LogService
 }
@@ -26,6 +29,7 @@ type NodeService struct {
 // NewNodeService 创建节点服务
 func NewNodeService(db *gorm.DB) *NodeService {
 	return &NodeService{
+		db:         db,
 		nodeRepo:   repository.NewNodeRepository(db),
 		logService: NewLogService(db),
 	}
@@ -43,15 +47,22 @@ func (s *NodeService) Create(req *dto.CreateNodeReq, userID uint, username strin
 		return nil, errors.ErrNodeNameExists
 	}
 
+	portRanges, reservedPorts, err := normalizePortPool(req.PortRanges, req.ReservedPorts)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建节点
 	node := &model.GostNode{
-		Name:     req.Name,
-		Address:  req.Address,
-		Port:     req.Port,
-		Username: req.Username,
-		Password: req.Password,
-		Remark:   req.Remark,
-		Status:   model.NodeStatusOffline,
+		Name:          req.Name,
+		Address:       req.Address,
+		Port:          req.Port,
+		Username:      req.Username,
+		Password:      req.Password,
+		Remark:        req.Remark,
+		PortRanges:    portRanges,
+		ReservedPorts: reservedPorts,
+		Status:        model.NodeStatusOffline,
 	}
 
 	if err = s.nodeRepo.Create(node); err != nil {
@@ -94,13 +105,23 @@ func (s *NodeService) Update(id uint, req *dto.UpdateNodeReq, userID uint, usern
 		return nil, errors.ErrNodeNameExists
 	}
 
+	portRanges, reservedPorts, err := normalizePortPool(req.PortRanges, req.ReservedPorts)
+	if err != nil {
+		return nil, err
+	}
+
 	// 更新节点
 	node.Name = req.Name
 	node.Address = req.Address
 	node.Port = req.Port
+	// API Token 读取的节点不含密码，用户名不变且未填写密码时沿用原密码
+	if req.Password != "" || req.Username != node.Username {
+		node.Password = req.Password
+	}
 	node.Username = req.Username
-	node.Password = req.Password
 	node.Remark = req.Remark
+	node.PortRanges = portRanges
+	node.ReservedPorts = reservedPorts
 
 	if err = s.nodeRepo.Update(node); err != nil {
 		return nil, err
@@ -137,15 +158,23 @@ func (s *NodeService) Delete(id uint, userID uint, username string, ip, userAgen
 		return errors.ErrNodeHasRules
 	}
 
-	// 删除节点前，用户需要手动删除相关隧道
+	// 删除节点前，用户需要手动删除相关隧道（包括以该节点为附加出口的隧道）
 	if len(node.EntryTunnels) > 0 || len(node.ExitTunnels) > 0 {
 		return errors.ErrNodeHasTunnels
 	}
+	tunnels, err := repository.NewTunnelRepository(s.db).FindByExitNodeID(id)
+	if err != nil {
+		return err
+	}
+	if len(tunnels) > 0 {
+		return errors.ErrNodeHasTunnels
+	}
 
 	// 删除节点
 	if err = s.nodeRepo.Delete(id); err != nil {
 		return err
 	}
+	metrics.ForgetNode(id)
 
 	// 记录操作日志
 	s.logService.Record(
@@ -203,6 +232,152 @@ func (s *NodeService) List(req *dto.NodeListReq) ([]model.GostNode, int64, error
 	return s.nodeRepo.List(opt)
 }
 
+// SetMaintenance 开启或关闭节点维护模式
+func (s *NodeService) SetMaintenance(id uint, req *dto.NodeMaintenanceReq, userID uint, username string, ip, userAgent string) (*model.GostNode, error) {
+	node, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var details string
+	if req.Enabled {
+		now := time.Now()
+		node.Maintenance = true
+		node.MaintenanceReason = req.Reason
+		if node.MaintenanceAt == nil {
+			node.MaintenanceAt = &now
+		}
+		details = fmt.Sprintf("节点进入维护模式: %s", node.Name)
+		if req.Reason != "" {
+			details += fmt.Sprintf(" (%s)", req.Reason)
+		}
+	} else {
+		node.Maintenance = false
+		node.MaintenanceReason = ""
+		node.MaintenanceAt = nil
+		details = fmt.Sprintf("节点退出维护模式: %s", node.Name)
+	}
+
+	if err = s.nodeRepo.Update(node); err != nil {
+		return nil, err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionMaintenance,
+		model.ResourceTypeNode,
+		node.ID,
+		details,
+		ip,
+		userAgent)
+
+	logger.Info(details)
+	return node, nil
+}
+
+// Drain 排空维护中的节点
+// 端口转发规则迁移到替换节点，以该节点为出口的隧道改用替换节点作为出口（多出口隧道只替换该出口）；
+// 以该节点为入口的隧道（及其规则）需要手动处理，结果中标记为跳过
+func (s *NodeService) Drain(id uint, req *dto.DrainNodeReq, userID uint, username string, ip, userAgent string) (*dto.BatchResp, error) {
+	node, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !node.Maintenance {
+		return nil, errors.ErrNodeNotInMaintenance
+	}
+	if req.TargetNodeID == id {
+		return nil, errors.ErrDrainTargetInvalid
+	}
+	target, err := s.GetByID(req.TargetNodeID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Maintenance {
+		return nil, errors.ErrDrainTargetInvalid
+	}
+
+	ruleService := NewRuleService(s.db)
+	tunnelService := NewTunnelService(s.db)
+
+	rules, err := repository.NewRuleRepository(s.db).FindByNodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	tunnels, err := repository.NewTunnelRepository(s.db).FindByNodeID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]batchItem, 0, len(rules)+len(tunnels))
+	for i := range tunnels {
+		tunnel := &tunnels[i]
+		item := batchItem{Resource: model.ResourceTypeTunnel, ID: tunnel.ID, Name: tunnel.Name}
+		if tunnel.EntryNodeID == id {
+			item.Run = func() error {
+				return batchSkip("节点是隧道入口，需手动迁移隧道及其规则")
+			}
+		} else {
+			item.Run = func() error {
+				if err := tunnelService.changeExit(tunnel, id, target.ID, req.AutoPort); err != nil {
+					return err
+				}
+				logger.Infof("排空节点 %s: 隧道 %s 出口已改为 %s", node.Name, tunnel.Name, target.Name)
+				return nil
+			}
+		}
+		items = append(items, item)
+	}
+	for i := range rules {
+		rule := &rules[i]
+		items = append(items, batchItem{Resource: model.ResourceTypeRule, ID: rule.ID, Name: rule.Name, Run: func() error {
+			if _, _, err := ruleService.migrate(rule, &dto.MigrateRuleReq{NodeID: &target.ID, KeepStats: req.KeepStats, AutoPort: req.AutoPort}); err != nil {
+				return err
+			}
+			logger.Infof("排空节点 %s: 规则 %s 已迁移到 %s", node.Name, rule.Name, target.Name)
+			return nil
+		}})
+	}
+
+	resp := runBatch(model.ActionDrain, items)
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionDrain,
+		model.ResourceTypeNode,
+		node.ID,
+		batchLogDetails(resp, fmt.Sprintf("排空节点 %s -> %s", node.Name, target.Name)),
+		ip,
+		userAgent)
+
+	logger.Infof("排空节点完成: %s -> %s, 成功 %d, 跳过 %d, 失败 %d", node.Name, target.Name, resp.Succeeded, resp.Skipped, resp.Failed)
+	return resp, nil
+}
+
+// Ports 查询节点端口池及占用情况
+func (s *NodeService) Ports(id uint) (*dto.NodePortsResp, error) {
+	node, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return NewPortService(s.db).Usage(node)
+}
+
+// normalizePortPool 校验并规范化端口池范围和保留端口
+func normalizePortPool(portRanges, reservedPorts string) (string, string, error) {
+	ranges, err := utils.NormalizePortRanges(portRanges)
+	if err != nil {
+		return "", "", errors.ErrPortRangeInvalid
+	}
+	reserved, err := utils.NormalizePortRanges(reservedPorts)
+	if err != nil {
+		return "", "", errors.ErrPortRangeInvalid
+	}
+	return ranges, reserved, nil
+}
+
 // CreateGostClient 创建节点的 Gost 客户端
 func (s *NodeService) CreateGostClient(id uint) (*gost.Client, error) {
 	node, err := s.nodeRepo.FindByID(id)
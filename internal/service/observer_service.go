@@ -1,12 +1,15 @@
 package service
 
 import (
+	stderrors "errors"
+
 	"gost-panel/internal/dto"
 	"gost-panel/internal/errors"
-	"gost-panel/internal/model"
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/repository"
 	"gost-panel/pkg/gost"
 	"gost-panel/pkg/logger"
+	"slices"
 	"strings"
 
 	"gorm.io/gorm"
@@ -31,7 +34,9 @@ func NewObserverService(db *gorm.DB) *ObserverService {
 // HandleReport 处理观察器上报的数据
 func (s *ObserverService) HandleReport(req *dto.ObserverReportReq) error {
 	for _, event := range req.Events {
-		if err := s.processEvent(&event); err != nil {
+		err := s.processEvent(&event)
+		metrics.ObserveEvent(err)
+		if err != nil {
 			logger.Warnf("处理观察器事件失败: %v", err)
 		}
 	}
@@ -77,35 +82,50 @@ func (s *ObserverService) processEvent(event *dto.ObserverEvent) error {
 
 // updateRuleStats 更新规则统计
 func (s *ObserverService) updateRuleStats(serviceName, rawServiceName string, stats *dto.ObserverStats, prefix string) error {
+	// 端口范围规则的服务名为 {prefix}{id}-p{port}，按服务独立计数后汇总到规则
+	rangeService := false
+	if i := strings.LastIndex(serviceName, "-p"); i > len(prefix) {
+		serviceName, rangeService = serviceName[:i], true
+	}
+
 	// 解析 ID
 	var id uint
 	if _, err := parseServiceID(serviceName, prefix, &id); err != nil {
 		return err
 	}
 
-	// 更新规则统计数据
-	inputDelta, outputDelta, _, err := s.ruleRepo.UpdateStats(id, rawServiceName, stats.InputBytes, stats.OutputBytes, stats.TotalConns)
-	if err != nil {
-		return err
-	}
-
-	// 同步更新节点统计
-	// 1. 查询规则获取关联节点
+	// 查询规则获取协议和关联节点
 	rule, err := s.ruleRepo.FindByID(id)
 	if err != nil {
 		// 如果找不到规则，可能已被删除，忽略错误
 		return nil
 	}
 
-	// 2. 确定节点 ID
+	// 忽略规则未启用协议的服务上报（如修改协议前遗留的服务）
+	if protocol := serviceProtocol(rawServiceName); protocol != "" && !slices.Contains(rule.Protocols(), protocol) {
+		logger.Debugf("忽略规则 %d 未启用协议的服务上报: %s", id, rawServiceName)
+		return nil
+	}
+
+	// 更新规则统计数据
+	update := s.ruleRepo.UpdateStats
+	if rangeService {
+		update = s.ruleRepo.UpdateServiceStats
+	}
+	inputDelta, outputDelta, _, err := update(id, rawServiceName, stats.InputBytes, stats.OutputBytes, stats.TotalConns)
+	if err != nil {
+		return err
+	}
+	metrics.ObserveRuleConns(id, rawServiceName, stats.CurrentConns)
+
+	// 同步更新节点统计
 	var nodeID uint
-	if rule.Type == model.RuleTypeTunnel && rule.Tunnel != nil {
+	if rule.TunnelID != nil && rule.Tunnel != nil {
 		nodeID = rule.Tunnel.EntryNodeID
 	} else if rule.NodeID != nil {
 		nodeID = *rule.NodeID
 	}
 
-	// 3. 更新节点流量
 	if nodeID > 0 {
 		if err := s.nodeRepo.AddStatsDelta(nodeID, inputDelta, outputDelta); err != nil {
 			logger.Warnf("更新节点流量失败: %v", err)
@@ -117,8 +137,28 @@ func (s *ObserverService) updateRuleStats(serviceName, rawServiceName string, st
 	return nil
 }
 
+// serviceProtocol 由服务名的协议后缀得到协议，未带后缀的旧服务名返回空
+func serviceProtocol(serviceName string) string {
+	for _, protocol := range []string{"tcp", "udp"} {
+		if strings.HasSuffix(serviceName, "-"+protocol) {
+			return protocol
+		}
+	}
+	return ""
+}
+
 // updateTunnelStats 更新隧道统计
 func (s *ObserverService) updateTunnelStats(serviceName string, stats *dto.ObserverStats, prefix string) error {
+	// 附加出口的 Relay 服务名为 {prefix}{id}-n{nodeID}，按出口独立计数后汇总到隧道
+	relayService := serviceName
+	var exitNodeID uint
+	if i := strings.LastIndex(serviceName, "-n"); i > len(prefix) {
+		if _, err := parseUint(serviceName[i+2:], &exitNodeID); err != nil {
+			return err
+		}
+		serviceName = serviceName[:i]
+	}
+
 	// 解析 ID
 	var id uint
 	if _, err := parseServiceID(serviceName, prefix, &id); err != nil {
@@ -126,19 +166,34 @@ func (s *ObserverService) updateTunnelStats(serviceName string, stats *dto.Obser
 	}
 
 	// 更新隧道统计数据
-	inputDelta, outputDelta, err := s.tunnelRepo.UpdateStats(id, stats.InputBytes, stats.OutputBytes)
+	var inputDelta, outputDelta int64
+	var err error
+	if exitNodeID > 0 {
+		inputDelta, outputDelta, err = s.tunnelRepo.UpdateExitStats(id, exitNodeID, stats.InputBytes, stats.OutputBytes)
+	} else {
+		inputDelta, outputDelta, err = s.tunnelRepo.UpdateStats(id, stats.InputBytes, stats.OutputBytes)
+	}
 	if err != nil {
+		// 出口已从隧道中移除，忽略遗留服务的上报
+		if exitNodeID > 0 && stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
 		return err
 	}
 
+	metrics.ObserveTunnelConns(id, relayService, stats.CurrentConns)
+
 	// 同步更新出口节点统计
-	tunnel, err := s.tunnelRepo.FindByID(id)
-	if err != nil {
-		return nil
+	if exitNodeID == 0 {
+		tunnel, err := s.tunnelRepo.FindByID(id)
+		if err != nil {
+			return nil
+		}
+		exitNodeID = tunnel.ExitNodeID
 	}
 
-	if tunnel.ExitNodeID > 0 {
-		if err := s.nodeRepo.AddStatsDelta(tunnel.ExitNodeID, inputDelta, outputDelta); err != nil {
+	if exitNodeID > 0 {
+		if err := s.nodeRepo.AddStatsDelta(exitNodeID, inputDelta, outputDelta); err != nil {
 			logger.Warnf("更新节点流量失败: %v", err)
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
 	"gost-panel/internal/utils"
@@ -15,26 +16,29 @@ import (
 // NodeHealthService 节点健康检测服务
 // 使用 Gost API 进行健康检查
 type NodeHealthService struct {
-	nodeRepo   *repository.NodeRepository
-	ruleRepo   *repository.RuleRepository
-	tunnelRepo *repository.TunnelRepository
-	ticker     *time.Ticker
-	stopChan   chan struct{}
-	wg         sync.WaitGroup
+	nodeRepo      *repository.NodeRepository
+	ruleRepo      *repository.RuleRepository
+	tunnelRepo    *repository.TunnelRepository
+	tunnelService *TunnelService
+	ticker        *time.Ticker
+	stopChan      chan struct{}
+	wg            sync.WaitGroup
 }
 
 // NewNodeHealthService 创建节点健康检测服务
 func NewNodeHealthService(db *gorm.DB) *NodeHealthService {
 	return &NodeHealthService{
-		nodeRepo:   repository.NewNodeRepository(db),
-		ruleRepo:   repository.NewRuleRepository(db),
-		tunnelRepo: repository.NewTunnelRepository(db),
-		stopChan:   make(chan struct{}),
+		nodeRepo:      repository.NewNodeRepository(db),
+		ruleRepo:      repository.NewRuleRepository(db),
+		tunnelRepo:    repository.NewTunnelRepository(db),
+		tunnelService: NewTunnelService(db),
+		stopChan:      make(chan struct{}),
 	}
 }
 
 // Start 启动定时健康检测（每 5 秒）
 func (s *NodeHealthService) Start() {
+	s.stopChan = make(chan struct{})
 	s.ticker = time.NewTicker(5 * time.Second)
 	s.wg.Add(1)
 
@@ -80,26 +84,40 @@ func (s *NodeHealthService) checkNodes() {
 		return
 	}
 
+	// 本轮检测前的节点状态，用于判断多出口隧道是否仍有在线出口
+	statuses := make(map[uint]model.NodeStatus, len(nodes))
+	for _, node := range nodes {
+		statuses[node.ID] = node.Status
+	}
+
 	for _, node := range nodes {
 		go func(n model.GostNode) {
 			status := s.checkNodeHealth(n)
 
 			// 状态变更处理
 			if status != n.Status {
-				logger.Infof("节点 %s 状态变更: %s -> %s", n.Name, n.Status, status)
+				// 维护中的节点状态变化属于预期，不告警
+				if n.Maintenance {
+					logger.Debugf("维护中的节点 %s 状态变更: %s -> %s", n.Name, n.Status, status)
+				} else {
+					logger.Infof("节点 %s 状态变更: %s -> %s", n.Name, n.Status, status)
+				}
 				oldStatus := n.Status
 				if err = s.nodeRepo.UpdateStatus(n.ID, status); err != nil {
 					logger.Errorf("更新节点 %s 状态失败: %v", n.Name, err)
 				}
 
-				// 节点从离线恢复到在线，尝试重启之前运行的规则和隧道
-				if oldStatus == model.NodeStatusOffline && status == model.NodeStatusOnline {
+				// 节点从离线恢复到在线，尝试重启之前运行的规则和隧道（维护中的节点不处理）
+				if oldStatus == model.NodeStatusOffline && status == model.NodeStatusOnline && !n.Maintenance {
 					logger.Infof("节点 %s 恢复在线，准备重启关联的规则和隧道", n.Name)
 					// 注意：这里只更新状态为stopped，实际重启需要通过API手动触发
 					// 或者可以在这里调用RuleService.Start()和TunnelService.Start()自动重启
 					// 但这需要注入这些服务，暂时只记录日志提示
 					logger.Warnf("节点 %s 已恢复，请手动重启相关规则和隧道，或等待后续版本支持自动重启", n.Name)
 				}
+
+				// 节点作为多出口隧道的出口时，离线则从跳点中移除，恢复则重新加入
+				s.refreshTunnelExits(n, status)
 			}
 
 			if status == model.NodeStatusOnline {
@@ -108,16 +126,18 @@ func (s *NodeHealthService) checkNodes() {
 				// 停止其关联的所有规则和隧道
 				_ = s.ruleRepo.StopByNodeID(n.ID)
 
-				// 查找并停止受影响的隧道关联的规则
+				// 查找并停止受影响的隧道及其关联的规则，仍有在线出口的多出口隧道继续运行
 				if tunnels, err := s.tunnelRepo.FindByNodeID(n.ID); err == nil && len(tunnels) > 0 {
 					var tunnelIDs []uint
 					for _, t := range tunnels {
+						if t.EntryNodeID != n.ID && hasOnlineExit(&t, n.ID, statuses) {
+							continue
+						}
 						tunnelIDs = append(tunnelIDs, t.ID)
 					}
 					_ = s.ruleRepo.StopByTunnelIDs(tunnelIDs)
+					_ = s.tunnelRepo.StopByIDs(tunnelIDs)
 				}
-
-				_ = s.tunnelRepo.StopByNodeID(n.ID)
 				logger.Debugf("节点 %s 离线, status=%s, old=%s", n.Name, status, n.Status)
 			}
 
@@ -126,18 +146,54 @@ func (s *NodeHealthService) checkNodes() {
 	}
 }
 
+// refreshTunnelExits 节点状态变化后刷新以其为出口的运行中多出口隧道
+func (s *NodeHealthService) refreshTunnelExits(n model.GostNode, status model.NodeStatus) {
+	tunnels, err := s.tunnelRepo.FindByExitNodeID(n.ID)
+	if err != nil {
+		logger.Errorf("获取节点 %s 的隧道失败: %v", n.Name, err)
+		return
+	}
+
+	var recovered uint
+	if status == model.NodeStatusOnline {
+		recovered = n.ID
+	}
+	for _, t := range tunnels {
+		if t.Status != model.TunnelStatusRunning || len(t.ExtraExits) == 0 || t.EntryNodeID == n.ID {
+			continue
+		}
+		if err = s.tunnelService.refreshExits(t.ID, recovered); err != nil {
+			logger.Warnf("刷新隧道 %s 的出口失败: %v", t.Name, err)
+		}
+	}
+}
+
+// hasOnlineExit 除离线节点外，隧道是否还有在线的出口
+func hasOnlineExit(tunnel *model.GostTunnel, offlineNodeID uint, statuses map[uint]model.NodeStatus) bool {
+	for _, id := range tunnel.ExitNodeIDs() {
+		if id != offlineNodeID && statuses[id] == model.NodeStatusOnline {
+			return true
+		}
+	}
+	return false
+}
+
 // checkNodeHealth 检查单个节点的健康状态
 // 通过调用 Gost API 的 /config 接口来判断节点是否可用
 func (s *NodeHealthService) checkNodeHealth(node model.GostNode) model.NodeStatus {
 	// 检查地址是否有效
 	if node.Address == "" || node.Port == 0 {
+		metrics.ObserveNodeHealthCheck(node.ID, 0, false)
 		return model.NodeStatusOffline
 	}
 
 	// 验证 Gost API 是否可用
 	client := utils.GetGostClient(&node)
 
-	if err := client.HealthCheck(); err != nil {
+	start := time.Now()
+	err := client.HealthCheck()
+	metrics.ObserveNodeHealthCheck(node.ID, time.Since(start), err == nil)
+	if err != nil {
 		logger.Debugf("节点 %d (%s) API 检查失败: %v", node.ID, node.Name, err)
 		return model.NodeStatusOffline
 	}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+	"gost-panel/internal/utils"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// defaultPortRange 节点未配置端口池时自动分配使用的范围
+var defaultPortRange = utils.PortRange{Start: 10000, End: 65535}
+
+// portAllocMu 串行化端口分配与写入，避免并发请求分到同一端口
+var portAllocMu sync.Mutex
+
+// reservedPorts 已分配但不写入数据库的临时端口（节点 ID -> 端口 -> 占用者）
+var (
+	reservedPorts   = make(map[uint]map[int]string)
+	reservedPortsMu sync.Mutex
+)
+
+// reservePort 预留节点端口，应在持有 portAllocMu 分配端口后立即调用
+// 预留后即可释放 portAllocMu 再创建服务，其他分配不会选到该端口
+func reservePort(nodeID uint, port int, owner string) {
+	reservedPortsMu.Lock()
+	defer reservedPortsMu.Unlock()
+	if reservedPorts[nodeID] == nil {
+		reservedPorts[nodeID] = make(map[int]string)
+	}
+	reservedPorts[nodeID][port] = owner
+}
+
+// releasePort 释放预留的节点端口
+func releasePort(nodeID uint, port int) {
+	reservedPortsMu.Lock()
+	defer reservedPortsMu.Unlock()
+	delete(reservedPorts[nodeID], port)
+	if len(reservedPorts[nodeID]) == 0 {
+		delete(reservedPorts, nodeID)
+	}
+}
+
+// PortExclude 冲突检查时忽略的占用者（修改或迁移自身时）
+// Shared 用于反向代理规则：其他反向代理规则的监听端口和共享监听服务可以共用
+type PortExclude struct {
+	RuleID   uint
+	TunnelID uint
+	Shared   bool
+}
+
+// PortService 节点端口池
+// 占用来源：监听在节点上的规则（包括反向代理规则的内部端口）、以节点为出口的隧道 Relay 端口、节点 API 端口、
+// 预留的临时端口，以及节点在线时实际运行的服务（包括不由面板管理的服务）
+type PortService struct {
+	ruleRepo   *repository.RuleRepository
+	tunnelRepo *repository.TunnelRepository
+}
+
+// NewPortService 创建端口池服务
+func NewPortService(db *gorm.DB) *PortService {
+	return &PortService{
+		ruleRepo:   repository.NewRuleRepository(db),
+		tunnelRepo: repository.NewTunnelRepository(db),
+	}
+}
+
+// Check 检查端口能否在节点上使用
+func (s *PortService) Check(node *model.GostNode, port int, exclude PortExclude) error {
+	return s.CheckRange(node, port, port, exclude)
+}
+
+// CheckRange 检查端口范围 start ~ end 能否在节点上使用
+func (s *PortService) CheckRange(node *model.GostNode, start, end int, exclude PortExclude) error {
+	if err := checkPortPool(node, start, end); err != nil {
+		return err
+	}
+
+	used, err := s.usedPorts(node, exclude, true)
+	if err != nil {
+		return err
+	}
+	for port := start; port <= end; port++ {
+		if owner, ok := used[port]; ok {
+			logger.Debugf("节点 %s 端口 %d 已被占用: %s", node.Name, port, owner)
+			return errors.ErrRulePortExists
+		}
+	}
+	return nil
+}
+
+// Allocate 从节点端口池中选出下一个可用端口
+func (s *PortService) Allocate(node *model.GostNode, exclude PortExclude) (int, error) {
+	return s.AllocateRange(node, 1, exclude)
+}
+
+// AllocateRange 从节点端口池中选出 count 个连续可用端口，返回起始端口
+func (s *PortService) AllocateRange(node *model.GostNode, count int, exclude PortExclude) (int, error) {
+	used, err := s.usedPorts(node, exclude, true)
+	if err != nil {
+		return 0, err
+	}
+	port, err := nextFreePorts(node, used, count)
+	if err != nil {
+		return 0, err
+	}
+	if port == 0 {
+		return 0, errors.ErrNoFreePort
+	}
+	return port, nil
+}
+
+// AllocateShared 为多个节点选出同一个可用端口（如多出口隧道的 Relay 端口）
+// 按第一个节点的端口池顺序查找，端口须同时在其他节点的端口池内且未被占用
+func (s *PortService) AllocateShared(nodes []*model.GostNode, exclude PortExclude) (int, error) {
+	used := make(map[int]string)
+	for _, node := range nodes {
+		nodeUsed, err := s.usedPorts(node, exclude, true)
+		if err != nil {
+			return 0, err
+		}
+		for port, owner := range nodeUsed {
+			used[port] = owner
+		}
+	}
+	for {
+		port, err := nextFreePorts(nodes[0], used, 1)
+		if err != nil {
+			return 0, err
+		}
+		if port == 0 {
+			return 0, errors.ErrNoFreePort
+		}
+		available := true
+		for _, node := range nodes[1:] {
+			if err = checkPortPool(node, port, port); err != nil {
+				used[port] = "节点 " + node.Name + " 端口池外"
+				available = false
+				break
+			}
+		}
+		if available {
+			return port, nil
+		}
+	}
+}
+
+// AllocateInner 为反向代理规则分配内部端口，listenPort 为规则本次使用（可能尚未保存）的监听端口
+func (s *PortService) AllocateInner(node *model.GostNode, ruleID uint, listenPort int) (int, error) {
+	used, err := s.usedPorts(node, PortExclude{RuleID: ruleID}, true)
+	if err != nil {
+		return 0, err
+	}
+	used[listenPort] = "规则监听端口"
+	port, err := nextFreePorts(node, used, 1)
+	if err != nil {
+		return 0, err
+	}
+	if port == 0 {
+		return 0, errors.ErrNoFreePort
+	}
+	return port, nil
+}
+
+// Usage 查询节点端口池及占用情况
+func (s *PortService) Usage(node *model.GostNode) (*dto.NodePortsResp, error) {
+	live := node.Status == model.NodeStatusOnline
+	used, err := s.usedPorts(node, PortExclude{}, live)
+	if err != nil {
+		return nil, err
+	}
+	next, err := nextFreePorts(node, used, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.NodePortsResp{
+		NodeID:        node.ID,
+		PortRanges:    node.PortRanges,
+		ReservedPorts: node.ReservedPorts,
+		Live:          live,
+		Used:          make([]dto.NodePortUsage, 0, len(used)),
+		NextFree:      next,
+	}
+	for port, owner := range used {
+		resp.Used = append(resp.Used, dto.NodePortUsage{Port: port, Owner: owner})
+	}
+	sort.Slice(resp.Used, func(i, j int) bool { return resp.Used[i].Port < resp.Used[j].Port })
+	return resp, nil
+}
+
+// usedPorts 汇总节点上已占用的端口及占用者
+// live 为 true 且节点在线时，同时查询节点上实际运行的服务，查询失败时忽略
+func (s *PortService) usedPorts(node *model.GostNode, exclude PortExclude, live bool) (map[int]string, error) {
+	used := map[int]string{node.Port: "节点 API"}
+
+	rules, err := s.ruleRepo.FindByEntryNodeID(node.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		// 内部端口修改自身时也不可占用
+		if rule.InnerPort > 0 {
+			used[rule.InnerPort] = "规则 " + rule.Name + " 内部端口"
+		}
+		if rule.ID == exclude.RuleID || (exclude.Shared && rule.Type == model.RuleTypeReverse) {
+			continue
+		}
+		for _, port := range rule.ListenPorts() {
+			used[port] = "规则 " + rule.Name
+		}
+	}
+
+	tunnels, err := s.tunnelRepo.FindByExitNodeID(node.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tunnel := range tunnels {
+		if tunnel.ID != exclude.TunnelID {
+			used[tunnel.RelayPort] = "隧道 " + tunnel.Name + " Relay"
+		}
+	}
+	reservedPortsMu.Lock()
+	for port, owner := range reservedPorts[node.ID] {
+		used[port] = owner
+	}
+	reservedPortsMu.Unlock()
+
+	if !live || node.Status != model.NodeStatusOnline {
+		return used, nil
+	}
+	config, err := utils.GetGostClient(node).GetConfig()
+	if err != nil {
+		logger.Debugf("查询节点 %s 服务失败，跳过实际端口检查: %v", node.Name, err)
+		return used, nil
+	}
+	for _, svc := range config.Services {
+		port := utils.AddrPort(svc.Addr)
+		if port == 0 || isExcludedService(svc.Name, exclude) {
+			continue
+		}
+		if _, ok := used[port]; !ok {
+			used[port] = "节点服务 " + svc.Name
+		}
+	}
+	return used, nil
+}
+
+// isPortConflict 是否为端口不可用类错误（可通过重新分配端口解决）
+func isPortConflict(err error) bool {
+	return stderrors.Is(err, errors.ErrRulePortExists) ||
+		stderrors.Is(err, errors.ErrPortReserved) ||
+		stderrors.Is(err, errors.ErrPortOutOfRange)
+}
+
+// isExcludedService 服务是否属于被忽略的规则或隧道
+func isExcludedService(name string, exclude PortExclude) bool {
+	if exclude.Shared && strings.HasPrefix(name, "vhost-") {
+		return true
+	}
+	var prefixes []string
+	if exclude.RuleID > 0 {
+		prefixes = append(prefixes, fmt.Sprintf("rule-%d", exclude.RuleID))
+	}
+	if exclude.TunnelID > 0 {
+		prefixes = append(prefixes, fmt.Sprintf("relay-tunnel-%d", exclude.TunnelID))
+	}
+	for _, prefix := range prefixes {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// nextFreePorts 按端口池顺序找出第一段 count 个连续的未占用、未保留端口，返回起始端口，没有时返回 0
+// 连续端口不跨越端口池中的不同区间
+func nextFreePorts(node *model.GostNode, used map[int]string, count int) (int, error) {
+	reserved, ranges, err := nodePortPool(node)
+	if err != nil {
+		return 0, err
+	}
+	if len(ranges) == 0 {
+		ranges = []utils.PortRange{defaultPortRange}
+	}
+	for _, r := range ranges {
+		start, run := 0, 0
+		for port := r.Start; port <= r.End; port++ {
+			if _, ok := used[port]; ok || inPortRanges(reserved, port) {
+				run = 0
+				continue
+			}
+			if run == 0 {
+				start = port
+			}
+			if run++; run == count {
+				return start, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// checkPortPool 检查端口范围 start ~ end 是否在节点端口池内且未被保留
+func checkPortPool(node *model.GostNode, start, end int) error {
+	reserved, ranges, err := nodePortPool(node)
+	if err != nil {
+		return err
+	}
+	for port := start; port <= end; port++ {
+		if inPortRanges(reserved, port) {
+			return errors.ErrPortReserved
+		}
+		if len(ranges) > 0 && !inPortRanges(ranges, port) {
+			return errors.ErrPortOutOfRange
+		}
+	}
+	return nil
+}
+
+// nodePortPool 解析节点的保留端口和端口池范围
+func nodePortPool(node *model.GostNode) ([]utils.PortRange, []utils.PortRange, error) {
+	reserved, err := utils.ParsePortRanges(node.ReservedPorts)
+	if err != nil {
+		return nil, nil, errors.ErrPortRangeInvalid
+	}
+	ranges, err := utils.ParsePortRanges(node.PortRanges)
+	if err != nil {
+		return nil, nil, errors.ErrPortRangeInvalid
+	}
+	return reserved, ranges, nil
+}
+
+// inPortRanges 端口是否落在任一区间内
+func inPortRanges(ranges []utils.PortRange, port int) bool {
+	for _, r := range ranges {
+		if r.Contains(port) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+)
+
+// 测试节点均为离线状态，端口检查只使用数据库中的占用
+func TestPortServiceCheck(t *testing.T) {
+	db := newTestDB(t)
+	node := &model.GostNode{
+		Name: "hk", Address: "1.1.1.1", Port: 18080, Status: model.NodeStatusOffline,
+		PortRanges: "10000-10100", ReservedPorts: "10050",
+	}
+	exit := &model.GostNode{Name: "sg", Address: "2.2.2.2", Port: 18080, Status: model.NodeStatusOffline}
+	mustCreate(t, db, node, exit)
+	rule := &model.GostRule{Name: "web", Type: model.RuleTypeForward, NodeID: &node.ID, ListenPort: 10001, InnerPort: 10003}
+	tunnel := &model.GostTunnel{Name: "t1", EntryNodeID: exit.ID, ExitNodeID: node.ID, RelayPort: 10002}
+	mustCreate(t, db, rule, tunnel)
+	s := NewPortService(db)
+
+	cases := []struct {
+		name    string
+		port    int
+		exclude PortExclude
+		want    error
+	}{
+		{"空闲端口", 10010, PortExclude{}, nil},
+		{"规则监听端口", 10001, PortExclude{}, errors.ErrRulePortExists},
+		{"修改规则自身", 10001, PortExclude{RuleID: rule.ID}, nil},
+		{"规则内部端口", 10003, PortExclude{RuleID: rule.ID}, errors.ErrRulePortExists},
+		{"隧道 Relay 端口", 10002, PortExclude{}, errors.ErrRulePortExists},
+		{"修改隧道自身", 10002, PortExclude{TunnelID: tunnel.ID}, nil},
+		{"保留端口", 10050, PortExclude{}, errors.ErrPortReserved},
+		{"端口池外", 20000, PortExclude{}, errors.ErrPortOutOfRange},
+		{"节点 API 端口", 18080, PortExclude{}, errors.ErrPortOutOfRange},
+	}
+	for _, c := range cases {
+		err := s.Check(node, c.port, c.exclude)
+		if !stderrors.Is(err, c.want) {
+			t.Errorf("%s: Check(%d) = %v，期望 %v", c.name, c.port, err, c.want)
+		}
+		if c.want != nil && !isPortConflict(err) {
+			t.Errorf("%s: isPortConflict(%v) = false", c.name, err)
+		}
+	}
+
+	// 未配置端口池的节点只检查占用
+	if err := s.Check(exit, 18080, PortExclude{}); !stderrors.Is(err, errors.ErrRulePortExists) {
+		t.Errorf("节点 API 端口: err = %v，期望 ErrRulePortExists", err)
+	}
+	if err := s.Check(exit, 10002, PortExclude{}); err != nil {
+		t.Errorf("入口节点不占用 Relay 端口: err = %v", err)
+	}
+
+	// 附加出口节点同样占用 Relay 端口
+	extra := &model.GostNode{Name: "jp", Address: "3.3.3.3", Port: 18080, Status: model.NodeStatusOffline}
+	mustCreate(t, db, extra)
+	mustCreate(t, db, &model.TunnelExit{TunnelID: tunnel.ID, NodeID: extra.ID, Position: 1})
+	if err := s.Check(extra, 10002, PortExclude{}); !stderrors.Is(err, errors.ErrRulePortExists) {
+		t.Errorf("附加出口 Relay 端口: err = %v，期望 ErrRulePortExists", err)
+	}
+	if err := s.Check(extra, 10002, PortExclude{TunnelID: tunnel.ID}); err != nil {
+		t.Errorf("修改隧道自身（附加出口）: err = %v", err)
+	}
+
+	// 预留的临时端口
+	reservePort(node.ID, 10020, "测试预留")
+	err := s.Check(node, 10020, PortExclude{})
+	releasePort(node.ID, 10020)
+	if !stderrors.Is(err, errors.ErrRulePortExists) {
+		t.Errorf("预留端口: err = %v，期望 ErrRulePortExists", err)
+	}
+	if err = s.Check(node, 10020, PortExclude{}); err != nil {
+		t.Errorf("释放后: err = %v", err)
+	}
+}
+
+func TestPortServiceAllocate(t *testing.T) {
+	db := newTestDB(t)
+	node := &model.GostNode{
+		Name: "hk", Address: "1.1.1.1", Port: 18080, Status: model.NodeStatusOffline,
+		PortRanges: "10000-10005,20000-20010", ReservedPorts: "10001",
+	}
+	other := &model.GostNode{
+		Name: "sg", Address: "2.2.2.2", Port: 18080, Status: model.NodeStatusOffline,
+		PortRanges: "10004-10010",
+	}
+	mustCreate(t, db, node, other)
+	mustCreate(t, db, &model.GostRule{Name: "web", Type: model.RuleTypeForward, NodeID: &node.ID, ListenPort: 10000})
+	s := NewPortService(db)
+
+	// 10000 被占用、10001 保留
+	if port, err := s.Allocate(node, PortExclude{}); err != nil || port != 10002 {
+		t.Errorf("Allocate = %d, %v，期望 10002", port, err)
+	}
+	// 端口池用尽
+	full := &model.GostNode{Name: "full", Address: "3.3.3.3", Port: 18080, Status: model.NodeStatusOffline, PortRanges: "10001", ReservedPorts: "10001"}
+	if _, err := s.Allocate(full, PortExclude{}); !stderrors.Is(err, errors.ErrNoFreePort) {
+		t.Errorf("端口池用尽: err = %v，期望 ErrNoFreePort", err)
+	}
+
+	// 连续端口不跨越区间
+	if port, err := s.AllocateRange(node, 5, PortExclude{}); err != nil || port != 20000 {
+		t.Errorf("AllocateRange(5) = %d, %v，期望 20000", port, err)
+	}
+	if _, err := s.AllocateRange(node, 20, PortExclude{}); !stderrors.Is(err, errors.ErrNoFreePort) {
+		t.Errorf("AllocateRange(20): err = %v，期望 ErrNoFreePort", err)
+	}
+
+	// 共享端口须同时在两个节点的端口池内
+	if port, err := s.AllocateShared([]*model.GostNode{node, other}, PortExclude{}); err != nil || port != 10004 {
+		t.Errorf("AllocateShared = %d, %v，期望 10004", port, err)
+	}
+	// 内部端口不使用规则本次的监听端口
+	if port, err := s.AllocateInner(node, 0, 10002); err != nil || port != 10003 {
+		t.Errorf("AllocateInner = %d, %v，期望 10003", port, err)
+	}
+
+	node.PortRanges = "bad"
+	if _, err := s.Allocate(node, PortExclude{}); !stderrors.Is(err, errors.ErrPortRangeInvalid) {
+		t.Errorf("端口池格式错误: err = %v，期望 ErrPortRangeInvalid", err)
+	}
+}
@@ -4,10 +4,15 @@ package service
 import (
 	stderrors "errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"gost-panel/internal/dto"
 	"gost-panel/internal/errors"
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
 	"gost-panel/internal/utils"
@@ -17,25 +22,41 @@ import (
 	"gorm.io/gorm"
 )
 
+// ruleMaxPorts 单条端口范围规则最多包含的端口数
+const ruleMaxPorts = 1000
+
+// reverseHostPattern 反向代理规则的域名，支持 *. 开头的通配
+var reverseHostPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// reverseMu 串行化反向代理规则的启停，保证共享监听服务按最新的运行中规则重建
+var reverseMu sync.Mutex
+
 // RuleService 规则服务
 // 入口选择：NodeID 或 TunnelID 二选一
 // - 端口转发 (forward)：选择 NodeID，直接在该节点上创建转发服务
 // - 隧道转发 (tunnel)：选择 TunnelID，在隧道的入口节点上创建转发服务，使用隧道的 Chain
+// - 代理 (proxy)、反向代理 (reverse)：NodeID 或 TunnelID 均可
 type RuleService struct {
+	db            *gorm.DB
 	ruleRepo      *repository.RuleRepository
 	nodeRepo      *repository.NodeRepository
 	tunnelRepo    *repository.TunnelRepository
+	ipListRepo    *repository.IPListRepository
 	sysRepo       *repository.SystemConfigRepository
 	logService    *LogService
 	tunnelService *TunnelService
+	portService   *PortService
 }
 
 // NewRuleService 创建规则服务
 func NewRuleService(db *gorm.DB) *RuleService {
 	return &RuleService{
+		db:            db,
 		ruleRepo:      repository.NewRuleRepository(db),
 		nodeRepo:      repository.NewNodeRepository(db),
 		tunnelRepo:    repository.NewTunnelRepository(db),
+		ipListRepo:    repository.NewIPListRepository(db),
+		portService:   NewPortService(db),
 		sysRepo:       repository.NewSystemConfigRepository(db),
 		logService:    NewLogService(db),
 		tunnelService: NewTunnelService(db),
@@ -54,13 +75,16 @@ func (s *RuleService) Create(req *dto.CreateRuleReq, userID uint, username strin
 			return nil, errors.ErrNodeRequired
 		}
 		// 检查节点是否存在
-		_, err := s.nodeRepo.FindByID(*req.NodeID)
+		node, err := s.nodeRepo.FindByID(*req.NodeID)
 		if err != nil {
 			if stderrors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, errors.ErrNodeNotFound
 			}
 			return nil, err
 		}
+		if node.Maintenance {
+			return nil, errors.ErrNodeMaintenance
+		}
 		entryNodeID = *req.NodeID
 	} else if req.Type == string(model.RuleTypeTunnel) {
 		// 隧道转发：需要 TunnelID
@@ -75,19 +99,57 @@ func (s *RuleService) Create(req *dto.CreateRuleReq, userID uint, username strin
 			}
 			return nil, err
 		}
+		if tunnel.EntryNode != nil && tunnel.EntryNode.Maintenance {
+			return nil, errors.ErrNodeMaintenance
+		}
 		// 使用隧道的入口节点
 		entryNodeID = tunnel.EntryNodeID
+	} else if req.Type == string(model.RuleTypeProxy) || req.Type == string(model.RuleTypeReverse) {
+		// 代理、反向代理：入口为节点时直接出网，为隧道时经隧道出口
+		_, nodeID, _, err := s.resolveEntry(req.NodeID, req.TunnelID)
+		if err != nil {
+			return nil, err
+		}
+		entryNodeID = nodeID
 	} else {
 		return nil, errors.ErrRuleTypeInvalid
 	}
 
-	// 检查端口是否已被使用
-	exists, err := s.ruleRepo.ExistsByPort(entryNodeID, req.ListenPort)
+	// 端口范围需指定起始端口
+	if req.ListenPort == 0 && req.ListenPortEnd > 0 {
+		return nil, errors.ErrRulePortRangeInvalid
+	}
+	portEnd, mapping, err := normalizePortRange(req.ListenPort, req.ListenPortEnd, req.PortMapping, req.Targets)
+	if err != nil {
+		return nil, err
+	}
+	proxyType, proxyUsers, err := normalizeProxy(model.RuleType(req.Type), req.ProxyType, req.ProxyUsers, portEnd)
+	if err != nil {
+		return nil, err
+	}
+	host, err := normalizeReverse(model.RuleType(req.Type), req.Host, portEnd)
+	if err != nil {
+		return nil, err
+	}
+	targetOptions, err := normalizeTargetOptions(model.RuleType(req.Type), req.Targets, req.TargetOptions)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := s.normalizeSources(model.RuleType(req.Type), req.AllowSources, req.DenySources, req.AllowLists, req.DenyLists)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查端口是否可用，未指定时从端口池分配；反向代理规则之间可共用端口，但域名不能重复
+	portAllocMu.Lock()
+	defer portAllocMu.Unlock()
+	exclude := PortExclude{Shared: host != ""}
+	port, err := s.entryPort(entryNodeID, req.ListenPort, 0, portSpan(req.ListenPort, portEnd), false, exclude)
 	if err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, errors.ErrRulePortExists
+	if err = s.checkReverseHost(entryNodeID, port, host, 0); err != nil {
+		return nil, err
 	}
 
 	// 创建规则
@@ -96,13 +158,24 @@ func (s *RuleService) Create(req *dto.CreateRuleReq, userID uint, username strin
 		TunnelID:   req.TunnelID,
 		Name:       req.Name,
 		Type:       model.RuleType(req.Type),
-		ListenPort: req.ListenPort,
+		ListenPort: port,
 		Targets:    req.Targets,
 		Strategy:   req.Strategy,
 		EnableTLS:  req.EnableTLS,
 		Remark:     req.Remark,
 		Status:     model.RuleStatusStopped,
+
+		ListenPortEnd: portEnd,
+		PortMapping:   mapping,
+		Protocol:      ruleProtocol(req.Protocol),
+
+		MaxFails:      req.MaxFails,
+		FailTimeout:   req.FailTimeout,
+		TargetOptions: targetOptions,
 	}
+	setRuleProxy(rule, proxyType, proxyUsers)
+	setRuleReverse(rule, host)
+	setRuleSources(rule, sources)
 
 	if err = s.ruleRepo.Create(rule); err != nil {
 		return nil, err
@@ -119,7 +192,7 @@ func (s *RuleService) Create(req *dto.CreateRuleReq, userID uint, username strin
 		ip,
 		userAgent)
 
-	logger.Infof("创建规则成功: %s (:%d)", rule.Name, rule.ListenPort)
+	logger.Infof("创建规则成功: %s (:%s)", rule.Name, rulePortLabel(rule))
 	return rule, nil
 }
 
@@ -133,67 +206,867 @@ func (s *RuleService) Update(id uint, req *dto.UpdateRuleReq, userID uint, usern
 		return nil, err
 	}
 
-	// 检查是否在运行中
-	if rule.Status == model.RuleStatusRunning {
-		return nil, errors.ErrRuleRunning
+	// 检查是否在运行中
+	if rule.Status == model.RuleStatusRunning {
+		return nil, errors.ErrRuleRunning
+	}
+
+	// 获取入口节点 ID（用于端口冲突检查）
+	entryNodeID := s.getEntryNodeID(rule)
+
+	portEnd, mapping, err := normalizePortRange(req.ListenPort, req.ListenPortEnd, req.PortMapping, req.Targets)
+	if err != nil {
+		return nil, err
+	}
+	// API Token 读取的规则不含代理用户密码，未填写时沿用同名用户的原密码
+	fillProxyPasswords(req.ProxyUsers, rule.ProxyUsers)
+	proxyType, proxyUsers, err := normalizeProxy(rule.Type, req.ProxyType, req.ProxyUsers, portEnd)
+	if err != nil {
+		return nil, err
+	}
+	host, err := normalizeReverse(rule.Type, req.Host, portEnd)
+	if err != nil {
+		return nil, err
+	}
+	targetOptions, err := normalizeTargetOptions(rule.Type, req.Targets, req.TargetOptions)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := s.normalizeSources(rule.Type, req.AllowSources, req.DenySources, req.AllowLists, req.DenyLists)
+	if err != nil {
+		return nil, err
+	}
+
+	// 修改端口时检查新端口是否可用（排除自身），端口池收紧前创建的规则不改端口仍可更新
+	portAllocMu.Lock()
+	defer portAllocMu.Unlock()
+	if req.ListenPort != rule.ListenPort || portEnd != rule.ListenPortEnd {
+		exclude := PortExclude{RuleID: id, Shared: host != ""}
+		if _, err = s.entryPort(entryNodeID, req.ListenPort, 0, portSpan(req.ListenPort, portEnd), false, exclude); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.checkReverseHost(entryNodeID, req.ListenPort, host, id); err != nil {
+		return nil, err
+	}
+
+	// 更新规则（不修改类型和入口）
+	rule.Name = req.Name
+	rule.ListenPort = req.ListenPort
+	rule.ListenPortEnd = portEnd
+	rule.PortMapping = mapping
+	rule.Protocol = ruleProtocol(req.Protocol)
+	rule.Targets = req.Targets
+	rule.Strategy = req.Strategy
+	rule.EnableTLS = req.EnableTLS
+	rule.Remark = req.Remark
+	rule.MaxFails = req.MaxFails
+	rule.FailTimeout = req.FailTimeout
+	rule.TargetOptions = targetOptions
+	setRuleProxy(rule, proxyType, proxyUsers)
+	setRuleReverse(rule, host)
+	setRuleSources(rule, sources)
+
+	if err = s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionUpdate,
+		model.ResourceTypeRule,
+		rule.ID,
+		fmt.Sprintf("更新规则: %s", rule.Name),
+		ip,
+		userAgent)
+
+	return rule, nil
+}
+
+// Clone 克隆规则到指定节点或隧道
+// 复制目标地址、负载均衡等配置，可选立即启动并停止原规则
+func (s *RuleService) Clone(id uint, req *dto.CloneRuleReq, userID uint, username string, ip, userAgent string) (*model.GostRule, error) {
+	source, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleType, entryNodeID, target, err := s.resolveEntry(req.NodeID, req.TunnelID)
+	if err != nil {
+		return nil, err
+	}
+
+	portAllocMu.Lock()
+	span := portSpan(source.ListenPort, source.ListenPortEnd)
+	exclude := PortExclude{Shared: source.Type == model.RuleTypeReverse}
+	port, err := s.entryPort(entryNodeID, req.ListenPort, source.ListenPort, span, req.AutoPort, exclude)
+	if err == nil {
+		err = s.checkReverseHost(entryNodeID, port, source.Host, 0)
+	}
+	if err != nil {
+		portAllocMu.Unlock()
+		return nil, err
+	}
+
+	name := req.Name
+	if name == "" {
+		name = source.Name + "-copy"
+	}
+
+	rule := &model.GostRule{
+		Name:       name,
+		Type:       entryRuleType(source.Type, ruleType),
+		ListenPort: port,
+		Targets:    source.Targets,
+		Strategy:   source.Strategy,
+		EnableTLS:  source.EnableTLS,
+		Remark:     source.Remark,
+		Status:     model.RuleStatusStopped,
+
+		PortMapping: source.PortMapping,
+		Protocol:    source.Protocol,
+		ProxyType:   source.ProxyType,
+		ProxyUsers:  append(model.ProxyUserList(nil), source.ProxyUsers...),
+		Host:        source.Host,
+
+		MaxFails:      source.MaxFails,
+		FailTimeout:   source.FailTimeout,
+		TargetOptions: append(model.TargetOptionList(nil), source.TargetOptions...),
+
+		AllowSources: append(model.StringList(nil), source.AllowSources...),
+		DenySources:  append(model.StringList(nil), source.DenySources...),
+		AllowLists:   append(model.IDList(nil), source.AllowLists...),
+		DenyLists:    append(model.IDList(nil), source.DenyLists...),
+	}
+	if source.IsPortRange() {
+		rule.ListenPortEnd = port + span - 1
+	}
+	setRuleEntry(rule, req.NodeID, req.TunnelID)
+	if req.KeepStats {
+		rule.InputBytes = source.InputBytes
+		rule.OutputBytes = source.OutputBytes
+		rule.TotalBytes = source.TotalBytes
+		rule.TotalRequests = source.TotalRequests
+	}
+
+	err = s.ruleRepo.Create(rule)
+	portAllocMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// 启动失败时撤销克隆，原规则不受影响
+	if req.Start {
+		if err = s.start(rule); err != nil {
+			_ = s.ruleRepo.Delete(rule.ID)
+			return nil, err
+		}
+	}
+
+	details := fmt.Sprintf("克隆规则: %s -> %s (%s)", source.Name, rule.Name, target)
+	if req.StopSource && source.Status == model.RuleStatusRunning {
+		if err = s.stop(source); err != nil {
+			logger.Warnf("停止原规则失败: %v", err)
+		} else {
+			details += "，已停止原规则"
+		}
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionClone,
+		model.ResourceTypeRule,
+		rule.ID,
+		details,
+		ip,
+		userAgent)
+
+	logger.Infof("克隆规则成功: %s -> %s (%s)", source.Name, rule.Name, target)
+	return s.GetByID(rule.ID)
+}
+
+// Migrate 迁移规则到指定节点或隧道
+// 规则 ID 不变；运行中的规则先在原入口停止，再在目标上启动，启动失败时恢复原入口
+func (s *RuleService) Migrate(id uint, req *dto.MigrateRuleReq, userID uint, username string, ip, userAgent string) (*model.GostRule, error) {
+	rule, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	source, target, err := s.migrate(rule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionMigrate,
+		model.ResourceTypeRule,
+		rule.ID,
+		fmt.Sprintf("迁移规则: %s (%s -> %s)", rule.Name, source, target),
+		ip,
+		userAgent)
+
+	logger.Infof("迁移规则成功: %s (%s -> %s)", rule.Name, source, target)
+	return s.GetByID(rule.ID)
+}
+
+// migrate 更换规则入口，返回原入口和目标的描述
+func (s *RuleService) migrate(rule *model.GostRule, req *dto.MigrateRuleReq) (string, string, error) {
+	ruleType, entryNodeID, target, err := s.resolveEntry(req.NodeID, req.TunnelID)
+	if err != nil {
+		return "", "", err
+	}
+	if isSameEntry(rule, req.NodeID, req.TunnelID) {
+		return "", "", errors.ErrRuleTargetSame
+	}
+
+	// 端口分配锁只保护选择端口与写入数据库，停止、启动服务的节点请求在释放锁后进行
+	portAllocMu.Lock()
+	span := portSpan(rule.ListenPort, rule.ListenPortEnd)
+	exclude := PortExclude{RuleID: rule.ID, Shared: rule.Type == model.RuleTypeReverse}
+	port, err := s.entryPort(entryNodeID, req.ListenPort, rule.ListenPort, span, req.AutoPort, exclude)
+	if err != nil {
+		portAllocMu.Unlock()
+		return "", "", err
+	}
+	// 反向代理规则在目标节点上重新分配内部端口（持有端口分配锁，不能留到启动时分配）
+	innerPort := 0
+	if rule.Type == model.RuleTypeReverse {
+		if err = s.checkReverseHost(entryNodeID, port, rule.Host, rule.ID); err != nil {
+			portAllocMu.Unlock()
+			return "", "", err
+		}
+		if innerPort, err = s.allocateInnerPort(entryNodeID, rule.ID, port); err != nil {
+			portAllocMu.Unlock()
+			return "", "", err
+		}
+	}
+
+	source := ruleEntryLabel(rule)
+	wasRunning := rule.Status == model.RuleStatusRunning
+
+	// Save 会按已加载的关联回写外键，更新入口前先清空
+	rule.Node, rule.Tunnel = nil, nil
+	running := *rule
+	rule.Status = model.RuleStatusStopped
+	original := *rule
+
+	rule.Type = entryRuleType(rule.Type, ruleType)
+	if rule.IsPortRange() {
+		rule.ListenPortEnd = port + span - 1
+	}
+	rule.ListenPort = port
+	rule.InnerPort = innerPort
+	rule.ServiceID = ""
+	rule.ObserverID = ""
+	setRuleEntry(rule, req.NodeID, req.TunnelID)
+
+	// 目标上是新服务，上报的累计值从零开始
+	rule.LastReportedInputBytesTCP = 0
+	rule.LastReportedOutputBytesTCP = 0
+	rule.LastReportedTotalConnsTCP = 0
+	rule.LastReportedInputBytesUDP = 0
+	rule.LastReportedOutputBytesUDP = 0
+	rule.LastReportedTotalConnsUDP = 0
+	if !req.KeepStats {
+		rule.InputBytes = 0
+		rule.OutputBytes = 0
+		rule.TotalBytes = 0
+		rule.TotalRequests = 0
+	}
+
+	err = s.ruleRepo.Update(rule)
+	portAllocMu.Unlock()
+	if err != nil {
+		*rule = running
+		return "", "", err
+	}
+
+	// 在原入口停止，此时数据库中已是新入口，反向代理的共享监听服务会移除该规则的域名
+	if wasRunning {
+		if err = s.stop(&running); err != nil {
+			s.rollbackMigrate(rule, &original, wasRunning)
+			return "", "", err
+		}
+	}
+
+	if wasRunning || req.Start {
+		if err = s.start(rule); err != nil {
+			s.rollbackMigrate(rule, &original, wasRunning)
+			return "", "", err
+		}
+	}
+
+	// 服务已在新入口上启动，清理原入口服务的累计值
+	if err = s.ruleRepo.DeleteServiceCounters(rule.ID); err != nil {
+		logger.Warnf("清理规则服务累计值失败: %v", err)
+	}
+	return source, target, nil
+}
+
+// rollbackMigrate 迁移失败时删除新入口上已创建的服务，恢复原入口，原来在运行的重新启动
+func (s *RuleService) rollbackMigrate(rule, original *model.GostRule, wasRunning bool) {
+	if err := s.stop(rule); err != nil {
+		logger.Warnf("清理新入口上的规则服务失败: %v", err)
+	}
+	if err := s.ruleRepo.Update(original); err != nil {
+		logger.Errorf("恢复规则入口失败: %v", err)
+	} else if wasRunning {
+		if err = s.start(original); err != nil {
+			logger.Warnf("恢复启动原规则失败: %v", err)
+		}
+	}
+	*rule = *original
+}
+
+// entryRuleType 更换入口后的规则类型，代理、反向代理规则保持不变
+func entryRuleType(current, resolved model.RuleType) model.RuleType {
+	if current == model.RuleTypeProxy || current == model.RuleTypeReverse {
+		return current
+	}
+	return resolved
+}
+
+// resolveEntry 校验克隆/迁移目标，返回规则类型、入口节点 ID 和目标描述
+func (s *RuleService) resolveEntry(nodeID, tunnelID *uint) (model.RuleType, uint, string, error) {
+	hasNode := nodeID != nil && *nodeID > 0
+	hasTunnel := tunnelID != nil && *tunnelID > 0
+	if hasNode == hasTunnel {
+		return "", 0, "", errors.ErrRuleTargetRequired
+	}
+
+	if hasNode {
+		node, err := s.nodeRepo.FindByID(*nodeID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return "", 0, "", errors.ErrNodeNotFound
+			}
+			return "", 0, "", err
+		}
+		if node.Maintenance {
+			return "", 0, "", errors.ErrNodeMaintenance
+		}
+		return model.RuleTypeForward, node.ID, "节点 " + node.Name, nil
+	}
+
+	tunnel, err := s.tunnelRepo.FindByID(*tunnelID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return "", 0, "", errors.ErrTunnelNotFound
+		}
+		return "", 0, "", err
+	}
+	if tunnel.EntryNode != nil && tunnel.EntryNode.Maintenance {
+		return "", 0, "", errors.ErrNodeMaintenance
+	}
+	return model.RuleTypeTunnel, tunnel.EntryNodeID, "隧道 " + tunnel.Name, nil
+}
+
+// entryPort 确定规则在入口节点上的监听（起始）端口，span 为端口数
+// 指定 port 时检查能否使用；否则沿用 fallback，fallback 冲突且允许自动分配（或没有 fallback）时从端口池分配
+func (s *RuleService) entryPort(entryNodeID uint, port, fallback, span int, autoPort bool, exclude PortExclude) (int, error) {
+	node, err := s.nodeRepo.FindByID(entryNodeID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.ErrNodeNotFound
+		}
+		return 0, err
+	}
+
+	if port > 0 {
+		if port+span-1 > 65535 {
+			return 0, errors.ErrRulePortRangeInvalid
+		}
+		return port, s.portService.CheckRange(node, port, port+span-1, exclude)
+	}
+	if fallback > 0 {
+		err = s.portService.CheckRange(node, fallback, fallback+span-1, exclude)
+		if err == nil || !autoPort || !isPortConflict(err) {
+			return fallback, err
+		}
+	}
+	return s.portService.AllocateRange(node, span, exclude)
+}
+
+// normalizePortRange 校验端口范围，返回规范化的结束端口和映射方式
+// 结束端口为 0 或等于起始端口时为单端口规则；1:1 映射时检查目标端口偏移后不越界
+func normalizePortRange(start, end int, mapping string, targets []string) (int, string, error) {
+	if end == 0 || end == start {
+		return 0, "", nil
+	}
+	if end < start {
+		return 0, "", errors.ErrRulePortRangeInvalid
+	}
+	if end-start+1 > ruleMaxPorts {
+		return 0, "", errors.ErrRulePortRangeTooLarge
+	}
+	if mapping == "" {
+		mapping = model.PortMappingRange
+	}
+	if mapping == model.PortMappingRange {
+		for _, target := range targets {
+			if _, err := gost.OffsetTarget(target, end-start); err != nil {
+				return 0, "", errors.ErrRulePortRangeInvalid
+			}
+		}
+	}
+	return end, mapping, nil
+}
+
+// normalizeProxy 校验代理规则配置，返回代理类型和认证用户；非代理规则返回空值
+func normalizeProxy(ruleType model.RuleType, proxyType string, users []dto.ProxyUser, portEnd int) (string, model.ProxyUserList, error) {
+	if ruleType != model.RuleTypeProxy {
+		return "", nil, nil
+	}
+	if portEnd > 0 {
+		return "", nil, errors.ErrProxyPortRange
+	}
+	if proxyType == "" {
+		proxyType = model.ProxyTypeAuto
+	}
+	if len(users) == 0 {
+		return "", nil, errors.ErrProxyUsersInvalid
+	}
+
+	list := make(model.ProxyUserList, 0, len(users))
+	seen := make(map[string]bool, len(users))
+	for _, user := range users {
+		if user.Username == "" || user.Password == "" || seen[user.Username] {
+			return "", nil, errors.ErrProxyUsersInvalid
+		}
+		seen[user.Username] = true
+		list = append(list, model.ProxyUser{Username: user.Username, Password: user.Password})
+	}
+	return proxyType, list, nil
+}
+
+// setRuleProxy 设置代理配置；代理规则只监听 TCP，不使用转发目标
+func setRuleProxy(rule *model.GostRule, proxyType string, users model.ProxyUserList) {
+	rule.ProxyType, rule.ProxyUsers = proxyType, users
+	if rule.Type == model.RuleTypeProxy {
+		rule.Protocol = model.RuleProtocolTCP
+		rule.Targets = nil
+		rule.Strategy = ""
+	}
+}
+
+// normalizeReverse 校验反向代理规则配置，返回规范化的域名；非反向代理规则返回空值
+func normalizeReverse(ruleType model.RuleType, host string, portEnd int) (string, error) {
+	if ruleType != model.RuleTypeReverse {
+		return "", nil
+	}
+	if portEnd > 0 {
+		return "", errors.ErrReversePortRange
+	}
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if len(host) > 253 || !reverseHostPattern.MatchString(host) {
+		return "", errors.ErrReverseHostInvalid
+	}
+	return host, nil
+}
+
+// normalizeTargetOptions 校验目标权重与备用设置，只保留非默认值；代理规则返回空值
+func normalizeTargetOptions(ruleType model.RuleType, targets []string, options []dto.TargetOption) (model.TargetOptionList, error) {
+	if ruleType == model.RuleTypeProxy || len(options) == 0 {
+		return nil, nil
+	}
+
+	list := make(model.TargetOptionList, 0, len(options))
+	seen := make(map[string]bool, len(options))
+	backups := 0
+	for _, opt := range options {
+		target := strings.TrimSpace(opt.Target)
+		if !slices.Contains(targets, target) || seen[target] || opt.Weight < 0 || opt.Weight > 100 {
+			return nil, errors.ErrTargetOptionsInvalid
+		}
+		seen[target] = true
+		if opt.Backup {
+			backups++
+		}
+		if opt.Weight > 0 || opt.Backup {
+			list = append(list, model.TargetOption{Target: target, Weight: opt.Weight, Backup: opt.Backup})
+		}
+	}
+	// 至少保留一个非备用目标
+	if backups >= len(targets) {
+		return nil, errors.ErrTargetOptionsInvalid
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list, nil
+}
+
+// ruleTargetOptions 按目标顺序返回 GOST 节点的权重与备用设置
+func ruleTargetOptions(rule *model.GostRule) []gost.TargetOption {
+	options := make([]gost.TargetOption, len(rule.Targets))
+	for i, target := range rule.Targets {
+		opt := model.FindTargetOption(rule.TargetOptions, target)
+		options[i] = gost.TargetOption{Weight: opt.Weight, Backup: opt.Backup}
+	}
+	return options
+}
+
+// ruleSources 规范化后的来源 IP 访问控制配置
+type ruleSources struct {
+	allow, deny           model.StringList
+	allowLists, denyLists model.IDList
+}
+
+// normalizeSources 校验来源 IP 访问控制配置，引用的 IP 列表须存在
+func (s *RuleService) normalizeSources(ruleType model.RuleType, allow, deny []string, allowLists, denyLists []uint) (*ruleSources, error) {
+	sources := &ruleSources{}
+	var err error
+	if sources.allow, err = normalizeIPEntries(allow); err != nil {
+		return nil, errors.ErrRuleSourcesInvalid
+	}
+	if sources.deny, err = normalizeIPEntries(deny); err != nil {
+		return nil, errors.ErrRuleSourcesInvalid
+	}
+	if sources.allowLists, err = s.checkIPLists(allowLists); err != nil {
+		return nil, err
+	}
+	if sources.denyLists, err = s.checkIPLists(denyLists); err != nil {
+		return nil, err
+	}
+	if ruleType == model.RuleTypeReverse && len(sources.allow)+len(sources.deny)+len(sources.allowLists)+len(sources.denyLists) > 0 {
+		return nil, errors.ErrReverseAdmission
+	}
+	return sources, nil
+}
+
+// checkIPLists 去重并检查引用的 IP 列表是否存在
+func (s *RuleService) checkIPLists(ids []uint) (model.IDList, error) {
+	var result model.IDList
+	for _, id := range ids {
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	lists, err := s.ipListRepo.FindByIDs(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) != len(result) {
+		return nil, errors.ErrIPListNotFound
+	}
+	return result, nil
+}
+
+// setRuleSources 设置来源 IP 访问控制
+func setRuleSources(rule *model.GostRule, sources *ruleSources) {
+	rule.AllowSources, rule.DenySources = sources.allow, sources.deny
+	rule.AllowLists, rule.DenyLists = sources.allowLists, sources.denyLists
+}
+
+// ruleAdmissionNames 规则的准入控制器名称（允许、拒绝）
+func ruleAdmissionNames(rule *model.GostRule) (string, string) {
+	serviceID := rule.ServiceID
+	if serviceID == "" {
+		serviceID = fmt.Sprintf("rule-%d", rule.ID)
+	}
+	return serviceID + "-allow", serviceID + "-deny"
+}
+
+// buildRuleAdmissions 合并规则自身与引用 IP 列表的条目，生成准入控制器配置
+// 配置了允许（拒绝）来源时总会生成对应的控制器，引用的列表为空也不例外，
+// 这样更新 IP 列表只需更新控制器，不需要改动规则的服务
+func (s *RuleService) buildRuleAdmissions(rule *model.GostRule, serviceName string) ([]*gost.AdmissionConfig, error) {
+	var admissions []*gost.AdmissionConfig
+	if len(rule.AllowSources)+len(rule.AllowLists) > 0 {
+		matchers, err := s.mergeSources(rule.AllowSources, rule.AllowLists)
+		if err != nil {
+			return nil, err
+		}
+		admissions = append(admissions, &gost.AdmissionConfig{Name: serviceName + "-allow", Whitelist: true, Matchers: matchers})
+	}
+	if len(rule.DenySources)+len(rule.DenyLists) > 0 {
+		matchers, err := s.mergeSources(rule.DenySources, rule.DenyLists)
+		if err != nil {
+			return nil, err
+		}
+		admissions = append(admissions, &gost.AdmissionConfig{Name: serviceName + "-deny", Matchers: matchers})
+	}
+	return admissions, nil
+}
+
+// mergeSources 合并来源条目与 IP 列表条目（去重，保持顺序）
+func (s *RuleService) mergeSources(entries model.StringList, listIDs model.IDList) ([]string, error) {
+	matchers := append([]string{}, entries...)
+	lists, err := s.ipListRepo.FindByIDs(listIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		for _, entry := range list.Entries {
+			if !slices.Contains(matchers, entry) {
+				matchers = append(matchers, entry)
+			}
+		}
+	}
+	return matchers, nil
+}
+
+// deleteRuleAdmissions 删除规则的准入控制器
+func deleteRuleAdmissions(client *gost.Client, rule *model.GostRule) {
+	allow, deny := ruleAdmissionNames(rule)
+	for _, name := range []string{allow, deny} {
+		if err := client.DeleteAdmission(name); err != nil {
+			logger.Warnf("删除 Gost 准入控制器失败: %v", err)
+		}
+	}
+}
+
+// RefreshAdmissions 按最新的 IP 列表更新引用它的运行中规则的准入控制器，返回更新的规则数
+func (s *RuleService) RefreshAdmissions(listID uint) (int, error) {
+	rules, _, err := s.ruleRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.RuleStatusRunning},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for i := range rules {
+		rule := &rules[i]
+		if !slices.Contains(rule.AllowLists, listID) && !slices.Contains(rule.DenyLists, listID) {
+			continue
+		}
+		node, err := s.nodeRepo.FindByID(s.getEntryNodeID(rule))
+		if err != nil || node.Status == model.NodeStatusOffline {
+			logger.Warnf("规则 %s 的入口节点不可用，跳过更新准入控制器", rule.Name)
+			continue
+		}
+		serviceName := rule.ServiceID
+		if serviceName == "" {
+			serviceName = fmt.Sprintf("rule-%d", rule.ID)
+		}
+		admissions, err := s.buildRuleAdmissions(rule, serviceName)
+		if err != nil {
+			return refreshed, err
+		}
+		client := utils.GetGostClient(node)
+		for _, admission := range admissions {
+			if err = client.ApplyAdmission(admission); err != nil {
+				logger.Warnf("更新规则 %s 的准入控制器失败: %v", rule.Name, err)
+			}
+		}
+		_ = client.SaveConfig()
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// setRuleReverse 设置反向代理域名；反向代理规则只转发 TCP
+func setRuleReverse(rule *model.GostRule, host string) {
+	rule.Host = host
+	if rule.Type == model.RuleTypeReverse {
+		rule.Protocol = model.RuleProtocolTCP
+	}
+}
+
+// checkReverseHost 检查入口节点同一端口上是否已有相同域名的反向代理规则，host 为空时不检查
+func (s *RuleService) checkReverseHost(entryNodeID uint, port int, host string, excludeID uint) error {
+	if host == "" {
+		return nil
+	}
+	rules, err := s.ruleRepo.FindByEntryNodeID(entryNodeID)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if r.ID != excludeID && r.Type == model.RuleTypeReverse && r.ListenPort == port && r.Host == host {
+			return errors.ErrReverseHostExists
+		}
+	}
+	return nil
+}
+
+// allocateInnerPort 为反向代理规则分配入口节点上的内部端口，调用方需持有 portAllocMu
+func (s *RuleService) allocateInnerPort(entryNodeID, ruleID uint, listenPort int) (int, error) {
+	node, err := s.nodeRepo.FindByID(entryNodeID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.ErrNodeNotFound
+		}
+		return 0, err
+	}
+	return s.portService.AllocateInner(node, ruleID, listenPort)
+}
+
+// ensureInnerPort 反向代理规则还没有内部端口时（如清单导入、克隆的规则）分配并保存
+func (s *RuleService) ensureInnerPort(rule *model.GostRule, entryNodeID uint) error {
+	if rule.InnerPort > 0 {
+		return nil
+	}
+	portAllocMu.Lock()
+	defer portAllocMu.Unlock()
+	port, err := s.allocateInnerPort(entryNodeID, rule.ID, rule.ListenPort)
+	if err != nil {
+		return err
+	}
+	if err = s.ruleRepo.UpdateInnerPort(rule.ID, port); err != nil {
+		return err
+	}
+	rule.InnerPort = port
+	return nil
+}
+
+// syncHostRoutes 按入口节点上运行中的反向代理规则重建 port 上的共享监听服务，没有规则时删除
+// 调用方需持有 reverseMu
+func (s *RuleService) syncHostRoutes(client *gost.Client, entryNodeID uint, port int) error {
+	rules, err := s.ruleRepo.FindByEntryNodeID(entryNodeID)
+	if err != nil {
+		return err
+	}
+	var routes []gost.HostRoute
+	for _, r := range rules {
+		if r.Type != model.RuleTypeReverse || r.ListenPort != port || r.Status != model.RuleStatusRunning || r.InnerPort == 0 {
+			continue
+		}
+		routes = append(routes, gost.HostRoute{
+			Name: fmt.Sprintf("rule-%d", r.ID),
+			Host: r.Host,
+			Addr: fmt.Sprintf("127.0.0.1:%d", r.InnerPort),
+		})
+	}
+
+	if len(routes) == 0 {
+		err = client.DeleteService(gost.HostRouteServiceName(port))
+	} else {
+		err = client.ApplyService(gost.BuildHostRouteService(port, routes))
+	}
+	if err != nil {
+		return err
+	}
+	_ = client.SaveConfig()
+	return nil
+}
+
+// buildRuleAuther 构建代理规则的认证器配置
+func buildRuleAuther(name string, users model.ProxyUserList) *gost.AutherConfig {
+	auther := &gost.AutherConfig{Name: name, Auths: make([]*gost.AuthConfig, 0, len(users))}
+	for _, user := range users {
+		auther.Auths = append(auther.Auths, &gost.AuthConfig{Username: user.Username, Password: user.Password})
+	}
+	return auther
+}
+
+// ruleAutherName 代理规则在节点上的认证器名称
+func ruleAutherName(rule *model.GostRule) string {
+	serviceID := rule.ServiceID
+	if serviceID == "" {
+		serviceID = fmt.Sprintf("rule-%d", rule.ID)
+	}
+	return serviceID + "-auther"
+}
+
+// ruleProtocol 规范化规则协议，未指定时为 TCP+UDP
+func ruleProtocol(protocol string) model.RuleProtocol {
+	if protocol == "" {
+		return model.RuleProtocolBoth
 	}
+	return model.RuleProtocol(protocol)
+}
 
-	// 获取入口节点 ID（用于端口冲突检查）
-	entryNodeID := s.getEntryNodeID(rule)
+// portSpan 端口范围包含的端口数
+func portSpan(start, end int) int {
+	if end > start {
+		return end - start + 1
+	}
+	return 1
+}
 
-	// 检查端口是否已被使用（排除自身）
-	exists, err := s.ruleRepo.ExistsByPort(entryNodeID, req.ListenPort, id)
-	if err != nil {
-		return nil, err
+// rulePortLabel 规则监听端口的描述，如 "8080" 或 "27015-27030"
+func rulePortLabel(rule *model.GostRule) string {
+	if rule.IsPortRange() {
+		return fmt.Sprintf("%d-%d", rule.ListenPort, rule.ListenPortEnd)
 	}
-	if exists {
-		return nil, errors.ErrRulePortExists
+	return fmt.Sprint(rule.ListenPort)
+}
+
+// ruleServiceNames 规则在节点上对应的全部 GOST 服务名
+// 每个协议一个服务，端口范围规则每个端口一组；单端口规则兼容未拆分协议的旧服务名
+func ruleServiceNames(rule *model.GostRule) []string {
+	serviceID := rule.ServiceID
+	if serviceID == "" {
+		serviceID = fmt.Sprintf("rule-%d", rule.ID)
 	}
 
-	// 更新规则（不修改类型和入口）
-	rule.Name = req.Name
-	rule.ListenPort = req.ListenPort
-	rule.Targets = req.Targets
-	rule.Strategy = req.Strategy
-	rule.EnableTLS = req.EnableTLS
-	rule.Remark = req.Remark
+	protocols := rule.Protocols()
+	if rule.IsPortRange() {
+		names := make([]string, 0, portSpan(rule.ListenPort, rule.ListenPortEnd)*len(protocols))
+		for _, port := range rule.ListenPorts() {
+			name := gost.RangeServiceName(serviceID, port)
+			for _, protocol := range protocols {
+				names = append(names, name+"-"+protocol)
+			}
+		}
+		return names
+	}
 
-	if err = s.ruleRepo.Update(rule); err != nil {
-		return nil, err
+	names := []string{serviceID}
+	if !strings.HasSuffix(serviceID, "-tcp") && !strings.HasSuffix(serviceID, "-udp") {
+		for _, protocol := range protocols {
+			names = append(names, serviceID+"-"+protocol)
+		}
 	}
+	return names
+}
 
-	s.logService.Record(
-		userID,
-		username,
-		model.ActionUpdate,
-		model.ResourceTypeRule,
-		rule.ID,
-		fmt.Sprintf("更新规则: %s", rule.Name),
-		ip,
-		userAgent)
+// setRuleEntry 设置规则入口，节点与隧道只保留其一
+func setRuleEntry(rule *model.GostRule, nodeID, tunnelID *uint) {
+	if nodeID != nil && *nodeID > 0 {
+		id := *nodeID
+		rule.NodeID, rule.TunnelID = &id, nil
+		return
+	}
+	id := *tunnelID
+	rule.NodeID, rule.TunnelID = nil, &id
+}
 
-	return rule, nil
+// isSameEntry 目标是否为规则当前的入口
+func isSameEntry(rule *model.GostRule, nodeID, tunnelID *uint) bool {
+	if nodeID != nil && *nodeID > 0 {
+		return rule.TunnelID == nil && rule.NodeID != nil && *rule.NodeID == *nodeID
+	}
+	return rule.TunnelID != nil && *rule.TunnelID == *tunnelID
+}
+
+// ruleEntryLabel 规则入口描述，用于日志
+func ruleEntryLabel(rule *model.GostRule) string {
+	if rule.TunnelID != nil {
+		if rule.Tunnel != nil {
+			return "隧道 " + rule.Tunnel.Name
+		}
+		if rule.TunnelID != nil {
+			return fmt.Sprintf("隧道 #%d", *rule.TunnelID)
+		}
+	}
+	if rule.Node != nil {
+		return "节点 " + rule.Node.Name
+	}
+	if rule.NodeID != nil {
+		return fmt.Sprintf("节点 #%d", *rule.NodeID)
+	}
+	return "-"
 }
 
 // Delete 删除规则
 func (s *RuleService) Delete(id uint, userID uint, username string, ip, userAgent string) error {
-	rule, err := s.ruleRepo.FindByID(id)
+	rule, err := s.GetByID(id)
 	if err != nil {
-		if stderrors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.ErrRuleNotFound
-		}
 		return err
 	}
 
-	// 如果正在运行，先停止
-	if rule.Status == model.RuleStatusRunning {
-		if err = s.Stop(id, userID, username, ip, userAgent); err != nil {
-			logger.Warnf("停止规则失败: %v", err)
-		}
-	}
-
-	// 删除规则
-	if err = s.ruleRepo.Delete(id); err != nil {
+	if err = s.remove(rule); err != nil {
 		return err
 	}
 
@@ -211,6 +1084,23 @@ func (s *RuleService) Delete(id uint, userID uint, username string, ip, userAgen
 	return nil
 }
 
+// remove 删除规则，正在运行时先停止
+func (s *RuleService) remove(rule *model.GostRule) error {
+	if rule.Status == model.RuleStatusRunning {
+		if err := s.stop(rule); err != nil {
+			logger.Warnf("停止规则失败: %v", err)
+		}
+	}
+	if err := s.ruleRepo.Delete(rule.ID); err != nil {
+		return err
+	}
+	metrics.ForgetRule(rule.ID)
+	if err := s.ruleRepo.DeleteServiceCounters(rule.ID); err != nil {
+		logger.Warnf("清理规则服务累计值失败: %v", err)
+	}
+	return nil
+}
+
 // GetByID 获取规则详情
 func (s *RuleService) GetByID(id uint) (*model.GostRule, error) {
 	rule, err := s.ruleRepo.FindByID(id)
@@ -223,6 +1113,43 @@ func (s *RuleService) GetByID(id uint) (*model.GostRule, error) {
 	return rule, nil
 }
 
+// Targets 获取规则转发目标的权重、备用设置及探测状态
+// 状态来自面板对运行中规则目标的定期 TCP 探测，按规则的失败判定计算目标是否失效
+func (s *RuleService) Targets(id uint) (*dto.RuleTargetsResp, error) {
+	rule, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	maxFails := rule.SelectorMaxFails()
+	failTimeout := rule.SelectorFailTimeout()
+	resp := &dto.RuleTargetsResp{
+		RuleID:      rule.ID,
+		MaxFails:    maxFails,
+		FailTimeout: failTimeout,
+		Reason:      targetCheckSkipReason(rule),
+		Targets:     make([]dto.RuleTargetStatus, 0, len(rule.Targets)),
+	}
+	if resp.Reason == "" && rule.Status != model.RuleStatusRunning {
+		resp.Reason = "规则未运行"
+	}
+	resp.Checked = resp.Reason == ""
+
+	for _, target := range rule.Targets {
+		opt := model.FindTargetOption(rule.TargetOptions, target)
+		item := dto.RuleTargetStatus{Target: target, Weight: opt.Weight, Backup: opt.Backup}
+		if state, ok := targetHealth.get(rule.ID, target); ok && resp.Checked {
+			lastCheck := state.lastCheck
+			item.Failed = state.failed(maxFails, time.Duration(failTimeout)*time.Second)
+			item.Fails = state.fails
+			item.LastError = state.lastError
+			item.LastCheck = &lastCheck
+		}
+		resp.Targets = append(resp.Targets, item)
+	}
+	return resp, nil
+}
+
 // List 获取规则列表
 func (s *RuleService) List(req *dto.RuleListReq) ([]model.GostRule, int64, error) {
 	req.SetDefaults()
@@ -232,33 +1159,47 @@ func (s *RuleService) List(req *dto.RuleListReq) ([]model.GostRule, int64, error
 			Page:     req.Page,
 			PageSize: req.PageSize,
 		},
-		Conditions: make(map[string]any),
+		Conditions: ruleConditions(&dto.RuleFilter{
+			NodeID:   req.NodeID,
+			TunnelID: req.TunnelID,
+			Type:     req.Type,
+			Status:   req.Status,
+			Keyword:  req.Keyword,
+		}),
 	}
 
-	if req.NodeID > 0 {
-		opt.Conditions["node_id = ?"] = req.NodeID
+	return s.ruleRepo.List(opt)
+}
+
+// ruleConditions 规则筛选条件
+func ruleConditions(f *dto.RuleFilter) map[string]any {
+	conditions := make(map[string]any)
+	if f.NodeID > 0 {
+		// 隧道转发规则监听在隧道的入口节点上，与 RuleRepository.FindByEntryNodeID 一致
+		conditions["node_id = ? OR tunnel_id IN (SELECT id FROM tunnels WHERE entry_node_id = ?)"] = []interface{}{
+			f.NodeID, f.NodeID,
+		}
 	}
-	if req.TunnelID > 0 {
-		opt.Conditions["tunnel_id = ?"] = req.TunnelID
+	if f.TunnelID > 0 {
+		conditions["tunnel_id = ?"] = f.TunnelID
 	}
-	if req.Type != "" {
-		opt.Conditions["type = ?"] = req.Type
+	if f.Type != "" {
+		conditions["type = ?"] = f.Type
 	}
-	if req.Status != "" {
-		opt.Conditions["status = ?"] = req.Status
+	if f.Status != "" {
+		conditions["status = ?"] = f.Status
 	}
-	if req.Keyword != "" {
-		opt.Conditions["name LIKE ?"] = []interface{}{
-			"%" + req.Keyword + "%",
+	if f.Keyword != "" {
+		conditions["name LIKE ?"] = []interface{}{
+			"%" + f.Keyword + "%",
 		}
 	}
-
-	return s.ruleRepo.List(opt)
+	return conditions
 }
 
 // Start 启动规则
 func (s *RuleService) Start(id uint, userID uint, username string, ip, userAgent string) error {
-	rule, err := s.ruleRepo.FindByID(id)
+	rule, err := s.GetByID(id)
 	if err != nil {
 		return err
 	}
@@ -268,6 +1209,26 @@ func (s *RuleService) Start(id uint, userID uint, username string, ip, userAgent
 		return nil
 	}
 
+	if err = s.start(rule); err != nil {
+		return err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionStart,
+		model.ResourceTypeRule,
+		id,
+		fmt.Sprintf("启动规则: %s", rule.Name),
+		ip,
+		userAgent)
+
+	logger.Infof("启动规则成功: %s", rule.Name)
+	return nil
+}
+
+// start 在入口节点上创建规则服务
+func (s *RuleService) start(rule *model.GostRule) error {
 	// 获取入口节点
 	entryNodeID := s.getEntryNodeID(rule)
 	node, err := s.nodeRepo.FindByID(entryNodeID)
@@ -281,28 +1242,46 @@ func (s *RuleService) Start(id uint, userID uint, username string, ip, userAgent
 	client := utils.GetGostClient(node)
 	serviceName := fmt.Sprintf("rule-%d", rule.ID)
 
-	// 根据规则类型处理
-	if rule.Type == model.RuleTypeTunnel {
-		if err = s.startTunnelRule(rule, client, serviceName); err != nil {
-			return err
-		}
-	} else {
-		if err = s.startForwardRule(rule, client, serviceName); err != nil {
-			return err
-		}
+	if rule.Type == model.RuleTypeReverse {
+		return s.startReverseRule(rule, client, serviceName, node.ID)
 	}
 
-	s.logService.Record(
-		userID,
-		username,
-		model.ActionStart,
-		model.ResourceTypeRule,
-		id,
-		fmt.Sprintf("启动规则: %s", rule.Name),
-		ip,
-		userAgent)
+	// 根据入口处理：经隧道的规则（隧道转发、经隧道出口的代理）使用隧道的 Chain
+	if rule.TunnelID != nil {
+		return s.startTunnelRule(rule, client, serviceName)
+	}
+	return s.startForwardRule(rule, client, serviceName)
+}
 
-	logger.Infof("启动规则成功: %s", rule.Name)
+// startReverseRule 启动反向代理规则
+// 先在 127.0.0.1 内部端口上创建规则自己的转发服务，再把域名加入入口端口的共享监听服务
+func (s *RuleService) startReverseRule(rule *model.GostRule, client *gost.Client, serviceName string, entryNodeID uint) error {
+	if err := s.ensureInnerPort(rule, entryNodeID); err != nil {
+		return err
+	}
+
+	reverseMu.Lock()
+	defer reverseMu.Unlock()
+
+	var err error
+	if rule.TunnelID != nil {
+		err = s.startTunnelRule(rule, client, serviceName)
+	} else {
+		err = s.startForwardRule(rule, client, serviceName)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = s.syncHostRoutes(client, entryNodeID, rule.ListenPort); err != nil {
+		logger.Warnf("更新共享监听服务失败: %v", err)
+		for _, name := range ruleServiceNames(rule) {
+			_ = client.DeleteService(name)
+		}
+		_ = client.SaveConfig()
+		_ = s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusError)
+		return errors.ErrRuleStartFailed
+	}
 	return nil
 }
 
@@ -341,7 +1320,7 @@ func (s *RuleService) startTunnelRule(rule *model.GostRule, client *gost.Client,
 
 // Stop 停止规则
 func (s *RuleService) Stop(id uint, userID uint, username string, ip, userAgent string) error {
-	rule, err := s.ruleRepo.FindByID(id)
+	rule, err := s.GetByID(id)
 	if err != nil {
 		return err
 	}
@@ -350,61 +1329,76 @@ func (s *RuleService) Stop(id uint, userID uint, username string, ip, userAgent
 		return nil
 	}
 
+	if err = s.stop(rule); err != nil {
+		return err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionStop,
+		model.ResourceTypeRule,
+		id,
+		fmt.Sprintf("停止规则: %s", rule.Name),
+		ip,
+		userAgent)
+
+	logger.Infof("停止规则成功: %s", rule.Name)
+	return nil
+}
+
+// stop 删除入口节点上的规则服务并更新状态
+func (s *RuleService) stop(rule *model.GostRule) error {
 	// 获取入口节点
 	entryNodeID := s.getEntryNodeID(rule)
 	node, err := s.nodeRepo.FindByID(entryNodeID)
 	if err != nil {
 		// 节点不存在，直接更新状态
-		_ = s.ruleRepo.UpdateStatus(id, model.RuleStatusStopped)
-		return nil
+		return s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusStopped)
 	}
 
 	if node.Status == model.NodeStatusOffline {
 		// 节点离线，直接更新状态
-		_ = s.ruleRepo.UpdateStatus(id, model.RuleStatusStopped)
-		return nil
+		return s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusStopped)
 	}
 
 	client := utils.GetGostClient(node)
 
-	// 删除服务（TCP/UDP）
-	serviceID := rule.ServiceID
-	if serviceID == "" {
-		serviceID = fmt.Sprintf("rule-%d", rule.ID)
-	}
-
-	serviceIDs := []string{serviceID}
-	if !strings.HasSuffix(serviceID, "-tcp") && !strings.HasSuffix(serviceID, "-udp") {
-		serviceIDs = append(serviceIDs, serviceID+"-tcp", serviceID+"-udp")
+	// 反向代理规则停止后从共享监听服务中移除域名
+	if rule.Type == model.RuleTypeReverse {
+		reverseMu.Lock()
+		defer reverseMu.Unlock()
+		defer func() {
+			if err := s.syncHostRoutes(client, node.ID, rule.ListenPort); err != nil {
+				logger.Warnf("更新共享监听服务失败: %v", err)
+			}
+		}()
 	}
 
-	for _, id := range serviceIDs {
+	// 删除服务（TCP/UDP，端口范围规则为每个端口的服务）
+	for _, id := range ruleServiceNames(rule) {
 		if err = client.DeleteService(id); err != nil {
 			logger.Warnf("删除 Gost 服务失败: %v", err)
 		}
 	}
+	if rule.Type == model.RuleTypeProxy {
+		if err = client.DeleteAuther(ruleAutherName(rule)); err != nil {
+			logger.Warnf("删除 Gost 认证器失败: %v", err)
+		}
+	}
+	if rule.HasAdmission() {
+		deleteRuleAdmissions(client, rule)
+	}
 
-	_ = s.ruleRepo.UpdateStatus(id, model.RuleStatusStopped)
+	_ = s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusStopped)
 	_ = client.SaveConfig()
-
-	s.logService.Record(
-		userID,
-		username,
-		model.ActionStop,
-		model.ResourceTypeRule,
-		id,
-		fmt.Sprintf("停止规则: %s", rule.Name),
-		ip,
-		userAgent)
-
-	logger.Infof("停止规则成功: %s", rule.Name)
 	return nil
 }
 
 // getEntryNodeID 获取规则的入口节点 ID
 func (s *RuleService) getEntryNodeID(rule *model.GostRule) uint {
-	if rule.Type == model.RuleTypeTunnel && rule.TunnelID != nil {
-		// 隧道转发：使用隧道的入口节点
+	if rule.TunnelID != nil {
+		// 隧道转发、经隧道出口的代理：使用隧道的入口节点
 		nodeID, err := s.tunnelService.GetEntryNodeID(*rule.TunnelID)
 		if err == nil {
 			return nodeID
@@ -417,8 +1411,8 @@ func (s *RuleService) getEntryNodeID(rule *model.GostRule) uint {
 	return 0
 }
 
-// setupRuleObserver 配置规则的观察器
-func (s *RuleService) setupRuleObserver(client *gost.Client, rule *model.GostRule, svc *gost.ServiceConfig) error {
+// setupRuleObserver 配置规则各服务的观察器
+func (s *RuleService) setupRuleObserver(client *gost.Client, rule *model.GostRule, services []*gost.ServiceConfig) error {
 	// 确保全局观察器存在
 	observerName, err := EnsureGlobalObserver(client, s.sysRepo)
 	if err != nil {
@@ -429,7 +1423,10 @@ func (s *RuleService) setupRuleObserver(client *gost.Client, rule *model.GostRul
 	_ = s.ruleRepo.UpdateObserverID(rule.ID, observerName)
 
 	// 配置服务的观察器参数
-	if observerName != "" {
+	if observerName == "" {
+		return nil
+	}
+	for _, svc := range services {
 		svc.Observer = observerName
 		if svc.Metadata == nil {
 			svc.Metadata = make(map[string]any)
@@ -449,8 +1446,31 @@ func (s *RuleService) buildAndStartService(client *gost.Client, rule *model.Gost
 		strategy = "round"
 	}
 
-	// 使用全流量转发（TCP + UDP 同时监听）
-	services := gost.BuildFullForwardService(serviceName, rule.ListenPort, targets, strategy)
+	// 按规则协议每个协议一个服务，端口范围规则每个端口一组服务；代理规则为单个代理服务
+	services := gost.BuildForwardServices(serviceName, rule.ListenPort, targets, strategy, rule.Protocols())
+	autherName := ""
+	if rule.Type == model.RuleTypeProxy {
+		autherName = serviceName + "-auther"
+		services = []*gost.ServiceConfig{gost.BuildProxyService(serviceName+"-tcp", rule.ListenPort, rule.ProxyType, autherName)}
+	} else if rule.Type == model.RuleTypeReverse {
+		// 反向代理规则的服务只在内部端口上监听，由共享监听服务按域名转发进来
+		svc := gost.BuildTCPForwardService(serviceName+"-tcp", rule.InnerPort, targets, strategy)
+		svc.Addr = fmt.Sprintf("127.0.0.1:%d", rule.InnerPort)
+		services = []*gost.ServiceConfig{svc}
+	} else if rule.IsPortRange() {
+		var err error
+		services, err = gost.BuildRangeForwardServices(serviceName, rule.ListenPort, rule.ListenPortEnd, targets, strategy,
+			rule.PortMapping != model.PortMappingSingle, rule.Protocols())
+		if err != nil {
+			logger.Warnf("构建端口范围服务失败: %v", err)
+			return errors.ErrRulePortRangeInvalid
+		}
+	}
+
+	// 目标失败判定与权重、备用设置（代理规则没有转发目标）
+	if rule.Type != model.RuleTypeProxy {
+		gost.ApplySelector(services, rule.SelectorMaxFails(), time.Duration(rule.SelectorFailTimeout())*time.Second, ruleTargetOptions(rule))
+	}
 
 	// 如果有 Chain ID，则关联（用于隧道转发）
 	if chainID != "" {
@@ -460,11 +1480,53 @@ func (s *RuleService) buildAndStartService(client *gost.Client, rule *model.Gost
 	}
 
 	// 为每个服务配置观察器
-	for _, svc := range services {
-		if err := s.setupRuleObserver(client, rule, svc); err != nil {
-			return err
+	if err := s.setupRuleObserver(client, rule, services); err != nil {
+		return err
+	}
+
+	// 来源 IP 访问控制：先创建准入控制器，再由服务引用
+	admissions, err := s.buildRuleAdmissions(rule, serviceName)
+	if err != nil {
+		return err
+	}
+	for _, admission := range admissions {
+		if err = client.ApplyAdmission(admission); err != nil {
+			logger.Warnf("创建 Gost 准入控制器失败: %v", err)
+			deleteRuleAdmissions(client, rule)
+			_ = s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusError)
+			return errors.ErrRuleStartFailed
+		}
+		for _, svc := range services {
+			svc.Admissions = append(svc.Admissions, admission.Name)
+		}
+	}
+
+	// 代理规则先创建认证器
+	if autherName != "" {
+		if err := client.CreateAuther(buildRuleAuther(autherName, rule.ProxyUsers)); err != nil {
+			logger.Warnf("创建 Gost 认证器失败: %v", err)
+			if len(admissions) > 0 {
+				deleteRuleAdmissions(client, rule)
+			}
+			_ = s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusError)
+			return errors.ErrRuleStartFailed
 		}
+	}
+	for _, svc := range services {
 		if err := client.CreateService(svc); err != nil {
+			// 撤销已创建的服务，避免端口范围只启动一部分
+			for _, created := range services {
+				if created == svc {
+					break
+				}
+				_ = client.DeleteService(created.Name)
+			}
+			if autherName != "" {
+				_ = client.DeleteAuther(autherName)
+			}
+			if len(admissions) > 0 {
+				deleteRuleAdmissions(client, rule)
+			}
 			_ = s.ruleRepo.UpdateStatus(rule.ID, model.RuleStatusError)
 			return errors.ErrRuleStartFailed
 		}
@@ -476,3 +1538,126 @@ func (s *RuleService) buildAndStartService(client *gost.Client, rule *model.Gost
 
 	return nil
 }
+
+// Batch 批量启动/停止/重启/删除规则
+// 按 ID 列表或筛选条件选择规则，并发执行并返回逐项结果，只记录一条操作日志
+func (s *RuleService) Batch(req *dto.BatchRuleReq, userID uint, username string, ip, userAgent string) (*dto.BatchResp, error) {
+	items, err := s.batchItems(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量删除前自动备份，备份失败则不执行
+	var snapshot string
+	if req.Action == BatchActionDelete {
+		if snapshot, err = NewBackupService(s.db).Snapshot(BackupTriggerPreDelete); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := runBatch(req.Action, items)
+	resp.Snapshot = snapshot
+
+	s.logService.Record(
+		userID,
+		username,
+		batchLogAction(req.Action),
+		model.ResourceTypeRule,
+		0,
+		batchLogDetails(resp, batchTitle(req.Action, "规则")),
+		ip,
+		userAgent)
+
+	logger.Infof("批量操作规则完成: %s, 成功 %d, 跳过 %d, 失败 %d", req.Action, resp.Succeeded, resp.Skipped, resp.Failed)
+	return resp, nil
+}
+
+// batchItems 解析批量操作对象
+func (s *RuleService) batchItems(req *dto.BatchRuleReq) ([]batchItem, error) {
+	if len(req.IDs) > 0 {
+		ids, err := batchIDs(req.IDs)
+		if err != nil {
+			return nil, err
+		}
+		rules, _, err := s.ruleRepo.List(&repository.QueryOption{
+			Conditions: map[string]any{"id IN ?": ids},
+		})
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[uint]*model.GostRule, len(rules))
+		for i := range rules {
+			found[rules[i].ID] = &rules[i]
+		}
+
+		items := make([]batchItem, 0, len(ids))
+		for _, id := range ids {
+			rule, ok := found[id]
+			if !ok {
+				items = append(items, batchItem{ID: id, Run: func() error {
+					return errors.ErrRuleNotFound
+				}})
+				continue
+			}
+			items = append(items, s.batchItem(req.Action, rule))
+		}
+		return items, nil
+	}
+
+	if req.Filter.IsEmpty() {
+		return nil, errors.ErrBatchTargetRequired
+	}
+	rules, total, err := s.ruleRepo.List(&repository.QueryOption{
+		Conditions: ruleConditions(req.Filter),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, errors.ErrBatchEmpty
+	}
+	if total > batchMaxItems {
+		return nil, errors.ErrBatchTooMany
+	}
+
+	items := make([]batchItem, 0, len(rules))
+	for i := range rules {
+		items = append(items, s.batchItem(req.Action, &rules[i]))
+	}
+	return items, nil
+}
+
+// batchItem 单条规则的批量操作
+func (s *RuleService) batchItem(action string, rule *model.GostRule) batchItem {
+	item := batchItem{ID: rule.ID, Name: rule.Name}
+	switch action {
+	case BatchActionStart:
+		item.Run = func() error {
+			if rule.Status == model.RuleStatusRunning {
+				return errBatchSkipped
+			}
+			return s.start(rule)
+		}
+	case BatchActionStop:
+		item.Run = func() error {
+			if rule.Status != model.RuleStatusRunning {
+				return errBatchSkipped
+			}
+			return s.stop(rule)
+		}
+	case BatchActionRestart:
+		item.Run = func() error {
+			if rule.Status == model.RuleStatusRunning {
+				if err := s.stop(rule); err != nil {
+					return err
+				}
+			}
+			return s.start(rule)
+		}
+	default:
+		item.Run = func() error {
+			return s.remove(rule)
+		}
+	}
+	return item
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	stderrors "errors"
+	"slices"
+	"testing"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
+	"gost-panel/internal/model"
+)
+
+func TestNormalizePortRange(t *testing.T) {
+	targets := []string{"10.0.0.1:8000", "[::1]:9000"}
+	cases := []struct {
+		name        string
+		start, end  int
+		mapping     string
+		targets     []string
+		wantEnd     int
+		wantMapping string
+		wantErr     error
+	}{
+		{"单端口", 10000, 0, "", targets, 0, "", nil},
+		{"结束端口等于起始端口", 10000, 10000, model.PortMappingSingle, targets, 0, "", nil},
+		{"默认 1:1 映射", 10000, 10009, "", targets, 10009, model.PortMappingRange, nil},
+		{"全部转发到同一端口", 10000, 10009, model.PortMappingSingle, targets, 10009, model.PortMappingSingle, nil},
+		{"结束端口小于起始端口", 10000, 9999, "", targets, 0, "", errors.ErrRulePortRangeInvalid},
+		{"最多端口数", 10000, 10000 + ruleMaxPorts - 1, model.PortMappingSingle, targets, 10000 + ruleMaxPorts - 1, model.PortMappingSingle, nil},
+		{"超过最多端口数", 10000, 10000 + ruleMaxPorts, "", targets, 0, "", errors.ErrRulePortRangeTooLarge},
+		{"1:1 映射目标端口越界", 10000, 10009, "", []string{"10.0.0.1:65530"}, 0, "", errors.ErrRulePortRangeInvalid},
+		{"单端口映射不检查偏移", 10000, 10009, model.PortMappingSingle, []string{"10.0.0.1:65530"}, 10009, model.PortMappingSingle, nil},
+		{"目标地址无效", 10000, 10009, "", []string{"10.0.0.1"}, 0, "", errors.ErrRulePortRangeInvalid},
+	}
+	for _, c := range cases {
+		end, mapping, err := normalizePortRange(c.start, c.end, c.mapping, c.targets)
+		if !stderrors.Is(err, c.wantErr) || end != c.wantEnd || mapping != c.wantMapping {
+			t.Errorf("%s: normalizePortRange = %d, %q, %v，期望 %d, %q, %v",
+				c.name, end, mapping, err, c.wantEnd, c.wantMapping, c.wantErr)
+		}
+	}
+}
+
+func TestPortRangeConflict(t *testing.T) {
+	db := newTestDB(t)
+	node := &model.GostNode{Name: "hk", Address: "1.1.1.1", Port: 18080, Status: model.NodeStatusOffline, PortRanges: "10000-10100"}
+	mustCreate(t, db, node)
+	rule := &model.GostRule{Name: "range", Type: model.RuleTypeForward, NodeID: &node.ID, ListenPort: 10010, ListenPortEnd: 10019}
+	mustCreate(t, db, rule)
+	s := NewPortService(db)
+
+	cases := []struct {
+		name       string
+		start, end int
+		exclude    PortExclude
+		want       error
+	}{
+		{"不重叠", 10020, 10029, PortExclude{}, nil},
+		{"与范围规则部分重叠", 10015, 10024, PortExclude{}, errors.ErrRulePortExists},
+		{"单端口落在范围内", 10019, 10019, PortExclude{}, errors.ErrRulePortExists},
+		{"修改范围规则自身", 10012, 10021, PortExclude{RuleID: rule.ID}, nil},
+		{"超出端口池", 10095, 10104, PortExclude{}, errors.ErrPortOutOfRange},
+	}
+	for _, c := range cases {
+		if err := s.CheckRange(node, c.start, c.end, c.exclude); !stderrors.Is(err, c.want) {
+			t.Errorf("%s: CheckRange(%d-%d) = %v，期望 %v", c.name, c.start, c.end, err, c.want)
+		}
+	}
+
+	// 分配连续端口时跳过范围规则占用的端口
+	if port, err := s.AllocateRange(node, 15, PortExclude{}); err != nil || port != 10020 {
+		t.Errorf("AllocateRange(15) = %d, %v，期望 10020", port, err)
+	}
+}
+
+func TestRuleProtocols(t *testing.T) {
+	cases := []struct {
+		name     string
+		rule     model.GostRule
+		want     []string
+		services []string
+	}{
+		{"未设置", model.GostRule{ID: 1, Type: model.RuleTypeForward}, []string{"tcp", "udp"},
+			[]string{"rule-1", "rule-1-tcp", "rule-1-udp"}},
+		{"仅 TCP", model.GostRule{ID: 1, Type: model.RuleTypeForward, Protocol: model.RuleProtocolTCP}, []string{"tcp"},
+			[]string{"rule-1", "rule-1-tcp"}},
+		{"仅 UDP", model.GostRule{ID: 1, Type: model.RuleTypeForward, Protocol: model.RuleProtocolUDP}, []string{"udp"},
+			[]string{"rule-1", "rule-1-udp"}},
+		{"代理规则只有 TCP", model.GostRule{ID: 1, Type: model.RuleTypeProxy, Protocol: model.RuleProtocolBoth}, []string{"tcp"},
+			[]string{"rule-1", "rule-1-tcp"}},
+		{"端口范围仅 UDP", model.GostRule{ID: 1, Type: model.RuleTypeForward, Protocol: model.RuleProtocolUDP, ListenPort: 100, ListenPortEnd: 101},
+			[]string{"udp"}, []string{"rule-1-p100-udp", "rule-1-p101-udp"}},
+	}
+	for _, c := range cases {
+		if got := c.rule.Protocols(); !slices.Equal(got, c.want) {
+			t.Errorf("%s: Protocols() = %v，期望 %v", c.name, got, c.want)
+		}
+		if got := ruleServiceNames(&c.rule); !slices.Equal(got, c.services) {
+			t.Errorf("%s: ruleServiceNames() = %v，期望 %v", c.name, got, c.services)
+		}
+	}
+
+	if got := ruleProtocol(""); got != model.RuleProtocolBoth {
+		t.Errorf("ruleProtocol(\"\") = %q，期望 both", got)
+	}
+}
+
+func TestPortConflictAcrossProtocols(t *testing.T) {
+	db := newTestDB(t)
+	node := &model.GostNode{Name: "hk", Address: "1.1.1.1", Port: 18080, Status: model.NodeStatusOffline}
+	mustCreate(t, db, node)
+	mustCreate(t, db, &model.GostRule{Name: "dns", Type: model.RuleTypeForward, NodeID: &node.ID, ListenPort: 10053, Protocol: model.RuleProtocolUDP})
+	s := NewPortService(db)
+
+	// 端口按节点占用，仅 UDP 的规则同样占用该端口，TCP 规则不能复用
+	if err := s.Check(node, 10053, PortExclude{}); !stderrors.Is(err, errors.ErrRulePortExists) {
+		t.Errorf("Check(10053) = %v，期望 ErrRulePortExists", err)
+	}
+}
+
+func TestBatchFilterByNode(t *testing.T) {
+	db := newTestDB(t)
+	entry := &model.GostNode{Name: "hk", Address: "1.1.1.1", Port: 18080, Status: model.NodeStatusOffline}
+	exit := &model.GostNode{Name: "sg", Address: "2.2.2.2", Port: 18080, Status: model.NodeStatusOffline}
+	mustCreate(t, db, entry, exit)
+	tunnel := &model.GostTunnel{Name: "t1", EntryNodeID: entry.ID, ExitNodeID: exit.ID, RelayPort: 10002}
+	mustCreate(t, db, tunnel)
+	direct := &model.GostRule{Name: "direct", Type: model.RuleTypeForward, NodeID: &entry.ID, ListenPort: 10001, Status: model.RuleStatusStopped}
+	viaTunnel := &model.GostRule{Name: "via-tunnel", Type: model.RuleTypeTunnel, TunnelID: &tunnel.ID, ListenPort: 10003, Status: model.RuleStatusStopped}
+	onExit := &model.GostRule{Name: "on-exit", Type: model.RuleTypeForward, NodeID: &exit.ID, ListenPort: 10004, Status: model.RuleStatusStopped}
+	running := &model.GostRule{Name: "running", Type: model.RuleTypeForward, NodeID: &entry.ID, ListenPort: 10005, Status: model.RuleStatusRunning}
+	mustCreate(t, db, direct, viaTunnel, onExit, running)
+	s := NewRuleService(db)
+
+	// 隧道规则监听在入口节点上，与其他条件组合时仍需同时满足
+	items, err := s.batchItems(&dto.BatchRuleReq{
+		Action: BatchActionStart,
+		Filter: &dto.RuleFilter{NodeID: entry.ID, Status: string(model.RuleStatusStopped)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	slices.Sort(ids)
+	if want := []uint{direct.ID, viaTunnel.ID}; !slices.Equal(ids, want) {
+		t.Errorf("按入口节点筛选 = %v，期望 %v", ids, want)
+	}
+}
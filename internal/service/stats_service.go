@@ -2,6 +2,7 @@ package service
 
 import (
 	"gost-panel/internal/config"
+	"gost-panel/internal/dto"
 	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
 
@@ -26,40 +27,9 @@ func NewStatsService(db *gorm.DB) *StatsService {
 	}
 }
 
-// DashboardStats 仪表盘统计
-type DashboardStats struct {
-	Nodes   NodeStats   `json:"nodes"`
-	Rules   RuleStats   `json:"rules"`
-	Tunnels TunnelStats `json:"tunnels"`
-	Version string      `json:"version"`
-}
-
-// NodeStats 节点统计
-type NodeStats struct {
-	Total   int64 `json:"total"`
-	Online  int64 `json:"online"`
-	Offline int64 `json:"offline"`
-}
-
-// RuleStats 规则统计
-type RuleStats struct {
-	Total       int64 `json:"total"`
-	Running     int64 `json:"running"`
-	Stopped     int64 `json:"stopped"`
-	ForwardType int64 `json:"forward_type"` // 端口转发类型数量
-	TunnelType  int64 `json:"tunnel_type"`  // 隧道转发类型数量
-}
-
-// TunnelStats 隧道统计
-type TunnelStats struct {
-	Total   int64 `json:"total"`
-	Running int64 `json:"running"`
-	Stopped int64 `json:"stopped"`
-}
-
 // GetDashboardStats 获取仪表盘统计
-func (s *StatsService) GetDashboardStats() (*DashboardStats, error) {
-	stats := &DashboardStats{}
+func (s *StatsService) GetDashboardStats() (*dto.DashboardStats, error) {
+	stats := &dto.DashboardStats{}
 
 	// 节点统计
 	nodeTotal, err := s.nodeRepo.CountAll()
@@ -70,7 +40,7 @@ func (s *StatsService) GetDashboardStats() (*DashboardStats, error) {
 	if err != nil {
 		return nil, err
 	}
-	stats.Nodes = NodeStats{
+	stats.Nodes = dto.NodeStats{
 		Total:   nodeTotal,
 		Online:  nodeOnline,
 		Offline: nodeTotal - nodeOnline,
@@ -93,12 +63,22 @@ func (s *StatsService) GetDashboardStats() (*DashboardStats, error) {
 	if err != nil {
 		return nil, err
 	}
-	stats.Rules = RuleStats{
+	proxyType, err := s.ruleRepo.CountByType(model.RuleTypeProxy)
+	if err != nil {
+		return nil, err
+	}
+	reverseType, err := s.ruleRepo.CountByType(model.RuleTypeReverse)
+	if err != nil {
+		return nil, err
+	}
+	stats.Rules = dto.RuleStats{
 		Total:       ruleTotal,
 		Running:     ruleRunning,
 		Stopped:     ruleTotal - ruleRunning,
 		ForwardType: forwardType,
 		TunnelType:  tunnelType,
+		ProxyType:   proxyType,
+		ReverseType: reverseType,
 	}
 
 	// 隧道统计
@@ -110,7 +90,7 @@ func (s *StatsService) GetDashboardStats() (*DashboardStats, error) {
 	if err != nil {
 		return nil, err
 	}
-	stats.Tunnels = TunnelStats{
+	stats.Tunnels = dto.TunnelStats{
 		Total:   tunnelTotal,
 		Running: tunnelRunning,
 		Stopped: tunnelTotal - tunnelRunning,
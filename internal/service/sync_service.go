@@ -8,6 +8,7 @@ import (
 	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
 	"gost-panel/internal/utils"
+	"gost-panel/pkg/gost"
 	"gost-panel/pkg/logger"
 
 	"gorm.io/gorm"
@@ -36,6 +37,7 @@ func NewRuleSyncService(db *gorm.DB) *RuleSyncService {
 
 // Start 启动定时同步任务（每 5 秒）
 func (s *RuleSyncService) Start() {
+	s.stopChan = make(chan struct{})
 	s.ticker = time.NewTicker(5 * time.Second)
 	s.wg.Add(1)
 
@@ -140,18 +142,18 @@ func (s *RuleSyncService) syncNodeRules(node model.GostNode) {
 
 // syncRuleStatus 同步规则状态
 func (s *RuleSyncService) syncRuleStatus(r model.GostRule, serviceStates map[string]string) {
-	serviceID := r.ServiceID
-	if serviceID == "" {
-		serviceID = fmt.Sprintf("rule-%d", r.ID)
-	}
-
-	candidates := []string{serviceID, serviceID + "-tcp", serviceID + "-udp"}
 	var states []string
-	for _, name := range candidates {
+	for _, name := range ruleServiceNames(&r) {
 		if state, ok := serviceStates[name]; ok {
 			states = append(states, state)
 		}
 	}
+	// 反向代理规则还需要共享监听服务，否则域名无法访问
+	if r.Type == model.RuleTypeReverse {
+		if _, ok := serviceStates[gost.HostRouteServiceName(r.ListenPort)]; !ok {
+			states = nil
+		}
+	}
 
 	newStatus := resolveRuleStatus(states)
 
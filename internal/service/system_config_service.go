@@ -1,10 +1,17 @@
 package service
 
 import (
+	"strings"
+
 	"gost-panel/internal/dto"
+	"gost-panel/internal/errors"
 	"gost-panel/internal/repository"
+	"gost-panel/pkg/cron"
 )
 
+// secretMask 配置中已设置的密钥在接口返回时替换为该占位符，更新时原样提交表示保持不变
+const secretMask = "******"
+
 // SystemConfigService 系统配置服务
 type SystemConfigService struct {
 	repo *repository.SystemConfigRepository
@@ -45,6 +52,20 @@ func (s *SystemConfigService) GetConfig() (*dto.SystemConfigResp, error) {
 		Backup: dto.BackupConfigResp{
 			AutoBackup:     config.AutoBackup,
 			RetentionCount: config.BackupRetentionCount,
+			Schedule:       config.BackupSchedule,
+			NextBackupAt:   nextBackupAt(config),
+			Compress:       config.BackupCompress,
+			Passphrase:     maskSecret(config.BackupPassphrase),
+			S3: dto.S3ConfigResp{
+				Enabled:   config.BackupS3Enabled,
+				Endpoint:  config.BackupS3Endpoint,
+				Region:    config.BackupS3Region,
+				Bucket:    config.BackupS3Bucket,
+				AccessKey: config.BackupS3AccessKey,
+				SecretKey: maskSecret(config.BackupS3SecretKey),
+				Prefix:    config.BackupS3Prefix,
+				PathStyle: config.BackupS3PathStyle,
+			},
 		},
 	}, nil
 }
@@ -65,6 +86,12 @@ func (s *SystemConfigService) GetPublicConfig() (*dto.PublicSystemConfigResp, er
 
 // UpdateConfig 更新配置
 func (s *SystemConfigService) UpdateConfig(req *dto.UpdateSystemConfigReq) error {
+	if req.Backup.Schedule != "" {
+		if _, err := cron.Parse(req.Backup.Schedule); err != nil {
+			return errors.ErrBackupScheduleInvalid
+		}
+	}
+
 	config, err := s.repo.Get()
 	if err != nil {
 		return err
@@ -92,6 +119,33 @@ func (s *SystemConfigService) UpdateConfig(req *dto.UpdateSystemConfigReq) error
 	// 映射 Backup
 	config.AutoBackup = req.Backup.AutoBackup
 	config.BackupRetentionCount = req.Backup.RetentionCount
+	config.BackupSchedule = strings.TrimSpace(req.Backup.Schedule)
+	config.BackupCompress = req.Backup.Compress
+	config.BackupPassphrase = keepSecret(req.Backup.Passphrase, config.BackupPassphrase)
+	config.BackupS3Enabled = req.Backup.S3.Enabled
+	config.BackupS3Endpoint = req.Backup.S3.Endpoint
+	config.BackupS3Region = req.Backup.S3.Region
+	config.BackupS3Bucket = req.Backup.S3.Bucket
+	config.BackupS3AccessKey = req.Backup.S3.AccessKey
+	config.BackupS3SecretKey = keepSecret(req.Backup.S3.SecretKey, config.BackupS3SecretKey)
+	config.BackupS3Prefix = req.Backup.S3.Prefix
+	config.BackupS3PathStyle = req.Backup.S3.PathStyle
 
 	return s.repo.Update(config)
 }
+
+// maskSecret 已设置的密钥返回占位符，未设置返回空
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return secretMask
+}
+
+// keepSecret 提交的值为占位符时保留原值
+func keepSecret(value, stored string) string {
+	if value == secretMask {
+		return stored
+	}
+	return value
+}
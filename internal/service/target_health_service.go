@@ -0,0 +1,204 @@
+package service
+
+import (
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"gost-panel/internal/model"
+	"gost-panel/internal/repository"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 目标探测参数
+const (
+	targetCheckInterval = 10 * time.Second // 探测间隔
+	targetDialTimeout   = 3 * time.Second  // 单次连接超时
+)
+
+// targetState 单个转发目标的探测状态
+type targetState struct {
+	fails     int       // 连续失败次数
+	lastError string    // 最近一次失败原因
+	lastCheck time.Time // 最近一次探测时间
+	lastFail  time.Time // 最近一次失败时间
+}
+
+// failed 按 GOST 选择器的规则判断目标是否失效：
+// 连续失败达到 maxFails 次，且距最近一次失败不足 failTimeout
+func (t *targetState) failed(maxFails int, failTimeout time.Duration) bool {
+	return t.fails >= maxFails && time.Since(t.lastFail) < failTimeout
+}
+
+// targetHealthStore 运行中规则的目标探测状态（仅保存在内存中）
+type targetHealthStore struct {
+	mu     sync.RWMutex
+	states map[uint]map[string]*targetState // 规则 ID -> 目标地址 -> 状态
+}
+
+// targetHealth 全局目标探测状态，由 TargetHealthService 更新、RuleService 读取
+var targetHealth = &targetHealthStore{states: make(map[uint]map[string]*targetState)}
+
+// record 记录一次探测结果
+func (s *targetHealthStore) record(ruleID uint, target string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := s.states[ruleID]
+	if states == nil {
+		states = make(map[string]*targetState)
+		s.states[ruleID] = states
+	}
+	state := states[target]
+	if state == nil {
+		state = &targetState{}
+		states[target] = state
+	}
+
+	state.lastCheck = time.Now()
+	if err != nil {
+		state.fails++
+		state.lastError = err.Error()
+		state.lastFail = state.lastCheck
+		return
+	}
+	state.fails = 0
+	state.lastError = ""
+}
+
+// get 目标的探测状态副本，未探测过时返回 false
+func (s *targetHealthStore) get(ruleID uint, target string) (targetState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[ruleID][target]
+	if !ok {
+		return targetState{}, false
+	}
+	return *state, true
+}
+
+// retain 只保留仍在探测的规则与目标
+func (s *targetHealthStore) retain(targets map[uint][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ruleID, states := range s.states {
+		keep, ok := targets[ruleID]
+		if !ok {
+			delete(s.states, ruleID)
+			continue
+		}
+		for target := range states {
+			if !slices.Contains(keep, target) {
+				delete(states, target)
+			}
+		}
+	}
+}
+
+// targetCheckSkipReason 规则目标无法由面板探测的原因，可探测时返回空字符串
+func targetCheckSkipReason(rule *model.GostRule) string {
+	switch {
+	case rule.Type == model.RuleTypeProxy:
+		return "代理规则没有转发目标"
+	case rule.TunnelID != nil:
+		return "目标经隧道出口节点访问，面板无法直接探测"
+	case rule.Protocol == model.RuleProtocolUDP:
+		return "UDP 目标无法通过连接探测"
+	case len(rule.Targets) == 0:
+		return "规则没有转发目标"
+	}
+	return ""
+}
+
+// TargetHealthService 转发目标探测服务
+// GOST API 不提供选择器的失效标记，面板定期对运行中规则的目标发起 TCP 连接，
+// 按规则的最大失败次数和失败超时判断目标是否被 GOST 视为失效
+type TargetHealthService struct {
+	ruleRepo *repository.RuleRepository
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewTargetHealthService 创建转发目标探测服务
+func NewTargetHealthService(db *gorm.DB) *TargetHealthService {
+	return &TargetHealthService{
+		ruleRepo: repository.NewRuleRepository(db),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start 启动定时探测（每 10 秒）
+func (s *TargetHealthService) Start() {
+	s.stopChan = make(chan struct{})
+	s.ticker = time.NewTicker(targetCheckInterval)
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		logger.Info("转发目标探测服务已启动")
+
+		s.checkAll()
+
+		for {
+			select {
+			case <-s.ticker.C:
+				s.checkAll()
+			case <-s.stopChan:
+				logger.Info("转发目标探测服务已停止")
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止探测
+func (s *TargetHealthService) Stop() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// checkAll 探测所有运行中规则的目标，等待本轮探测结束后返回
+func (s *TargetHealthService) checkAll() {
+	rules, _, err := s.ruleRepo.List(&repository.QueryOption{
+		Conditions: map[string]any{"status = ?": model.RuleStatusRunning},
+	})
+	if err != nil {
+		logger.Errorf("获取运行中规则失败: %v", err)
+		return
+	}
+
+	checking := make(map[uint][]string, len(rules))
+	var wg sync.WaitGroup
+	for i := range rules {
+		rule := &rules[i]
+		if targetCheckSkipReason(rule) != "" {
+			continue
+		}
+		checking[rule.ID] = rule.Targets
+		for _, target := range rule.Targets {
+			wg.Add(1)
+			go func(ruleID uint, target string) {
+				defer wg.Done()
+				conn, err := net.DialTimeout("tcp", target, targetDialTimeout)
+				if err == nil {
+					_ = conn.Close()
+				} else {
+					logger.Debugf("规则 %d 目标 %s 探测失败: %v", ruleID, target, err)
+				}
+				targetHealth.record(ruleID, target, err)
+			}(rule.ID, target)
+		}
+	}
+	wg.Wait()
+
+	targetHealth.retain(checking)
+}
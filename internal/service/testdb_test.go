@@ -0,0 +1,44 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gost-panel/internal/database"
+	"gost-panel/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// 服务层直接调用全局日志，测试中只输出错误
+	if err := logger.Init(&logger.Config{Level: "error", Format: "console"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// newTestDB 创建按当前模型建表的临时 SQLite 数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+	if err = db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return db
+}
+
+// mustCreate 写入测试数据
+func mustCreate(t *testing.T, db *gorm.DB, values ...any) {
+	t.Helper()
+	for _, v := range values {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("写入 %T 失败: %v", v, err)
+		}
+	}
+}
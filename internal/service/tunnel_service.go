@@ -2,11 +2,19 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	stderrors "errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"gost-panel/internal/dto"
 	"gost-panel/internal/errors"
+	"gost-panel/internal/metrics"
 	"gost-panel/internal/model"
 	"gost-panel/internal/repository"
 	"gost-panel/internal/utils"
@@ -19,20 +27,25 @@ import (
 // TunnelService 隧道服务
 // 负责隧道的 CRUD 操作及启停控制
 // 启动隧道时：在出口节点创建 Relay 服务，在入口节点创建 Chain 连接到出口节点
+// 多出口隧道在每个在线出口上创建 Relay 服务，Chain 的跳点按策略在出口间选择
 type TunnelService struct {
-	tunnelRepo *repository.TunnelRepository
-	nodeRepo   *repository.NodeRepository
-	logService *LogService
-	sysRepo    *repository.SystemConfigRepository
+	db          *gorm.DB
+	tunnelRepo  *repository.TunnelRepository
+	nodeRepo    *repository.NodeRepository
+	logService  *LogService
+	sysRepo     *repository.SystemConfigRepository
+	portService *PortService
 }
 
 // NewTunnelService 创建隧道服务
 func NewTunnelService(db *gorm.DB) *TunnelService {
 	return &TunnelService{
-		tunnelRepo: repository.NewTunnelRepository(db),
-		nodeRepo:   repository.NewNodeRepository(db),
-		logService: NewLogService(db),
-		sysRepo:    repository.NewSystemConfigRepository(db),
+		db:          db,
+		tunnelRepo:  repository.NewTunnelRepository(db),
+		nodeRepo:    repository.NewNodeRepository(db),
+		logService:  NewLogService(db),
+		sysRepo:     repository.NewSystemConfigRepository(db),
+		portService: NewPortService(db),
 	}
 }
 
@@ -61,18 +74,48 @@ func (s *TunnelService) Create(req *dto.CreateTunnelReq, userID uint, username s
 		return nil, err
 	}
 
+	if entryNode.Maintenance || exitNode.Maintenance {
+		return nil, errors.ErrNodeMaintenance
+	}
+
+	extraNodes, err := s.resolveExtraExits(req.EntryNodeID, req.ExitNodeID, req.ExtraExitNodeIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := normalizeTunnelOptions(req.Protocol, &req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查出口节点上的 Relay 端口，未指定时从端口池分配（多出口使用同一端口）
+	portAllocMu.Lock()
+	defer portAllocMu.Unlock()
+	exitNodes := append([]*model.GostNode{exitNode}, extraNodes...)
+	relayPort := req.RelayPort
+	if relayPort == 0 {
+		relayPort, err = s.portService.AllocateShared(exitNodes, PortExclude{})
+	} else {
+		err = s.checkRelayPort(exitNodes, relayPort, PortExclude{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建隧道
 	tunnel := &model.GostTunnel{
 		Name:        req.Name,
 		EntryNodeID: req.EntryNodeID,
 		ExitNodeID:  req.ExitNodeID,
 		Protocol:    req.Protocol,
-		RelayPort:   req.RelayPort,
+		RelayPort:   relayPort,
 		Remark:      req.Remark,
 		Status:      model.TunnelStatusStopped,
+		Options:     options,
+		Strategy:    normalizeStrategy(req.Strategy),
 	}
 
-	if err = s.tunnelRepo.Create(tunnel); err != nil {
+	if err = s.saveTunnel(tunnel, req.ExtraExitNodeIDs); err != nil {
 		return nil, err
 	}
 
@@ -83,15 +126,15 @@ func (s *TunnelService) Create(req *dto.CreateTunnelReq, userID uint, username s
 		model.ActionCreate,
 		model.ResourceTypeTunnel,
 		tunnel.ID,
-		fmt.Sprintf("创建隧道: %s (%s -> %s)", tunnel.Name, entryNode.Name, exitNode.Name),
+		fmt.Sprintf("创建隧道: %s (%s -> %s)", tunnel.Name, entryNode.Name, nodeNames(exitNodes)),
 		ip,
 		userAgent)
 
 	logger.Infof("创建隧道成功: %s", tunnel.Name)
-	return tunnel, nil
+	return s.GetByID(tunnel.ID)
 }
 
-// Update 更新隧道（仅支持更新非运行中的隧道，且不能修改入口/出口节点）
+// Update 更新隧道（仅支持更新非运行中的隧道，且不能修改入口/主出口节点，附加出口可以调整）
 func (s *TunnelService) Update(id uint, req *dto.UpdateTunnelReq, userID uint, username string, ip, userAgent string) (*model.GostTunnel, error) {
 	tunnel, err := s.tunnelRepo.FindByID(id)
 	if err != nil {
@@ -106,13 +149,44 @@ func (s *TunnelService) Update(id uint, req *dto.UpdateTunnelReq, userID uint, u
 		return nil, errors.ErrTunnelRunning
 	}
 
-	// 更新隧道（不能修改入口/出口节点）
+	// 响应中不返回 KCP 密钥，未填写时沿用原密钥
+	if kcp := req.Options.KCP; kcp != nil && kcp.Key == "" && tunnel.Options.KCP != nil &&
+		slices.Contains(model.TunnelOptionGroups(req.Protocol), "kcp") {
+		filled := *kcp
+		filled.Key = tunnel.Options.KCP.Key
+		req.Options.KCP = &filled
+	}
+	options, err := normalizeTunnelOptions(req.Protocol, &req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	extraNodes, err := s.resolveExtraExits(tunnel.EntryNodeID, tunnel.ExitNodeID, req.ExtraExitNodeIDs, tunnel.ExitNodeIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	// 修改 Relay 端口时检查各出口节点上的新端口，新增的出口也需要检查（排除自身）
+	portAllocMu.Lock()
+	defer portAllocMu.Unlock()
+	for _, node := range append([]*model.GostNode{tunnel.ExitNode}, extraNodes...) {
+		if node == nil || (req.RelayPort == tunnel.RelayPort && tunnel.HasExit(node.ID)) {
+			continue
+		}
+		if err = s.portService.Check(node, req.RelayPort, PortExclude{TunnelID: tunnel.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	// 更新隧道（不能修改入口/主出口节点）
 	tunnel.Name = req.Name
 	tunnel.Protocol = req.Protocol
 	tunnel.RelayPort = req.RelayPort
 	tunnel.Remark = req.Remark
+	tunnel.Options = options
+	tunnel.Strategy = normalizeStrategy(req.Strategy)
 
-	if err = s.tunnelRepo.Update(tunnel); err != nil {
+	if err = s.saveTunnel(tunnel, req.ExtraExitNodeIDs); err != nil {
 		return nil, err
 	}
 
@@ -126,7 +200,7 @@ func (s *TunnelService) Update(id uint, req *dto.UpdateTunnelReq, userID uint, u
 		ip,
 		userAgent)
 
-	return tunnel, nil
+	return s.GetByID(tunnel.ID)
 }
 
 // Delete 删除隧道
@@ -140,24 +214,7 @@ func (s *TunnelService) Delete(id uint, userID uint, username string, ip, userAg
 		return err
 	}
 
-	// 检查是否有规则正在使用此隧道
-	hasRules, err := s.tunnelRepo.HasRules(id)
-	if err != nil {
-		return err
-	}
-	if hasRules {
-		return errors.ErrTunnelHasRules
-	}
-
-	// 如果隧道正在运行，先停止
-	if tunnel.Status == model.TunnelStatusRunning {
-		if err = s.Stop(id, userID, username, ip, userAgent); err != nil {
-			logger.Warnf("停止隧道失败: %v", err)
-		}
-	}
-
-	// 删除隧道
-	if err = s.tunnelRepo.Delete(id); err != nil {
+	if err = s.remove(tunnel); err != nil {
 		return err
 	}
 
@@ -175,6 +232,27 @@ func (s *TunnelService) Delete(id uint, userID uint, username string, ip, userAg
 	return nil
 }
 
+// remove 删除隧道，正在运行时先停止
+func (s *TunnelService) remove(tunnel *model.GostTunnel) error {
+	// 检查是否有规则正在使用此隧道
+	hasRules, err := s.tunnelRepo.HasRules(tunnel.ID)
+	if err != nil {
+		return err
+	}
+	if hasRules {
+		return errors.ErrTunnelHasRules
+	}
+
+	if tunnel.Status == model.TunnelStatusRunning {
+		s.stop(tunnel)
+	}
+	if err = s.tunnelRepo.Delete(tunnel.ID); err != nil {
+		return err
+	}
+	metrics.ForgetTunnel(tunnel.ID)
+	return nil
+}
+
 // GetByID 获取隧道详情
 func (s *TunnelService) GetByID(id uint) (*model.GostTunnel, error) {
 	tunnel, err := s.tunnelRepo.FindByID(id)
@@ -196,28 +274,37 @@ func (s *TunnelService) List(req *dto.TunnelListReq) ([]model.GostTunnel, int64,
 			Page:     req.Page,
 			PageSize: req.PageSize,
 		},
-		Conditions: make(map[string]any),
+		Conditions: tunnelConditions(&dto.TunnelFilter{
+			NodeID:  req.NodeID,
+			Status:  req.Status,
+			Keyword: req.Keyword,
+		}),
 	}
 
-	if req.NodeID > 0 {
-		opt.Conditions["entry_node_id = ? OR exit_node_id = ?"] = []interface{}{req.NodeID, req.NodeID}
+	return s.tunnelRepo.List(opt)
+}
+
+// tunnelConditions 隧道筛选条件
+func tunnelConditions(f *dto.TunnelFilter) map[string]any {
+	conditions := make(map[string]any)
+	if f.NodeID > 0 {
+		conditions["(entry_node_id = ? OR exit_node_id = ? OR id IN (SELECT tunnel_id FROM tunnel_exits WHERE node_id = ?))"] = []interface{}{f.NodeID, f.NodeID, f.NodeID}
 	}
-	if req.Status != "" {
-		opt.Conditions["status = ?"] = req.Status
+	if f.Status != "" {
+		conditions["status = ?"] = f.Status
 	}
-	if req.Keyword != "" {
-		opt.Conditions["name LIKE ?"] = []interface{}{
-			"%" + req.Keyword + "%",
+	if f.Keyword != "" {
+		conditions["name LIKE ?"] = []interface{}{
+			"%" + f.Keyword + "%",
 		}
 	}
-
-	return s.tunnelRepo.List(opt)
+	return conditions
 }
 
 // Start 启动隧道
 // 在出口节点创建 Relay 服务，在入口节点创建 Chain 连接到出口节点
 func (s *TunnelService) Start(id uint, userID uint, username string, ip, userAgent string) error {
-	tunnel, err := s.tunnelRepo.FindByID(id)
+	tunnel, err := s.GetByID(id)
 	if err != nil {
 		return err
 	}
@@ -227,37 +314,117 @@ func (s *TunnelService) Start(id uint, userID uint, username string, ip, userAge
 		return nil
 	}
 
+	if err = s.start(tunnel); err != nil {
+		return err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionStart,
+		model.ResourceTypeTunnel,
+		id,
+		fmt.Sprintf("启动隧道: %s", tunnel.Name),
+		ip,
+		userAgent)
+
+	return nil
+}
+
+// start 在出口节点创建 Relay 服务，在入口节点创建 Chain
+// 多出口隧道只使用在线的出口，全部出口离线时无法启动
+func (s *TunnelService) start(tunnel *model.GostTunnel) error {
+	id := tunnel.ID
+
 	// 获取入口和出口节点
 	entryNode, err := s.nodeRepo.FindByID(tunnel.EntryNodeID)
 	if err != nil {
 		return errors.ErrEntryNodeNotFound
 	}
-	exitNode, err := s.nodeRepo.FindByID(tunnel.ExitNodeID)
+	exitNodes, err := s.exitNodes(tunnel)
 	if err != nil {
-		return errors.ErrExitNodeNotFound
+		return err
 	}
 
 	// 检查节点状态
 	if entryNode.Status == model.NodeStatusOffline {
 		return errors.ErrEntryNodeOffline
 	}
-	if exitNode.Status == model.NodeStatusOffline {
+	exitNodes = onlineNodes(exitNodes)
+	if len(exitNodes) == 0 {
 		return errors.ErrExitNodeOffline
 	}
 
-	// 步骤1：在出口节点创建 Relay 服务
+	// 入口节点通过出口节点的地址连接 Relay 服务
+	for _, exitNode := range exitNodes {
+		if exitNode.Address == "" {
+			_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusError)
+			return errors.ErrExtractHostFailed
+		}
+	}
+
+	// 旧版本创建的隧道没有凭据，启动时补充生成
+	if tunnel.RelayUsername == "" {
+		if err = s.renewCredentials(tunnel); err != nil {
+			return err
+		}
+	}
+
+	// 步骤1：在出口节点创建 Relay 服务，使用独立的认证器以便轮换凭据时原地更新
+	for i, exitNode := range exitNodes {
+		if err = s.createRelay(tunnel, exitNode, false); err != nil {
+			logger.Warnf("在出口节点 %s 创建隧道 Relay 服务失败: %v", exitNode.Name, err)
+			// 回滚：删除已创建的 Relay 服务
+			s.deleteRelays(tunnel, exitNodes[:i])
+			_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusError)
+			return errors.ErrTunnelRelayCreateFailed
+		}
+	}
+
+	// 步骤2：在入口节点创建 Chain 连接到出口节点的 Relay 服务
+	entryClient := utils.GetGostClient(entryNode)
+	chain := tunnelChain(tunnel, exitNodes)
+	chainName := chain.Name
+
+	if err = entryClient.CreateChain(chain); err != nil {
+		// 回滚：删除出口节点的 Relay 服务
+		s.deleteRelays(tunnel, exitNodes)
+		_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusError)
+		return errors.ErrTunnelChainCreateFailed
+	}
+
+	// 保存入口节点配置
+	_ = entryClient.SaveConfig()
+
+	// 更新隧道状态和服务 ID
+	relayServiceName := relayServiceName(tunnel, tunnel.ExitNodeID)
+	_ = s.tunnelRepo.UpdateServiceInfo(id, relayServiceName, chainName)
+	_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusRunning)
+
+	logger.Infof("启动隧道成功: %s (Relay: %s, 出口 %d 个 -> Chain: %s)", tunnel.Name, relayServiceName, len(exitNodes), chainName)
+	return nil
+}
+
+// createRelay 在出口节点创建认证器和 Relay 服务，apply 为 true 时覆盖节点上已有的同名配置
+func (s *TunnelService) createRelay(tunnel *model.GostTunnel, exitNode *model.GostNode, apply bool) error {
 	exitClient := utils.GetGostClient(exitNode)
-	relayServiceName := fmt.Sprintf("relay-tunnel-%d", tunnel.ID)
+	createAuther, createService := exitClient.CreateAuther, exitClient.CreateService
+	if apply {
+		createAuther, createService = exitClient.ApplyAuther, exitClient.ApplyService
+	}
+
+	if err := createAuther(relayAuther(tunnel)); err != nil {
+		return err
+	}
 
 	relaySvc := &gost.ServiceConfig{
-		Name: relayServiceName,
+		Name: relayServiceName(tunnel, exitNode.ID),
 		Addr: fmt.Sprintf(":%d", tunnel.RelayPort),
 		Handler: &gost.HandlerConfig{
-			Type: "relay",
-		},
-		Listener: &gost.ListenerConfig{
-			Type: tunnel.Protocol,
+			Type:   "relay",
+			Auther: relayAutherName(tunnel),
 		},
+		Listener: tunnelListener(tunnel),
 	}
 
 	// 配置观察器用于流量统计
@@ -272,132 +439,699 @@ func (s *TunnelService) Start(id uint, userID uint, username string, ip, userAge
 		relaySvc.Metadata["observer.resetTraffic"] = false // 使用累计模式，避免流量丢失
 	}
 
-	if err = exitClient.CreateService(relaySvc); err != nil {
-		_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusError)
-		return errors.ErrTunnelRelayCreateFailed
+	if err := createService(relaySvc); err != nil {
+		_ = exitClient.DeleteAuther(relayAutherName(tunnel))
+		return err
 	}
 
 	// 保存出口节点配置
 	_ = exitClient.SaveConfig()
+	return nil
+}
 
-	// 步骤2：在入口节点创建 Chain 连接到出口节点的 Relay 服务
-	entryClient := utils.GetGostClient(entryNode)
-
-	// 从出口节点配置中获取主机 IP
-	exitHost := exitNode.Address
-	if exitHost == "" {
-		// 回滚：删除出口节点的 Relay 服务
-		_ = exitClient.DeleteService(relayServiceName)
+// deleteRelays 删除出口节点上的 Relay 服务和认证器
+func (s *TunnelService) deleteRelays(tunnel *model.GostTunnel, exitNodes []*model.GostNode) {
+	for _, exitNode := range exitNodes {
+		exitClient := utils.GetGostClient(exitNode)
+		if err := exitClient.DeleteService(relayServiceName(tunnel, exitNode.ID)); err != nil {
+			logger.Warnf("删除隧道 Relay 服务失败: %v", err)
+		}
+		if err := exitClient.DeleteAuther(relayAutherName(tunnel)); err != nil {
+			logger.Warnf("删除隧道 Relay 认证器失败: %v", err)
+		}
 		_ = exitClient.SaveConfig()
-		_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusError)
-		return errors.ErrExtractHostFailed
-	}
-
-	chainName := fmt.Sprintf("tunnel-%d-chain", tunnel.ID)
-	relayAddr := fmt.Sprintf("%s:%d", exitHost, tunnel.RelayPort)
-
-	chain := &gost.ChainConfig{
-		Name: chainName,
-		Hops: []*gost.HopConfig{
-			{
-				Name: "hop-0",
-				Nodes: []*gost.NodeConfig{
-					{
-						Name: "exit-relay",
-						Addr: relayAddr,
-						Connector: &gost.ConnectorConfig{
-							Type: "relay",
-						},
-						Dialer: &gost.DialerConfig{
-							Type: tunnel.Protocol,
-						},
-					},
-				},
-			},
-		},
 	}
+}
 
-	if err = entryClient.CreateChain(chain); err != nil {
-		// 回滚：删除出口节点的 Relay 服务
-		_ = exitClient.DeleteService(relayServiceName)
-		_ = exitClient.SaveConfig()
-		_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusError)
-		return errors.ErrTunnelChainCreateFailed
+// tunnelRefreshMu 串行化多出口隧道的跳点刷新，避免多个出口同时变更状态时互相覆盖 Chain
+var tunnelRefreshMu sync.Mutex
+
+// refreshExits 按出口节点的在线状态重建运行中多出口隧道的跳点
+// 离线出口从跳点中移除；recovered 为恢复在线的出口，重新下发 Relay 服务后加入。入口节点上的规则无需重建
+func (s *TunnelService) refreshExits(id, recovered uint) error {
+	tunnelRefreshMu.Lock()
+	defer tunnelRefreshMu.Unlock()
+
+	tunnel, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if tunnel.Status != model.TunnelStatusRunning || len(tunnel.ExtraExits) == 0 {
+		return nil
+	}
+	if tunnel.EntryNode == nil || tunnel.EntryNode.Status == model.NodeStatusOffline {
+		return errors.ErrEntryNodeOffline
+	}
+	exitNodes, err := s.exitNodes(tunnel)
+	if err != nil {
+		return err
 	}
 
-	// 保存入口节点配置
+	// 节点离线期间 Relay 服务可能已丢失，恢复后覆盖下发，失败时不加入跳点
+	var ready []*model.GostNode
+	for _, exitNode := range onlineNodes(exitNodes) {
+		if exitNode.ID == recovered {
+			if err = s.createRelay(tunnel, exitNode, true); err != nil {
+				logger.Warnf("在恢复的出口节点 %s 上创建隧道 Relay 服务失败: %v", exitNode.Name, err)
+				continue
+			}
+		}
+		ready = append(ready, exitNode)
+	}
+	if len(ready) == 0 {
+		return errors.ErrExitNodeOffline
+	}
+
+	entryClient := utils.GetGostClient(tunnel.EntryNode)
+	if err = entryClient.ApplyChain(tunnelChain(tunnel, ready)); err != nil {
+		logger.Warnf("更新隧道 Chain 失败: %v", err)
+		return errors.ErrTunnelChainCreateFailed
+	}
 	_ = entryClient.SaveConfig()
 
-	// 更新隧道状态和服务 ID
-	_ = s.tunnelRepo.UpdateServiceInfo(id, relayServiceName, chainName)
-	_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusRunning)
+	logger.Infof("隧道 %s 出口已更新: %s", tunnel.Name, nodeNames(ready))
+	return nil
+}
+
+// Stop 停止隧道
+// 删除入口节点的 Chain 和出口节点的 Relay 服务
+func (s *TunnelService) Stop(id uint, userID uint, username string, ip, userAgent string) error {
+	tunnel, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// 未运行则跳过
+	if tunnel.Status != model.TunnelStatusRunning {
+		return nil
+	}
+
+	s.stop(tunnel)
 
 	s.logService.Record(
 		userID,
 		username,
-		model.ActionStart,
+		model.ActionStop,
 		model.ResourceTypeTunnel,
 		id,
-		fmt.Sprintf("启动隧道: %s", tunnel.Name),
+		fmt.Sprintf("停止隧道: %s", tunnel.Name),
 		ip,
 		userAgent)
 
-	logger.Infof("启动隧道成功: %s (Relay: %s -> Chain: %s)", tunnel.Name, relayServiceName, chainName)
+	logger.Infof("停止隧道成功: %s", tunnel.Name)
 	return nil
 }
 
-// Stop 停止隧道
-// 删除入口节点的 Chain 和出口节点的 Relay 服务
-func (s *TunnelService) Stop(id uint, userID uint, username string, ip, userAgent string) error {
-	tunnel, err := s.tunnelRepo.FindByID(id)
+// RotateCredentials 重新生成隧道的 Relay 认证凭据
+// 运行中的隧道先让出口节点同时接受新旧凭据，入口节点 Chain 切换到新凭据后再移除旧凭据，不中断转发
+func (s *TunnelService) RotateCredentials(id uint, userID uint, username string, ip, userAgent string) (*model.GostTunnel, error) {
+	tunnel, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case tunnel.Status != model.TunnelStatusRunning:
+		err = s.renewCredentials(tunnel)
+	case tunnel.RelayUsername == "":
+		// 旧版本启动的隧道 Relay 服务未启用认证，生成凭据后重启隧道
+		if err = s.renewCredentials(tunnel); err == nil {
+			s.stop(tunnel)
+			err = s.start(tunnel)
+		}
+	default:
+		err = s.rotateRunning(tunnel)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logService.Record(
+		userID,
+		username,
+		model.ActionUpdate,
+		model.ResourceTypeTunnel,
+		tunnel.ID,
+		fmt.Sprintf("轮换隧道凭据: %s", tunnel.Name),
+		ip,
+		userAgent)
+
+	logger.Infof("轮换隧道凭据成功: %s", tunnel.Name)
+	return s.GetByID(id)
+}
+
+// rotateRunning 轮换运行中隧道的凭据，失败时恢复原凭据
+// 离线的出口不在跳点中，恢复在线重新加入时使用新凭据下发
+func (s *TunnelService) rotateRunning(tunnel *model.GostTunnel) error {
+	entryNode, err := s.nodeRepo.FindByID(tunnel.EntryNodeID)
+	if err != nil {
+		return errors.ErrEntryNodeNotFound
+	}
+	exitNodes, err := s.exitNodes(tunnel)
 	if err != nil {
 		return err
 	}
+	if entryNode.Status == model.NodeStatusOffline {
+		return errors.ErrEntryNodeOffline
+	}
+	exitNodes = onlineNodes(exitNodes)
+	if len(exitNodes) == 0 {
+		return errors.ErrExitNodeOffline
+	}
 
-	// 未运行则跳过
-	if tunnel.Status != model.TunnelStatusRunning {
+	entryClient := utils.GetGostClient(entryNode)
+	old := *tunnel
+	next := *tunnel
+	if next.RelayUsername, next.RelayPassword, err = generateRelayCredentials(); err != nil {
+		return err
+	}
+
+	// restore 恢复出口节点上的原凭据
+	restore := func(exitNodes []*model.GostNode) {
+		for _, exitNode := range exitNodes {
+			if rbErr := utils.GetGostClient(exitNode).ApplyAuther(relayAuther(&old)); rbErr != nil {
+				logger.Errorf("恢复隧道 Relay 认证器失败: %v", rbErr)
+			}
+		}
+	}
+
+	// 步骤1：出口节点同时接受新旧凭据
+	transition := relayAuther(&next)
+	transition.Auths = append(transition.Auths, relayAuther(&old).Auths...)
+	for i, exitNode := range exitNodes {
+		if err = utils.GetGostClient(exitNode).ApplyAuther(transition); err != nil {
+			logger.Warnf("更新隧道 Relay 认证器失败: %v", err)
+			restore(exitNodes[:i])
+			return errors.ErrTunnelCredentialsRotateFailed
+		}
+	}
+
+	// 步骤2：入口节点 Chain 切换到新凭据
+	if err = entryClient.ApplyChain(tunnelChain(&next, exitNodes)); err != nil {
+		logger.Warnf("更新隧道 Chain 失败: %v", err)
+		restore(exitNodes)
+		return errors.ErrTunnelCredentialsRotateFailed
+	}
+
+	// 步骤3：出口节点移除旧凭据，失败时旧凭据仍可用，不影响转发
+	for _, exitNode := range exitNodes {
+		exitClient := utils.GetGostClient(exitNode)
+		if err = exitClient.ApplyAuther(relayAuther(&next)); err != nil {
+			logger.Warnf("移除隧道旧凭据失败: %v", err)
+		}
+		_ = exitClient.SaveConfig()
+	}
+	_ = entryClient.SaveConfig()
+
+	now := time.Now()
+	if err = s.tunnelRepo.UpdateCredentials(tunnel.ID, next.RelayUsername, next.RelayPassword, now); err != nil {
+		return err
+	}
+	tunnel.RelayUsername, tunnel.RelayPassword, tunnel.CredentialsRotatedAt = next.RelayUsername, next.RelayPassword, &now
+	return nil
+}
+
+// renewCredentials 生成并保存新的凭据
+func (s *TunnelService) renewCredentials(tunnel *model.GostTunnel) error {
+	user, password, err := generateRelayCredentials()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err = s.tunnelRepo.UpdateCredentials(tunnel.ID, user, password, now); err != nil {
+		return err
+	}
+	tunnel.RelayUsername, tunnel.RelayPassword, tunnel.CredentialsRotatedAt = user, password, &now
+	return nil
+}
+
+// generateRelayCredentials 生成随机的 Relay 用户名和密码
+func generateRelayCredentials() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	return "relay-" + hex.EncodeToString(buf[:6]), base64.RawURLEncoding.EncodeToString(buf[8:]), nil
+}
+
+// relayServiceName 出口节点上 Relay 服务的名称，附加出口带节点后缀以便按出口统计流量
+func relayServiceName(tunnel *model.GostTunnel, exitNodeID uint) string {
+	if exitNodeID == tunnel.ExitNodeID {
+		return fmt.Sprintf("relay-tunnel-%d", tunnel.ID)
+	}
+	return fmt.Sprintf("relay-tunnel-%d-n%d", tunnel.ID, exitNodeID)
+}
+
+// relayAutherName 出口节点上 Relay 服务的认证器名称
+func relayAutherName(tunnel *model.GostTunnel) string {
+	return fmt.Sprintf("relay-tunnel-%d", tunnel.ID)
+}
+
+// relayAuther 出口节点 Relay 服务的认证器配置
+func relayAuther(tunnel *model.GostTunnel) *gost.AutherConfig {
+	return &gost.AutherConfig{
+		Name:  relayAutherName(tunnel),
+		Auths: []*gost.AuthConfig{{Username: tunnel.RelayUsername, Password: tunnel.RelayPassword}},
+	}
+}
+
+// tunnelChain 入口节点连接出口节点 Relay 服务的 Chain 配置
+// 多出口隧道的跳点包含全部可用出口，按隧道策略选择，连续失败的出口暂时跳过
+func tunnelChain(tunnel *model.GostTunnel, exitNodes []*model.GostNode) *gost.ChainConfig {
+	hop := &gost.HopConfig{Name: "hop-0"}
+	for _, exitNode := range exitNodes {
+		name := "exit-relay"
+		if exitNode.ID != tunnel.ExitNodeID {
+			name = fmt.Sprintf("exit-relay-%d", exitNode.ID)
+		}
+		hop.Nodes = append(hop.Nodes, &gost.NodeConfig{
+			Name: name,
+			Addr: fmt.Sprintf("%s:%d", exitNode.Address, tunnel.RelayPort),
+			Connector: &gost.ConnectorConfig{
+				Type: "relay",
+				Auth: &gost.AuthConfig{Username: tunnel.RelayUsername, Password: tunnel.RelayPassword},
+			},
+			Dialer: tunnelDialer(tunnel),
+		})
+	}
+	if len(tunnel.ExtraExits) > 0 {
+		hop.Selector = &gost.SelectorConfig{
+			Strategy:    normalizeStrategy(tunnel.Strategy),
+			MaxFails:    model.DefaultMaxFails,
+			FailTimeout: model.DefaultFailTimeout * time.Second,
+		}
+	}
+
+	return &gost.ChainConfig{
+		Name: fmt.Sprintf("tunnel-%d-chain", tunnel.ID),
+		Hops: []*gost.HopConfig{hop},
+	}
+}
+
+// kcpCrypts KCP 支持的加密方式
+var kcpCrypts = []string{"aes", "aes-128", "aes-192", "salsa20", "blowfish", "twofish", "cast5", "3des", "tea", "xtea", "xor", "sm4", "none", "null"}
+
+// normalizeTunnelOptions 校验隧道传输参数，参数分组须与协议匹配，空分组会被丢弃
+func normalizeTunnelOptions(protocol string, req *dto.TunnelOptions) (model.TunnelOptions, error) {
+	var options model.TunnelOptions
+	if req.WS != nil && *req.WS != (dto.TunnelWSOptions{}) {
+		ws := model.TunnelWSOptions(*req.WS)
+		if ws.Path != "" && !strings.HasPrefix(ws.Path, "/") {
+			return options, errors.ErrTunnelOptionsInvalid
+		}
+		options.WS = &ws
+	}
+	if req.TLS != nil && *req.TLS != (dto.TunnelTLSOptions{}) {
+		tls := model.TunnelTLSOptions(*req.TLS)
+		if (tls.CertFile == "") != (tls.KeyFile == "") {
+			return options, errors.ErrTunnelOptionsInvalid
+		}
+		options.TLS = &tls
+	}
+	if req.Mux != nil && *req.Mux != (dto.TunnelMuxOptions{}) {
+		mux := model.TunnelMuxOptions(*req.Mux)
+		if mux.Version < 0 || mux.Version > 2 || anyNegative(mux.KeepaliveInterval, mux.KeepaliveTimeout, mux.MaxFrameSize, mux.MaxReceiveBuffer, mux.MaxStreamBuffer) {
+			return options, errors.ErrTunnelOptionsInvalid
+		}
+		options.Mux = &mux
+	}
+	if req.QUIC != nil && *req.QUIC != (dto.TunnelQUICOptions{}) {
+		quic := model.TunnelQUICOptions(*req.QUIC)
+		if anyNegative(quic.KeepAlivePeriod, quic.HandshakeTimeout, quic.MaxIdleTimeout, quic.MaxStreams) {
+			return options, errors.ErrTunnelOptionsInvalid
+		}
+		options.QUIC = &quic
+	}
+	if req.KCP != nil && *req.KCP != (dto.TunnelKCPOptions{}) {
+		kcp := model.TunnelKCPOptions(*req.KCP)
+		if !slices.Contains([]string{"", "normal", "fast", "fast2", "fast3"}, kcp.Mode) ||
+			(kcp.Crypt != "" && !slices.Contains(kcpCrypts, kcp.Crypt)) ||
+			anyNegative(kcp.MTU, kcp.SndWnd, kcp.RcvWnd, kcp.DataShard, kcp.ParityShard, kcp.KeepAlive) {
+			return options, errors.ErrTunnelOptionsInvalid
+		}
+		options.KCP = &kcp
+	}
+
+	allowed := model.TunnelOptionGroups(protocol)
+	for _, group := range options.Groups() {
+		if !slices.Contains(allowed, group) {
+			return options, errors.ErrTunnelOptionsMismatch
+		}
+	}
+	return options, nil
+}
+
+// tunnelOptionsDTO 将传输参数转换为请求结构，未设置任何分组时返回 nil
+func tunnelOptionsDTO(o model.TunnelOptions) *dto.TunnelOptions {
+	if len(o.Groups()) == 0 {
 		return nil
 	}
+	req := &dto.TunnelOptions{}
+	if o.WS != nil {
+		ws := dto.TunnelWSOptions(*o.WS)
+		req.WS = &ws
+	}
+	if o.TLS != nil {
+		tls := dto.TunnelTLSOptions(*o.TLS)
+		req.TLS = &tls
+	}
+	if o.Mux != nil {
+		mux := dto.TunnelMuxOptions(*o.Mux)
+		req.Mux = &mux
+	}
+	if o.QUIC != nil {
+		quic := dto.TunnelQUICOptions(*o.QUIC)
+		req.QUIC = &quic
+	}
+	if o.KCP != nil {
+		kcp := dto.TunnelKCPOptions(*o.KCP)
+		req.KCP = &kcp
+	}
+	return req
+}
 
-	// 获取入口和出口节点
+// anyNegative 是否有负数
+func anyNegative(values ...int) bool {
+	return slices.ContainsFunc(values, func(v int) bool { return v < 0 })
+}
+
+// tunnelListener 出口节点 Relay 服务的监听器配置
+func tunnelListener(tunnel *model.GostTunnel) *gost.ListenerConfig {
+	opts := tunnel.Options
+	listener := &gost.ListenerConfig{Type: tunnel.Protocol}
+	md := tunnelMetadata(opts)
+	if opts.WS != nil && opts.WS.Path != "" {
+		md["path"] = opts.WS.Path
+	}
+	if opts.TLS != nil && opts.TLS.CertFile != "" {
+		listener.TLS = &gost.TLSConfig{CertFile: opts.TLS.CertFile, KeyFile: opts.TLS.KeyFile}
+	}
+	if opts.QUIC != nil && opts.QUIC.MaxStreams > 0 {
+		md["maxStreams"] = opts.QUIC.MaxStreams
+	}
+	if len(md) > 0 {
+		listener.Metadata = md
+	}
+	return listener
+}
+
+// tunnelDialer 入口节点 Chain 的拨号器配置
+func tunnelDialer(tunnel *model.GostTunnel) *gost.DialerConfig {
+	opts := tunnel.Options
+	dialer := &gost.DialerConfig{Type: tunnel.Protocol}
+	md := tunnelMetadata(opts)
+	if opts.WS != nil {
+		if opts.WS.Path != "" {
+			md["path"] = opts.WS.Path
+		}
+		if opts.WS.Host != "" {
+			md["host"] = opts.WS.Host
+		}
+	}
+	if opts.TLS != nil && (opts.TLS.ServerName != "" || opts.TLS.Secure || opts.TLS.CAFile != "") {
+		dialer.TLS = &gost.TLSConfig{ServerName: opts.TLS.ServerName, Secure: opts.TLS.Secure, CAFile: opts.TLS.CAFile}
+	}
+	if len(md) > 0 {
+		dialer.Metadata = md
+	}
+	return dialer
+}
+
+// tunnelMetadata 监听器与拨号器共用的元数据（多路复用、QUIC 超时、KCP 配置）
+func tunnelMetadata(opts model.TunnelOptions) map[string]any {
+	md := make(map[string]any)
+	if mux := opts.Mux; mux != nil {
+		setPositive(md, "mux.version", mux.Version)
+		setSeconds(md, "mux.keepaliveInterval", mux.KeepaliveInterval)
+		setSeconds(md, "mux.keepaliveTimeout", mux.KeepaliveTimeout)
+		setPositive(md, "mux.maxFrameSize", mux.MaxFrameSize)
+		setPositive(md, "mux.maxReceiveBuffer", mux.MaxReceiveBuffer)
+		setPositive(md, "mux.maxStreamBuffer", mux.MaxStreamBuffer)
+	}
+	if quic := opts.QUIC; quic != nil {
+		if quic.KeepAlivePeriod > 0 {
+			md["keepAlive"] = true
+			setSeconds(md, "ttl", quic.KeepAlivePeriod)
+		}
+		setSeconds(md, "handshakeTimeout", quic.HandshakeTimeout)
+		setSeconds(md, "maxIdleTimeout", quic.MaxIdleTimeout)
+	}
+	if kcp := opts.KCP; kcp != nil {
+		config := make(map[string]any)
+		if kcp.Mode != "" {
+			config["mode"] = kcp.Mode
+		}
+		if kcp.Crypt != "" {
+			config["crypt"] = kcp.Crypt
+		}
+		if kcp.Key != "" {
+			config["key"] = kcp.Key
+		}
+		setPositive(config, "mtu", kcp.MTU)
+		setPositive(config, "sndwnd", kcp.SndWnd)
+		setPositive(config, "rcvwnd", kcp.RcvWnd)
+		setPositive(config, "datashard", kcp.DataShard)
+		setPositive(config, "parityshard", kcp.ParityShard)
+		setPositive(config, "keepalive", kcp.KeepAlive)
+		if kcp.NoComp {
+			config["nocomp"] = true
+		}
+		if len(config) > 0 {
+			md["config"] = config
+		}
+	}
+	return md
+}
+
+// setPositive 值大于 0 时写入元数据
+func setPositive(md map[string]any, key string, value int) {
+	if value > 0 {
+		md[key] = value
+	}
+}
+
+// setSeconds 值大于 0 时以时长格式写入元数据
+func setSeconds(md map[string]any, key string, seconds int) {
+	if seconds > 0 {
+		md[key] = fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// stop 删除节点上的 Chain 和 Relay 服务并更新状态，节点不可用时仅更新状态
+func (s *TunnelService) stop(tunnel *model.GostTunnel) {
+	// 获取入口节点
 	entryNode, _ := s.nodeRepo.FindByID(tunnel.EntryNodeID)
-	exitNode, _ := s.nodeRepo.FindByID(tunnel.ExitNodeID)
 
 	// 步骤1：删除入口节点的 Chain
 	if entryNode != nil && entryNode.Status == model.NodeStatusOnline && tunnel.ChainID != "" {
 		entryClient := utils.GetGostClient(entryNode)
-		if err = entryClient.DeleteChain(tunnel.ChainID); err != nil {
+		if err := entryClient.DeleteChain(tunnel.ChainID); err != nil {
 			logger.Warnf("删除隧道 Chain 失败: %v", err)
 		}
 		_ = entryClient.SaveConfig()
 	}
 
-	// 步骤2：删除出口节点的 Relay 服务
-	if exitNode != nil && exitNode.Status == model.NodeStatusOnline && tunnel.ServiceID != "" {
-		exitClient := utils.GetGostClient(exitNode)
-		if err = exitClient.DeleteService(tunnel.ServiceID); err != nil {
-			logger.Warnf("删除隧道 Relay 服务失败: %v", err)
+	// 步骤2：删除在线出口节点的 Relay 服务
+	if tunnel.ServiceID != "" {
+		var exitNodes []*model.GostNode
+		for _, exitNodeID := range tunnel.ExitNodeIDs() {
+			if exitNode, _ := s.nodeRepo.FindByID(exitNodeID); exitNode != nil && exitNode.Status == model.NodeStatusOnline {
+				exitNodes = append(exitNodes, exitNode)
+			}
 		}
-		_ = exitClient.SaveConfig()
+		s.deleteRelays(tunnel, exitNodes)
 	}
 
 	// 更新状态
-	_ = s.tunnelRepo.UpdateStatus(id, model.TunnelStatusStopped)
+	_ = s.tunnelRepo.UpdateStatus(tunnel.ID, model.TunnelStatusStopped)
+}
 
-	s.logService.Record(
-		userID,
-		username,
-		model.ActionStop,
-		model.ResourceTypeTunnel,
-		id,
-		fmt.Sprintf("停止隧道: %s", tunnel.Name),
-		ip,
-		userAgent)
+// changeExit 将隧道的出口 from（主出口或附加出口）更换为 exitNodeID，运行中的隧道会重新建立，失败时恢复原出口
+// 新出口已是隧道的其他出口时只移除 from；Relay 端口在新出口上冲突时，autoPort 为 true 则为全部出口重新分配，否则返回错误
+// Chain 名称不变，入口节点上使用该隧道的规则无需重建
+func (s *TunnelService) changeExit(tunnel *model.GostTunnel, from, exitNodeID uint, autoPort bool) error {
+	if tunnel.EntryNodeID == exitNodeID {
+		return errors.ErrTunnelNodeSame
+	}
 
-	logger.Infof("停止隧道成功: %s", tunnel.Name)
+	exitNode, err := s.nodeRepo.FindByID(exitNodeID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrExitNodeNotFound
+		}
+		return err
+	}
+
+	exitIDs := tunnel.ExitNodeIDs()
+	i := slices.Index(exitIDs, from)
+	if i < 0 {
+		return errors.ErrExitNodeNotFound
+	}
+	added := !slices.Contains(exitIDs, exitNodeID)
+	if added {
+		exitIDs[i] = exitNodeID
+	} else {
+		exitIDs = slices.Delete(exitIDs, i, i+1)
+	}
+
+	// 端口分配锁只保护选择 Relay 端口与保存出口，停止、启动隧道的节点请求在释放锁后进行
+	portAllocMu.Lock()
+	relayPort, err := s.changeExitRelayPort(tunnel, exitNode, exitIDs, added, autoPort)
+	if err != nil {
+		portAllocMu.Unlock()
+		return err
+	}
+
+	wasRunning := tunnel.Status == model.TunnelStatusRunning
+	running := *tunnel
+	tunnel.Status = model.TunnelStatusStopped
+	oldExitIDs, oldRelayPort := tunnel.ExitNodeIDs(), tunnel.RelayPort
+	tunnel.ExitNodeID = exitIDs[0]
+	tunnel.RelayPort = relayPort
+	err = s.saveTunnel(tunnel, exitIDs[1:])
+	portAllocMu.Unlock()
+	if err != nil {
+		*tunnel = running
+		return err
+	}
+
+	if !wasRunning {
+		return nil
+	}
+	// 按原出口删除 Relay 与 Chain，再按新出口重新建立
+	s.stop(&running)
+	if err := s.start(tunnel); err != nil {
+		// 删除新出口上已创建的 Relay，恢复原出口后重新启动
+		s.stop(tunnel)
+		tunnel.ExitNodeID, tunnel.RelayPort = oldExitIDs[0], oldRelayPort
+		tunnel.Status = model.TunnelStatusStopped
+		portAllocMu.Lock()
+		rbErr := s.saveTunnel(tunnel, oldExitIDs[1:])
+		portAllocMu.Unlock()
+		if rbErr != nil {
+			logger.Errorf("恢复隧道出口失败: %v", rbErr)
+		} else if rbErr = s.start(tunnel); rbErr != nil {
+			logger.Warnf("恢复启动隧道失败: %v", rbErr)
+		}
+		return err
+	}
 	return nil
 }
 
+// changeExitRelayPort 更换出口后使用的 Relay 端口，调用方需持有端口分配锁
+// 新出口上原端口冲突且 autoPort 为 true 时，为全部出口重新分配
+func (s *TunnelService) changeExitRelayPort(tunnel *model.GostTunnel, exitNode *model.GostNode, exitIDs []uint, added, autoPort bool) (int, error) {
+	relayPort := tunnel.RelayPort
+	if !added {
+		return relayPort, nil
+	}
+	exclude := PortExclude{TunnelID: tunnel.ID}
+	err := s.portService.Check(exitNode, relayPort, exclude)
+	if err == nil {
+		return relayPort, nil
+	}
+	if !autoPort || !isPortConflict(err) {
+		return 0, err
+	}
+	exitNodes := []*model.GostNode{exitNode}
+	for _, id := range exitIDs {
+		if id == exitNode.ID {
+			continue
+		}
+		node, err := s.nodeRepo.FindByID(id)
+		if err != nil {
+			return 0, errors.ErrExitNodeNotFound
+		}
+		exitNodes = append(exitNodes, node)
+	}
+	return s.portService.AllocateShared(exitNodes, exclude)
+}
+
+// saveTunnel 保存隧道并按顺序替换附加出口
+func (s *TunnelService) saveTunnel(tunnel *model.GostTunnel, extraExitNodeIDs []uint) error {
+	// Save 会按已加载的关联回写，保存前先清空
+	tunnel.EntryNode, tunnel.ExitNode, tunnel.ExtraExits = nil, nil, nil
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tunnelRepo := repository.NewTunnelRepository(tx)
+		if err := tunnelRepo.Update(tunnel); err != nil {
+			return err
+		}
+		return tunnelRepo.ReplaceExtraExits(tunnel.ID, extraExitNodeIDs)
+	})
+	if err != nil {
+		return err
+	}
+	for _, nodeID := range extraExitNodeIDs {
+		tunnel.ExtraExits = append(tunnel.ExtraExits, model.TunnelExit{TunnelID: tunnel.ID, NodeID: nodeID})
+	}
+	return nil
+}
+
+// resolveExtraExits 校验附加出口节点：须存在，且不能与入口、主出口或其他附加出口重复
+// 维护中的节点不能新加入为出口，current 中已有的出口不受限制
+func (s *TunnelService) resolveExtraExits(entryNodeID, exitNodeID uint, ids, current []uint) ([]*model.GostNode, error) {
+	seen := []uint{entryNodeID, exitNodeID}
+	nodes := make([]*model.GostNode, 0, len(ids))
+	for _, id := range ids {
+		if slices.Contains(seen, id) {
+			return nil, errors.ErrTunnelExitsInvalid
+		}
+		seen = append(seen, id)
+
+		node, err := s.nodeRepo.FindByID(id)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.ErrTunnelExitsInvalid
+			}
+			return nil, err
+		}
+		if node.Maintenance && !slices.Contains(current, id) {
+			return nil, errors.ErrNodeMaintenance
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// checkRelayPort 检查 Relay 端口能否在全部出口节点上使用
+func (s *TunnelService) checkRelayPort(exitNodes []*model.GostNode, port int, exclude PortExclude) error {
+	for _, exitNode := range exitNodes {
+		if err := s.portService.Check(exitNode, port, exclude); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exitNodes 查询隧道的全部出口节点，主出口在前
+func (s *TunnelService) exitNodes(tunnel *model.GostTunnel) ([]*model.GostNode, error) {
+	exitNodes := make([]*model.GostNode, 0, 1+len(tunnel.ExtraExits))
+	for _, id := range tunnel.ExitNodeIDs() {
+		exitNode, err := s.nodeRepo.FindByID(id)
+		if err != nil {
+			return nil, errors.ErrExitNodeNotFound
+		}
+		exitNodes = append(exitNodes, exitNode)
+	}
+	return exitNodes, nil
+}
+
+// onlineNodes 过滤掉离线的节点
+func onlineNodes(nodes []*model.GostNode) []*model.GostNode {
+	return slices.DeleteFunc(slices.Clone(nodes), func(node *model.GostNode) bool {
+		return node.Status == model.NodeStatusOffline
+	})
+}
+
+// nodeNames 节点名称列表，用于日志
+func nodeNames(nodes []*model.GostNode) string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 // GetChainID 获取隧道的 Chain ID（供规则服务使用）
 func (s *TunnelService) GetChainID(tunnelID uint) (string, error) {
 	tunnel, err := s.tunnelRepo.FindByID(tunnelID)
@@ -415,3 +1149,125 @@ func (s *TunnelService) GetEntryNodeID(tunnelID uint) (uint, error) {
 	}
 	return tunnel.EntryNodeID, nil
 }
+
+// Batch 批量启动/停止/重启/删除隧道
+// 按 ID 列表或筛选条件选择隧道，并发执行并返回逐项结果，只记录一条操作日志
+func (s *TunnelService) Batch(req *dto.BatchTunnelReq, userID uint, username string, ip, userAgent string) (*dto.BatchResp, error) {
+	items, err := s.batchItems(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量删除前自动备份，备份失败则不执行
+	var snapshot string
+	if req.Action == BatchActionDelete {
+		if snapshot, err = NewBackupService(s.db).Snapshot(BackupTriggerPreDelete); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := runBatch(req.Action, items)
+	resp.Snapshot = snapshot
+
+	s.logService.Record(
+		userID,
+		username,
+		batchLogAction(req.Action),
+		model.ResourceTypeTunnel,
+		0,
+		batchLogDetails(resp, batchTitle(req.Action, "隧道")),
+		ip,
+		userAgent)
+
+	logger.Infof("批量操作隧道完成: %s, 成功 %d, 跳过 %d, 失败 %d", req.Action, resp.Succeeded, resp.Skipped, resp.Failed)
+	return resp, nil
+}
+
+// batchItems 解析批量操作对象
+func (s *TunnelService) batchItems(req *dto.BatchTunnelReq) ([]batchItem, error) {
+	if len(req.IDs) > 0 {
+		ids, err := batchIDs(req.IDs)
+		if err != nil {
+			return nil, err
+		}
+		tunnels, _, err := s.tunnelRepo.List(&repository.QueryOption{
+			Conditions: map[string]any{"id IN ?": ids},
+		})
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[uint]*model.GostTunnel, len(tunnels))
+		for i := range tunnels {
+			found[tunnels[i].ID] = &tunnels[i]
+		}
+
+		items := make([]batchItem, 0, len(ids))
+		for _, id := range ids {
+			tunnel, ok := found[id]
+			if !ok {
+				items = append(items, batchItem{ID: id, Run: func() error {
+					return errors.ErrTunnelNotFound
+				}})
+				continue
+			}
+			items = append(items, s.batchItem(req.Action, tunnel))
+		}
+		return items, nil
+	}
+
+	if req.Filter.IsEmpty() {
+		return nil, errors.ErrBatchTargetRequired
+	}
+	tunnels, total, err := s.tunnelRepo.List(&repository.QueryOption{
+		Conditions: tunnelConditions(req.Filter),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, errors.ErrBatchEmpty
+	}
+	if total > batchMaxItems {
+		return nil, errors.ErrBatchTooMany
+	}
+
+	items := make([]batchItem, 0, len(tunnels))
+	for i := range tunnels {
+		items = append(items, s.batchItem(req.Action, &tunnels[i]))
+	}
+	return items, nil
+}
+
+// batchItem 单个隧道的批量操作
+func (s *TunnelService) batchItem(action string, tunnel *model.GostTunnel) batchItem {
+	item := batchItem{ID: tunnel.ID, Name: tunnel.Name}
+	switch action {
+	case BatchActionStart:
+		item.Run = func() error {
+			if tunnel.Status == model.TunnelStatusRunning {
+				return errBatchSkipped
+			}
+			return s.start(tunnel)
+		}
+	case BatchActionStop:
+		item.Run = func() error {
+			if tunnel.Status != model.TunnelStatusRunning {
+				return errBatchSkipped
+			}
+			s.stop(tunnel)
+			return nil
+		}
+	case BatchActionRestart:
+		item.Run = func() error {
+			if tunnel.Status == model.TunnelStatusRunning {
+				s.stop(tunnel)
+			}
+			return s.start(tunnel)
+		}
+	default:
+		item.Run = func() error {
+			return s.remove(tunnel)
+		}
+	}
+	return item
+}
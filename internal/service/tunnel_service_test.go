@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+)
+
+func TestTunnelKCPKeyMasked(t *testing.T) {
+	db := newTestDB(t)
+	entry := &model.GostNode{Name: "hk", Address: "1.1.1.1", Port: 18080, Status: model.NodeStatusOffline}
+	exit := &model.GostNode{Name: "sg", Address: "2.2.2.2", Port: 18080, Status: model.NodeStatusOffline}
+	mustCreate(t, db, entry, exit)
+	tunnel := &model.GostTunnel{
+		Name: "kcp", Protocol: "kcp", EntryNodeID: entry.ID, ExitNodeID: exit.ID, RelayPort: 10002,
+		Options: model.TunnelOptions{KCP: &model.TunnelKCPOptions{Mode: "fast", Key: "s3cret"}},
+	}
+	mustCreate(t, db, tunnel)
+	s := NewTunnelService(db)
+
+	got, err := s.GetByID(tunnel.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Options.KCP == nil || got.Options.KCP.Key != "s3cret" {
+		t.Fatalf("读取的 KCP 参数 = %+v，期望保留密钥", got.Options.KCP)
+	}
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("响应包含 KCP 密钥: %s", data)
+	}
+
+	cases := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"未填写密钥时沿用原密钥", "", "s3cret"},
+		{"填写新密钥", "n3w", "n3w"},
+	}
+	for _, c := range cases {
+		req := &dto.UpdateTunnelReq{
+			Name: "kcp", Protocol: "kcp", RelayPort: 10002,
+			Options: dto.TunnelOptions{KCP: &dto.TunnelKCPOptions{Mode: "fast2", Key: c.key}},
+		}
+		got, err = s.Update(tunnel.ID, req, 1, "admin", "", "")
+		if err != nil {
+			t.Fatalf("%s: Update: %v", c.name, err)
+		}
+		if kcp := got.Options.KCP; kcp == nil || kcp.Key != c.want || kcp.Mode != "fast2" {
+			t.Errorf("%s: KCP 参数 = %+v，期望密钥 %q", c.name, kcp, c.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PortRange 端口区间（闭区间）
+type PortRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Contains 端口是否在区间内
+func (r PortRange) Contains(port int) bool {
+	return port >= r.Start && port <= r.End
+}
+
+// String 格式化为 "10000-20000"，单个端口时只输出端口
+func (r PortRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// ParsePortRanges 解析逗号分隔的端口范围，如 "10000-20000,30000"
+// 区间按起始端口排序，空字符串返回 nil
+func ParsePortRanges(s string) ([]PortRange, error) {
+	var ranges []PortRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		start, end, found := strings.Cut(part, "-")
+		if !found {
+			end = start
+		}
+		r := PortRange{}
+		var err error
+		if r.Start, err = parsePort(start); err != nil {
+			return nil, err
+		}
+		if r.End, err = parsePort(end); err != nil {
+			return nil, err
+		}
+		if r.Start > r.End {
+			return nil, fmt.Errorf("端口范围无效: %s", part)
+		}
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	return ranges, nil
+}
+
+// FormatPortRanges 将端口范围格式化为规范写法
+func FormatPortRanges(ranges []PortRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// NormalizePortRanges 校验并规范化端口范围字符串
+func NormalizePortRanges(s string) (string, error) {
+	ranges, err := ParsePortRanges(s)
+	if err != nil {
+		return "", err
+	}
+	return FormatPortRanges(ranges), nil
+}
+
+// AddrPort 从服务监听地址（如 ":8080"、"0.0.0.0:8080"）中取出端口，无法解析时返回 0
+func AddrPort(addr string) int {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("端口无效: %s", s)
+	}
+	return port, nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"gost-panel/internal/dto"
+)
+
+// Login 用户名密码登录，成功后客户端使用返回的 JWT
+func (c *Client) Login(ctx context.Context, username, password string) (*LoginResult, error) {
+	var result LoginResult
+	req := dto.LoginReq{Username: username, Password: password}
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/auth/login", nil, req, &result); err != nil {
+		return nil, err
+	}
+	c.token = result.Token
+	return &result, nil
+}
+
+// UserInfo 当前用户信息
+func (c *Client) UserInfo(ctx context.Context) (*dto.UserInfoResp, error) {
+	var info dto.UserInfoResp
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/auth/info", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ChangePassword 修改密码
+func (c *Client) ChangePassword(ctx context.Context, oldPassword, newPassword string) error {
+	req := dto.ChangePasswordReq{OldPassword: oldPassword, NewPassword: newPassword}
+	return c.do(ctx, http.MethodPut, apiPrefix+"/auth/password", nil, req, nil)
+}
+
+// RefreshToken 刷新 JWT，成功后客户端使用新 Token
+func (c *Client) RefreshToken(ctx context.Context) (string, error) {
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/auth/refresh", nil, nil, &result); err != nil {
+		return "", err
+	}
+	c.token = result.Token
+	return result.Token, nil
+}
+
+// ListAPITokens 当前用户的 API Token 列表
+func (c *Client) ListAPITokens(ctx context.Context) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/auth/tokens", nil, nil, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// CreateAPIToken 创建 API Token
+func (c *Client) CreateAPIToken(ctx context.Context, req *dto.CreateAPITokenReq) (*CreatedAPIToken, error) {
+	var result CreatedAPIToken
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/auth/tokens", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAPIToken 吊销 API Token
+func (c *Client) DeleteAPIToken(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, idPath("auth/tokens", id), nil, nil, nil)
+}
+
+// Health 健康检查（无需认证）
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	resp, err := c.send(ctx, http.MethodGet, apiPrefix+"/health", nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{HTTPStatus: resp.StatusCode, Code: resp.StatusCode * 100, Message: "健康检查失败: HTTP " + strconv.Itoa(resp.StatusCode)}
+	}
+
+	var health Health
+	if err = json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
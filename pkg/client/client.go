@@ -0,0 +1,246 @@
+// Package client GOST Panel API 的 Go 客户端
+// 封装统一响应结构与业务错误码，请求与响应类型直接复用面板的 model 与 dto 定义。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiPrefix 接口路径前缀
+const apiPrefix = "/api/v1"
+
+// defaultTimeout 默认请求超时
+const defaultTimeout = 30 * time.Second
+
+// Client 面板 API 客户端
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithToken 设置认证 Token（JWT 或个人 API Token）
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient 使用自定义 HTTP 客户端
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout 设置请求超时
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// New 创建客户端，baseURL 为面板地址，如 http://127.0.0.1:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetToken 设置认证 Token
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Token 当前使用的认证 Token
+func (c *Client) Token() string {
+	return c.token
+}
+
+// envelope 统一响应结构
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// do 发送 JSON 请求并解析统一响应结构，out 为 nil 时忽略 data
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(ctx, method, path, query, reader, contentType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return decodeResponse(resp, out)
+}
+
+// send 发送请求，返回原始响应，调用方负责关闭 Body
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return c.httpClient.Do(req)
+}
+
+// decodeResponse 解析统一响应结构，业务失败时返回 *Error
+func decodeResponse(resp *http.Response, out any) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var env envelope
+	if err = json.Unmarshal(data, &env); err != nil {
+		// 非统一响应结构（如未匹配的路由）
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &Error{
+				HTTPStatus: resp.StatusCode,
+				Code:       resp.StatusCode * 100,
+				Message:    strings.TrimSpace(http.StatusText(resp.StatusCode) + " " + string(data)),
+			}
+		}
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	if env.Code != 0 || resp.StatusCode >= http.StatusBadRequest {
+		return &Error{
+			HTTPStatus: resp.StatusCode,
+			Code:       env.Code,
+			Message:    env.Message,
+			Data:       env.Data,
+		}
+	}
+
+	if out == nil || len(env.Data) == 0 || string(env.Data) == "null" {
+		return nil
+	}
+	if err = json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("解析响应数据失败: %w", err)
+	}
+	return nil
+}
+
+// download 请求文件类接口，成功时将内容写入 w
+func (c *Client) download(ctx context.Context, method, path string, body any, w io.Writer) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(ctx, method, path, nil, reader, contentType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// 失败时返回统一响应结构
+	if resp.StatusCode != http.StatusOK {
+		if err = decodeResponse(resp, nil); err != nil {
+			return err
+		}
+		return &Error{HTTPStatus: resp.StatusCode, Code: resp.StatusCode * 100, Message: http.StatusText(resp.StatusCode)}
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// idPath 拼接资源 ID 路径
+func idPath(resource string, id uint, action ...string) string {
+	p := apiPrefix + "/" + resource + "/" + strconv.FormatUint(uint64(id), 10)
+	for _, a := range action {
+		p += "/" + a
+	}
+	return p
+}
+
+// encodeQuery 按 form 标签将查询结构转换为查询参数，忽略零值字段
+func encodeQuery(v any) url.Values {
+	query := url.Values{}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return query
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return query
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		name := strings.Split(f.Tag.Get("form"), ",")[0]
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.IsZero() {
+			continue
+		}
+		query.Set(name, fmt.Sprint(fv.Interface()))
+	}
+	return query
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bizerrors "gost-panel/internal/errors"
+	"gost-panel/pkg/response"
+)
+
+// Error 接口返回的业务错误
+type Error struct {
+	HTTPStatus int             // HTTP 状态码
+	Code       int             // 业务错误码
+	Message    string          // 错误消息
+	Data       json.RawMessage // 错误附带的数据（如导入冲突时的计划）
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code=%d, http=%d)", e.Message, e.Code, e.HTTPStatus)
+}
+
+// Is 按业务错误码比较，支持 *Error 与面板定义的 *errors.BizError
+//
+//	errors.Is(err, client.ErrUnauthorized)
+//	errors.Is(err, bizerrors.ErrRuleNotFound)
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *Error:
+		return t.Code == e.Code
+	case *bizerrors.BizError:
+		return t.Code == e.Code
+	}
+	return false
+}
+
+// 通用错误码
+var (
+	// ErrBadRequest 请求参数错误
+	ErrBadRequest = &Error{Code: response.CodeBadRequest, Message: response.MsgBadRequest}
+	// ErrUnauthorized 未登录或 Token 无效
+	ErrUnauthorized = &Error{Code: response.CodeUnauthorized, Message: response.MsgUnauthorized}
+	// ErrForbidden 无权限（如 API Token 权限范围不足）
+	ErrForbidden = &Error{Code: response.CodeForbidden, Message: response.MsgForbidden}
+	// ErrNotFound 资源不存在
+	ErrNotFound = &Error{Code: response.CodeNotFound, Message: response.MsgNotFound}
+	// ErrInternal 服务器内部错误
+	ErrInternal = &Error{Code: response.CodeInternalError, Message: response.MsgInternalError}
+)
@@ -0,0 +1,319 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"gost-panel/internal/dto"
+)
+
+// ==================== 节点 ====================
+
+// ListNodes 节点列表，req 为 nil 时使用默认分页
+func (c *Client) ListNodes(ctx context.Context, req *dto.NodeListReq) (*Page[Node], error) {
+	var page Page[Node]
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/nodes", encodeQuery(req), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetNode 节点详情
+func (c *Client) GetNode(ctx context.Context, id uint) (*Node, error) {
+	var node Node
+	if err := c.do(ctx, http.MethodGet, idPath("nodes", id), nil, nil, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// CreateNode 创建节点
+func (c *Client) CreateNode(ctx context.Context, req *dto.CreateNodeReq) (*Node, error) {
+	var node Node
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/nodes", nil, req, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// UpdateNode 更新节点
+func (c *Client) UpdateNode(ctx context.Context, id uint, req *dto.UpdateNodeReq) (*Node, error) {
+	var node Node
+	if err := c.do(ctx, http.MethodPut, idPath("nodes", id), nil, req, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// DeleteNode 删除节点
+func (c *Client) DeleteNode(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, idPath("nodes", id), nil, nil, nil)
+}
+
+// SetNodeMaintenance 开启或关闭节点维护模式
+func (c *Client) SetNodeMaintenance(ctx context.Context, id uint, req *dto.NodeMaintenanceReq) (*Node, error) {
+	var node Node
+	if err := c.do(ctx, http.MethodPut, idPath("nodes", id, "maintenance"), nil, req, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// DrainNode 排空维护中的节点，逐项结果见返回值
+func (c *Client) DrainNode(ctx context.Context, id uint, req *dto.DrainNodeReq) (*BatchResult, error) {
+	var result BatchResult
+	if err := c.do(ctx, http.MethodPost, idPath("nodes", id, "drain"), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetNodePorts 节点端口池及占用情况
+func (c *Client) GetNodePorts(ctx context.Context, id uint) (*NodePorts, error) {
+	var ports NodePorts
+	if err := c.do(ctx, http.MethodGet, idPath("nodes", id, "ports"), nil, nil, &ports); err != nil {
+		return nil, err
+	}
+	return &ports, nil
+}
+
+// GetNodeConfig 节点上当前的 GOST 配置
+func (c *Client) GetNodeConfig(ctx context.Context, id uint) (*NodeConfig, error) {
+	var cfg NodeConfig
+	if err := c.do(ctx, http.MethodGet, idPath("nodes", id, "config"), nil, nil, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ==================== 规则 ====================
+
+// ListRules 规则列表，req 为 nil 时使用默认分页
+func (c *Client) ListRules(ctx context.Context, req *dto.RuleListReq) (*Page[Rule], error) {
+	var page Page[Rule]
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/rules", encodeQuery(req), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetRule 规则详情
+func (c *Client) GetRule(ctx context.Context, id uint) (*Rule, error) {
+	var rule Rule
+	if err := c.do(ctx, http.MethodGet, idPath("rules", id), nil, nil, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetRuleTargets 获取规则转发目标的设置与探测状态
+func (c *Client) GetRuleTargets(ctx context.Context, id uint) (*dto.RuleTargetsResp, error) {
+	var resp dto.RuleTargetsResp
+	if err := c.do(ctx, http.MethodGet, idPath("rules", id, "targets"), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateRule 创建规则
+func (c *Client) CreateRule(ctx context.Context, req *dto.CreateRuleReq) (*Rule, error) {
+	var rule Rule
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/rules", nil, req, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateRule 更新规则
+func (c *Client) UpdateRule(ctx context.Context, id uint, req *dto.UpdateRuleReq) (*Rule, error) {
+	var rule Rule
+	if err := c.do(ctx, http.MethodPut, idPath("rules", id), nil, req, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeleteRule 删除规则
+func (c *Client) DeleteRule(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, idPath("rules", id), nil, nil, nil)
+}
+
+// StartRule 启动规则
+func (c *Client) StartRule(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, idPath("rules", id, "start"), nil, nil, nil)
+}
+
+// StopRule 停止规则
+func (c *Client) StopRule(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, idPath("rules", id, "stop"), nil, nil, nil)
+}
+
+// CloneRule 克隆规则到其他节点或隧道，返回新规则
+func (c *Client) CloneRule(ctx context.Context, id uint, req *dto.CloneRuleReq) (*Rule, error) {
+	var rule Rule
+	if err := c.do(ctx, http.MethodPost, idPath("rules", id, "clone"), nil, req, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// MigrateRule 迁移规则到其他节点或隧道
+func (c *Client) MigrateRule(ctx context.Context, id uint, req *dto.MigrateRuleReq) (*Rule, error) {
+	var rule Rule
+	if err := c.do(ctx, http.MethodPost, idPath("rules", id, "migrate"), nil, req, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// BatchRules 批量启动/停止/重启/删除规则，逐项结果见返回值
+func (c *Client) BatchRules(ctx context.Context, req *dto.BatchRuleReq) (*BatchResult, error) {
+	var result BatchResult
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/rules/batch", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ==================== IP 列表 ====================
+
+// ListIPLists 全部命名 IP 列表
+func (c *Client) ListIPLists(ctx context.Context) ([]IPList, error) {
+	var lists []IPList
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/ip-lists", nil, nil, &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// GetIPList IP 列表详情
+func (c *Client) GetIPList(ctx context.Context, id uint) (*IPList, error) {
+	var list IPList
+	if err := c.do(ctx, http.MethodGet, idPath("ip-lists", id), nil, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CreateIPList 创建 IP 列表
+func (c *Client) CreateIPList(ctx context.Context, req *dto.CreateIPListReq) (*IPList, error) {
+	var list IPList
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/ip-lists", nil, req, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// UpdateIPList 更新 IP 列表，引用它的运行中规则随之更新
+func (c *Client) UpdateIPList(ctx context.Context, id uint, req *dto.UpdateIPListReq) (*IPList, error) {
+	var list IPList
+	if err := c.do(ctx, http.MethodPut, idPath("ip-lists", id), nil, req, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// DeleteIPList 删除 IP 列表
+func (c *Client) DeleteIPList(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, idPath("ip-lists", id), nil, nil, nil)
+}
+
+// ==================== 隧道 ====================
+
+// ListTunnels 隧道列表，req 为 nil 时使用默认分页
+func (c *Client) ListTunnels(ctx context.Context, req *dto.TunnelListReq) (*Page[Tunnel], error) {
+	var page Page[Tunnel]
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/tunnels", encodeQuery(req), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetTunnel 隧道详情
+func (c *Client) GetTunnel(ctx context.Context, id uint) (*Tunnel, error) {
+	var tunnel Tunnel
+	if err := c.do(ctx, http.MethodGet, idPath("tunnels", id), nil, nil, &tunnel); err != nil {
+		return nil, err
+	}
+	return &tunnel, nil
+}
+
+// CreateTunnel 创建隧道
+func (c *Client) CreateTunnel(ctx context.Context, req *dto.CreateTunnelReq) (*Tunnel, error) {
+	var tunnel Tunnel
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/tunnels", nil, req, &tunnel); err != nil {
+		return nil, err
+	}
+	return &tunnel, nil
+}
+
+// UpdateTunnel 更新隧道
+func (c *Client) UpdateTunnel(ctx context.Context, id uint, req *dto.UpdateTunnelReq) (*Tunnel, error) {
+	var tunnel Tunnel
+	if err := c.do(ctx, http.MethodPut, idPath("tunnels", id), nil, req, &tunnel); err != nil {
+		return nil, err
+	}
+	return &tunnel, nil
+}
+
+// DeleteTunnel 删除隧道
+func (c *Client) DeleteTunnel(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, idPath("tunnels", id), nil, nil, nil)
+}
+
+// StartTunnel 启动隧道
+func (c *Client) StartTunnel(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, idPath("tunnels", id, "start"), nil, nil, nil)
+}
+
+// StopTunnel 停止隧道
+func (c *Client) StopTunnel(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, idPath("tunnels", id, "stop"), nil, nil, nil)
+}
+
+// RotateTunnelCredentials 轮换隧道 Relay 认证凭据
+func (c *Client) RotateTunnelCredentials(ctx context.Context, id uint) (*Tunnel, error) {
+	var tunnel Tunnel
+	if err := c.do(ctx, http.MethodPost, idPath("tunnels", id, "rotate-credentials"), nil, nil, &tunnel); err != nil {
+		return nil, err
+	}
+	return &tunnel, nil
+}
+
+// BatchTunnels 批量启动/停止/重启/删除隧道，逐项结果见返回值
+func (c *Client) BatchTunnels(ctx context.Context, req *dto.BatchTunnelReq) (*BatchResult, error) {
+	var result BatchResult
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/tunnels/batch", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ==================== 统计与日志 ====================
+
+// DashboardStats 仪表盘统计
+func (c *Client) DashboardStats(ctx context.Context) (*DashboardStats, error) {
+	var stats DashboardStats
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/dashboard/stats", nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// LatencyStats 各探测路径的延迟与丢失率，req 为 nil 时统计最近 1 小时
+func (c *Client) LatencyStats(ctx context.Context, req *dto.LatencyStatsReq) ([]LatencyStats, error) {
+	var stats []LatencyStats
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/latency", encodeQuery(req), nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListLogs 操作日志列表，req 为 nil 时使用默认分页
+func (c *Client) ListLogs(ctx context.Context, req *dto.LogListReq) (*Page[OperationLog], error) {
+	var page Page[OperationLog]
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/logs", encodeQuery(req), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
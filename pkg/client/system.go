@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"gost-panel/internal/dto"
+)
+
+// ==================== 系统设置 ====================
+
+// GetSystemConfig 系统配置
+func (c *Client) GetSystemConfig(ctx context.Context) (*dto.SystemConfigResp, error) {
+	var cfg dto.SystemConfigResp
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/system/config", nil, nil, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetPublicConfig 公开系统配置（无需认证）
+func (c *Client) GetPublicConfig(ctx context.Context) (*dto.PublicSystemConfigResp, error) {
+	var cfg dto.PublicSystemConfigResp
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/system/public-config", nil, nil, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// UpdateSystemConfig 更新系统配置
+func (c *Client) UpdateSystemConfig(ctx context.Context, req *dto.UpdateSystemConfigReq) error {
+	return c.do(ctx, http.MethodPut, apiPrefix+"/system/config", nil, req, nil)
+}
+
+// TestEmail 发送测试邮件
+func (c *Client) TestEmail(ctx context.Context, req *dto.EmailConfigReq) error {
+	return c.do(ctx, http.MethodPost, apiPrefix+"/system/email/test", nil, req, nil)
+}
+
+// ==================== 备份 ====================
+
+// CreateBackup 立即备份
+func (c *Client) CreateBackup(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, apiPrefix+"/system/backup", nil, nil, nil)
+}
+
+// TestS3 测试 S3 连接
+func (c *Client) TestS3(ctx context.Context, req *dto.S3ConfigReq) error {
+	return c.do(ctx, http.MethodPost, apiPrefix+"/system/backup/s3/test", nil, req, nil)
+}
+
+// ListBackups 备份文件列表
+func (c *Client) ListBackups(ctx context.Context) ([]BackupFile, error) {
+	var list []BackupFile
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/system/backups", nil, nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DownloadBackup 下载备份文件并写入 w
+func (c *Client) DownloadBackup(ctx context.Context, name string, w io.Writer) error {
+	return c.download(ctx, http.MethodGet, apiPrefix+"/system/backups/"+url.PathEscape(name)+"/download", nil, w)
+}
+
+// UploadBackup 上传备份文件，返回保存的文件名
+// passphrase 用于校验加密备份，为空时使用系统配置中的口令
+func (c *Client) UploadBackup(ctx context.Context, filename string, r io.Reader, passphrase string) (string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(part, r); err != nil {
+		return "", err
+	}
+	if passphrase != "" {
+		if err = mw.WriteField("passphrase", passphrase); err != nil {
+			return "", err
+		}
+	}
+	if err = mw.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := c.send(ctx, http.MethodPost, apiPrefix+"/system/backups/upload", nil, &buf, mw.FormDataContentType())
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	if err = decodeResponse(resp, &result); err != nil {
+		return "", err
+	}
+	return result.Name, nil
+}
+
+// RestoreBackup 从备份恢复，passphrase 仅加密备份需要
+func (c *Client) RestoreBackup(ctx context.Context, name, passphrase string) (*dto.RestoreBackupResp, error) {
+	var body any
+	if passphrase != "" {
+		body = dto.RestoreBackupReq{Passphrase: passphrase}
+	}
+
+	var result dto.RestoreBackupResp
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/system/backups/"+url.PathEscape(name)+"/restore", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ==================== 资源清单 ====================
+
+// ExportInventory 导出资源清单，返回 YAML 或 JSON 内容
+func (c *Client) ExportInventory(ctx context.Context, req *dto.ExportInventoryReq) ([]byte, error) {
+	if req == nil {
+		req = &dto.ExportInventoryReq{}
+	}
+
+	var buf bytes.Buffer
+	if err := c.download(ctx, http.MethodPost, apiPrefix+"/inventory/export", req, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PlanInventory 预览导入资源清单的变更
+func (c *Client) PlanInventory(ctx context.Context, req *dto.ImportInventoryReq) (*InventoryPlan, error) {
+	var plan InventoryPlan
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/inventory/plan", nil, req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ApplyInventory 导入资源清单
+// 存在冲突时同时返回包含冲突项的计划和错误
+func (c *Client) ApplyInventory(ctx context.Context, req *dto.ImportInventoryReq) (*InventoryPlan, error) {
+	var plan InventoryPlan
+	err := c.do(ctx, http.MethodPost, apiPrefix+"/inventory/apply", nil, req, &plan)
+	if err == nil {
+		return &plan, nil
+	}
+
+	var apiErr *Error
+	if stderrors.As(err, &apiErr) && len(apiErr.Data) > 0 && string(apiErr.Data) != "null" {
+		if json.Unmarshal(apiErr.Data, &plan) == nil {
+			return &plan, err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"time"
+
+	"gost-panel/internal/dto"
+	"gost-panel/internal/model"
+	"gost-panel/pkg/gost"
+)
+
+// 资源类型，与面板接口返回的结构一致
+type (
+	// Node 节点
+	Node = model.GostNode
+	// Rule 转发规则
+	Rule = model.GostRule
+	// IPList 命名 IP 列表
+	IPList = model.IPList
+	// Tunnel 隧道
+	Tunnel = model.GostTunnel
+	// OperationLog 操作日志
+	OperationLog = model.OperationLog
+	// User 用户
+	User = model.User
+	// APIToken 个人 API Token
+	APIToken = model.APIToken
+	// NodeConfig 节点上的 GOST 配置
+	NodeConfig = gost.GostConfig
+	// DashboardStats 仪表盘统计
+	DashboardStats = dto.DashboardStats
+	// BackupFile 备份文件
+	BackupFile = dto.BackupFileResp
+	// InventoryPlan 资源清单导入计划
+	InventoryPlan = dto.InventoryPlan
+	// BatchResult 批量操作结果
+	BatchResult = dto.BatchResp
+	// NodePorts 节点端口池及占用情况
+	NodePorts = dto.NodePortsResp
+	// LatencyStats 探测路径的延迟统计
+	LatencyStats = dto.LatencyPathStats
+)
+
+// Page 分页数据
+type Page[T any] struct {
+	List     []T   `json:"list"`     // 数据列表
+	Total    int64 `json:"total"`    // 总数
+	Page     int   `json:"page"`     // 当前页
+	PageSize int   `json:"pageSize"` // 每页大小
+}
+
+// LoginResult 登录结果
+type LoginResult struct {
+	Token    string `json:"token"`     // JWT Token
+	ExpireAt int64  `json:"expire_at"` // 过期时间戳
+	User     *User  `json:"user"`      // 用户信息
+}
+
+// ExpireTime 过期时间
+func (r *LoginResult) ExpireTime() time.Time {
+	return time.Unix(r.ExpireAt, 0)
+}
+
+// CreatedAPIToken 新建的 API Token，Token 明文只在创建时返回一次
+type CreatedAPIToken struct {
+	Token    string    `json:"token"`     // Token 明文
+	APIToken *APIToken `json:"api_token"` // Token 信息
+}
+
+// Health 健康检查结果
+type Health struct {
+	Status    string `json:"status"`     // 状态
+	Version   string `json:"version"`    // 面板版本
+	BuildTime string `json:"build_time"` // 构建时间
+}
@@ -0,0 +1,177 @@
+// Package cron 提供标准 5 段 cron 表达式解析
+// 格式: 分 时 日 月 周，支持 *、数字、范围 a-b、列表 a,b、步长 */n 与 a-b/n，
+// 以及 @hourly、@daily、@weekly、@monthly 等预定义表达式
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldBounds 各字段取值范围
+var fieldBounds = [5]struct{ min, max int }{
+	{0, 59}, // 分
+	{0, 23}, // 时
+	{1, 31}, // 日
+	{1, 12}, // 月
+	{0, 6},  // 周 (0 为周日，7 也视为周日)
+}
+
+// macros 预定义表达式
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule 解析后的调度计划
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// 日和周均被限定时按 cron 惯例取并集
+	domStar, dowStar bool
+}
+
+// Parse 解析 cron 表达式
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(expr)]; ok {
+		expr = m
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 表达式需要 5 个字段，实际 %d 个: %q", len(fields), expr)
+	}
+
+	var bits [5]uint64
+	for i, f := range fields {
+		max := fieldBounds[i].max
+		if i == 4 {
+			max = 7 // 允许 7 表示周日
+		}
+		b, err := parseField(f, fieldBounds[i].min, max)
+		if err != nil {
+			return nil, fmt.Errorf("cron 字段 %q 无效: %v", f, err)
+		}
+		bits[i] = b
+	}
+
+	// 周字段 7 归一为 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseField 解析单个字段为位图
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("步长无效")
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("范围无效")
+			}
+		default:
+			v, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("数值无效")
+			}
+			lo = v
+			// 带步长的单个数值表示从该值到最大值，如 5/10
+			if !strings.Contains(part, "/") {
+				hi = v
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("超出范围 %d-%d", min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Matches 判断指定时间（精确到分钟）是否命中计划
+func (s *Schedule) Matches(t time.Time) bool {
+	return has(s.minute, t.Minute()) &&
+		has(s.hour, t.Hour()) &&
+		has(s.month, int(t.Month())) &&
+		s.dayMatches(t)
+}
+
+// Next 返回晚于 t 的下一次执行时间，5 年内无匹配时返回零值
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 判断日期是否匹配日、周字段
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// has 判断位图中是否包含指定值
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
@@ -0,0 +1,83 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"@every",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) 应返回错误", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-10-19 为周一
+	base := time.Date(2026, 10, 19, 10, 30, 15, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 19, 10, 45, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 10, 19, 10, 45, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 10, 20, 10, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{" @Weekly ", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 9 1 * *", time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)},
+		// 日和周均被限定时取并集
+		{"0 0 1,15 * 5", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// 永不命中
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) 失败: %v", c.expr, err)
+			continue
+		}
+		if got := s.Next(base); !got.Equal(c.want) {
+			t.Errorf("%q 下次执行 = %v，期望 %v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	s, err := Parse("0 0 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), true},  // 周一
+		{time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC), false}, // 周日
+		{time.Date(2026, 10, 19, 0, 1, 0, 0, time.UTC), false},
+	}
+	for _, c := range cases {
+		if got := s.Matches(c.at); got != c.want {
+			t.Errorf("Matches(%v) = %v，期望 %v", c.at, got, c.want)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"gost-panel/internal/errors"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"gost-panel/pkg/logger"
@@ -58,6 +60,8 @@ type ServiceConfig struct {
 	Observer  string           `json:"observer,omitempty"` // 观察器名称
 	Metadata  map[string]any   `json:"metadata,omitempty"` // 元数据配置
 	Status    *ServiceStatus   `json:"status,omitempty"`   // 服务运行状态
+
+	Admissions []string `json:"admissions,omitempty"` // 准入控制器名称，全部通过才接受连接
 }
 
 // ServiceStatus 服务运行时状态信息
@@ -72,14 +76,17 @@ type GostResponse struct {
 
 // HandlerConfig 处理器配置
 type HandlerConfig struct {
-	Type  string      `json:"type"`
-	Chain string      `json:"chain,omitempty"` // 链名称
-	Auth  *AuthConfig `json:"auth,omitempty"`
+	Type     string         `json:"type"`
+	Chain    string         `json:"chain,omitempty"` // 链名称
+	Auth     *AuthConfig    `json:"auth,omitempty"`
+	Auther   string         `json:"auther,omitempty"`   // 认证器名称
+	Metadata map[string]any `json:"metadata,omitempty"` // 元数据配置
 }
 
 // ListenerConfig 监听器配置
 type ListenerConfig struct {
 	Type     string         `json:"type"`
+	TLS      *TLSConfig     `json:"tls,omitempty"`      // TLS 证书配置
 	Metadata map[string]any `json:"metadata,omitempty"` // 元数据配置
 }
 
@@ -98,8 +105,15 @@ type SelectorConfig struct {
 
 // ForwarderNode 转发目标节点
 type ForwarderNode struct {
-	Name string `json:"name"`
-	Addr string `json:"addr"`
+	Name     string            `json:"name"`
+	Addr     string            `json:"addr"`
+	Filter   *NodeFilterConfig `json:"filter,omitempty"`   // 节点过滤，用于按域名选择目标
+	Metadata map[string]any    `json:"metadata,omitempty"` // 元数据配置（weight 权重、backup 备用节点）
+}
+
+// NodeFilterConfig 转发节点过滤配置
+type NodeFilterConfig struct {
+	Host string `json:"host,omitempty"` // 匹配的 HTTP Host / TLS SNI
 }
 
 // AuthConfig 认证配置
@@ -108,6 +122,12 @@ type AuthConfig struct {
 	Password string `json:"password,omitempty"`
 }
 
+// AutherConfig 认证器配置（多用户认证）
+type AutherConfig struct {
+	Name  string        `json:"name"`
+	Auths []*AuthConfig `json:"auths,omitempty"`
+}
+
 // ChainConfig 链配置
 type ChainConfig struct {
 	Name string       `json:"name"`
@@ -116,8 +136,9 @@ type ChainConfig struct {
 
 // HopConfig 跳配置
 type HopConfig struct {
-	Name  string        `json:"name"`
-	Nodes []*NodeConfig `json:"nodes,omitempty"`
+	Name     string          `json:"name"`
+	Selector *SelectorConfig `json:"selector,omitempty"`
+	Nodes    []*NodeConfig   `json:"nodes,omitempty"`
 }
 
 // NodeConfig 链节点配置
@@ -136,7 +157,18 @@ type ConnectorConfig struct {
 
 // DialerConfig 拨号器配置
 type DialerConfig struct {
-	Type string `json:"type"`
+	Type     string         `json:"type"`
+	TLS      *TLSConfig     `json:"tls,omitempty"`      // TLS 校验配置
+	Metadata map[string]any `json:"metadata,omitempty"` // 元数据配置
+}
+
+// TLSConfig TLS 配置，文件路径均为节点上的路径
+type TLSConfig struct {
+	CertFile   string `json:"certFile,omitempty"`
+	KeyFile    string `json:"keyFile,omitempty"`
+	CAFile     string `json:"caFile,omitempty"`
+	Secure     bool   `json:"secure,omitempty"`
+	ServerName string `json:"serverName,omitempty"`
 }
 
 // LimiterConfig 流量速率限制器配置
@@ -167,6 +199,15 @@ type RLimiterConfig struct {
 	Plugin *PluginConfig `json:"plugin,omitempty"` // 插件配置
 }
 
+// AdmissionConfig 准入控制器配置
+// whitelist 为 true 时只接受匹配的来源，否则拒绝匹配的来源
+// matchers 为 IP 或 CIDR，如 "192.168.1.1"、"10.0.0.0/8"
+type AdmissionConfig struct {
+	Name      string   `json:"name"`
+	Whitelist bool     `json:"whitelist,omitempty"` // 白名单模式
+	Matchers  []string `json:"matchers,omitempty"`  // 匹配规则列表
+}
+
 // ObserverConfig 观察器配置
 type ObserverConfig struct {
 	Name   string        `json:"name"`
@@ -307,6 +348,27 @@ func (c *Client) DeleteService(name string) error {
 	return nil
 }
 
+// ApplyService 创建或更新服务，已存在时整体替换配置
+func (c *Client) ApplyService(svc *ServiceConfig) error {
+	path := fmt.Sprintf("/config/services/%s", svc.Name)
+	if !c.exists(path) {
+		return c.CreateService(svc)
+	}
+
+	resp, err := c.doRequest("PUT", path, svc)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("更新服务失败: %s", string(body))
+	}
+
+	return nil
+}
+
 // CreateChain 创建链 (幂等)
 func (c *Client) CreateChain(chain *ChainConfig) error {
 	path := fmt.Sprintf("/config/chains/%s", chain.Name)
@@ -351,6 +413,27 @@ func (c *Client) DeleteChain(name string) error {
 	return nil
 }
 
+// ApplyChain 创建或更新链，引用该链的服务在新建连接时使用新配置
+func (c *Client) ApplyChain(chain *ChainConfig) error {
+	path := fmt.Sprintf("/config/chains/%s", chain.Name)
+	if !c.exists(path) {
+		return c.CreateChain(chain)
+	}
+
+	resp, err := c.doRequest("PUT", path, chain)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("更新链失败: %s", string(body))
+	}
+
+	return nil
+}
+
 // doRequest 执行 HTTP 请求
 func (c *Client) doRequest(method, path string, body interface{}) (*http.Response, error) {
 	var reqBody io.Reader
@@ -459,16 +542,165 @@ func BuildUDPForwardService(name string, listenPort int, targets []string, strat
 // BuildFullForwardService 构建 TCP+UDP 全流量转发服务配置
 // 同时监听 TCP 和 UDP，适用于需要双协议支持的场景（如游戏服务器、DNS 等）
 func BuildFullForwardService(name string, listenPort int, targets []string, strategy string) []*ServiceConfig {
+	return BuildForwardServices(name, listenPort, targets, strategy, []string{"tcp", "udp"})
+}
+
+// BuildForwardServices 按协议构建转发服务配置，protocols 为 tcp、udp 的组合
+// 每个协议一个服务，服务名为 name 追加协议后缀
+func BuildForwardServices(name string, listenPort int, targets []string, strategy string, protocols []string) []*ServiceConfig {
 	// 默认策略
 	if strategy == "" {
 		strategy = "round"
 	}
 
-	// 创建 TCP 和 UDP 两个服务
-	tcpService := BuildTCPForwardService(name+"-tcp", listenPort, targets, strategy)
-	udpService := BuildUDPForwardService(name+"-udp", listenPort, targets, strategy)
+	services := make([]*ServiceConfig, 0, len(protocols))
+	for _, protocol := range protocols {
+		switch protocol {
+		case "tcp":
+			services = append(services, BuildTCPForwardService(name+"-tcp", listenPort, targets, strategy))
+		case "udp":
+			services = append(services, BuildUDPForwardService(name+"-udp", listenPort, targets, strategy))
+		}
+	}
+	return services
+}
+
+// TargetOption 转发目标的权重与备用设置
+type TargetOption struct {
+	Weight int  // 权重，0 为默认
+	Backup bool // 备用节点：其他节点全部失效时才使用
+}
+
+// ApplySelector 设置服务转发器的失败判定，以及各目标节点的权重与备用标记
+// options 按目标顺序对应转发节点 target-{i}，端口范围规则的每个服务同样适用
+func ApplySelector(services []*ServiceConfig, maxFails int, failTimeout time.Duration, options []TargetOption) {
+	for _, svc := range services {
+		if svc.Forwarder == nil {
+			continue
+		}
+		if svc.Forwarder.Selector == nil {
+			svc.Forwarder.Selector = &SelectorConfig{Strategy: "round"}
+		}
+		svc.Forwarder.Selector.MaxFails = maxFails
+		svc.Forwarder.Selector.FailTimeout = failTimeout
+
+		for i, node := range svc.Forwarder.Nodes {
+			if i >= len(options) {
+				break
+			}
+			opt := options[i]
+			if opt.Weight <= 0 && !opt.Backup {
+				continue
+			}
+			if node.Metadata == nil {
+				node.Metadata = make(map[string]any)
+			}
+			if opt.Weight > 0 {
+				node.Metadata["weight"] = opt.Weight
+			}
+			if opt.Backup {
+				node.Metadata["backup"] = true
+			}
+		}
+	}
+}
+
+// RangeServiceName 端口范围规则中单个端口的服务名，TCP/UDP 服务再追加协议后缀
+func RangeServiceName(name string, port int) string {
+	return fmt.Sprintf("%s-p%d", name, port)
+}
+
+// OffsetTarget 将目标地址的端口偏移 offset，用于端口范围 1:1 映射
+func OffsetTarget(target string, offset int) (string, error) {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return "", fmt.Errorf("目标地址无效: %s", target)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port+offset < 1 || port+offset > 65535 {
+		return "", fmt.Errorf("目标端口超出范围: %s (+%d)", target, offset)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port+offset)), nil
+}
+
+// BuildRangeForwardServices 按协议构建端口范围的转发服务配置
+// 每个监听端口一组服务，服务名见 RangeServiceName；
+// oneToOne 为 true 时目标端口随监听端口偏移（1:1 映射），否则全部转发到目标地址中的端口
+func BuildRangeForwardServices(name string, startPort, endPort int, targets []string, strategy string, oneToOne bool, protocols []string) ([]*ServiceConfig, error) {
+	services := make([]*ServiceConfig, 0, (endPort-startPort+1)*len(protocols))
+	for port := startPort; port <= endPort; port++ {
+		portTargets := targets
+		if oneToOne {
+			portTargets = make([]string, len(targets))
+			for i, target := range targets {
+				mapped, err := OffsetTarget(target, port-startPort)
+				if err != nil {
+					return nil, err
+				}
+				portTargets[i] = mapped
+			}
+		}
+		services = append(services, BuildForwardServices(RangeServiceName(name, port), port, portTargets, strategy, protocols)...)
+	}
+	return services, nil
+}
+
+// BuildProxyService 构建代理服务配置
+// proxyType 为 GOST 处理器类型（http、socks5、auto），auther 为认证器名称
+func BuildProxyService(name string, listenPort int, proxyType, auther string) *ServiceConfig {
+	return &ServiceConfig{
+		Name: name,
+		Addr: fmt.Sprintf(":%d", listenPort),
+		Handler: &HandlerConfig{
+			Type:   proxyType,
+			Auther: auther,
+		},
+		Listener: &ListenerConfig{
+			Type: "tcp",
+		},
+	}
+}
+
+// HostRoute 共享监听服务中的一条域名路由
+type HostRoute struct {
+	Name string // 转发节点名称
+	Host string // 匹配的域名，支持 *.example.com
+	Addr string // 转发地址
+}
 
-	return []*ServiceConfig{tcpService, udpService}
+// HostRouteServiceName 节点上监听 port 的共享域名分流服务名
+func HostRouteServiceName(port int) string {
+	return fmt.Sprintf("vhost-%d", port)
+}
+
+// BuildHostRouteService 构建按域名分流的共享监听服务配置
+// TCP 处理器开启 sniffing，嗅探 HTTP Host 与 TLS SNI，按转发节点的 host 过滤选择目标
+func BuildHostRouteService(port int, routes []HostRoute) *ServiceConfig {
+	nodes := make([]*ForwarderNode, 0, len(routes))
+	for _, route := range routes {
+		nodes = append(nodes, &ForwarderNode{
+			Name:   route.Name,
+			Addr:   route.Addr,
+			Filter: &NodeFilterConfig{Host: route.Host},
+		})
+	}
+
+	return &ServiceConfig{
+		Name: HostRouteServiceName(port),
+		Addr: fmt.Sprintf(":%d", port),
+		Handler: &HandlerConfig{
+			Type: "tcp",
+			Metadata: map[string]any{
+				"sniffing": true,
+			},
+		},
+		Listener: &ListenerConfig{
+			Type: "tcp",
+		},
+		Forwarder: &ForwarderConfig{
+			Nodes: nodes,
+		},
+	}
 }
 
 // CreateLimiter 创建限流器 (幂等)
@@ -645,3 +877,166 @@ func (c *Client) DeleteRLimiter(name string) error {
 
 	return nil
 }
+
+// CreateAuther 创建认证器 (幂等)
+func (c *Client) CreateAuther(auther *AutherConfig) error {
+	path := fmt.Sprintf("/config/authers/%s", auther.Name)
+	if c.exists(path) {
+		logger.Debugf("认证器 %s 已存在，跳过创建", auther.Name)
+		return nil
+	}
+
+	resp, err := c.doRequest("POST", "/config/authers", auther)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("创建认证器失败: %s", string(body))
+	}
+
+	return nil
+}
+
+// ApplyAuther 创建或更新认证器，已引用它的服务立即按新用户生效
+func (c *Client) ApplyAuther(auther *AutherConfig) error {
+	path := fmt.Sprintf("/config/authers/%s", auther.Name)
+	if !c.exists(path) {
+		return c.CreateAuther(auther)
+	}
+
+	resp, err := c.doRequest("PUT", path, auther)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("更新认证器失败: %s", string(body))
+	}
+
+	return nil
+}
+
+// DeleteAuther 删除认证器 (幂等)
+func (c *Client) DeleteAuther(name string) error {
+	path := fmt.Sprintf("/config/authers/%s", name)
+	if !c.exists(path) {
+		logger.Debugf("认证器 %s 不存在，跳过删除", name)
+		return nil
+	}
+
+	resp, err := c.doRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("删除认证器失败: %s", string(body))
+	}
+
+	return nil
+}
+
+// CreateAdmission 创建准入控制器 (幂等)
+func (c *Client) CreateAdmission(admission *AdmissionConfig) error {
+	path := fmt.Sprintf("/config/admissions/%s", admission.Name)
+	if c.exists(path) {
+		logger.Debugf("准入控制器 %s 已存在，跳过创建", admission.Name)
+		return nil
+	}
+
+	resp, err := c.doRequest("POST", "/config/admissions", admission)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("创建准入控制器失败: %s", string(body))
+	}
+
+	return nil
+}
+
+// GetAdmission 获取准入控制器配置，不存在时返回 nil
+func (c *Client) GetAdmission(name string) (*AdmissionConfig, error) {
+	resp, err := c.doRequest("GET", fmt.Sprintf("/config/admissions/%s", name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("获取准入控制器失败: %s", string(body))
+	}
+
+	var gResp GostResponse
+	if err = json.NewDecoder(resp.Body).Decode(&gResp); err != nil {
+		return nil, fmt.Errorf("解析准入控制器失败: %v", err)
+	}
+	if len(gResp.Data) == 0 || string(gResp.Data) == "null" {
+		return nil, nil
+	}
+	var admission AdmissionConfig
+	if err = json.Unmarshal(gResp.Data, &admission); err != nil {
+		return nil, fmt.Errorf("解析准入控制器失败: %v", err)
+	}
+	return &admission, nil
+}
+
+// UpdateAdmission 更新准入控制器，已引用它的服务立即按新规则生效
+func (c *Client) UpdateAdmission(admission *AdmissionConfig) error {
+	resp, err := c.doRequest("PUT", fmt.Sprintf("/config/admissions/%s", admission.Name), admission)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("更新准入控制器失败: %s", string(body))
+	}
+
+	return nil
+}
+
+// ApplyAdmission 创建或更新准入控制器
+func (c *Client) ApplyAdmission(admission *AdmissionConfig) error {
+	if !c.exists(fmt.Sprintf("/config/admissions/%s", admission.Name)) {
+		return c.CreateAdmission(admission)
+	}
+	return c.UpdateAdmission(admission)
+}
+
+// DeleteAdmission 删除准入控制器 (幂等)
+func (c *Client) DeleteAdmission(name string) error {
+	path := fmt.Sprintf("/config/admissions/%s", name)
+	if !c.exists(path) {
+		logger.Debugf("准入控制器 %s 不存在，跳过删除", name)
+		return nil
+	}
+
+	resp, err := c.doRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("删除准入控制器失败: %s", string(body))
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"gost-panel/internal/errors"
 	"gost-panel/pkg/logger"
 	"net/http"
+	"reflect"
 
 	"github.com/gin-gonic/gin"
 )
@@ -43,8 +44,43 @@ const (
 	MsgInternalError = "服务器内部错误"
 )
 
+// MaskCredentialsKey 上下文标记，设置后成功响应中隐藏认证信息（API Token 请求）
+const MaskCredentialsKey = "maskCredentials"
+
+// CredentialMasker 可隐藏认证信息的响应数据
+type CredentialMasker interface {
+	MaskCredentials()
+}
+
+// maskCredentials 请求带有 MaskCredentialsKey 标记时，隐藏数据或列表元素中的认证信息
+func maskCredentials(c *gin.Context, data interface{}) {
+	if !c.GetBool(MaskCredentialsKey) || data == nil {
+		return
+	}
+	if m, ok := data.(CredentialMasker); ok {
+		m.MaskCredentials()
+		return
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() != reflect.Pointer {
+			elem = elem.Addr()
+		} else if elem.IsNil() {
+			continue
+		}
+		if m, ok := elem.Interface().(CredentialMasker); ok {
+			m.MaskCredentials()
+		}
+	}
+}
+
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
+	maskCredentials(c, data)
 	c.JSON(http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: MsgSuccess,
@@ -54,6 +90,7 @@ func Success(c *gin.Context, data interface{}) {
 
 // SuccessWithMessage 成功响应（自定义消息）
 func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
+	maskCredentials(c, data)
 	c.JSON(http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: message,
@@ -63,6 +100,7 @@ func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
 
 // SuccessPage 分页成功响应
 func SuccessPage(c *gin.Context, list interface{}, total int64, page, pageSize int) {
+	maskCredentials(c, list)
 	c.JSON(http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: MsgSuccess,
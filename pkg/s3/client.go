@@ -0,0 +1,341 @@
+// Package s3 提供 S3 兼容对象存储的精简客户端
+// 仅实现备份所需的对象上传、下载、列举和删除，使用 AWS Signature V4 签名，
+// 兼容 AWS S3、MinIO 以及其他 S3 兼容服务
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config 客户端配置
+type Config struct {
+	Endpoint  string // 服务地址，如 https://s3.amazonaws.com 或 http://127.0.0.1:9000
+	Region    string // 区域，默认 us-east-1
+	Bucket    string // 存储桶
+	AccessKey string // Access Key
+	SecretKey string // Secret Key
+	PathStyle bool   // 是否使用路径风格访问（MinIO 需开启）
+	Timeout   time.Duration
+}
+
+// Client S3 客户端
+type Client struct {
+	scheme     string
+	host       string
+	region     string
+	bucket     string
+	accessKey  string
+	secretKey  string
+	pathStyle  bool
+	httpClient *http.Client
+}
+
+// Object 对象信息
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// Error S3 返回的错误
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("S3 请求失败，状态码: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("S3 请求失败 (%d %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// NewClient 创建 S3 客户端
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 地址和存储桶不能为空")
+	}
+
+	endpoint := cfg.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("无效的 S3 地址: %s", cfg.Endpoint)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	return &Client{
+		scheme:    u.Scheme,
+		host:      u.Host,
+		region:    region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+// PutObject 上传对象
+func (c *Client) PutObject(key string, body io.ReadSeeker, size int64) error {
+	resp, err := c.doRequest(http.MethodPut, key, nil, body, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// GetObject 下载对象，调用方负责关闭返回的 ReadCloser
+func (c *Client) GetObject(key string) (io.ReadCloser, error) {
+	resp, err := c.doRequest(http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DeleteObject 删除对象
+func (c *Client) DeleteObject(key string) error {
+	resp, err := c.doRequest(http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// listBucketResult ListObjectsV2 响应
+type listBucketResult struct {
+	Contents              []Object `xml:"Contents"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// ListObjects 列举指定前缀下的所有对象
+func (c *Client) ListObjects(prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.doRequest(http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkResponse(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析对象列表失败: %v", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// checkResponse 检查响应状态码，失败时解析错误信息
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	s3Err := &Error{StatusCode: resp.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = xml.Unmarshal(body, s3Err)
+	return s3Err
+}
+
+// doRequest 构建、签名并发送请求
+func (c *Client) doRequest(method, key string, query url.Values, body io.ReadSeeker, size int64) (*http.Response, error) {
+	host := c.host
+	path := "/"
+	if c.pathStyle {
+		path += c.bucket + "/"
+	} else {
+		host = c.bucket + "." + c.host
+	}
+	path += strings.TrimPrefix(key, "/")
+
+	payloadHash, err := hashPayload(body)
+	if err != nil {
+		return nil, err
+	}
+
+	rawQuery := canonicalQuery(query)
+	u := &url.URL{
+		Scheme:   c.scheme,
+		Host:     host,
+		Path:     path,
+		RawPath:  encodePath(path),
+		RawQuery: rawQuery,
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = body
+	}
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	req.Host = host
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	c.sign(req, host, encodePath(path), rawQuery, payloadHash, now)
+	return c.httpClient.Do(req)
+}
+
+// sign 使用 AWS Signature V4 为请求签名
+func (c *Client) sign(req *http.Request, host, canonicalURI, rawQuery, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		rawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.secretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, c.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// hashPayload 计算请求体的 SHA256，并将读取位置重置到开头
+func hashPayload(body io.ReadSeeker) (string, error) {
+	if body == nil {
+		return hexSHA256(nil), nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalQuery 生成规范化查询字符串（按键排序，RFC 3986 编码）
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodePath 编码对象路径（保留 /）
+func encodePath(path string) string {
+	return uriEncode(path, false)
+}
+
+// uriEncode 按 SigV4 规则进行 URI 编码
+func uriEncode(s string, encodeSlash bool) string {
+	var buf bytes.Buffer
+	for _, b := range []byte(s) {
+		switch {
+		case (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9'),
+			b == '-', b == '_', b == '.', b == '~':
+			buf.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			buf.WriteByte(b)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", b)
+		}
+	}
+	return buf.String()
+}
+
+// hexSHA256 计算 SHA256 十六进制摘要
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
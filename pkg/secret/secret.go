@@ -0,0 +1,129 @@
+// Package secret 提供基于口令的对称加解密
+// 使用 scrypt 从口令派生密钥，AES-256-GCM 进行加密
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+
+	// stringPrefix 加密字符串前缀，便于识别已加密字段
+	stringPrefix = "enc:v1:"
+)
+
+// 错误定义
+var (
+	ErrEmptyPassphrase = errors.New("口令不能为空")
+	ErrCiphertextShort = errors.New("密文长度不足")
+	ErrDecryptFailed   = errors.New("解密失败，口令错误或数据已损坏")
+)
+
+// deriveKey 从口令和盐派生密钥
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keySize)
+}
+
+// Encrypt 使用口令加密数据
+// 输出格式: salt(16) | nonce(12) | ciphertext
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, saltSize+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+// Decrypt 使用口令解密由 Encrypt 生成的数据
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	if len(data) < saltSize {
+		return nil, ErrCiphertextShort
+	}
+
+	gcm, err := newGCM(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	rest := data[saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrCiphertextShort
+	}
+
+	plaintext, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
+
+// EncryptString 加密字符串，返回带前缀的 base64 文本
+func EncryptString(plaintext, passphrase string) (string, error) {
+	data, err := Encrypt([]byte(plaintext), passphrase)
+	if err != nil {
+		return "", err
+	}
+	return stringPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptString 解密由 EncryptString 生成的文本
+func DecryptString(text, passphrase string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(text, stringPrefix))
+	if err != nil {
+		return "", ErrDecryptFailed
+	}
+	plaintext, err := Decrypt(data, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncryptedString 判断字符串是否为 EncryptString 的输出
+func IsEncryptedString(text string) bool {
+	return strings.HasPrefix(text, stringPrefix)
+}
+
+// newGCM 创建 AES-GCM 实例
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
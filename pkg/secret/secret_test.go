@@ -0,0 +1,180 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	cases := [][]byte{
+		{},
+		[]byte("gost-panel"),
+		bytes.Repeat([]byte("节点密码"), 1024),
+	}
+	for _, plaintext := range cases {
+		data, err := Encrypt(plaintext, "pass")
+		if err != nil {
+			t.Fatalf("Encrypt 失败: %v", err)
+		}
+		got, err := Decrypt(data, "pass")
+		if err != nil {
+			t.Fatalf("Decrypt 失败: %v", err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("解密结果不一致: %q", got)
+		}
+	}
+}
+
+func TestEncryptRandomized(t *testing.T) {
+	a, err := Encrypt([]byte("same"), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encrypt([]byte("same"), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Error("相同明文两次加密结果相同，盐或 nonce 未随机")
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	data, err := Encrypt([]byte("gost-panel"), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 0xff
+
+	cases := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		want       error
+	}{
+		{"口令错误", data, "wrong", ErrDecryptFailed},
+		{"数据被篡改", tampered, "pass", ErrDecryptFailed},
+		{"口令为空", data, "", ErrEmptyPassphrase},
+		{"长度不足", data[:saltSize-1], "pass", ErrCiphertextShort},
+		{"缺少 nonce", data[:saltSize+4], "pass", ErrCiphertextShort},
+	}
+	for _, c := range cases {
+		if _, err := Decrypt(c.data, c.passphrase); !errors.Is(err, c.want) {
+			t.Errorf("%s: err = %v，期望 %v", c.name, err, c.want)
+		}
+	}
+
+	if _, err := Encrypt([]byte("x"), ""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("空口令加密: err = %v，期望 ErrEmptyPassphrase", err)
+	}
+}
+
+func TestEncryptString(t *testing.T) {
+	text, err := EncryptString("secret", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedString(text) {
+		t.Errorf("IsEncryptedString(%q) = false", text)
+	}
+	if IsEncryptedString("secret") {
+		t.Error("明文被识别为密文")
+	}
+
+	got, err := DecryptString(text, "pass")
+	if err != nil || got != "secret" {
+		t.Errorf("DecryptString = %q, %v", got, err)
+	}
+	if _, err = DecryptString(text, "wrong"); !errors.Is(err, ErrDecryptFailed) {
+		t.Errorf("口令错误: err = %v，期望 ErrDecryptFailed", err)
+	}
+	if _, err = DecryptString("enc:v1:!!!", "pass"); !errors.Is(err, ErrDecryptFailed) {
+		t.Errorf("非法 base64: err = %v，期望 ErrDecryptFailed", err)
+	}
+}
+
+// encryptStream 使用流式加密写入器分多次写入 plaintext
+func encryptStream(t *testing.T, plaintext []byte, passphrase string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, passphrase)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter 失败: %v", err)
+	}
+	for len(plaintext) > 0 {
+		n := min(len(plaintext), 1000)
+		if _, err = w.Write(plaintext[:n]); err != nil {
+			t.Fatalf("Write 失败: %v", err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close 失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// decryptStream 读取流式密文的全部明文
+func decryptStream(data []byte, passphrase string) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(data), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 17} {
+		plaintext := bytes.Repeat([]byte{'g'}, size)
+		data := encryptStream(t, plaintext, "pass")
+		got, err := decryptStream(data, "pass")
+		if err != nil {
+			t.Fatalf("大小 %d: 解密失败: %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("大小 %d: 解密结果不一致，长度 %d", size, len(got))
+		}
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("gost"), 3*streamChunkSize/4)
+	data := encryptStream(t, plaintext, "pass")
+	chunk := streamChunkSize + 16
+	header := saltSize + streamPrefixSize
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 0xff
+	// 交换前两块
+	swapped := bytes.Clone(data)
+	copy(swapped[header:], data[header+chunk:header+2*chunk])
+	copy(swapped[header+chunk:], data[header:header+chunk])
+
+	cases := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		want       error
+	}{
+		{"口令错误", data, "wrong", ErrDecryptFailed},
+		{"数据被篡改", tampered, "pass", ErrDecryptFailed},
+		{"在块边界截断", data[:header+chunk], "pass", ErrDecryptFailed},
+		{"在块中间截断", data[:header+chunk+100], "pass", ErrDecryptFailed},
+		{"块顺序被调换", swapped, "pass", ErrDecryptFailed},
+		{"缺少文件头", data[:header-1], "pass", ErrCiphertextShort},
+		{"口令为空", data, "", ErrEmptyPassphrase},
+	}
+	for _, c := range cases {
+		if _, err := decryptStream(c.data, c.passphrase); !errors.Is(err, c.want) {
+			t.Errorf("%s: err = %v，期望 %v", c.name, err, c.want)
+		}
+	}
+
+	if _, err := NewEncryptWriter(io.Discard, ""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("空口令加密: err = %v，期望 ErrEmptyPassphrase", err)
+	}
+}
@@ -0,0 +1,197 @@
+package secret
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// 流式加密格式: salt(16) | nonce 前缀(7) | 密文块...
+// 明文按 streamChunkSize 分块，每块单独加密并附带认证标签；
+// nonce = 前缀(7) | 块序号(4, 大端) | 末块标记(1)，块被调换、删除或文件被截断时解密失败
+const (
+	streamChunkSize  = 64 * 1024
+	streamPrefixSize = 7
+)
+
+// ErrStreamTooLong 流式加密的数据超过块序号上限
+var ErrStreamTooLong = errors.New("加密数据过大")
+
+// encryptWriter 分块加密写入器
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	seq    uint32
+	buf    []byte // 待加密的明文
+	out    []byte // 密文缓冲
+	closed bool
+}
+
+// NewEncryptWriter 返回流式加密写入器，写入的数据加密后输出到 w
+// 必须调用 Close 写入末块，Close 不会关闭 w
+func NewEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	header := make([]byte, saltSize+streamPrefixSize)
+	if _, err := io.ReadFull(rand.Reader, header); err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(passphrase, header[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		prefix: header[saltSize:],
+		buf:    make([]byte, 0, streamChunkSize),
+		out:    make([]byte, 0, streamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Write 缓存明文，凑满一块且还有后续数据时才加密输出，保证末块在 Close 时写入
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, io.ErrClosedPipe
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(e.buf) == streamChunkSize {
+			if err := e.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := min(len(p), streamChunkSize-len(e.buf))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close 加密并写入末块
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.flush(true)
+}
+
+// flush 加密当前缓存的明文块
+func (e *encryptWriter) flush(last bool) error {
+	if e.seq == ^uint32(0) {
+		return ErrStreamTooLong
+	}
+	e.out = e.aead.Seal(e.out[:0], streamNonce(e.prefix, e.seq, last), e.buf, nil)
+	if _, err := e.w.Write(e.out); err != nil {
+		return err
+	}
+	e.seq++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader 分块解密读取器
+type decryptReader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	seq    uint32
+	buf    []byte // 当前密文块，解密后原地存放明文
+	plain  []byte // 未读取的明文
+	done   bool
+	err    error
+}
+
+// NewDecryptReader 返回流式解密读取器，读取由 NewEncryptWriter 生成的数据
+// 创建时即解密第一块，口令错误时立即返回 ErrDecryptFailed
+func NewDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	header := make([]byte, saltSize+streamPrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrCiphertextShort
+	}
+	aead, err := newGCM(passphrase, header[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decryptReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		prefix: header[saltSize:],
+		buf:    make([]byte, streamChunkSize+aead.Overhead()),
+	}
+	if err = d.next(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Read 读取解密后的明文
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一块，不足一块或其后没有数据时按末块解密
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.buf)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err = d.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := d.aead.Open(d.buf[:0], streamNonce(d.prefix, d.seq, last), d.buf[:n], nil)
+	if err != nil {
+		return ErrDecryptFailed
+	}
+	d.seq++
+	d.plain = plain
+	d.done = last
+	return nil
+}
+
+// streamNonce 生成块的 nonce
+func streamNonce(prefix []byte, seq uint32, last bool) []byte {
+	nonce := make([]byte, streamPrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamPrefixSize:], seq)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,43 @@
+import request from '@/utils/request'
+
+/**
+ * 获取 IP 列表
+ */
+export function getIPLists() {
+    return request({
+        url: '/ip-lists',
+        method: 'get'
+    })
+}
+
+/**
+ * 创建 IP 列表
+ */
+export function createIPList(data) {
+    return request({
+        url: '/ip-lists',
+        method: 'post',
+        data
+    })
+}
+
+/**
+ * 更新 IP 列表
+ */
+export function updateIPList(id, data) {
+    return request({
+        url: `/ip-lists/${id}`,
+        method: 'put',
+        data
+    })
+}
+
+/**
+ * 删除 IP 列表
+ */
+export function deleteIPList(id) {
+    return request({
+        url: `/ip-lists/${id}`,
+        method: 'delete'
+    })
+}
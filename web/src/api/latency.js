@@ -0,0 +1,12 @@
+import request from '@/utils/request'
+
+/**
+ * 获取延迟探测统计
+ */
+export function getLatencyStats(params) {
+    return request({
+        url: '/latency',
+        method: 'get',
+        params
+    })
+}
@@ -71,4 +71,13 @@ export function stopRule(id) {
         url: `/rules/${id}/stop`,
         method: 'post'
     })
-}
\ No newline at end of file
+}
+/**
+ * 获取规则转发目标状态
+ */
+export function getRuleTargets(id) {
+    return request({
+        url: `/rules/${id}/targets`,
+        method: 'get'
+    })
+}
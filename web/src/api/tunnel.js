@@ -63,6 +63,16 @@ export function startTunnel(id) {
     })
 }
 
+/**
+ * 轮换隧道 Relay 认证凭据
+ */
+export function rotateTunnelCredentials(id) {
+    return request({
+        url: `/tunnels/${id}/rotate-credentials`,
+        method: 'post'
+    })
+}
+
 /**
  * 停止隧道
  */
@@ -34,12 +34,24 @@ const routes = [
                 component: () => import('@/views/Rules.vue'),
                 meta: { title: '规则管理', icon: 'Switch' }
             },
+            {
+                path: 'ip-lists',
+                name: 'IPLists',
+                component: () => import('@/views/IPLists.vue'),
+                meta: { title: 'IP 列表', icon: 'List' }
+            },
             {
                 path: 'tunnels',
                 name: 'Tunnels',
                 component: () => import('@/views/Tunnels.vue'),
                 meta: { title: '隧道管理', icon: 'Connection' }
             },
+            {
+                path: 'latency',
+                name: 'Latency',
+                component: () => import('@/views/Latency.vue'),
+                meta: { title: '延迟探测', icon: 'Timer' }
+            },
             {
                 path: 'logs',
                 name: 'Logs',